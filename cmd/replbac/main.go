@@ -38,6 +38,6 @@ func main() {
 	// Execute command with context
 	if err := cmd.ExecuteWithContext(ctx); err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-		os.Exit(1)
+		os.Exit(cmd.ExitCode(err))
 	}
 }
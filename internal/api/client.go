@@ -10,7 +10,9 @@ import (
 	"net/http"
 	"net/url"
 	"strings"
+	"sync"
 	"time"
+	"unicode"
 
 	"replbac/internal/logging"
 	"replbac/internal/models"
@@ -45,11 +47,48 @@ type Client struct {
 	httpClient *http.Client
 	logger     *logging.Logger
 	maxRetries int
+	apiVersion string
+
+	tokenMu sync.RWMutex   // Guards apiToken and refresh
+	refresh TokenRefresher // Obtains a new token when the API rejects apiToken, if set
+}
+
+// SupportedAPIVersions lists the vendor API versions the client can talk to
+var SupportedAPIVersions = []string{models.DefaultAPIVersion}
+
+// ValidateAPIVersion returns an error if version is not a supported vendor API version
+func ValidateAPIVersion(version string) error {
+	for _, supported := range SupportedAPIVersions {
+		if version == supported {
+			return nil
+		}
+	}
+	return fmt.Errorf("unsupported API version %q (supported: %s)", version, strings.Join(SupportedAPIVersions, ", "))
 }
 
+// DefaultMaxRetries is the number of times NewClient retries a failed request, with
+// exponential backoff starting at one second
+const DefaultMaxRetries = 3
+
 // NewClient creates a new API client with the given base URL and API token
 func NewClient(baseURL, apiToken string, logger *logging.Logger) (*Client, error) {
-	return NewClientWithRetry(baseURL, apiToken, logger, 3)
+	return NewClientWithRetry(baseURL, apiToken, logger, DefaultMaxRetries)
+}
+
+// normalizeToken trims the whitespace around token, such as the trailing newline of a
+// token file, and rejects tokens that are empty or contain control characters, which
+// would corrupt the Authorization header and fail every request with an opaque 401
+func normalizeToken(token string) (string, error) {
+	token = strings.TrimSpace(token)
+	if token == "" {
+		return "", fmt.Errorf("API token is required")
+	}
+	for i, r := range token {
+		if unicode.IsControl(r) {
+			return "", fmt.Errorf("API token contains a control character (%q) at position %d; check it for line breaks pasted into the middle", r, i)
+		}
+	}
+	return token, nil
 }
 
 // NewClientWithRetry creates a new API client with configurable retry logic
@@ -61,27 +100,76 @@ func NewClientWithRetry(baseURL, apiToken string, logger *logging.Logger, maxRet
 	}
 
 	// Validate API token
-	if strings.TrimSpace(apiToken) == "" {
-		return nil, fmt.Errorf("API token is required")
+	apiToken, err = normalizeToken(apiToken)
+	if err != nil {
+		return nil, err
 	}
 
 	logger.Debug("creating API client for endpoint: %s", baseURL)
 
+	httpClient := &http.Client{
+		Timeout: 30 * time.Second,
+	}
+	if logger.HTTPTraceEnabled() {
+		logger.Debug("HTTP request/response tracing enabled")
+		httpClient.Transport = newTraceTransport(http.DefaultTransport, logger, apiToken)
+	}
+
 	return &Client{
-		baseURL:  strings.TrimSuffix(baseURL, "/"),
-		apiToken: apiToken,
-		httpClient: &http.Client{
-			Timeout: 30 * time.Second,
-		},
+		baseURL:    strings.TrimSuffix(baseURL, "/"),
+		apiToken:   apiToken,
+		httpClient: httpClient,
 		logger:     logger,
 		maxRetries: maxRetries,
+		apiVersion: models.DefaultAPIVersion,
 	}, nil
 }
 
+// SetAPIVersion selects the vendor API version used for role and invitation requests.
+// An empty version selects the default.
+func (c *Client) SetAPIVersion(version string) error {
+	if version == "" {
+		version = models.DefaultAPIVersion
+	}
+	if err := ValidateAPIVersion(version); err != nil {
+		return err
+	}
+	c.apiVersion = version
+	return nil
+}
+
+// vendorURL returns the URL of a vendor API path under the configured API version.
+// Team member endpoints and invitation deletion only exist in v1 and are not versioned.
+func (c *Client) vendorURL(path string) string {
+	return c.baseURL + "/vendor/" + c.apiVersion + path
+}
+
+// policiesURL returns the URL for listing policies, or for the policy with the given ID
+func (c *Client) policiesURL(id string) string {
+	if id == "" {
+		return c.vendorURL("/policies")
+	}
+	return c.vendorURL("/policies/" + id)
+}
+
+// policyURL returns the URL for creating a policy, or for the policy with the given ID
+func (c *Client) policyURL(id string) string {
+	if id == "" {
+		return c.vendorURL("/policy")
+	}
+	return c.vendorURL("/policy/" + id)
+}
+
 // executeWithRetry performs HTTP requests with exponential backoff retry logic
 func (c *Client) executeWithRetry(ctx context.Context, req *http.Request) (*http.Response, error) {
 	var lastErr error
 
+	// A body can only be read once, so capture it to give every attempt the full payload
+	getBody, err := replayableBody(req)
+	if err != nil {
+		return nil, err
+	}
+
 	for attempt := 0; attempt <= c.maxRetries; attempt++ {
 		// Check if context was cancelled
 		select {
@@ -102,13 +190,28 @@ func (c *Client) executeWithRetry(ctx context.Context, req *http.Request) (*http
 			}
 		}
 
-		// Clone request for retry (can't reuse request body)
+		// Clone request for retry with a fresh reader over the body and the current token,
+		// which an earlier attempt may have refreshed
 		reqClone := req.Clone(ctx)
+		reqClone.Header.Set("Authorization", c.token())
+		if getBody != nil {
+			body, err := getBody()
+			if err != nil {
+				return nil, fmt.Errorf("failed to rewind request body: %w", err)
+			}
+			reqClone.Body = body
+		}
 
-		resp, err := c.httpClient.Do(reqClone)
+		resp, err := c.do(reqClone)
 		if err != nil {
-			lastErr = fmt.Errorf("HTTP request failed: %w", err)
-			c.logger.Warn("request attempt %d failed: %v", attempt+1, err)
+			// Retrying cannot fix a permanent failure such as an untrusted certificate
+			connErr := classifyConnectionError(err)
+			if !connErr.Transient() {
+				c.logger.Error("request failed with %s error, not retrying: %v", connErr.Kind, err)
+				return nil, connErr
+			}
+			lastErr = fmt.Errorf("HTTP request failed: %w", connErr)
+			c.logger.Warn("request attempt %d failed with %s error: %v", attempt+1, connErr.Kind, err)
 			continue
 		}
 
@@ -127,60 +230,201 @@ func (c *Client) executeWithRetry(ctx context.Context, req *http.Request) (*http
 	return nil, fmt.Errorf("request failed after %d attempts: %w", c.maxRetries+1, lastErr)
 }
 
+// replayableBody returns a function that opens a new reader over req's body, or nil
+// if the request has no body. Bodies without GetBody are read into memory.
+func replayableBody(req *http.Request) (func() (io.ReadCloser, error), error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil, nil
+	}
+	if req.GetBody != nil {
+		return req.GetBody, nil
+	}
+
+	data, err := io.ReadAll(req.Body)
+	_ = req.Body.Close()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read request body: %w", err)
+	}
+	return func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(data)), nil
+	}, nil
+}
+
 // getPolicies is a helper method to fetch raw policy data from the API
 func (c *Client) getPolicies() ([]models.Policy, error) {
 	return c.getPoliciesWithContext(context.Background())
 }
 
-// getPoliciesWithContext is a helper method to fetch raw policy data from the API with context
+// maxPolicyPages bounds how many pages of policies are followed, guarding against
+// an API that keeps returning a next page
+const maxPolicyPages = 100
+
+// getPoliciesWithContext is a helper method to fetch raw policy data from the API with context.
+// Paginated responses are followed until the last page, whether the next page is given by a
+// Link header or by a "next" field in the response body.
 func (c *Client) getPoliciesWithContext(ctx context.Context) ([]models.Policy, error) {
-	url := c.baseURL + "/vendor/v3/policies"
-	c.logger.Debug("fetching policies from API endpoint: %s", url)
+	pageURL := c.policiesURL("")
+	visited := make(map[string]bool)
+	var policies []models.Policy
+
+	for page := 1; pageURL != ""; page++ {
+		if page > maxPolicyPages {
+			c.logger.Error("policy listing exceeded %d pages", maxPolicyPages)
+			return nil, fmt.Errorf("policy listing exceeded %d pages", maxPolicyPages)
+		}
+		if visited[pageURL] {
+			c.logger.Error("policy listing returned page %s more than once", pageURL)
+			return nil, fmt.Errorf("policy listing returned page %s more than once", pageURL)
+		}
+		visited[pageURL] = true
 
-	req, err := http.NewRequest(http.MethodGet, url, nil)
+		pagePolicies, nextURL, err := c.getPoliciesPage(ctx, pageURL)
+		if err != nil {
+			return nil, err
+		}
+		policies = append(policies, pagePolicies...)
+		if nextURL != "" {
+			c.logger.Debug("following next page of policies: %s", nextURL)
+		}
+		pageURL = nextURL
+	}
+
+	c.logger.Debug("successfully fetched %d policies from API", len(policies))
+	return policies, nil
+}
+
+// getPoliciesPage fetches a single page of policies, returning the URL of the next page
+// or an empty string if this is the last one
+func (c *Client) getPoliciesPage(ctx context.Context, pageURL string) ([]models.Policy, string, error) {
+	c.logger.Debug("fetching policies from API endpoint: %s", pageURL)
+
+	req, err := http.NewRequest(http.MethodGet, pageURL, nil)
 	if err != nil {
-		c.logger.Error("failed to create HTTP request for %s: %v", url, err)
-		return nil, fmt.Errorf("failed to create request: %w", err)
+		c.logger.Error("failed to create HTTP request for %s: %v", pageURL, err)
+		return nil, "", fmt.Errorf("failed to create request: %w", err)
 	}
 
-	req.Header.Set("Authorization", c.apiToken)
+	req.Header.Set("Authorization", c.token())
 	req.Header.Set("Accept", "application/json")
 
 	resp, err := c.executeWithRetry(ctx, req)
 	if err != nil {
-		c.logger.Error("HTTP request failed for %s: %v", url, err)
-		return nil, fmt.Errorf("failed to execute request: %w", err)
+		c.logger.Error("HTTP request failed for %s: %v", pageURL, err)
+		return nil, "", fmt.Errorf("failed to execute request: %w", err)
 	}
 	defer func() { _ = resp.Body.Close() }()
 
 	c.logger.Debug("received HTTP response: status=%d", resp.StatusCode)
 	if resp.StatusCode != http.StatusOK {
-		c.logger.Error("API request failed: GET %s returned status %d", url, resp.StatusCode)
-		return nil, c.handleErrorResponse(resp)
+		c.logger.Error("API request failed: GET %s returned status %d", pageURL, resp.StatusCode)
+		return nil, "", c.handleErrorResponse(resp)
 	}
 
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		c.logger.Error("failed to read response body from %s: %v", url, err)
-		return nil, fmt.Errorf("failed to read response body: %w", err)
+		c.logger.Error("failed to read response body from %s: %v", pageURL, err)
+		return nil, "", fmt.Errorf("failed to read response body: %w", err)
 	}
 
 	c.logger.Debug("received response body of %d bytes", len(body))
-	// Parse the response which has a "policies" wrapper
+	// Parse the response which has a "policies" wrapper and, when paginated, a "next" cursor
 	var response struct {
 		Policies []models.Policy `json:"policies"`
+		Next     string          `json:"next"`
 	}
 	if err := json.Unmarshal(body, &response); err != nil {
-		c.logger.Error("failed to parse JSON response from %s: %v", url, err)
-		return nil, fmt.Errorf("failed to parse response: %w", err)
+		c.logger.Error("failed to parse JSON response from %s: %v", pageURL, err)
+		return nil, "", fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	nextURL, err := c.nextPageURL(pageURL, resp.Header.Get("Link"), response.Next)
+	if err != nil {
+		c.logger.Error("invalid next page reference from %s: %v", pageURL, err)
+		return nil, "", err
+	}
+	return response.Policies, nextURL, nil
+}
+
+// nextPageURL resolves the next page of a paginated response. A Link header with
+// rel="next" takes precedence; otherwise next is either a URL (absolute or relative to
+// the current page) or an opaque cursor passed back in the "cursor" query parameter.
+// Pages are requested with the API token, so a next page on another scheme or host than
+// the client's base URL is an error rather than being followed.
+func (c *Client) nextPageURL(currentURL, linkHeader, next string) (string, error) {
+	current, err := url.Parse(currentURL)
+	if err != nil {
+		return "", fmt.Errorf("invalid page URL %s: %w", currentURL, err)
+	}
+
+	if link := nextLink(linkHeader); link != "" {
+		ref, err := url.Parse(link)
+		if err != nil {
+			return "", fmt.Errorf("invalid next page link %q: %w", link, err)
+		}
+		return c.sameOriginURL(current.ResolveReference(ref))
+	}
+
+	if next == "" {
+		return "", nil
+	}
+	if strings.HasPrefix(next, "/") || strings.Contains(next, "://") {
+		ref, err := url.Parse(next)
+		if err != nil {
+			return "", fmt.Errorf("invalid next page %q: %w", next, err)
+		}
+		return c.sameOriginURL(current.ResolveReference(ref))
 	}
 
-	c.logger.Debug("successfully fetched %d policies from API", len(response.Policies))
-	return response.Policies, nil
+	query := current.Query()
+	query.Set("cursor", next)
+	current.RawQuery = query.Encode()
+	return current.String(), nil
 }
 
-// GetRoles retrieves all roles from the API
+// sameOriginURL returns next as a string if it has the scheme and host of the client's
+// base URL, so that following it cannot send the API token elsewhere
+func (c *Client) sameOriginURL(next *url.URL) (string, error) {
+	base, err := url.Parse(c.baseURL)
+	if err != nil {
+		return "", fmt.Errorf("invalid base URL %s: %w", c.baseURL, err)
+	}
+	if !strings.EqualFold(next.Scheme, base.Scheme) || !strings.EqualFold(next.Host, base.Host) {
+		return "", fmt.Errorf("refusing to follow next page %s: not on %s://%s", next.Redacted(), base.Scheme, base.Host)
+	}
+	return next.String(), nil
+}
+
+// nextLink extracts the rel="next" target from an RFC 8288 Link header
+func nextLink(header string) string {
+	for _, link := range strings.Split(header, ",") {
+		parts := strings.Split(link, ";")
+		target := strings.TrimSpace(parts[0])
+		if !strings.HasPrefix(target, "<") || !strings.HasSuffix(target, ">") {
+			continue
+		}
+		for _, param := range parts[1:] {
+			key, value, _ := strings.Cut(strings.TrimSpace(param), "=")
+			if strings.EqualFold(key, "rel") && strings.EqualFold(strings.Trim(value, `"`), "next") {
+				return strings.TrimSuffix(strings.TrimPrefix(target, "<"), ">")
+			}
+		}
+	}
+	return ""
+}
+
+// RoleLister is implemented by clients that can list roles without reading team members
+type RoleLister interface {
+	GetRolesWithOptions(includeMembers bool) ([]models.Role, error)
+}
+
+// GetRoles retrieves all roles from the API, with their members
 func (c *Client) GetRoles() ([]models.Role, error) {
+	return c.GetRolesWithOptions(true)
+}
+
+// GetRolesWithOptions retrieves all roles from the API. Members are only read, with an
+// extra request that needs permission to list the team, when includeMembers is true.
+func (c *Client) GetRolesWithOptions(includeMembers bool) ([]models.Role, error) {
 	c.logger.Debug("starting GetRoles operation")
 	policies, err := c.getPolicies()
 	if err != nil {
@@ -189,18 +433,25 @@ func (c *Client) GetRoles() ([]models.Role, error) {
 	}
 
 	c.logger.Debug("converting %d policies to roles", len(policies))
-	// Convert policies to local roles
+	// Convert policies to local roles. A policy whose definition cannot be read, such as
+	// one written with another definition version, fails the listing: leaving it out would
+	// plan a create for a role that already exists.
 	roles := make([]models.Role, 0, len(policies))
 	for _, policy := range policies {
 		c.logger.Debug("converting policy: %s", policy.Name)
 		role, err := policy.ToRole()
 		if err != nil {
-			c.logger.Error("failed to convert policy %s to role: %v", policy.Name, err)
-			return nil, fmt.Errorf("failed to convert policy %s: %w", policy.Name, err)
+			c.logger.Error("failed to read policy %s: %v", policy.Name, err)
+			return nil, fmt.Errorf("failed to read policy %s: %w", policy.Name, err)
 		}
 		roles = append(roles, role)
 	}
 
+	if !includeMembers {
+		c.logger.Debug("successfully retrieved %d roles from API without members", len(roles))
+		return roles, nil
+	}
+
 	c.logger.Debug("fetching team members to correlate with roles")
 	// Fetch team members and correlate with roles
 	members, err := c.GetTeamMembers()
@@ -210,20 +461,26 @@ func (c *Client) GetRoles() ([]models.Role, error) {
 	} else {
 		c.logger.Debug("correlating %d members with roles", len(members))
 		// Group members by policy ID
-		membersByPolicy := make(map[string][]string)
+		membersByPolicy := make(map[string][]models.TeamMember)
 		for _, member := range members {
 			if member.PolicyID != "" {
-				// Use the member ID (email) for the members list
-				membersByPolicy[member.PolicyID] = append(membersByPolicy[member.PolicyID], member.ID)
+				membersByPolicy[member.PolicyID] = append(membersByPolicy[member.PolicyID], member)
 			}
 		}
 
 		// Populate member data for each role
 		for i := range roles {
 			// Find members for this role by policy ID
-			policyID := policies[i].ID
-			if memberEmails, found := membersByPolicy[policyID]; found {
+			policyID := roles[i].ID
+			if policyMembers, found := membersByPolicy[policyID]; found {
+				// Use the member ID (email) for the members list and keep the
+				// full records so names and usernames can be displayed
+				memberEmails := make([]string, 0, len(policyMembers))
+				for _, member := range policyMembers {
+					memberEmails = append(memberEmails, member.ID)
+				}
 				roles[i].Members = memberEmails
+				roles[i].MemberDetails = policyMembers
 				c.logger.Debug("role %s has %d members", roles[i].Name, len(memberEmails))
 			}
 		}
@@ -250,10 +507,23 @@ func (c *Client) GetRole(roleName string) (models.Role, error) {
 	return models.Role{}, fmt.Errorf("role not found: %s", roleName)
 }
 
+// RoleCreatorWithID is implemented by clients that report the ID of the role they create,
+// sparing a lookup of the new role before its members are assigned
+type RoleCreatorWithID interface {
+	CreateRoleWithID(role models.Role) (string, error)
+}
+
 // CreateRole creates a new role via the API
 func (c *Client) CreateRole(role models.Role) error {
+	_, err := c.CreateRoleWithID(role)
+	return err
+}
+
+// CreateRoleWithID creates a new role via the API and returns its ID, read from the
+// created policy in the response. The ID is empty if the response does not include it.
+func (c *Client) CreateRoleWithID(role models.Role) (string, error) {
 	c.logger.Info("creating role: %s", role.Name)
-	url := c.baseURL + "/vendor/v3/policy"
+	url := c.policyURL("")
 	c.logger.Debug("creating role at endpoint: %s", url)
 
 	// Convert role to API format and create the policy structure
@@ -262,7 +532,7 @@ func (c *Client) CreateRole(role models.Role) error {
 	definitionJSON, err := json.Marshal(apiRole)
 	if err != nil {
 		c.logger.Error("failed to marshal role definition for %s: %v", role.Name, err)
-		return fmt.Errorf("failed to marshal role definition: %w", err)
+		return "", fmt.Errorf("failed to marshal role definition: %w", err)
 	}
 
 	// Create the policy payload
@@ -277,33 +547,49 @@ func (c *Client) CreateRole(role models.Role) error {
 
 	body, err := json.Marshal(policy)
 	if err != nil {
-		return fmt.Errorf("failed to marshal policy: %w", err)
+		return "", fmt.Errorf("failed to marshal policy: %w", err)
 	}
 
 	req, err := http.NewRequest(http.MethodPost, url, bytes.NewBuffer(body))
 	if err != nil {
-		return fmt.Errorf("failed to create request: %w", err)
+		return "", fmt.Errorf("failed to create request: %w", err)
 	}
 
-	req.Header.Set("Authorization", c.apiToken)
+	req.Header.Set("Authorization", c.token())
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("Accept", "application/json")
 
-	resp, err := c.httpClient.Do(req)
+	resp, err := c.do(req)
 	if err != nil {
 		c.logger.Error("HTTP request failed for CreateRole %s: %v", role.Name, err)
-		return fmt.Errorf("failed to execute request: %w", err)
+		return "", fmt.Errorf("failed to execute request: %w", err)
 	}
 	defer func() { _ = resp.Body.Close() }()
 
 	c.logger.Debug("CreateRole response for %s: status=%d", role.Name, resp.StatusCode)
 	if resp.StatusCode != http.StatusCreated && resp.StatusCode != http.StatusOK {
 		c.logger.Error("CreateRole failed for %s: status=%d", role.Name, resp.StatusCode)
-		return c.handleErrorResponse(resp)
+		return "", c.handleErrorResponse(resp)
 	}
 
 	c.logger.Info("successfully created role: %s", role.Name)
-	return nil
+	return createdPolicyID(resp.Body), nil
+}
+
+// createdPolicyID reads the ID of the created policy from a create response, which
+// holds either the policy itself or the policy under a "policy" key
+func createdPolicyID(body io.Reader) string {
+	var response struct {
+		ID     string         `json:"id"`
+		Policy *models.Policy `json:"policy"`
+	}
+	if err := json.NewDecoder(body).Decode(&response); err != nil {
+		return ""
+	}
+	if response.Policy != nil && response.Policy.ID != "" {
+		return response.Policy.ID
+	}
+	return response.ID
 }
 
 // UpdateRole updates an existing role via the API
@@ -314,7 +600,7 @@ func (c *Client) UpdateRole(role models.Role) error {
 		return fmt.Errorf("role ID is required for update operation")
 	}
 
-	url := c.baseURL + "/vendor/v3/policy/" + role.ID
+	url := c.policyURL(role.ID)
 	c.logger.Debug("updating role at endpoint: %s", url)
 
 	// Convert role to API format and create the policy update structure
@@ -344,11 +630,11 @@ func (c *Client) UpdateRole(role models.Role) error {
 		return fmt.Errorf("failed to create request: %w", err)
 	}
 
-	req.Header.Set("Authorization", c.apiToken)
+	req.Header.Set("Authorization", c.token())
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("Accept", "application/json")
 
-	resp, err := c.httpClient.Do(req)
+	resp, err := c.do(req)
 	if err != nil {
 		c.logger.Error("HTTP request failed for UpdateRole %s: %v", role.Name, err)
 		return fmt.Errorf("failed to execute request: %w", err)
@@ -390,7 +676,7 @@ func (c *Client) DeleteRole(roleName string) error {
 	}
 
 	c.logger.Debug("found policy ID %s for role %s", policyID, roleName)
-	url := c.baseURL + "/vendor/v3/policy/" + policyID
+	url := c.policyURL(policyID)
 	c.logger.Debug("deleting role at endpoint: %s", url)
 
 	req, err := http.NewRequest(http.MethodDelete, url, nil)
@@ -398,10 +684,10 @@ func (c *Client) DeleteRole(roleName string) error {
 		return fmt.Errorf("failed to create request: %w", err)
 	}
 
-	req.Header.Set("Authorization", c.apiToken)
+	req.Header.Set("Authorization", c.token())
 	req.Header.Set("Accept", "application/json")
 
-	resp, err := c.httpClient.Do(req)
+	resp, err := c.do(req)
 	if err != nil {
 		c.logger.Error("HTTP request failed for DeleteRole %s: %v", roleName, err)
 		return fmt.Errorf("failed to execute request: %w", err)
@@ -422,6 +708,9 @@ func (c *Client) DeleteRole(roleName string) error {
 func (c *Client) handleErrorResponse(resp *http.Response) error {
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
+		if isAuthStatus(resp.StatusCode) {
+			return &AuthError{StatusCode: resp.StatusCode}
+		}
 		return fmt.Errorf("API request failed with status %d", resp.StatusCode)
 	}
 
@@ -432,6 +721,9 @@ func (c *Client) handleErrorResponse(resp *http.Response) error {
 	}
 
 	if err := json.Unmarshal(body, &errorResp); err != nil {
+		if isAuthStatus(resp.StatusCode) {
+			return &AuthError{StatusCode: resp.StatusCode, Message: strings.TrimSpace(string(body))}
+		}
 		// If we can't parse the error response, return a generic error
 		return fmt.Errorf("API request failed with status %d: %s", resp.StatusCode, string(body))
 	}
@@ -440,6 +732,9 @@ func (c *Client) handleErrorResponse(resp *http.Response) error {
 	if errorMsg == "" {
 		errorMsg = errorResp.Message
 	}
+	if isAuthStatus(resp.StatusCode) {
+		return &AuthError{StatusCode: resp.StatusCode, Message: errorMsg}
+	}
 	if errorMsg == "" {
 		errorMsg = "unknown error"
 	}
@@ -501,7 +796,7 @@ func (c *Client) CreateRoleWithContext(ctx context.Context, role models.Role) er
 		c.logger.Debug("CreateRole for '%s' completed in %v", role.Name, time.Since(start))
 	}()
 
-	url := c.baseURL + "/vendor/v3/policies"
+	url := c.policiesURL("")
 	c.logger.Debug("creating role at endpoint: %s", url)
 
 	requestData := struct {
@@ -522,7 +817,7 @@ func (c *Client) CreateRoleWithContext(ctx context.Context, role models.Role) er
 		return fmt.Errorf("failed to create request: %w", err)
 	}
 
-	req.Header.Set("Authorization", c.apiToken)
+	req.Header.Set("Authorization", c.token())
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("Accept", "application/json")
 
@@ -556,7 +851,7 @@ func (c *Client) UpdateRoleWithContext(ctx context.Context, role models.Role) er
 		return fmt.Errorf("ID is required for updating role '%s'", role.Name)
 	}
 
-	url := c.baseURL + "/vendor/v3/policies/" + role.ID
+	url := c.policiesURL(role.ID)
 	c.logger.Debug("updating role at endpoint: %s", url)
 
 	requestData := struct {
@@ -577,7 +872,7 @@ func (c *Client) UpdateRoleWithContext(ctx context.Context, role models.Role) er
 		return fmt.Errorf("failed to create request: %w", err)
 	}
 
-	req.Header.Set("Authorization", c.apiToken)
+	req.Header.Set("Authorization", c.token())
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("Accept", "application/json")
 
@@ -616,7 +911,7 @@ func (c *Client) DeleteRoleWithContext(ctx context.Context, roleName string) err
 		return fmt.Errorf("ID is required for deleting role '%s'", roleName)
 	}
 
-	url := c.baseURL + "/vendor/v3/policies/" + role.ID
+	url := c.policiesURL(role.ID)
 	c.logger.Debug("deleting role at endpoint: %s", url)
 
 	req, err := http.NewRequest(http.MethodDelete, url, nil)
@@ -625,7 +920,7 @@ func (c *Client) DeleteRoleWithContext(ctx context.Context, roleName string) err
 		return fmt.Errorf("failed to create request: %w", err)
 	}
 
-	req.Header.Set("Authorization", c.apiToken)
+	req.Header.Set("Authorization", c.token())
 	req.Header.Set("Accept", "application/json")
 
 	resp, err := c.executeWithRetry(ctx, req)
@@ -667,7 +962,7 @@ func (c *Client) GetTeamMembersWithContext(ctx context.Context) ([]models.TeamMe
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
 
-	req.Header.Set("Authorization", c.apiToken)
+	req.Header.Set("Authorization", c.token())
 	req.Header.Set("Accept", "application/json")
 
 	resp, err := c.executeWithRetry(ctx, req)
@@ -733,10 +1028,10 @@ func (c *Client) AssignMemberRoleWithContext(ctx context.Context, memberEmail, r
 		return fmt.Errorf("failed to get team members: %w", err)
 	}
 
-	// Find the member ID for the given email
+	// Find the member ID for the given email, matched as models.NormalizeEmail does
 	var memberID string
 	for _, member := range members {
-		if member.Email == memberEmail {
+		if models.NormalizeEmail(member.Email) == models.NormalizeEmail(memberEmail) {
 			memberID = member.ID
 			break
 		}
@@ -772,7 +1067,7 @@ func (c *Client) AssignMemberRoleWithContext(ctx context.Context, memberEmail, r
 		return fmt.Errorf("failed to create request: %w", err)
 	}
 
-	req.Header.Set("Authorization", c.apiToken)
+	req.Header.Set("Authorization", c.token())
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("Accept", "application/json")
 
@@ -816,7 +1111,7 @@ func (c *Client) InviteUserWithContext(ctx context.Context, email, policyID stri
 		return nil, fmt.Errorf("policy ID is required")
 	}
 
-	url := c.baseURL + "/vendor/v3/team/invite"
+	url := c.vendorURL("/team/invite")
 	c.logger.Debug("inviting user at endpoint: %s", url)
 
 	// Create request payload
@@ -837,7 +1132,7 @@ func (c *Client) InviteUserWithContext(ctx context.Context, email, policyID stri
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
 
-	req.Header.Set("Authorization", c.apiToken)
+	req.Header.Set("Authorization", c.token())
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("Accept", "application/json")
 
@@ -913,7 +1208,7 @@ func (c *Client) DeleteInviteWithContext(ctx context.Context, email string) erro
 	// Find the invite ID for the given email
 	var inviteID string
 	for _, member := range members {
-		if member.Email == email && member.IsPendingInvite() {
+		if models.NormalizeEmail(member.Email) == models.NormalizeEmail(email) && member.IsPendingInvite() {
 			inviteID = member.ID
 			break
 		}
@@ -934,7 +1229,7 @@ func (c *Client) DeleteInviteWithContext(ctx context.Context, email string) erro
 		return fmt.Errorf("failed to create request: %w", err)
 	}
 
-	req.Header.Set("Authorization", c.apiToken)
+	req.Header.Set("Authorization", c.token())
 	req.Header.Set("Accept", "application/json")
 
 	resp, err := c.executeWithRetry(ctx, req)
@@ -976,10 +1271,10 @@ func (c *Client) removeMemberFromTeam(ctx context.Context, memberEmail string) e
 		return fmt.Errorf("failed to get team members: %w", err)
 	}
 
-	// Find the member ID for the given email
+	// Find the member ID for the given email, matched as models.NormalizeEmail does
 	var memberID string
 	for _, member := range members {
-		if member.Email == memberEmail {
+		if models.NormalizeEmail(member.Email) == models.NormalizeEmail(memberEmail) {
 			memberID = member.ID
 			break
 		}
@@ -1000,7 +1295,7 @@ func (c *Client) removeMemberFromTeam(ctx context.Context, memberEmail string) e
 		return fmt.Errorf("failed to create request: %w", err)
 	}
 
-	req.Header.Set("Authorization", c.apiToken)
+	req.Header.Set("Authorization", c.token())
 	req.Header.Set("Accept", "application/json")
 
 	resp, err := c.executeWithRetry(ctx, req)
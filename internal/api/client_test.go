@@ -4,10 +4,12 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"io"
 	"net/http"
 	"net/http/httptest"
 	"reflect"
+	"strings"
 	"testing"
 	"time"
 
@@ -70,6 +72,118 @@ func TestNewClient(t *testing.T) {
 	}
 }
 
+func TestNewClient_TokenWhitespace(t *testing.T) {
+	var authorization string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorization = r.Header.Get("Authorization")
+		_, _ = w.Write([]byte(`{"policies": []}`))
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name        string
+		apiToken    string
+		errorSubstr string
+	}{
+		{name: "trailing newline is trimmed", apiToken: "test-token\n"},
+		{name: "surrounding whitespace is trimmed", apiToken: "  test-token\r\n"},
+		{name: "whitespace-only token is rejected", apiToken: " \n", errorSubstr: "API token is required"},
+		{name: "embedded newline is rejected", apiToken: "test-\ntoken", errorSubstr: `control character ('\n') at position 5`},
+		{name: "embedded NUL is rejected", apiToken: "test\x00token", errorSubstr: "control character"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, err := NewClientWithRetry(server.URL, tt.apiToken, createTestLogger(), 0)
+			if tt.errorSubstr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.errorSubstr) {
+					t.Errorf("Expected error containing %q, got %v", tt.errorSubstr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			authorization = ""
+			if _, err := client.GetRolesWithOptions(false); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if authorization != "test-token" {
+				t.Errorf("Authorization header = %q, want %q", authorization, "test-token")
+			}
+		})
+	}
+}
+
+func TestSetAPIVersion(t *testing.T) {
+	var paths []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = io.WriteString(w, `{"policies": []}`)
+	}))
+	defer server.Close()
+
+	client, err := NewClient(server.URL, "test-token", createTestLogger())
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+
+	if err := client.SetAPIVersion("v9"); err == nil || err.Error() != `unsupported API version "v9" (supported: v3)` {
+		t.Errorf("Expected unsupported version error, got %v", err)
+	}
+	if err := client.SetAPIVersion(""); err != nil {
+		t.Fatalf("Expected empty version to select the default, got %v", err)
+	}
+
+	if _, err := client.GetRoles(); err != nil {
+		t.Fatalf("GetRoles failed: %v", err)
+	}
+	if len(paths) == 0 || paths[0] != "/vendor/v3/policies" {
+		t.Errorf("Expected request to /vendor/v3/policies, got %v", paths)
+	}
+}
+
+func TestGetRolesWithOptions(t *testing.T) {
+	memberRequests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/vendor/v3/policies":
+			_, _ = io.WriteString(w, `{"policies": [{"id": "p1", "name": "admin", "definition": "{\"v1\":{\"name\":\"admin\",\"resources\":{\"allowed\":[\"**/*\"]}}}"}]}`)
+		case "/v1/team/members":
+			memberRequests++
+			_, _ = io.WriteString(w, `[{"id": "alice@example.com", "email": "alice@example.com", "policyId": "p1"}]`)
+		default:
+			t.Errorf("Unexpected request to %s", r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	client, err := NewClient(server.URL, "test-token", createTestLogger())
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+
+	// A read-only client must pass the option through
+	roles, err := NewReadOnlyClient(client).GetRolesWithOptions(false)
+	if err != nil {
+		t.Fatalf("GetRolesWithOptions(false) failed: %v", err)
+	}
+	if memberRequests != 0 || len(roles) != 1 || len(roles[0].Members) != 0 {
+		t.Errorf("Expected roles without members and no member request, got %+v after %d member request(s)", roles, memberRequests)
+	}
+
+	roles, err = client.GetRoles()
+	if err != nil {
+		t.Fatalf("GetRoles failed: %v", err)
+	}
+	if memberRequests != 1 || len(roles) != 1 || len(roles[0].Members) != 1 {
+		t.Errorf("Expected roles with members after one member request, got %+v after %d member request(s)", roles, memberRequests)
+	}
+}
+
 func TestGetRoles(t *testing.T) {
 	tests := []struct {
 		name           string
@@ -124,6 +238,17 @@ func TestGetRoles(t *testing.T) {
 				},
 			},
 		},
+		{
+			name:           "policy with an unreadable definition is an error",
+			mockStatusCode: http.StatusOK,
+			mockResponse: `{
+				"policies": [
+					{"id": "test-future-id", "name": "future", "definition": "{\"v2\":{\"name\":\"future\"}}"},
+					{"id": "test-viewer-id", "name": "viewer", "definition": "{\"v1\":{\"name\":\"viewer\",\"resources\":{\"allowed\":[\"kots/app/*/read\"],\"denied\":[]}}}"}
+				]
+			}`,
+			expectError: true,
+		},
 		{
 			name:           "empty roles list",
 			mockStatusCode: http.StatusOK,
@@ -203,6 +328,171 @@ func TestGetRoles(t *testing.T) {
 	}
 }
 
+func TestGetRolesPagination(t *testing.T) {
+	policyJSON := func(id, name string) string {
+		return `{"id": "` + id + `", "name": "` + name + `", "definition": "{\"v1\":{\"name\":\"` + name + `\",\"resources\":{\"allowed\":[\"*\"],\"denied\":[]}}}"}`
+	}
+
+	tests := []struct {
+		name          string
+		handler       func(w http.ResponseWriter, r *http.Request, page string)
+		expectedNames []string
+		expectError   string
+	}{
+		{
+			name: "follows Link header",
+			handler: func(w http.ResponseWriter, r *http.Request, page string) {
+				if page == "" {
+					w.Header().Set("Link", `</vendor/v3/policies?page=2>; rel="next"`)
+					_, _ = io.WriteString(w, `{"policies": [`+policyJSON("1", "admin")+`]}`)
+					return
+				}
+				_, _ = io.WriteString(w, `{"policies": [`+policyJSON("2", "viewer")+`]}`)
+			},
+			expectedNames: []string{"admin", "viewer"},
+		},
+		{
+			name: "Link header to another host is not followed",
+			handler: func(w http.ResponseWriter, r *http.Request, page string) {
+				w.Header().Set("Link", `<https://attacker.example.com/vendor/v3/policies?page=2>; rel="next"`)
+				_, _ = io.WriteString(w, `{"policies": [`+policyJSON("1", "admin")+`]}`)
+			},
+			expectError: "refusing to follow next page https://attacker.example.com/vendor/v3/policies?page=2",
+		},
+		{
+			name: "next URL on another scheme is not followed",
+			handler: func(w http.ResponseWriter, r *http.Request, page string) {
+				_, _ = io.WriteString(w, `{"policies": [], "next": "https://`+r.Host+`/vendor/v3/policies?page=2"}`)
+			},
+			expectError: "refusing to follow next page",
+		},
+		{
+			name: "follows next cursor in body",
+			handler: func(w http.ResponseWriter, r *http.Request, page string) {
+				if r.URL.Query().Get("cursor") == "" {
+					_, _ = io.WriteString(w, `{"policies": [`+policyJSON("1", "admin")+`], "next": "abc123"}`)
+					return
+				}
+				if cursor := r.URL.Query().Get("cursor"); cursor != "abc123" {
+					t.Errorf("Expected cursor abc123, got %q", cursor)
+				}
+				_, _ = io.WriteString(w, `{"policies": [`+policyJSON("2", "viewer")+`], "next": ""}`)
+			},
+			expectedNames: []string{"admin", "viewer"},
+		},
+		{
+			name: "repeated page is an error",
+			handler: func(w http.ResponseWriter, r *http.Request, page string) {
+				_, _ = io.WriteString(w, `{"policies": [], "next": "/vendor/v3/policies"}`)
+			},
+			expectError: "more than once",
+		},
+		{
+			name: "page limit is enforced",
+			handler: func(w http.ResponseWriter, r *http.Request, page string) {
+				next := "1"
+				if page != "" {
+					next = page + "1"
+				}
+				_, _ = io.WriteString(w, `{"policies": [], "next": "/vendor/v3/policies?page=`+next+`"}`)
+			},
+			expectError: "exceeded 100 pages",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				switch r.URL.Path {
+				case "/vendor/v3/policies":
+					tt.handler(w, r, r.URL.Query().Get("page"))
+				case "/v1/team/members":
+					_, _ = io.WriteString(w, "[]")
+				default:
+					t.Errorf("Unexpected path: %s", r.URL.Path)
+					w.WriteHeader(http.StatusNotFound)
+				}
+			}))
+			defer server.Close()
+
+			client, err := NewClient(server.URL, "test-token", createTestLogger())
+			if err != nil {
+				t.Fatalf("Failed to create client: %v", err)
+			}
+
+			roles, err := client.GetRoles()
+			if tt.expectError != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.expectError) {
+					t.Fatalf("Expected error containing %q, got %v", tt.expectError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			names := make([]string, 0, len(roles))
+			for _, role := range roles {
+				names = append(names, role.Name)
+			}
+			if !reflect.DeepEqual(names, tt.expectedNames) {
+				t.Errorf("Role names = %v, want %v", names, tt.expectedNames)
+			}
+		})
+	}
+}
+
+func TestAuthErrorResponses(t *testing.T) {
+	tests := []struct {
+		name         string
+		statusCode   int
+		response     string
+		expectDetail string
+	}{
+		{
+			name:         "401 unauthorized",
+			statusCode:   http.StatusUnauthorized,
+			response:     `{"error": "unauthorized"}`,
+			expectDetail: "status 401: unauthorized",
+		},
+		{
+			name:         "403 forbidden without body",
+			statusCode:   http.StatusForbidden,
+			response:     `{}`,
+			expectDetail: "status 403: Forbidden",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.statusCode)
+				_, _ = io.WriteString(w, tt.response)
+			}))
+			defer server.Close()
+
+			client, err := NewClient(server.URL, "expired-token", createTestLogger())
+			if err != nil {
+				t.Fatalf("Failed to create client: %v", err)
+			}
+
+			_, err = client.GetRoles()
+			var authErr *AuthError
+			if !errors.As(err, &authErr) {
+				t.Fatalf("Expected AuthError, got %T: %v", err, err)
+			}
+			if authErr.StatusCode != tt.statusCode {
+				t.Errorf("StatusCode = %d, want %d", authErr.StatusCode, tt.statusCode)
+			}
+			for _, expected := range []string{tt.expectDetail, "check your REPLICATED_API_TOKEN (it may be expired or lack permissions)"} {
+				if !strings.Contains(err.Error(), expected) {
+					t.Errorf("Expected error to contain %q, got %q", expected, err.Error())
+				}
+			}
+		})
+	}
+}
+
 func TestCreateRole(t *testing.T) {
 	tests := []struct {
 		name           string
@@ -317,6 +607,42 @@ func TestCreateRole(t *testing.T) {
 	}
 }
 
+func TestCreateRoleWithID(t *testing.T) {
+	tests := []struct {
+		name         string
+		mockResponse string
+		expectedID   string
+	}{
+		{name: "policy in response", mockResponse: `{"policy": {"id": "policy-1", "name": "test-role"}}`, expectedID: "policy-1"},
+		{name: "bare policy response", mockResponse: `{"id": "policy-2", "name": "test-role"}`, expectedID: "policy-2"},
+		{name: "response without ID", mockResponse: `{}`},
+		{name: "empty response", mockResponse: ``},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusCreated)
+				_, _ = w.Write([]byte(tt.mockResponse))
+			}))
+			defer server.Close()
+
+			client, err := NewClient(server.URL, "test-token", createTestLogger())
+			if err != nil {
+				t.Fatalf("Failed to create client: %v", err)
+			}
+
+			id, err := client.CreateRoleWithID(models.Role{Name: "test-role"})
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if id != tt.expectedID {
+				t.Errorf("ID = %q, want %q", id, tt.expectedID)
+			}
+		})
+	}
+}
+
 func TestUpdateRole(t *testing.T) {
 	role := models.Role{
 		ID:   "test-role-id",
@@ -690,6 +1016,13 @@ func TestAssignMemberRole(t *testing.T) {
 			mockStatusCode: http.StatusOK,
 			mockResponse:   `{"success": true}`,
 		},
+		{
+			name:           "email matched regardless of case",
+			memberEmail:    "John@Example.COM",
+			roleID:         "role123",
+			mockStatusCode: http.StatusOK,
+			mockResponse:   `{"success": true}`,
+		},
 		{
 			name:           "member not found",
 			memberEmail:    "nonexistent@example.com",
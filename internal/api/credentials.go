@@ -0,0 +1,126 @@
+package api
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// CredentialProvider supplies the API token used to authenticate with the Replicated API
+type CredentialProvider interface {
+	Token() (string, error)
+}
+
+// CredentialProviderFactory creates a provider from the argument given after the
+// provider name in a provider spec, e.g. "/run/secrets/token" in "file:/run/secrets/token"
+type CredentialProviderFactory func(arg string) (CredentialProvider, error)
+
+var (
+	credentialProvidersMu sync.RWMutex
+	credentialProviders   = map[string]CredentialProviderFactory{}
+)
+
+func init() {
+	mustRegisterCredentialProvider("env", func(arg string) (CredentialProvider, error) {
+		if arg == "" {
+			return nil, fmt.Errorf("env credential provider requires a variable name, e.g. env:MY_TOKEN")
+		}
+		return EnvCredentials{Variables: strings.Split(arg, ",")}, nil
+	})
+	mustRegisterCredentialProvider("file", func(arg string) (CredentialProvider, error) {
+		if arg == "" {
+			return nil, fmt.Errorf("file credential provider requires a path, e.g. file:/run/secrets/token")
+		}
+		return FileCredentials{Path: arg}, nil
+	})
+}
+
+// RegisterCredentialProvider makes a provider available by name, so that it can be
+// selected with a "name:arg" spec. Registering a name twice is an error.
+func RegisterCredentialProvider(name string, factory CredentialProviderFactory) error {
+	if name == "" || strings.Contains(name, ":") {
+		return fmt.Errorf("invalid credential provider name %q", name)
+	}
+	if factory == nil {
+		return fmt.Errorf("credential provider %q has no factory", name)
+	}
+
+	credentialProvidersMu.Lock()
+	defer credentialProvidersMu.Unlock()
+	if _, exists := credentialProviders[name]; exists {
+		return fmt.Errorf("credential provider %q is already registered", name)
+	}
+	credentialProviders[name] = factory
+	return nil
+}
+
+// mustRegisterCredentialProvider registers a built-in provider, panicking on programmer error
+func mustRegisterCredentialProvider(name string, factory CredentialProviderFactory) {
+	if err := RegisterCredentialProvider(name, factory); err != nil {
+		panic(err)
+	}
+}
+
+// CredentialProviderNames returns the names of all registered providers in sorted order
+func CredentialProviderNames() []string {
+	credentialProvidersMu.RLock()
+	defer credentialProvidersMu.RUnlock()
+
+	names := make([]string, 0, len(credentialProviders))
+	for name := range credentialProviders {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// NewCredentialProvider creates a provider from a "name:arg" spec such as
+// "env:VAULT_REPLICATED_TOKEN" or "file:/run/secrets/replicated-token"
+func NewCredentialProvider(spec string) (CredentialProvider, error) {
+	name, arg, _ := strings.Cut(spec, ":")
+
+	credentialProvidersMu.RLock()
+	factory, exists := credentialProviders[name]
+	credentialProvidersMu.RUnlock()
+	if !exists {
+		return nil, fmt.Errorf("unknown credential provider %q (available: %s)", name, strings.Join(CredentialProviderNames(), ", "))
+	}
+
+	return factory(arg)
+}
+
+// EnvCredentials reads the API token from the first set environment variable
+type EnvCredentials struct {
+	Variables []string
+}
+
+// Token returns the value of the first non-empty variable
+func (e EnvCredentials) Token() (string, error) {
+	for _, name := range e.Variables {
+		if value := strings.TrimSpace(os.Getenv(name)); value != "" {
+			return value, nil
+		}
+	}
+	return "", fmt.Errorf("none of the environment variables %s are set", strings.Join(e.Variables, ", "))
+}
+
+// FileCredentials reads the API token from a file, ignoring surrounding whitespace
+type FileCredentials struct {
+	Path string
+}
+
+// Token returns the trimmed contents of the file
+func (f FileCredentials) Token() (string, error) {
+	// #nosec G304 -- Reading a user-specified token file is the purpose of this provider
+	data, err := os.ReadFile(f.Path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read token file: %w", err)
+	}
+	token := strings.TrimSpace(string(data))
+	if token == "" {
+		return "", fmt.Errorf("token file %s is empty", f.Path)
+	}
+	return token, nil
+}
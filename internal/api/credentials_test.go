@@ -0,0 +1,145 @@
+package api
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestNewCredentialProvider(t *testing.T) {
+	dir := t.TempDir()
+	tokenFile := filepath.Join(dir, "token")
+	if err := os.WriteFile(tokenFile, []byte("  file-token\n"), 0600); err != nil {
+		t.Fatalf("failed to write token file: %v", err)
+	}
+	emptyFile := filepath.Join(dir, "empty")
+	if err := os.WriteFile(emptyFile, []byte("\n"), 0600); err != nil {
+		t.Fatalf("failed to write empty file: %v", err)
+	}
+
+	t.Setenv("CREDENTIALS_TEST_PRIMARY", "")
+	t.Setenv("CREDENTIALS_TEST_FALLBACK", "env-token")
+
+	tests := []struct {
+		name          string
+		spec          string
+		expectToken   string
+		expectError   string
+		expectSpecErr bool
+	}{
+		{
+			name:        "env provider uses first set variable",
+			spec:        "env:CREDENTIALS_TEST_PRIMARY,CREDENTIALS_TEST_FALLBACK",
+			expectToken: "env-token",
+		},
+		{
+			name:        "env provider with no set variables",
+			spec:        "env:CREDENTIALS_TEST_PRIMARY",
+			expectError: "none of the environment variables CREDENTIALS_TEST_PRIMARY are set",
+		},
+		{
+			name:          "static tokens cannot be given as a spec",
+			spec:          "static:static-token",
+			expectError:   `unknown credential provider "static" (available: env, file, keyring)`,
+			expectSpecErr: true,
+		},
+		{
+			name:        "file provider trims whitespace",
+			spec:        "file:" + tokenFile,
+			expectToken: "file-token",
+		},
+		{
+			name:        "file provider with empty file",
+			spec:        "file:" + emptyFile,
+			expectError: "is empty",
+		},
+		{
+			name:        "file provider with missing file",
+			spec:        "file:" + filepath.Join(dir, "missing"),
+			expectError: "failed to read token file",
+		},
+		{
+			name:          "env provider requires a variable",
+			spec:          "env",
+			expectError:   "requires a variable name",
+			expectSpecErr: true,
+		},
+		{
+			name:          "unknown provider",
+			spec:          "vault:secret/replicated",
+			expectError:   `unknown credential provider "vault" (available: env, file, keyring)`,
+			expectSpecErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider, err := NewCredentialProvider(tt.spec)
+			if tt.expectSpecErr {
+				if err == nil || !strings.Contains(err.Error(), tt.expectError) {
+					t.Fatalf("expected spec error containing %q, got %v", tt.expectError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error creating provider: %v", err)
+			}
+
+			token, err := provider.Token()
+			if tt.expectError != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.expectError) {
+					t.Fatalf("expected error containing %q, got %v", tt.expectError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if token != tt.expectToken {
+				t.Errorf("expected token %q, got %q", tt.expectToken, token)
+			}
+		})
+	}
+}
+
+type stubCredentials struct {
+	token string
+	err   error
+}
+
+func (s stubCredentials) Token() (string, error) {
+	return s.token, s.err
+}
+
+func TestRegisterCredentialProvider(t *testing.T) {
+	const name = "credentials-test-custom"
+	t.Cleanup(func() {
+		credentialProvidersMu.Lock()
+		delete(credentialProviders, name)
+		credentialProvidersMu.Unlock()
+	})
+
+	err := RegisterCredentialProvider(name, func(arg string) (CredentialProvider, error) {
+		return stubCredentials{token: "custom-" + arg}, nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error registering provider: %v", err)
+	}
+
+	provider, err := NewCredentialProvider(name + ":abc")
+	if err != nil {
+		t.Fatalf("unexpected error creating provider: %v", err)
+	}
+	token, err := provider.Token()
+	if err != nil || token != "custom-abc" {
+		t.Errorf("expected token %q, got %q (err %v)", "custom-abc", token, err)
+	}
+
+	if err := RegisterCredentialProvider(name, func(string) (CredentialProvider, error) { return nil, nil }); err == nil {
+		t.Error("expected error registering a duplicate provider name")
+	}
+	if err := RegisterCredentialProvider("bad:name", func(string) (CredentialProvider, error) { return nil, nil }); err == nil {
+		t.Error("expected error registering a name containing ':'")
+	}
+}
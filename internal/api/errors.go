@@ -0,0 +1,100 @@
+package api
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"syscall"
+)
+
+// AuthError is returned when the API rejects a request because the API token is
+// invalid, expired, or lacks the required permissions (HTTP 401 or 403)
+type AuthError struct {
+	StatusCode int
+	Message    string // Error message returned by the API, if any
+}
+
+func (e *AuthError) Error() string {
+	detail := http.StatusText(e.StatusCode)
+	if e.Message != "" {
+		detail = e.Message
+	}
+	return fmt.Sprintf("authentication failed (status %d: %s) — check your REPLICATED_API_TOKEN (it may be expired or lack permissions)", e.StatusCode, detail)
+}
+
+// Forbidden reports whether the token was recognized but lacks permission for the request (HTTP 403)
+func (e *AuthError) Forbidden() bool {
+	return e.StatusCode == http.StatusForbidden
+}
+
+// isAuthStatus reports whether an HTTP status code indicates an authentication or authorization failure
+func isAuthStatus(statusCode int) bool {
+	return statusCode == http.StatusUnauthorized || statusCode == http.StatusForbidden
+}
+
+// ConnectionErrorKind classifies why a request failed before the API responded
+type ConnectionErrorKind string
+
+const (
+	ConnectionTimeout ConnectionErrorKind = "timeout"
+	ConnectionRefused ConnectionErrorKind = "connection refused"
+	ConnectionDNS     ConnectionErrorKind = "DNS lookup"
+	ConnectionTLS     ConnectionErrorKind = "TLS certificate"
+	ConnectionOther   ConnectionErrorKind = "network"
+)
+
+// ConnectionError is returned when a request fails before the API responds, such as
+// when the connection times out or the server's certificate is not trusted
+type ConnectionError struct {
+	Kind ConnectionErrorKind
+	Err  error
+}
+
+func (e *ConnectionError) Error() string {
+	if e.Kind == ConnectionTLS {
+		return fmt.Sprintf("TLS certificate verification failed: %v — the API endpoint's certificate is not trusted; check for an intercepting proxy or missing CA certificates", e.Err)
+	}
+	return e.Err.Error()
+}
+
+func (e *ConnectionError) Unwrap() error {
+	return e.Err
+}
+
+// Transient reports whether the failure may clear up on its own, so that retrying the
+// request is worthwhile. Only certificate failures are permanent.
+func (e *ConnectionError) Transient() bool {
+	return e.Kind != ConnectionTLS
+}
+
+// classifyConnectionError wraps an error returned by the HTTP client in a ConnectionError of
+// the matching kind, returning an error that is already classified as it is
+func classifyConnectionError(err error) *ConnectionError {
+	var classified *ConnectionError
+	if errors.As(err, &classified) {
+		return classified
+	}
+
+	var certErr *tls.CertificateVerificationError
+	var authorityErr x509.UnknownAuthorityError
+	var hostnameErr x509.HostnameError
+	var invalidErr x509.CertificateInvalidError
+	var dnsErr *net.DNSError
+	var netErr net.Error
+
+	kind := ConnectionOther
+	switch {
+	case errors.As(err, &certErr), errors.As(err, &authorityErr), errors.As(err, &hostnameErr), errors.As(err, &invalidErr):
+		kind = ConnectionTLS
+	case errors.As(err, &dnsErr):
+		kind = ConnectionDNS
+	case errors.Is(err, syscall.ECONNREFUSED):
+		kind = ConnectionRefused
+	case errors.As(err, &netErr) && netErr.Timeout():
+		kind = ConnectionTimeout
+	}
+	return &ConnectionError{Kind: kind, Err: err}
+}
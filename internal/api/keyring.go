@@ -0,0 +1,66 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/zalando/go-keyring"
+)
+
+// KeyringService is the service name under which replbac stores API tokens in the
+// OS keyring (macOS Keychain, Windows Credential Manager, or Secret Service on Linux)
+const KeyringService = "replbac"
+
+// DefaultKeyringAccount is the keyring account used when none is given
+const DefaultKeyringAccount = "api-token"
+
+// ErrKeyringTokenNotFound is returned when the keyring holds no token for the account
+var ErrKeyringTokenNotFound = errors.New("no API token stored in the OS keyring")
+
+func init() {
+	mustRegisterCredentialProvider("keyring", func(arg string) (CredentialProvider, error) {
+		return KeyringCredentials{Account: arg}, nil
+	})
+}
+
+// KeyringCredentials reads the API token from the OS keyring
+type KeyringCredentials struct {
+	Account string // Keyring account; DefaultKeyringAccount when empty
+}
+
+// Token returns the token stored for the account
+func (k KeyringCredentials) Token() (string, error) {
+	token, err := keyring.Get(KeyringService, k.account())
+	if errors.Is(err, keyring.ErrNotFound) {
+		return "", fmt.Errorf("%w (account %s); run 'replbac login' to store one", ErrKeyringTokenNotFound, k.account())
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to read OS keyring: %w", err)
+	}
+	token = strings.TrimSpace(token)
+	if token == "" {
+		return "", fmt.Errorf("%w (account %s); run 'replbac login' to store one", ErrKeyringTokenNotFound, k.account())
+	}
+	return token, nil
+}
+
+// Store saves token in the OS keyring, replacing any token already stored for the account
+func (k KeyringCredentials) Store(token string) error {
+	token = strings.TrimSpace(token)
+	if token == "" {
+		return fmt.Errorf("API token is empty")
+	}
+	if err := keyring.Set(KeyringService, k.account(), token); err != nil {
+		return fmt.Errorf("failed to write OS keyring: %w", err)
+	}
+	return nil
+}
+
+// account returns the keyring account, applying the default
+func (k KeyringCredentials) account() string {
+	if k.Account == "" {
+		return DefaultKeyringAccount
+	}
+	return k.Account
+}
@@ -0,0 +1,53 @@
+package api
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/zalando/go-keyring"
+)
+
+func TestKeyringCredentials(t *testing.T) {
+	keyring.MockInit()
+
+	creds := KeyringCredentials{}
+	if _, err := creds.Token(); !errors.Is(err, ErrKeyringTokenNotFound) {
+		t.Fatalf("expected ErrKeyringTokenNotFound before login, got %v", err)
+	}
+
+	if err := creds.Store("  stored-token\n"); err != nil {
+		t.Fatalf("unexpected error storing token: %v", err)
+	}
+	if err := creds.Store(" "); err == nil {
+		t.Error("expected error storing an empty token")
+	}
+
+	provider, err := NewCredentialProvider("keyring")
+	if err != nil {
+		t.Fatalf("unexpected error creating keyring provider: %v", err)
+	}
+	token, err := provider.Token()
+	if err != nil || token != "stored-token" {
+		t.Errorf("expected token %q, got %q (err %v)", "stored-token", token, err)
+	}
+
+	other, err := NewCredentialProvider("keyring:staging")
+	if err != nil {
+		t.Fatalf("unexpected error creating keyring provider: %v", err)
+	}
+	if _, err := other.Token(); !errors.Is(err, ErrKeyringTokenNotFound) {
+		t.Errorf("expected separate accounts to hold separate tokens, got %v", err)
+	}
+}
+
+func TestKeyringCredentials_Unavailable(t *testing.T) {
+	keyring.MockInitWithError(errors.New("no secret service"))
+
+	_, err := KeyringCredentials{}.Token()
+	if err == nil || errors.Is(err, ErrKeyringTokenNotFound) {
+		t.Fatalf("expected keyring access error, got %v", err)
+	}
+	if err := (KeyringCredentials{}).Store("token"); err == nil {
+		t.Error("expected error storing token in an unavailable keyring")
+	}
+}
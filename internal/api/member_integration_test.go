@@ -120,6 +120,8 @@ func TestGetRolesWithMembers(t *testing.T) {
 	membersResponse := `[
 		{
 			"id": "admin@example.com",
+			"name": "Ada Admin",
+			"username": "ada",
 			"createdAt": "2025-03-07T20:41:53Z", 
 			"lastActiveAt": null,
 			"policyId": "policy-123"
@@ -196,6 +198,10 @@ func TestGetRolesWithMembers(t *testing.T) {
 		t.Errorf("Expected admin role member 'admin@example.com', got '%s'", adminRole.Members[0])
 	}
 
+	if len(adminRole.MemberDetails) != 1 || adminRole.MemberDetails[0].DisplayName() != "admin@example.com (Ada Admin, @ada)" {
+		t.Errorf("Expected admin role member details for Ada Admin, got %+v", adminRole.MemberDetails)
+	}
+
 	// Find viewer role and verify it has the correct members
 	var viewerRole *models.Role
 	for i := range roles {
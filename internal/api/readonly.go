@@ -0,0 +1,98 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"replbac/internal/models"
+)
+
+// ErrReadOnly is returned for every write attempted through a ReadOnlyClient
+var ErrReadOnly = errors.New("writes to the Replicated API are blocked in read-only mode")
+
+// ReadOnlyClient wraps a client so that reads pass through and every write fails with
+// ErrReadOnly before any request is made. It enforces read-only access for all commands,
+// unlike --dry-run, which each command must honor itself.
+type ReadOnlyClient struct {
+	ClientInterface
+}
+
+// NewReadOnlyClient wraps client so that it cannot modify anything
+func NewReadOnlyClient(client ClientInterface) *ReadOnlyClient {
+	return &ReadOnlyClient{ClientInterface: client}
+}
+
+// GetRolesWithOptions lists roles through the wrapped client, reading members regardless
+// of includeMembers if the wrapped client cannot skip them
+func (c *ReadOnlyClient) GetRolesWithOptions(includeMembers bool) ([]models.Role, error) {
+	if lister, ok := c.ClientInterface.(RoleLister); ok {
+		return lister.GetRolesWithOptions(includeMembers)
+	}
+	return c.ClientInterface.GetRoles()
+}
+
+// blocked returns the error reported for a write operation
+func blocked(operation string) error {
+	return fmt.Errorf("cannot %s: %w", operation, ErrReadOnly)
+}
+
+// CreateRole is blocked in read-only mode
+func (c *ReadOnlyClient) CreateRole(role models.Role) error {
+	return blocked("create role " + role.Name)
+}
+
+// CreateRoleWithContext is blocked in read-only mode
+func (c *ReadOnlyClient) CreateRoleWithContext(ctx context.Context, role models.Role) error {
+	return c.CreateRole(role)
+}
+
+// UpdateRole is blocked in read-only mode
+func (c *ReadOnlyClient) UpdateRole(role models.Role) error {
+	return blocked("update role " + role.Name)
+}
+
+// UpdateRoleWithContext is blocked in read-only mode
+func (c *ReadOnlyClient) UpdateRoleWithContext(ctx context.Context, role models.Role) error {
+	return c.UpdateRole(role)
+}
+
+// DeleteRole is blocked in read-only mode
+func (c *ReadOnlyClient) DeleteRole(roleName string) error {
+	return blocked("delete role " + roleName)
+}
+
+// DeleteRoleWithContext is blocked in read-only mode
+func (c *ReadOnlyClient) DeleteRoleWithContext(ctx context.Context, roleName string) error {
+	return c.DeleteRole(roleName)
+}
+
+// AssignMemberRole is blocked in read-only mode
+func (c *ReadOnlyClient) AssignMemberRole(memberEmail, roleID string) error {
+	return blocked("assign a role to member " + memberEmail)
+}
+
+// AssignMemberRoleWithContext is blocked in read-only mode
+func (c *ReadOnlyClient) AssignMemberRoleWithContext(ctx context.Context, memberEmail, roleID string) error {
+	return c.AssignMemberRole(memberEmail, roleID)
+}
+
+// InviteUser is blocked in read-only mode
+func (c *ReadOnlyClient) InviteUser(email, policyID string) (*models.InviteUserResponse, error) {
+	return nil, blocked("invite " + email)
+}
+
+// InviteUserWithContext is blocked in read-only mode
+func (c *ReadOnlyClient) InviteUserWithContext(ctx context.Context, email, policyID string) (*models.InviteUserResponse, error) {
+	return c.InviteUser(email, policyID)
+}
+
+// DeleteInvite is blocked in read-only mode
+func (c *ReadOnlyClient) DeleteInvite(email string) error {
+	return blocked("cancel the invitation for " + email)
+}
+
+// DeleteInviteWithContext is blocked in read-only mode
+func (c *ReadOnlyClient) DeleteInviteWithContext(ctx context.Context, email string) error {
+	return c.DeleteInvite(email)
+}
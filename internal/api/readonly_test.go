@@ -0,0 +1,57 @@
+package api
+
+import (
+	"errors"
+	"testing"
+
+	"replbac/internal/models"
+)
+
+// recordingClient counts the calls that reach the wrapped client
+type recordingClient struct {
+	ClientInterface
+	calls int
+}
+
+func (r *recordingClient) GetRoles() ([]models.Role, error) {
+	r.calls++
+	return []models.Role{{Name: "admin"}}, nil
+}
+
+func (r *recordingClient) CreateRole(role models.Role) error {
+	r.calls++
+	return nil
+}
+
+func (r *recordingClient) DeleteInvite(email string) error {
+	r.calls++
+	return nil
+}
+
+func TestReadOnlyClient(t *testing.T) {
+	inner := &recordingClient{}
+	client := NewReadOnlyClient(inner)
+
+	roles, err := client.GetRoles()
+	if err != nil || len(roles) != 1 {
+		t.Fatalf("expected reads to pass through, got %v (err %v)", roles, err)
+	}
+
+	writes := map[string]error{
+		"CreateRole":       client.CreateRole(models.Role{Name: "admin"}),
+		"UpdateRole":       client.UpdateRole(models.Role{Name: "admin"}),
+		"DeleteRole":       client.DeleteRole("admin"),
+		"AssignMemberRole": client.AssignMemberRole("a@example.com", "1"),
+		"DeleteInvite":     client.DeleteInvite("a@example.com"),
+	}
+	_, writes["InviteUser"] = client.InviteUser("a@example.com", "1")
+	for name, err := range writes {
+		if !errors.Is(err, ErrReadOnly) {
+			t.Errorf("%s: expected ErrReadOnly, got %v", name, err)
+		}
+	}
+
+	if inner.calls != 1 {
+		t.Errorf("expected only the read to reach the wrapped client, got %d calls", inner.calls)
+	}
+}
@@ -0,0 +1,106 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// TokenRefresher obtains a fresh API token, e.g. from a broker issuing short-lived tokens
+type TokenRefresher func() (string, error)
+
+// SetTokenRefresher makes the client obtain a new token from refresh when the API rejects
+// the current one with 401 Unauthorized, and retry the rejected request once with it.
+// Without a refresher, a 401 fails the request as usual.
+func (c *Client) SetTokenRefresher(refresh TokenRefresher) {
+	c.tokenMu.Lock()
+	defer c.tokenMu.Unlock()
+	c.refresh = refresh
+}
+
+// token returns the current API token
+func (c *Client) token() string {
+	c.tokenMu.RLock()
+	defer c.tokenMu.RUnlock()
+	return c.apiToken
+}
+
+// do sends req. If the API rejects its token and a refresher is set, the token is
+// refreshed and req is sent once more with it; if the refresh fails, the 401 response
+// is returned so that it is reported as usual. A request that fails before the API
+// responds returns a ConnectionError.
+func (c *Client) do(req *http.Request) (*http.Response, error) {
+	c.tokenMu.RLock()
+	refreshing := c.refresh != nil
+	c.tokenMu.RUnlock()
+	if !refreshing {
+		return c.send(req)
+	}
+
+	getBody, err := replayableBody(req)
+	if err != nil {
+		return nil, err
+	}
+	if getBody != nil {
+		if req.Body, err = getBody(); err != nil {
+			return nil, fmt.Errorf("failed to rewind request body: %w", err)
+		}
+	}
+
+	resp, err := c.send(req)
+	if err != nil || resp.StatusCode != http.StatusUnauthorized {
+		return resp, err
+	}
+
+	token, err := c.refreshToken(req.Header.Get("Authorization"))
+	if err != nil {
+		c.logger.Warn("API token was rejected and could not be refreshed: %v", err)
+		return resp, nil
+	}
+	_ = resp.Body.Close() //nolint:errcheck
+
+	retry := req.Clone(req.Context())
+	retry.Header.Set("Authorization", token)
+	if getBody != nil {
+		if retry.Body, err = getBody(); err != nil {
+			return nil, fmt.Errorf("failed to rewind request body: %w", err)
+		}
+	}
+	c.logger.Debug("retrying %s %s with a refreshed API token", req.Method, req.URL.Path)
+	return c.send(retry)
+}
+
+// send sends req with the HTTP client, classifying a failure to get a response
+func (c *Client) send(req *http.Request) (*http.Response, error) {
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, classifyConnectionError(err)
+	}
+	return resp, nil
+}
+
+// refreshToken replaces the rejected token with one from the refresher. Concurrent
+// requests rejected with the same token share a single refresh.
+func (c *Client) refreshToken(rejected string) (string, error) {
+	c.tokenMu.Lock()
+	defer c.tokenMu.Unlock()
+	if c.apiToken != rejected {
+		return c.apiToken, nil
+	}
+
+	token, err := c.refresh()
+	if err != nil {
+		return "", err
+	}
+	if token, err = normalizeToken(token); err != nil {
+		return "", err
+	}
+	if token == rejected {
+		return "", fmt.Errorf("refresher returned no new token")
+	}
+	c.logger.Info("API token refreshed after the API rejected it")
+	if trace, ok := c.httpClient.Transport.(*traceTransport); ok {
+		trace.addToken(token)
+	}
+	c.apiToken = token
+	return token, nil
+}
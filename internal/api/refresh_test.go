@@ -0,0 +1,104 @@
+package api
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"replbac/internal/models"
+)
+
+func TestSetTokenRefresher(t *testing.T) {
+	var authorizations []string
+	var bodies []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorizations = append(authorizations, r.Header.Get("Authorization"))
+		body, _ := io.ReadAll(r.Body)
+		bodies = append(bodies, string(body))
+		if r.Header.Get("Authorization") != "fresh-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			_, _ = w.Write([]byte(`{"error": "token expired"}`))
+			return
+		}
+		if r.Method == http.MethodPost {
+			w.WriteHeader(http.StatusCreated)
+			_, _ = w.Write([]byte(`{"policy": {"id": "new-id"}}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"policies": []}`))
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name          string
+		refresh       TokenRefresher
+		expectAuth    []string
+		errorContains string
+	}{
+		{
+			name:          "without a refresher a 401 fails",
+			expectAuth:    []string{"expired-token"},
+			errorContains: "401",
+		},
+		{
+			name:       "a refreshed token is used to retry once",
+			refresh:    func() (string, error) { return "fresh-token", nil },
+			expectAuth: []string{"expired-token", "fresh-token"},
+		},
+		{
+			name:          "a failed refresh reports the 401",
+			refresh:       func() (string, error) { return "", errors.New("broker unavailable") },
+			expectAuth:    []string{"expired-token"},
+			errorContains: "401",
+		},
+		{
+			name:          "a rejected refreshed token is not retried again",
+			refresh:       func() (string, error) { return "also-expired", nil },
+			expectAuth:    []string{"expired-token", "also-expired"},
+			errorContains: "401",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			authorizations = nil
+			client, err := NewClientWithRetry(server.URL, "expired-token", createTestLogger(), 0)
+			if err != nil {
+				t.Fatalf("Failed to create client: %v", err)
+			}
+			if tt.refresh != nil {
+				client.SetTokenRefresher(tt.refresh)
+			}
+
+			_, err = client.GetRolesWithOptions(false)
+			if tt.errorContains != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.errorContains) {
+					t.Errorf("Expected error containing %q, got %v", tt.errorContains, err)
+				}
+			} else if err != nil {
+				t.Errorf("Unexpected error: %v", err)
+			}
+			if strings.Join(authorizations, ",") != strings.Join(tt.expectAuth, ",") {
+				t.Errorf("Expected requests with tokens %v, got %v", tt.expectAuth, authorizations)
+			}
+		})
+	}
+
+	// A request with a body is resent in full
+	authorizations, bodies = nil, nil
+	client, err := NewClientWithRetry(server.URL, "expired-token", createTestLogger(), 0)
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	client.SetTokenRefresher(func() (string, error) { return "fresh-token", nil })
+	id, err := client.CreateRoleWithID(models.Role{Name: "viewer", Resources: models.Resources{Allowed: []string{"read"}}})
+	if err != nil || id != "new-id" {
+		t.Fatalf("Expected the create to succeed after a refresh, got %q (%v)", id, err)
+	}
+	if len(bodies) != 2 || bodies[0] == "" || bodies[0] != bodies[1] {
+		t.Errorf("Expected the same body on both attempts, got %q", bodies)
+	}
+}
@@ -2,11 +2,22 @@ package api
 
 import (
 	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"io"
+	"log"
+	"net"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"strings"
 	"sync/atomic"
+	"syscall"
 	"testing"
 	"time"
+
+	"replbac/internal/models"
 )
 
 func TestClientWithRetry(t *testing.T) {
@@ -163,3 +174,203 @@ func TestRetryBackoff(t *testing.T) {
 		t.Errorf("Total retry time too short: %v", totalTime)
 	}
 }
+
+// timeoutError is a net.Error reporting a timeout
+type timeoutError struct{}
+
+func (timeoutError) Error() string   { return "i/o timeout" }
+func (timeoutError) Timeout() bool   { return true }
+func (timeoutError) Temporary() bool { return true }
+
+// failingTransport fails the first request with err and passes later ones to the default transport
+type failingTransport struct {
+	err      error
+	attempts int64
+}
+
+func (f *failingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if atomic.AddInt64(&f.attempts, 1) == 1 {
+		return nil, f.err
+	}
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+// connectionErrors returns one simulated failure of each kind
+func connectionErrors() map[ConnectionErrorKind]error {
+	return map[ConnectionErrorKind]error{
+		ConnectionTimeout: &net.OpError{Op: "read", Net: "tcp", Err: timeoutError{}},
+		ConnectionRefused: &net.OpError{Op: "dial", Net: "tcp", Err: os.NewSyscallError("connect", syscall.ECONNREFUSED)},
+		ConnectionDNS:     &net.DNSError{Err: "server misbehaving", Name: "api.replicated.com", IsTemporary: true},
+		ConnectionTLS:     &tls.CertificateVerificationError{Err: x509.UnknownAuthorityError{}},
+		ConnectionOther:   errors.New("connection reset by peer"),
+	}
+}
+
+func TestClassifyConnectionError(t *testing.T) {
+	for kind, err := range connectionErrors() {
+		connErr := classifyConnectionError(err)
+		if connErr.Kind != kind {
+			t.Errorf("classifyConnectionError(%v).Kind = %q, want %q", err, connErr.Kind, kind)
+		}
+		if connErr.Transient() != (kind != ConnectionTLS) {
+			t.Errorf("%s: Transient() = %t", kind, connErr.Transient())
+		}
+		if !errors.Is(connErr, err) {
+			t.Errorf("%s: expected the classified error to wrap the original", kind)
+		}
+	}
+}
+
+func TestRetryOnConnectionErrors(t *testing.T) {
+	for kind, connectionErr := range connectionErrors() {
+		t.Run(string(kind), func(t *testing.T) {
+			var serverAttempts int64
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				atomic.AddInt64(&serverAttempts, 1)
+				if _, err := w.Write([]byte(`{"policies": []}`)); err != nil {
+					t.Errorf("Failed to write response: %v", err)
+				}
+			}))
+			defer server.Close()
+
+			client, err := NewClientWithRetry(server.URL, "test-token", createTestLogger(), 1)
+			if err != nil {
+				t.Fatalf("Failed to create client: %v", err)
+			}
+			transport := &failingTransport{err: connectionErr}
+			client.httpClient.Transport = transport
+
+			_, err = client.getPoliciesWithContext(context.Background())
+
+			if kind == ConnectionTLS {
+				var connErr *ConnectionError
+				if !errors.As(err, &connErr) || !strings.Contains(err.Error(), "TLS certificate verification failed") {
+					t.Fatalf("Expected a TLS certificate error, got %v", err)
+				}
+				if attempts := atomic.LoadInt64(&transport.attempts); attempts != 1 {
+					t.Errorf("Expected the permanent error not to be retried, got %d attempts", attempts)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Expected the transient %s error to be retried, got %v", kind, err)
+			}
+			if attempts := atomic.LoadInt64(&transport.attempts); attempts != 2 {
+				t.Errorf("Expected 2 attempts, got %d", attempts)
+			}
+			if atomic.LoadInt64(&serverAttempts) != 1 {
+				t.Errorf("Expected the retry to reach the server once, got %d", serverAttempts)
+			}
+		})
+	}
+}
+
+func TestUntrustedCertificateFailsFast(t *testing.T) {
+	var attempts int64
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt64(&attempts, 1)
+	}))
+	server.Config.ErrorLog = log.New(io.Discard, "", 0) // the rejected handshake is expected
+	server.StartTLS()
+	defer server.Close()
+
+	// The test server's certificate is self-signed, so the default client rejects it
+	client, err := NewClientWithRetry(server.URL, "test-token", createTestLogger(), 3)
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+
+	start := time.Now()
+	_, err = client.GetRolesWithContext(context.Background())
+
+	var connErr *ConnectionError
+	if !errors.As(err, &connErr) || connErr.Kind != ConnectionTLS {
+		t.Fatalf("Expected a TLS connection error, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 900*time.Millisecond {
+		t.Errorf("Expected no retry backoff for a certificate failure, took %v", elapsed)
+	}
+	if atomic.LoadInt64(&attempts) != 0 {
+		t.Errorf("Expected no request to reach the handler, got %d", attempts)
+	}
+}
+
+func TestWriteConnectionErrorsAreClassified(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	serverURL := server.URL
+	server.Close() // Nothing listens on the address any more, so connecting is refused
+
+	client, err := NewClientWithRetry(serverURL, "test-token", createTestLogger(), 0)
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+
+	err = client.CreateRole(models.Role{Name: "admin", Resources: models.Resources{Allowed: []string{"*"}}})
+	var connErr *ConnectionError
+	if !errors.As(err, &connErr) || connErr.Kind != ConnectionRefused {
+		t.Fatalf("Expected a connection refused error, got %v", err)
+	}
+}
+
+func TestRetryReplaysRequestBody(t *testing.T) {
+	tests := []struct {
+		name string
+		send func(client *Client, url string) error
+	}{
+		{
+			name: "create role",
+			send: func(client *Client, url string) error {
+				return client.CreateRoleWithContext(context.Background(), models.Role{Name: "admin", Resources: models.Resources{Allowed: []string{"*"}}})
+			},
+		},
+		{
+			name: "body without GetBody",
+			send: func(client *Client, url string) error {
+				req, err := http.NewRequest(http.MethodPost, url+"/vendor/v3/policy", io.MultiReader(strings.NewReader(`{"name":"admin"}`)))
+				if err != nil {
+					return err
+				}
+				resp, err := client.executeWithRetry(context.Background(), req)
+				if err != nil {
+					return err
+				}
+				return resp.Body.Close()
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var bodies []string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body, err := io.ReadAll(r.Body)
+				if err != nil {
+					t.Errorf("Failed to read request body: %v", err)
+				}
+				bodies = append(bodies, string(body))
+				if len(bodies) == 1 {
+					w.WriteHeader(http.StatusServiceUnavailable)
+					return
+				}
+				w.WriteHeader(http.StatusCreated)
+			}))
+			defer server.Close()
+
+			client, err := NewClientWithRetry(server.URL, "test-token", createTestLogger(), 1)
+			if err != nil {
+				t.Fatalf("Failed to create client: %v", err)
+			}
+
+			if err := tt.send(client, server.URL); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if len(bodies) != 2 {
+				t.Fatalf("Expected 2 attempts, got %d", len(bodies))
+			}
+			if bodies[0] == "" || bodies[1] != bodies[0] {
+				t.Errorf("Expected the retried body to match the first, got %q then %q", bodies[0], bodies[1])
+			}
+		})
+	}
+}
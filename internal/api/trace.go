@@ -0,0 +1,152 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"replbac/internal/logging"
+)
+
+// redactedValue replaces the API token wherever it appears in traced output
+const redactedValue = "[REDACTED]"
+
+// traceTransport logs every request and response passing through the client,
+// including headers and bodies, with the API token redacted
+type traceTransport struct {
+	next   http.RoundTripper
+	logger *logging.Logger
+
+	mu     sync.RWMutex
+	tokens []string // Every API token the client has used
+}
+
+// newTraceTransport wraps next so that all HTTP traffic is written to the logger's trace output
+func newTraceTransport(next http.RoundTripper, logger *logging.Logger, apiToken string) *traceTransport {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	return &traceTransport{
+		next:   next,
+		logger: logger,
+		tokens: []string{apiToken},
+	}
+}
+
+// addToken redacts a refreshed API token as well as those used before it
+func (t *traceTransport) addToken(token string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.tokens = append(t.tokens, token)
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *traceTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	reqBody, req, err := t.captureRequestBody(req)
+	if err != nil {
+		return nil, err
+	}
+
+	t.logger.Trace("--> %s %s", req.Method, t.redact(req.URL.String()))
+	t.traceHeaders("-->", req.Header)
+	t.traceBody("-->", reqBody)
+
+	start := time.Now()
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		t.logger.Trace("<-- %s %s failed after %v: %s", req.Method, t.redact(req.URL.String()), time.Since(start).Round(time.Millisecond), t.redact(err.Error()))
+		return nil, err
+	}
+
+	respBody, err := io.ReadAll(resp.Body)
+	_ = resp.Body.Close() //nolint:errcheck
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(respBody))
+
+	t.logger.Trace("<-- %d %s %s (%v)", resp.StatusCode, req.Method, t.redact(req.URL.String()), time.Since(start).Round(time.Millisecond))
+	t.traceHeaders("<--", resp.Header)
+	t.traceBody("<--", respBody)
+
+	return resp, nil
+}
+
+// captureRequestBody reads the request body for logging and returns a request whose body can still be sent
+func (t *traceTransport) captureRequestBody(req *http.Request) ([]byte, *http.Request, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil, req, nil
+	}
+
+	// Prefer GetBody so the caller's request is left untouched
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to read request body: %w", err)
+		}
+		defer body.Close() //nolint:errcheck
+		data, err := io.ReadAll(body)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to read request body: %w", err)
+		}
+		return data, req, nil
+	}
+
+	data, err := io.ReadAll(req.Body)
+	_ = req.Body.Close() //nolint:errcheck
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read request body: %w", err)
+	}
+	clone := req.Clone(req.Context())
+	clone.Body = io.NopCloser(bytes.NewReader(data))
+	return data, clone, nil
+}
+
+// traceHeaders logs headers in a stable order, always redacting Authorization
+func (t *traceTransport) traceHeaders(direction string, headers http.Header) {
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		value := strings.Join(headers[name], ", ")
+		if strings.EqualFold(name, "Authorization") {
+			value = redactedValue
+		}
+		t.logger.Trace("%s %s: %s", direction, name, t.redact(value))
+	}
+}
+
+// traceBody logs a body, pretty-printing it when it is JSON
+func (t *traceTransport) traceBody(direction string, body []byte) {
+	if len(body) == 0 {
+		return
+	}
+
+	var pretty bytes.Buffer
+	text := string(body)
+	if err := json.Indent(&pretty, body, "", "  "); err == nil {
+		text = pretty.String()
+	}
+	t.logger.Trace("%s body:\n%s", direction, t.redact(text))
+}
+
+// redact removes the API tokens from any traced text
+func (t *traceTransport) redact(s string) string {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	for _, token := range t.tokens {
+		if token != "" {
+			s = strings.ReplaceAll(s, token, redactedValue)
+		}
+	}
+	return s
+}
@@ -0,0 +1,92 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"replbac/internal/logging"
+	"replbac/internal/models"
+)
+
+func TestHTTPTraceLogging(t *testing.T) {
+	const token = "secret-api-token-value"
+
+	tests := []struct {
+		name        string
+		enableTrace bool
+		expectLogs  []string
+	}{
+		{
+			name:        "trace enabled logs requests and responses",
+			enableTrace: true,
+			expectLogs: []string{
+				"[TRACE]",
+				"--> POST ",
+				"/vendor/v3/policy",
+				"--> Authorization: [REDACTED]",
+				"--> body:\n{\n  \"name\": \"traced-role\"",
+				"<-- 201 POST",
+				"<-- body:\n{\n  \"v1\": {",
+			},
+		},
+		{
+			name:        "trace disabled logs nothing",
+			enableTrace: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var requestBody []byte
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				requestBody, _ = io.ReadAll(r.Body)
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(http.StatusCreated)
+				_, _ = w.Write([]byte(`{"v1":{"name":"traced-role","token":"` + token + `"}}`))
+			}))
+			defer server.Close()
+
+			var output bytes.Buffer
+			logger := logging.NewLogger(&output, false)
+			if tt.enableTrace {
+				logger.EnableHTTPTrace()
+			}
+
+			client, err := NewClient(server.URL, token, logger)
+			if err != nil {
+				t.Fatalf("Failed to create client: %v", err)
+			}
+
+			role := models.Role{Name: "traced-role", Resources: models.Resources{Allowed: []string{"read"}}}
+			if err := client.CreateRole(role); err != nil {
+				t.Fatalf("CreateRole failed: %v", err)
+			}
+
+			// Tracing must not consume the body sent to the server
+			var sent struct {
+				Name string `json:"name"`
+			}
+			if err := json.Unmarshal(requestBody, &sent); err != nil || sent.Name != role.Name {
+				t.Errorf("Server received unexpected body %q (err: %v)", requestBody, err)
+			}
+
+			logs := output.String()
+			if strings.Contains(logs, token) {
+				t.Errorf("API token leaked into trace output:\n%s", logs)
+			}
+			if !tt.enableTrace && strings.Contains(logs, "[TRACE]") {
+				t.Errorf("Expected no trace output, got:\n%s", logs)
+			}
+			for _, expected := range tt.expectLogs {
+				if !strings.Contains(logs, expected) {
+					t.Errorf("Expected trace output to contain %q, got:\n%s", expected, logs)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,122 @@
+package api
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+)
+
+// TransportOptions configures how the client connects to the API, e.g. from behind a
+// corporate proxy that re-signs TLS traffic with its own certificate authority
+type TransportOptions struct {
+	// ProxyURL is the proxy for every request, with credentials in its user info if the
+	// proxy requires them. Empty uses the HTTPS_PROXY, HTTP_PROXY, and NO_PROXY
+	// environment variables.
+	ProxyURL string
+
+	// CACertFile is a PEM file of certificate authorities trusted in addition to the system's
+	CACertFile string
+
+	// InsecureSkipVerify accepts any certificate the API presents. It exposes the API
+	// token to anyone able to intercept traffic, so is only for debugging.
+	InsecureSkipVerify bool
+}
+
+// ValidateProxyURL returns an error if proxy is not an HTTP, HTTPS, or SOCKS5 URL
+func ValidateProxyURL(proxy string) error {
+	_, err := parseProxyURL(proxy)
+	return err
+}
+
+// parseProxyURL parses a proxy URL, which must name a host and use a scheme Go's HTTP
+// transport supports for proxies
+func parseProxyURL(proxy string) (*url.URL, error) {
+	parsed, err := url.Parse(proxy)
+	if err != nil || parsed.Host == "" {
+		return nil, fmt.Errorf("invalid proxy URL %q: must be a URL such as http://proxy.example.com:3128", proxy)
+	}
+	switch parsed.Scheme {
+	case "http", "https", "socks5":
+		return parsed, nil
+	}
+	return nil, fmt.Errorf("invalid proxy URL %q: scheme must be http, https, or socks5", parsed.Redacted())
+}
+
+// NewTransport returns an HTTP transport configured by opts, for the API client and for
+// other requests that should connect the same way, such as Slack notifications. Options
+// left empty keep Go's defaults, so a zero TransportOptions still honors the proxy
+// environment variables and trusts only the system's certificate authorities.
+func NewTransport(opts TransportOptions) (*http.Transport, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+
+	if opts.ProxyURL != "" {
+		proxy, err := parseProxyURL(opts.ProxyURL)
+		if err != nil {
+			return nil, err
+		}
+		transport.Proxy = http.ProxyURL(proxy)
+	}
+
+	if opts.CACertFile != "" || opts.InsecureSkipVerify {
+		tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+		if opts.CACertFile != "" {
+			pool, err := loadCertPool(opts.CACertFile)
+			if err != nil {
+				return nil, err
+			}
+			tlsConfig.RootCAs = pool
+		}
+		if opts.InsecureSkipVerify {
+			tlsConfig.InsecureSkipVerify = true // #nosec G402 -- Explicitly requested with --insecure-skip-verify
+		}
+		transport.TLSClientConfig = tlsConfig
+	}
+	return transport, nil
+}
+
+// SetTransportOptions replaces the client's HTTP transport with one configured by opts,
+// as NewTransport does
+func (c *Client) SetTransportOptions(opts TransportOptions) error {
+	transport, err := NewTransport(opts)
+	if err != nil {
+		return err
+	}
+	if opts.ProxyURL != "" {
+		proxy, _ := parseProxyURL(opts.ProxyURL)
+		c.logger.Debug("sending API requests through proxy %s", proxy.Redacted())
+	}
+	if opts.CACertFile != "" {
+		c.logger.Debug("trusting certificate authorities in %s", opts.CACertFile)
+	}
+	if opts.InsecureSkipVerify {
+		c.logger.Warn("TLS certificate verification is disabled; the API token can be intercepted")
+	}
+
+	var roundTripper http.RoundTripper = transport
+	if c.logger.HTTPTraceEnabled() {
+		roundTripper = newTraceTransport(transport, c.logger, c.token())
+	}
+	c.httpClient.Transport = roundTripper
+	return nil
+}
+
+// loadCertPool returns the system certificate pool with the PEM certificates in
+// filePath added
+func loadCertPool(filePath string) (*x509.CertPool, error) {
+	data, err := os.ReadFile(filePath) // #nosec G304 -- Reading user-provided CA bundle is expected behavior
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CA certificate file: %w", err)
+	}
+
+	pool, err := x509.SystemCertPool()
+	if err != nil || pool == nil {
+		pool = x509.NewCertPool()
+	}
+	if !pool.AppendCertsFromPEM(data) {
+		return nil, fmt.Errorf("no PEM certificates found in CA certificate file %s", filePath)
+	}
+	return pool, nil
+}
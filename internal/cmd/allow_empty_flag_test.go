@@ -0,0 +1,82 @@
+package cmd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+
+	"replbac/internal/models"
+)
+
+// TestAllowEmptyFlagBehavior tests that --delete refuses to run against an empty roles directory unless --allow-empty is set
+func TestAllowEmptyFlagBehavior(t *testing.T) {
+	tests := []struct {
+		name          string
+		flags         []string
+		expectError   string
+		expectDeletes int
+	}{
+		{
+			name:        "empty directory with --delete is refused",
+			flags:       []string{"delete", "force"},
+			expectError: "refusing to delete all remote roles (pass --allow-empty to override)",
+		},
+		{
+			name:          "empty directory with --delete and --allow-empty deletes remote roles",
+			flags:         []string{"delete", "force", "allow-empty"},
+			expectDeletes: 2,
+		},
+		{
+			name:  "empty directory without --delete is a no-op",
+			flags: []string{"force"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tempDir := t.TempDir()
+			remoteRoles := []models.Role{
+				{ID: "1", Name: "admin", Resources: models.Resources{Allowed: []string{"*"}, Denied: []string{}}},
+				{ID: "2", Name: "viewer", Resources: models.Resources{Allowed: []string{"**/read"}, Denied: []string{}}},
+			}
+
+			mockCalls := &MockAPICalls{}
+			cmd := NewSyncCommandWithOptions(NewMockClient(mockCalls, remoteRoles), func(cmd *cobra.Command) {
+				cmd.Flags().Bool("allow-empty", false, "allow --delete with no role files")
+			})
+			for _, flag := range tt.flags {
+				if err := cmd.Flags().Set(flag, "true"); err != nil {
+					t.Fatalf("Failed to set %s flag: %v", flag, err)
+				}
+			}
+
+			var stdout, stderr bytes.Buffer
+			cmd.SetOut(&stdout)
+			cmd.SetErr(&stderr)
+			cmd.SetArgs([]string{tempDir})
+
+			err := cmd.Execute()
+			if tt.expectError != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.expectError) {
+					t.Fatalf("Expected error containing %q, got %v", tt.expectError, err)
+				}
+				if !strings.Contains(err.Error(), "no role files found in "+tempDir) {
+					t.Errorf("Expected error to name the directory, got %v", err)
+				}
+				if len(mockCalls.DeleteCalls) != 0 {
+					t.Errorf("Expected no delete calls, got %v", mockCalls.DeleteCalls)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no error but got: %v", err)
+			}
+
+			if len(mockCalls.DeleteCalls) != tt.expectDeletes {
+				t.Errorf("Expected %d delete calls, got %v", tt.expectDeletes, mockCalls.DeleteCalls)
+			}
+		})
+	}
+}
@@ -0,0 +1,187 @@
+package cmd
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"replbac/internal/api"
+	"replbac/internal/logging"
+	"replbac/internal/models"
+	"replbac/internal/sync"
+)
+
+var (
+	assignFrom    string
+	assignInvite  bool
+	assignInvRate float64
+	assignVerbose bool
+	assignDebug   bool
+)
+
+// assignCmd represents the assign command
+var assignCmd = &cobra.Command{
+	Use:   "assign --from <file.csv>",
+	Short: "Assign members to roles from a CSV of email,role rows",
+	Long: `Assign gives team members roles listed in a CSV file, one email,role pair
+per row, for example an onboarding export. A first row of "email,role" is
+treated as a header. It is a targeted tool for membership changes and, unlike
+sync, never creates, updates, or deletes roles or removes members.
+
+Members already on the team are moved to the role. Members who are not on the
+team are reported as failures unless --invite is given, in which case they are
+invited with the role, at most --invite-rate per second.
+
+Every row is attempted even if an earlier one fails, and the outcome of each
+is reported at the end. Malformed rows are reported with their line number and
+skipped. The command exits with an error if any row failed or was malformed.
+
+Environment Variables:
+  This command supports all global environment variables.
+  See 'replbac --help' for full environment variable documentation.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return RunAssignCommand(cmd, cfg)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(assignCmd)
+
+	assignCmd.Flags().StringVar(&assignFrom, "from", "", "CSV file of email,role rows to assign (required)")
+	_ = assignCmd.MarkFlagRequired("from")
+	assignCmd.Flags().BoolVar(&assignInvite, "invite", false, "invite members who are not on the team instead of failing their rows")
+	assignCmd.Flags().Float64Var(&assignInvRate, "invite-rate", DefaultInviteRate, "with --invite, send at most this many invitations per second (0 for no limit)")
+	assignCmd.Flags().BoolVar(&assignVerbose, "verbose", false, "enable info-level logging to stderr (progress and results)")
+	assignCmd.Flags().BoolVar(&assignDebug, "debug", false, "enable debug-level logging to stderr (detailed operation info)")
+}
+
+// RunAssignCommand creates an API client and applies the assignments in the --from file
+func RunAssignCommand(cmd *cobra.Command, config models.Config) error {
+	if cmd.OutOrStdout() == os.Stderr {
+		cmd.SetOut(os.Stdout)
+	}
+
+	var logger *logging.Logger
+	if assignDebug {
+		logger = logging.NewDebugLogger(cmd.ErrOrStderr())
+	} else {
+		logger = logging.NewLogger(cmd.ErrOrStderr(), assignVerbose)
+	}
+	if getBoolFlag(cmd, "debug-http") {
+		logger.EnableHTTPTrace()
+	}
+	teeLogFile(logger)
+
+	client, err := newAPIClient(config, logger, api.DefaultMaxRetries)
+	if err != nil {
+		logger.Error("failed to create API client: %v", err)
+		return fmt.Errorf("failed to create API client: %w", err)
+	}
+
+	return RunAssignCommandWithClient(cmd, restrictClient(client, config), logger)
+}
+
+// RunAssignCommandWithClient reads the --from CSV and assigns each member to their role
+// using client, reporting every row's outcome
+func RunAssignCommandWithClient(cmd *cobra.Command, client api.ClientInterface, logger *logging.Logger) error {
+	path := getStringFlag(cmd, "from")
+	file, err := os.Open(path) // #nosec G304 -- Reading user-provided file path is expected behavior
+	if err != nil {
+		return fmt.Errorf("failed to open assignments file: %w", err)
+	}
+	defer func() { _ = file.Close() }()
+
+	assignments, malformed, err := readAssignments(file)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	logger.Debug("read %d assignment(s) and %d malformed row(s) from %s", len(assignments), len(malformed), path)
+
+	executor := sync.NewExecutorWithMembersAndInvite(client, logger, getBoolFlag(cmd, "invite"))
+	executor.SetInviteRate(getFloat64Flag(cmd, "invite-rate"))
+	executor.SetContext(cmd.Context())
+	results, err := executor.AssignMembers(assignments)
+	if err != nil {
+		return err
+	}
+
+	failed, notOnTeam := 0, 0
+	for _, result := range results {
+		switch {
+		case result.Error != nil:
+			failed++
+			if errors.Is(result.Error, sync.ErrNotOnTeam) {
+				notOnTeam++
+			}
+			cmd.Printf("line %d: FAILED %s -> %s: %v\n", result.Line, result.Email, result.Role, result.Error)
+		case result.Action == sync.AssignmentUnchanged:
+			cmd.Printf("line %d: %s already has role %s\n", result.Line, result.Email, result.Role)
+		default:
+			cmd.Printf("line %d: %s %s to role %s\n", result.Line, result.Action, result.Email, result.Role)
+		}
+	}
+	for _, row := range malformed {
+		cmd.Printf("%s (skipped)\n", row)
+	}
+
+	cmd.Printf("Assignments: %d succeeded, %d failed, %d malformed row(s) skipped\n", len(results)-failed, failed, len(malformed))
+	if notOnTeam > 0 {
+		cmd.Println("Members not on the team can be invited with --invite")
+	}
+	if failed > 0 || len(malformed) > 0 {
+		return fmt.Errorf("%d of %d row(s) in %s were not assigned", failed+len(malformed), len(results)+len(malformed), path)
+	}
+	return nil
+}
+
+// readAssignments parses email,role rows from CSV data, skipping a leading "email,role"
+// header. Each malformed row is described, with its line number, in the returned
+// messages instead of being parsed. An error is only returned if the data cannot be read.
+func readAssignments(r io.Reader) ([]sync.MemberAssignment, []string, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	var assignments []sync.MemberAssignment
+	var malformed []string
+	for first := true; ; first = false {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			var parseErr *csv.ParseError
+			if errors.As(err, &parseErr) {
+				malformed = append(malformed, fmt.Sprintf("line %d: %v", parseErr.StartLine, parseErr.Err))
+				continue
+			}
+			return nil, nil, err
+		}
+		line, _ := reader.FieldPos(0)
+
+		if len(record) != 2 {
+			malformed = append(malformed, fmt.Sprintf("line %d: expected 2 fields (email,role), got %d", line, len(record)))
+			continue
+		}
+		email, role := strings.TrimSpace(record[0]), strings.TrimSpace(record[1])
+		if first && strings.EqualFold(email, "email") && strings.EqualFold(role, "role") {
+			continue
+		}
+		if !strings.Contains(email, "@") {
+			malformed = append(malformed, fmt.Sprintf("line %d: invalid email %q", line, email))
+			continue
+		}
+		if role == "" {
+			malformed = append(malformed, fmt.Sprintf("line %d: missing role for %s", line, email))
+			continue
+		}
+		assignments = append(assignments, sync.MemberAssignment{Email: email, Role: role, Line: line})
+	}
+	return assignments, malformed, nil
+}
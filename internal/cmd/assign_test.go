@@ -0,0 +1,109 @@
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+
+	"replbac/internal/logging"
+	"replbac/internal/models"
+)
+
+// NewAssignCommand creates an assign command that uses the given client
+func NewAssignCommand(client *teamClient) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:  "assign",
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return RunAssignCommandWithClient(cmd, client, logging.NewLogger(cmd.ErrOrStderr(), false))
+		},
+	}
+
+	cmd.Flags().String("from", "", "CSV file of email,role rows")
+	cmd.Flags().Bool("invite", false, "invite members who are not on the team")
+
+	return cmd
+}
+
+func TestAssignCommand(t *testing.T) {
+	csvPath := filepath.Join(t.TempDir(), "assignments.csv")
+	content := "email,role\n" +
+		"alice@example.com,admin\n" +
+		"bob@example.com,viewer,extra\n" +
+		"carol@example.com, viewer\n" +
+		"not-an-email,viewer\n" +
+		"dave@example.com,ghost\n"
+	// #nosec G306 -- Test files need readable permissions
+	if err := os.WriteFile(csvPath, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write CSV: %v", err)
+	}
+
+	roles := []models.Role{{ID: "id-admin", Name: "admin"}, {ID: "id-viewer", Name: "viewer"}}
+	client := &teamClient{
+		MockClient: NewMockClient(&MockAPICalls{}, roles),
+		team: []models.TeamMember{
+			{ID: "u1", Email: "alice@example.com", PolicyID: "id-viewer"},
+		},
+		assignments: map[string]string{},
+	}
+
+	cmd := NewAssignCommand(client)
+	var stdout bytes.Buffer
+	cmd.SetOut(&stdout)
+	cmd.SetErr(&bytes.Buffer{})
+	cmd.SetArgs([]string{"--from", csvPath})
+
+	if err := cmd.Execute(); err == nil {
+		t.Fatal("Expected an error when rows fail or are malformed")
+	}
+	output := stdout.String()
+
+	if client.assignments["alice@example.com"] != "id-admin" {
+		t.Errorf("Expected alice to be assigned to admin, got %v", client.assignments)
+	}
+	for _, expected := range []string{
+		"line 2: assigned alice@example.com to role admin",
+		"line 3: expected 2 fields (email,role), got 3 (skipped)",
+		"line 4: FAILED carol@example.com -> viewer: member carol@example.com is not on the team",
+		`line 5: invalid email "not-an-email" (skipped)`,
+		"line 6: FAILED dave@example.com -> ghost",
+		"Assignments: 1 succeeded, 2 failed, 2 malformed row(s) skipped",
+		"Members not on the team can be invited with --invite",
+	} {
+		if !strings.Contains(output, expected) {
+			t.Errorf("Expected output to contain %q, got:\n%s", expected, output)
+		}
+	}
+	if strings.Contains(output, "line 1") {
+		t.Errorf("Expected the header row to be skipped, got:\n%s", output)
+	}
+}
+
+func TestAssignCommandInvite(t *testing.T) {
+	csvPath := filepath.Join(t.TempDir(), "assignments.csv")
+	// #nosec G306 -- Test files need readable permissions
+	if err := os.WriteFile(csvPath, []byte("carol@example.com,viewer\n"), 0644); err != nil {
+		t.Fatalf("Failed to write CSV: %v", err)
+	}
+
+	client := &teamClient{
+		MockClient:  NewMockClient(&MockAPICalls{}, []models.Role{{ID: "id-viewer", Name: "viewer"}}),
+		assignments: map[string]string{},
+	}
+	cmd := NewAssignCommand(client)
+	var stdout bytes.Buffer
+	cmd.SetOut(&stdout)
+	cmd.SetErr(&bytes.Buffer{})
+	cmd.SetArgs([]string{"--from", csvPath, "--invite"})
+
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if !strings.Contains(stdout.String(), "line 1: invited carol@example.com to role viewer") {
+		t.Errorf("Expected carol to be invited, got:\n%s", stdout.String())
+	}
+}
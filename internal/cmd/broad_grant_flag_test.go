@@ -0,0 +1,90 @@
+package cmd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+
+	"replbac/internal/models"
+)
+
+// TestBroadGrantWarnings tests the --warn-broad and --strict wildcard checks
+func TestBroadGrantWarnings(t *testing.T) {
+	tests := []struct {
+		name          string
+		flags         []string
+		expectWarning bool
+		expectError   bool
+		expectCreates int
+	}{
+		{
+			name:          "no warning by default",
+			expectCreates: 2,
+		},
+		{
+			name:          "warns with --warn-broad without blocking",
+			flags:         []string{"warn-broad"},
+			expectWarning: true,
+			expectCreates: 2,
+		},
+		{
+			name:          "warns by default in dry-run",
+			flags:         []string{"dry-run"},
+			expectWarning: true,
+		},
+		{
+			name:          "--strict aborts before changes",
+			flags:         []string{"strict"},
+			expectWarning: true,
+			expectError:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tempDir := t.TempDir()
+			for _, role := range []models.Role{
+				{Name: "viewer", Resources: models.Resources{Allowed: []string{"*"}}},
+				{Name: "admin", Resources: models.Resources{Allowed: []string{"**/*"}, Denied: []string{"kots/app/*/delete"}}},
+			} {
+				if err := createTestRoleFile(tempDir, role); err != nil {
+					t.Fatalf("Failed to create role file: %v", err)
+				}
+			}
+
+			mockCalls := &MockAPICalls{}
+			cmd := NewSyncCommandWithOptions(NewMockClient(mockCalls, []models.Role{}), func(cmd *cobra.Command) {
+				cmd.Flags().Bool("warn-broad", false, "warn about catch-all grants")
+				cmd.Flags().Bool("strict", false, "abort on catch-all grants")
+			})
+			for _, flag := range tt.flags {
+				if err := cmd.Flags().Set(flag, "true"); err != nil {
+					t.Fatalf("Failed to set %s flag: %v", flag, err)
+				}
+			}
+			var stdout, stderr bytes.Buffer
+			cmd.SetOut(&stdout)
+			cmd.SetErr(&stderr)
+			cmd.SetArgs([]string{tempDir})
+
+			err := cmd.Execute()
+			if tt.expectError != (err != nil) {
+				t.Fatalf("Expected error: %v, got: %v", tt.expectError, err)
+			}
+
+			output := stdout.String()
+			hasWarning := strings.Contains(output, "Warning: role viewer grants wildcard '*'")
+			if hasWarning != tt.expectWarning {
+				t.Errorf("Expected warning: %v, got output:\n%s", tt.expectWarning, output)
+			}
+			if strings.Contains(output, "role admin grants wildcard") {
+				t.Errorf("Did not expect a warning for a wildcard narrowed by denies, got:\n%s", output)
+			}
+			if len(mockCalls.CreateCalls) != tt.expectCreates {
+				t.Errorf("Expected %d create calls, got %d", tt.expectCreates, len(mockCalls.CreateCalls))
+			}
+		})
+	}
+}
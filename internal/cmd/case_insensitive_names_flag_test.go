@@ -0,0 +1,106 @@
+package cmd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+
+	"replbac/internal/models"
+)
+
+// TestCaseInsensitiveNamesFlagBehavior tests that --case-insensitive-names matches roles whose names differ only by case
+func TestCaseInsensitiveNamesFlagBehavior(t *testing.T) {
+	tests := []struct {
+		name          string
+		foldCase      bool
+		localRoles    []models.Role
+		expectCreates int
+		expectUpdates int
+		expectDeletes int
+		expectError   string
+		expectOutput  string
+	}{
+		{
+			name:          "case-sensitive matching creates and deletes",
+			localRoles:    []models.Role{{ID: "1", Name: "Admin", Resources: models.Resources{Allowed: []string{"*"}, Denied: []string{"billing/*"}}}},
+			expectCreates: 1,
+			expectDeletes: 1,
+		},
+		{
+			name:          "case-insensitive matching updates",
+			foldCase:      true,
+			localRoles:    []models.Role{{ID: "1", Name: "Admin", Resources: models.Resources{Allowed: []string{"*"}, Denied: []string{"billing/*"}}}},
+			expectUpdates: 1,
+			expectOutput:  `Warning: matching local role "Admin" to remote role "admin" (names differ only by case)`,
+		},
+		{
+			name:     "case-insensitive duplicate local roles are rejected",
+			foldCase: true,
+			localRoles: []models.Role{
+				{Name: "Admin", Resources: models.Resources{Allowed: []string{"*"}}},
+				{Name: "ADMIN", Resources: models.Resources{Allowed: []string{"*"}}},
+			},
+			expectError: "differ only by case",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tempDir := t.TempDir()
+			for _, role := range tt.localRoles {
+				if err := createTestRoleFile(tempDir, role); err != nil {
+					t.Fatalf("Failed to create role file: %v", err)
+				}
+			}
+
+			remoteRoles := []models.Role{
+				{ID: "1", Name: "admin", Resources: models.Resources{Allowed: []string{"*"}, Denied: []string{}}},
+			}
+
+			mockCalls := &MockAPICalls{}
+			cmd := NewSyncCommandWithOptions(NewMockClient(mockCalls, remoteRoles), func(cmd *cobra.Command) {
+				cmd.Flags().Bool("case-insensitive-names", false, "match role names regardless of case")
+			})
+			flags := []string{"delete", "force"}
+			if tt.foldCase {
+				flags = append(flags, "case-insensitive-names")
+			}
+			for _, flag := range flags {
+				if err := cmd.Flags().Set(flag, "true"); err != nil {
+					t.Fatalf("Failed to set %s flag: %v", flag, err)
+				}
+			}
+
+			var stdout, stderr bytes.Buffer
+			cmd.SetOut(&stdout)
+			cmd.SetErr(&stderr)
+			cmd.SetArgs([]string{tempDir})
+
+			err := cmd.Execute()
+			if tt.expectError != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.expectError) {
+					t.Fatalf("Expected error containing %q, got %v", tt.expectError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no error but got: %v", err)
+			}
+
+			if len(mockCalls.CreateCalls) != tt.expectCreates {
+				t.Errorf("Expected %d create calls, got %+v", tt.expectCreates, mockCalls.CreateCalls)
+			}
+			if len(mockCalls.UpdateCalls) != tt.expectUpdates {
+				t.Errorf("Expected %d update calls, got %+v", tt.expectUpdates, mockCalls.UpdateCalls)
+			}
+			if len(mockCalls.DeleteCalls) != tt.expectDeletes {
+				t.Errorf("Expected %d delete calls, got %v", tt.expectDeletes, mockCalls.DeleteCalls)
+			}
+			if tt.expectOutput != "" && !strings.Contains(stdout.String(), tt.expectOutput) {
+				t.Errorf("Expected output to contain %q, got:\n%s", tt.expectOutput, stdout.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,87 @@
+package cmd
+
+import (
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"replbac/internal/api"
+	"replbac/internal/logging"
+	"replbac/internal/models"
+	"replbac/internal/roles"
+)
+
+// RoleNameCompletion is a cobra ValidArgsFunction that completes role names from
+// the local role files. Roles are read from the directory given by the command's
+// --dir flag when it has one, otherwise from the current directory. Any problem
+// loading roles yields no completions rather than an error, so a missing
+// directory never breaks the shell.
+func RoleNameCompletion(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	dir := "."
+	if cmd.Flags().Lookup("dir") != nil {
+		if value, _ := cmd.Flags().GetString("dir"); value != "" {
+			dir = value
+		}
+	}
+
+	return completeRoleNames(dir, args, toComplete), cobra.ShellCompDirectiveNoFileComp
+}
+
+// RemoteRoleNameCompletion is a cobra ValidArgsFunction that completes role names
+// from the Replicated API, for commands such as delete that act on remote roles.
+// Without an API token, or when the roles cannot be fetched, it yields no
+// completions rather than an error.
+func RemoteRoleNameCompletion(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if cfg.APIToken == "" {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	client, err := newAPIClient(cfg, logging.NewLogger(io.Discard, false), 0)
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	return completeRemoteRoleNames(client, args, toComplete), cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeRoleNames returns the sorted names of local roles in dir that start with
+// toComplete, excluding names already given as arguments
+func completeRoleNames(dir string, args []string, toComplete string) []string {
+	localRoles, err := roles.LoadRolesFromDirectory(dir)
+	if err != nil {
+		return nil
+	}
+	return matchingRoleNames(localRoles, args, toComplete)
+}
+
+// completeRemoteRoleNames returns the sorted names of the roles client fetches that
+// start with toComplete, excluding names already given as arguments
+func completeRemoteRoleNames(client api.ClientInterface, args []string, toComplete string) []string {
+	remoteRoles, err := client.GetRoles()
+	if err != nil {
+		return nil
+	}
+	return matchingRoleNames(remoteRoles, args, toComplete)
+}
+
+// matchingRoleNames returns the sorted, distinct names of roleList that start with
+// toComplete and are not among args
+func matchingRoleNames(roleList []models.Role, args []string, toComplete string) []string {
+	used := make(map[string]bool, len(args))
+	for _, arg := range args {
+		used[arg] = true
+	}
+
+	var names []string
+	for _, role := range roleList {
+		if used[role.Name] || !strings.HasPrefix(role.Name, toComplete) {
+			continue
+		}
+		used[role.Name] = true
+		names = append(names, role.Name)
+	}
+
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,102 @@
+package cmd
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/spf13/cobra"
+
+	"replbac/internal/models"
+)
+
+// TestRoleNameCompletion tests completion of role names from local role files
+func TestRoleNameCompletion(t *testing.T) {
+	tempDir := t.TempDir()
+	for _, name := range []string{"viewer", "admin", "app-admin", "support"} {
+		if err := createTestRoleFile(tempDir, models.Role{Name: name}); err != nil {
+			t.Fatalf("Failed to create role file: %v", err)
+		}
+	}
+
+	tests := []struct {
+		name       string
+		dir        string
+		args       []string
+		toComplete string
+		expected   []string
+	}{
+		{name: "all roles sorted", dir: tempDir, expected: []string{"admin", "app-admin", "support", "viewer"}},
+		{name: "prefix filter", dir: tempDir, toComplete: "a", expected: []string{"admin", "app-admin"}},
+		{name: "excludes names already given", dir: tempDir, args: []string{"admin"}, toComplete: "a", expected: []string{"app-admin"}},
+		{name: "missing directory yields nothing", dir: filepath.Join(tempDir, "missing"), expected: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := &cobra.Command{Use: "test"}
+			cmd.Flags().String("dir", "", "roles directory")
+			if err := cmd.Flags().Set("dir", tt.dir); err != nil {
+				t.Fatalf("Failed to set dir flag: %v", err)
+			}
+
+			names, directive := RoleNameCompletion(cmd, tt.args, tt.toComplete)
+			if directive != cobra.ShellCompDirectiveNoFileComp {
+				t.Errorf("Expected NoFileComp directive, got %v", directive)
+			}
+			if !reflect.DeepEqual(names, tt.expected) {
+				t.Errorf("Expected completions %v, got %v", tt.expected, names)
+			}
+		})
+	}
+}
+
+// TestRemoteRoleNameCompletion tests completion of role names from the API, as used
+// by commands that act on remote roles
+func TestRemoteRoleNameCompletion(t *testing.T) {
+	remote := []models.Role{{Name: "viewer"}, {Name: "admin"}, {Name: "app-admin"}}
+
+	names := completeRemoteRoleNames(NewMockClient(&MockAPICalls{}, remote), []string{"admin"}, "a")
+	if expected := []string{"app-admin"}; !reflect.DeepEqual(names, expected) {
+		t.Errorf("Expected completions %v, got %v", expected, names)
+	}
+
+	failing := NewMockClient(&MockAPICalls{}, remote)
+	failing.shouldError = true
+	if names := completeRemoteRoleNames(failing, nil, ""); names != nil {
+		t.Errorf("Expected no completions when the API fails, got %v", names)
+	}
+
+	saved := cfg
+	defer func() { cfg = saved }()
+	cfg = models.Config{}
+	if names, directive := RemoteRoleNameCompletion(deleteCmd, nil, ""); names != nil || directive != cobra.ShellCompDirectiveNoFileComp {
+		t.Errorf("Expected no completions without an API token, got %v (%v)", names, directive)
+	}
+}
+
+// TestRoleNameArgumentsComplete tests that the commands taking a role name complete it
+func TestRoleNameArgumentsComplete(t *testing.T) {
+	for _, cmd := range []*cobra.Command{deleteCmd, showCmd} {
+		if cmd.ValidArgsFunction == nil {
+			t.Errorf("%s has no role name completion", cmd.Name())
+			continue
+		}
+		if names, _ := cmd.ValidArgsFunction(cmd, []string{"viewer"}, ""); names != nil {
+			t.Errorf("%s completed a second role name: %v", cmd.Name(), names)
+		}
+	}
+}
+
+// TestCompletionCommandsSkipConfigValidation tests that completion works without an API token
+func TestCompletionCommandsSkipConfigValidation(t *testing.T) {
+	completion := &cobra.Command{Use: "completion"}
+	bash := &cobra.Command{Use: "bash"}
+	completion.AddCommand(bash)
+
+	for _, cmd := range []*cobra.Command{completion, bash, {Use: cobra.ShellCompRequestCmd}} {
+		if commandNeedsAPI(cmd) {
+			t.Errorf("Expected %q not to require API configuration", cmd.CommandPath())
+		}
+	}
+}
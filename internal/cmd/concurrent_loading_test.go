@@ -0,0 +1,83 @@
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"replbac/internal/models"
+)
+
+// TestConcurrentRoleLoading tests that local and remote roles load together and a failure in either aborts
+func TestConcurrentRoleLoading(t *testing.T) {
+	tests := []struct {
+		name          string
+		missingDir    bool
+		remoteError   bool
+		expectError   string
+		expectOutput  string
+		expectCreates int
+	}{
+		{
+			name:          "both loads complete",
+			expectCreates: 1,
+		},
+		{
+			name:        "local load failure aborts",
+			missingDir:  true,
+			expectError: "failed to load local roles",
+		},
+		{
+			name:         "remote fetch failure aborts after local warnings",
+			remoteError:  true,
+			expectError:  "API connection failed",
+			expectOutput: "Warning: Skipped invalid.yaml",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			if err := createTestRoleFile(dir, models.Role{Name: "viewer", Resources: models.Resources{Allowed: []string{"read"}}}); err != nil {
+				t.Fatalf("Failed to create role file: %v", err)
+			}
+			if err := os.WriteFile(filepath.Join(dir, "invalid.yaml"), []byte("resources: {}\n"), 0600); err != nil {
+				t.Fatalf("Failed to write invalid file: %v", err)
+			}
+			if tt.missingDir {
+				dir = filepath.Join(dir, "missing")
+			}
+
+			mockCalls := &MockAPICalls{}
+			mockClient := NewMockClient(mockCalls, []models.Role{})
+			mockClient.shouldError = tt.remoteError
+			cmd := NewSyncCommandWithOptions(mockClient, nil)
+			var stdout, stderr bytes.Buffer
+			cmd.SetOut(&stdout)
+			cmd.SetErr(&stderr)
+			cmd.SetArgs([]string{dir})
+
+			err := cmd.Execute()
+			if tt.expectError != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.expectError) {
+					t.Fatalf("Expected error containing %q, got: %v", tt.expectError, err)
+				}
+			} else if err != nil {
+				t.Fatalf("Expected no error but got: %v", err)
+			}
+
+			// The remote fetch runs alongside the local load, even when the local load fails
+			if mockCalls.GetCalls != 1 {
+				t.Errorf("Expected remote roles to be fetched once, got %d calls", mockCalls.GetCalls)
+			}
+			if len(mockCalls.CreateCalls) != tt.expectCreates {
+				t.Errorf("Expected %d create calls, got %d", tt.expectCreates, len(mockCalls.CreateCalls))
+			}
+			if tt.expectOutput != "" && !strings.Contains(stdout.String(), tt.expectOutput) {
+				t.Errorf("Expected output to contain %q, got:\n%s", tt.expectOutput, stdout.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,138 @@
+package cmd
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"replbac/internal/api"
+	"replbac/internal/config"
+	"replbac/internal/models"
+)
+
+// configCmd groups commands that inspect replbac's configuration
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Inspect replbac configuration",
+}
+
+// configShowCmd represents the config show command
+var configShowCmd = &cobra.Command{
+	Use:   "show",
+	Short: "Print the effective configuration and where each value came from",
+	Long: `Show prints the configuration replbac will use after combining the config
+file, environment variables, and command-line flags, along with the source of
+each value. The API token is masked. No API calls are made.
+
+Environment Variables:
+  See 'replbac --help' for full environment variable documentation.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if credentialStore != "" && apiToken == "" && credentialProvider == "" {
+			if err := applyCredentialStore(cmd, credentialStore); err != nil {
+				return withExitCode(ExitCodeConfiguration, err)
+			}
+		}
+		return RunConfigShowCommand(cmd, cfg, cfgSource, credentialProvider)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(configCmd)
+	configCmd.AddCommand(configShowCmd)
+}
+
+// RunConfigShowCommand prints the effective configuration. The credential provider,
+// if one is given, is consulted for the token exactly as commands that call the API do.
+func RunConfigShowCommand(cmd *cobra.Command, effective models.Config, resolution config.Resolution, provider string) error {
+	token, tokenSource := maskToken(effective.APIToken), resolution.Sources["api_token"]
+	if provider != "" {
+		token, tokenSource = providerToken(provider), "credential provider "+provider
+	}
+
+	file := resolution.File
+	if file == "" {
+		file = "none"
+	}
+	version := effective.APIVersion
+	if version == "" {
+		version = models.DefaultAPIVersion
+	}
+	protected := "none"
+	if len(effective.ProtectedRoles) > 0 {
+		protected = strings.Join(effective.ProtectedRoles, ", ")
+	}
+	ignoredResources := "none"
+	if len(effective.IgnoreResources) > 0 {
+		ignoredResources = strings.Join(effective.IgnoreResources, ", ")
+	}
+	manage := effective.Manage
+	if manage == "" {
+		manage = models.ManageAll
+	}
+	transforms := "none"
+	if len(effective.Transforms) > 0 {
+		names := make([]string, 0, len(effective.Transforms))
+		for _, transform := range effective.Transforms {
+			names = append(names, transform.Name)
+		}
+		transforms = strings.Join(names, ", ")
+	}
+
+	proxyURL := "none; HTTPS_PROXY, HTTP_PROXY, and NO_PROXY apply"
+	if effective.Proxy != "" {
+		proxyURL = effective.Proxy
+		if parsed, err := url.Parse(effective.Proxy); err == nil {
+			proxyURL = parsed.Redacted()
+		}
+	}
+	caCert := "system only"
+	if effective.CACert != "" {
+		caCert = effective.CACert
+	}
+
+	cmd.Printf("Config file: %s\n", file)
+	cmd.Printf("API endpoint: %s (built in)\n", models.ReplicatedAPIEndpoint)
+	cmd.Printf("API version: %s (%s)\n", version, resolution.Sources["api_version"])
+	cmd.Printf("API token: %s (%s)\n", token, tokenSource)
+	cmd.Printf("Log level: %s (%s)\n", effective.LogLevel, resolution.Sources["log_level"])
+	cmd.Printf("Confirm: %t (%s)\n", effective.Confirm, resolution.Sources["confirm"])
+	cmd.Printf("Protected roles: %s (%s)\n", protected, resolution.Sources["protected_roles"])
+	cmd.Printf("Ignored resources: %s (%s)\n", ignoredResources, resolution.Sources["ignore_resources"])
+	cmd.Printf("Manage: %s (%s)\n", manage, resolution.Sources["manage"])
+	cmd.Printf("Read-only: %t (%s)\n", effective.ReadOnly, resolution.Sources["read_only"])
+	cmd.Printf("Transforms: %s (%s)\n", transforms, resolution.Sources["transforms"])
+	cmd.Printf("Proxy: %s (%s)\n", proxyURL, resolution.Sources["proxy"])
+	cmd.Printf("CA certificates: %s (%s)\n", caCert, resolution.Sources["ca_cert"])
+	cmd.Printf("Insecure skip verify: %t (%s)\n", effective.InsecureSkipVerify, resolution.Sources["insecure_skip_verify"])
+	cmd.Printf("Retries: %d per request, with exponential backoff (built in)\n", api.DefaultMaxRetries)
+	return nil
+}
+
+// providerToken returns the masked token supplied by a credential provider, or a
+// description of why it could not be obtained
+func providerToken(spec string) string {
+	provider, err := api.NewCredentialProvider(spec)
+	if err != nil {
+		return fmt.Sprintf("(unavailable: %v)", err)
+	}
+	token, err := provider.Token()
+	if err != nil {
+		return fmt.Sprintf("(unavailable: %v)", err)
+	}
+	return maskToken(token)
+}
+
+// maskToken hides all but the first four characters of an API token
+func maskToken(token string) string {
+	switch {
+	case token == "":
+		return "(not set)"
+	case len(token) <= 4:
+		return "****"
+	default:
+		return token[:4] + "****"
+	}
+}
@@ -0,0 +1,127 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"replbac/internal/config"
+	"replbac/internal/models"
+)
+
+// runRootPreRun runs the real root PersistentPreRunE for the sync command with the given
+// --config and --api-token values, restoring the global configuration afterwards
+func runRootPreRun(t *testing.T, configPath, token string) error {
+	t.Helper()
+
+	savedCfg, savedSource := cfg, cfgSource
+	savedFile, savedToken := cfgFile, apiToken
+	savedStore, savedProvider := credentialStore, credentialProvider
+	t.Cleanup(func() {
+		cfg, cfgSource = savedCfg, savedSource
+		cfgFile, apiToken = savedFile, savedToken
+		credentialStore, credentialProvider = savedStore, savedProvider
+	})
+
+	cfg, cfgSource = models.Config{}, config.Resolution{}
+	cfgFile, apiToken = configPath, token
+	credentialStore, credentialProvider = "", ""
+	return rootCmd.PersistentPreRunE(syncCmd, nil)
+}
+
+// writeConfigFile writes a config file holding token and returns its path
+func writeConfigFile(t *testing.T, dir, token string) string {
+	t.Helper()
+	path := filepath.Join(dir, "replbac.yaml")
+	// #nosec G306 -- Test files need readable permissions
+	if err := os.WriteFile(path, []byte("api_token: "+token+"\n"), 0644); err != nil {
+		t.Fatalf("Failed to write config file: %v", err)
+	}
+	return path
+}
+
+func TestConfigFlagSuppliesSyncToken(t *testing.T) {
+	t.Setenv("REPLICATED_API_TOKEN", "")
+	t.Setenv("REPLBAC_API_TOKEN", "")
+
+	// --config replaces REPLBAC_CONFIG rather than adding to it
+	t.Setenv("REPLBAC_CONFIG", writeConfigFile(t, t.TempDir(), "env-path-token"))
+	configPath := writeConfigFile(t, t.TempDir(), "custom-token")
+
+	if err := runRootPreRun(t, configPath, ""); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if cfg.APIToken != "custom-token" {
+		t.Errorf("Expected the token from %s, got %q", configPath, cfg.APIToken)
+	}
+	if got, want := cfgSource.Sources["api_token"], "config file "+configPath; got != want {
+		t.Errorf("Expected api_token source %q, got %q", want, got)
+	}
+}
+
+func TestConfigFlagPrecedence(t *testing.T) {
+	configPath := writeConfigFile(t, t.TempDir(), "file-token")
+
+	tests := []struct {
+		name        string
+		envToken    string
+		flagToken   string
+		expectToken string
+		expectFrom  string
+	}{
+		{
+			name:        "config file alone",
+			expectToken: "file-token",
+			expectFrom:  "config file " + configPath,
+		},
+		{
+			name:        "environment overrides config file",
+			envToken:    "env-token",
+			expectToken: "env-token",
+			expectFrom:  "environment variable REPLICATED_API_TOKEN",
+		},
+		{
+			name:        "flag overrides environment",
+			envToken:    "env-token",
+			flagToken:   "flag-token",
+			expectToken: "flag-token",
+			expectFrom:  "flag --api-token",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("REPLICATED_API_TOKEN", tt.envToken)
+			t.Setenv("REPLBAC_API_TOKEN", "")
+
+			if err := runRootPreRun(t, configPath, tt.flagToken); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if cfg.APIToken != tt.expectToken {
+				t.Errorf("Expected token %q, got %q", tt.expectToken, cfg.APIToken)
+			}
+			if got := cfgSource.Sources["api_token"]; !strings.HasPrefix(got, tt.expectFrom) {
+				t.Errorf("Expected api_token source %q, got %q", tt.expectFrom, got)
+			}
+		})
+	}
+}
+
+func TestConfigFlagMissingFile(t *testing.T) {
+	// A token available elsewhere does not excuse a --config path that is wrong
+	t.Setenv("REPLICATED_API_TOKEN", "env-token")
+	t.Setenv("REPLBAC_CONFIG", writeConfigFile(t, t.TempDir(), "env-path-token"))
+	missing := filepath.Join(t.TempDir(), "missing.yaml")
+
+	err := runRootPreRun(t, missing, "")
+	if err == nil {
+		t.Fatal("Expected an error for a missing --config file")
+	}
+	if !strings.Contains(err.Error(), "config file "+missing+" does not exist") {
+		t.Errorf("Expected the missing path in the error, got: %v", err)
+	}
+	if code := ExitCode(err); code != ExitCodeConfiguration {
+		t.Errorf("Expected exit code %d, got %d", ExitCodeConfiguration, code)
+	}
+}
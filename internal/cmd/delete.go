@@ -0,0 +1,172 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"replbac/internal/api"
+	"replbac/internal/logging"
+	"replbac/internal/models"
+	"replbac/internal/sync"
+)
+
+var (
+	deleteDryRun   bool
+	deleteForce    bool
+	deleteReassign string
+	deletePromptTO time.Duration
+	deleteVerbose  bool
+	deleteDebug    bool
+)
+
+// deleteCmd represents the delete command
+var deleteCmd = &cobra.Command{
+	Use:   "delete <role-name>",
+	Short: "Delete a single role from the Replicated API",
+	Long: `Delete removes one remote role by name, without removing a role file and
+running a full sync with --delete.
+
+The role's assigned members are listed before it is deleted. Use --reassign-to
+to move them to another role first; otherwise they are left without this role.
+
+Use --dry-run to preview the deletion.
+Use --force to skip the confirmation prompt.
+
+Environment Variables:
+  This command supports all global environment variables.
+  See 'replbac --help' for full environment variable documentation.`,
+	Args: cobra.ExactArgs(1),
+	ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) > 0 {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		return RemoteRoleNameCompletion(cmd, args, toComplete)
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return RunDeleteCommand(cmd, args[0], cfg, deleteDryRun, deleteForce)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(deleteCmd)
+
+	deleteCmd.Flags().BoolVar(&deleteDryRun, "dry-run", false, "preview the deletion without applying it")
+	deleteCmd.Flags().BoolVar(&deleteForce, "force", false, "skip the confirmation prompt")
+	deleteCmd.Flags().StringVar(&deleteReassign, "reassign-to", "", "assign the role's members to this role before deleting it")
+	deleteCmd.Flags().DurationVar(&deletePromptTO, "prompt-timeout", 0, "treat an unanswered confirmation prompt as \"no\" after this long, e.g. 30s (default: wait indefinitely)")
+	deleteCmd.Flags().BoolVar(&deleteVerbose, "verbose", false, "enable info-level logging to stderr (progress and results)")
+	deleteCmd.Flags().BoolVar(&deleteDebug, "debug", false, "enable debug-level logging to stderr (detailed operation info)")
+
+	_ = deleteCmd.RegisterFlagCompletionFunc("reassign-to", RemoteRoleNameCompletion)
+}
+
+// RunDeleteCommand creates an API client and deletes a single role
+func RunDeleteCommand(cmd *cobra.Command, roleName string, config models.Config, dryRun, force bool) error {
+	if cmd.OutOrStdout() == os.Stderr {
+		cmd.SetOut(os.Stdout)
+	}
+
+	var logger *logging.Logger
+	if deleteDebug {
+		logger = logging.NewDebugLogger(cmd.ErrOrStderr())
+	} else {
+		logger = logging.NewLogger(cmd.ErrOrStderr(), deleteVerbose)
+	}
+	if getBoolFlag(cmd, "debug-http") {
+		logger.EnableHTTPTrace()
+	}
+	teeLogFile(logger)
+
+	client, err := newAPIClient(config, logger, api.DefaultMaxRetries)
+	if err != nil {
+		logger.Error("failed to create API client: %v", err)
+		return fmt.Errorf("failed to create API client: %w", err)
+	}
+
+	return RunDeleteCommandWithClient(cmd, roleName, restrictClient(client, config), config, dryRun, promptsApproved(force, config))
+}
+
+// RunDeleteCommandWithClient deletes a single role using client, reassigning its members
+// first when --reassign-to is set. Roles matching protected_roles in config are refused.
+func RunDeleteCommandWithClient(cmd *cobra.Command, roleName string, client api.ClientInterface, config models.Config, dryRun, force bool) error {
+	if (sync.CompareOptions{Protected: config.ProtectedRoles}).Protects(roleName) {
+		return fmt.Errorf("role %q matches protected_roles in the configuration and cannot be deleted", roleName)
+	}
+
+	remoteRoles, err := client.GetRoles()
+	if err != nil {
+		return fmt.Errorf("failed to get remote roles: %w", err)
+	}
+
+	role, exists := findRole(remoteRoles, roleName)
+	if !exists {
+		return fmt.Errorf("role %q does not exist", roleName)
+	}
+
+	reassignTo := getStringFlag(cmd, "reassign-to")
+	var target models.Role
+	if reassignTo != "" {
+		if reassignTo == roleName {
+			return fmt.Errorf("cannot reassign members of role %q to itself", roleName)
+		}
+		target, exists = findRole(remoteRoles, reassignTo)
+		if !exists {
+			return fmt.Errorf("role %q given to --reassign-to does not exist", reassignTo)
+		}
+	}
+
+	if len(role.Members) > 0 {
+		cmd.Printf("Warning: role %s has %d assigned member(s):\n", roleName, len(role.Members))
+		for _, member := range role.Members {
+			cmd.Printf("  - %s\n", member)
+		}
+		if reassignTo == "" {
+			cmd.Printf("Help: Use --reassign-to ROLE to move them to another role before deleting\n")
+		}
+	}
+
+	if dryRun {
+		if reassignTo != "" && len(role.Members) > 0 {
+			cmd.Printf("Would reassign %d member(s) to role %s\n", len(role.Members), reassignTo)
+		}
+		cmd.Printf("Would delete role %s\n", roleName)
+		return nil
+	}
+
+	confirmed, err := confirmOperation(cmd, force, "", fmt.Sprintf("Delete role %s? (y/N): ", roleName), "deletions")
+	if err != nil {
+		return err
+	}
+	if !confirmed {
+		cmd.Println("Operation cancelled by user")
+		return nil
+	}
+
+	if reassignTo != "" {
+		for _, member := range role.Members {
+			if err := client.AssignMemberRole(member, target.ID); err != nil {
+				return fmt.Errorf("failed to reassign member %s to role %s: %w", member, reassignTo, err)
+			}
+			cmd.Printf("Reassigned %s to role %s\n", member, reassignTo)
+		}
+	}
+
+	if err := client.DeleteRole(roleName); err != nil {
+		return fmt.Errorf("failed to delete role '%s': %w", roleName, err)
+	}
+	cmd.Printf("Deleted role %s\n", roleName)
+	return nil
+}
+
+// findRole returns the role with the given name
+func findRole(roles []models.Role, name string) (models.Role, bool) {
+	for _, role := range roles {
+		if role.Name == name {
+			return role, true
+		}
+	}
+	return models.Role{}, false
+}
@@ -0,0 +1,161 @@
+package cmd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+
+	"replbac/internal/models"
+)
+
+// assignRecordingClient records member assignments made through the mock client
+type assignRecordingClient struct {
+	*MockClient
+	assignments map[string]string
+}
+
+// AssignMemberRole records the role ID assigned to a member
+func (a *assignRecordingClient) AssignMemberRole(memberEmail, roleID string) error {
+	a.assignments[memberEmail] = roleID
+	return nil
+}
+
+// NewDeleteCommand creates a delete command that uses the given client and configuration
+func NewDeleteCommand(client *assignRecordingClient, config models.Config) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:  "delete <role-name>",
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			dryRun, _ := cmd.Flags().GetBool("dry-run")
+			force, _ := cmd.Flags().GetBool("force")
+			return RunDeleteCommandWithClient(cmd, args[0], client, config, dryRun, force)
+		},
+	}
+
+	cmd.Flags().Bool("dry-run", false, "preview the deletion without applying it")
+	cmd.Flags().Bool("force", false, "skip the confirmation prompt")
+	cmd.Flags().String("reassign-to", "", "assign the role's members to this role before deleting it")
+
+	return cmd
+}
+
+func TestDeleteCommand(t *testing.T) {
+	tests := []struct {
+		name              string
+		args              []string
+		input             string
+		expectError       string
+		expectDeletes     []string
+		expectAssignments map[string]string
+		expectOutput      []string
+	}{
+		{
+			name:          "deletes role with --force",
+			args:          []string{"viewer", "--force"},
+			expectDeletes: []string{"viewer"},
+			expectOutput:  []string{"Deleted role viewer"},
+		},
+		{
+			name:          "deletes role after confirmation",
+			args:          []string{"viewer"},
+			input:         "y\n",
+			expectDeletes: []string{"viewer"},
+			expectOutput:  []string{"Delete role viewer? (y/N): ", "Deleted role viewer"},
+		},
+		{
+			name:         "declined confirmation deletes nothing",
+			args:         []string{"viewer"},
+			input:        "n\n",
+			expectOutput: []string{"Operation cancelled by user"},
+		},
+		{
+			name:         "dry run deletes nothing",
+			args:         []string{"viewer", "--dry-run"},
+			expectOutput: []string{"Would delete role viewer"},
+		},
+		{
+			name:          "warns about assigned members",
+			args:          []string{"editor", "--force"},
+			expectDeletes: []string{"editor"},
+			expectOutput: []string{
+				"Warning: role editor has 2 assigned member(s):",
+				"  - a@example.com",
+				"Help: Use --reassign-to ROLE",
+			},
+		},
+		{
+			name:              "reassigns members before deleting",
+			args:              []string{"editor", "--force", "--reassign-to", "viewer"},
+			expectDeletes:     []string{"editor"},
+			expectAssignments: map[string]string{"a@example.com": "viewer-id", "b@example.com": "viewer-id"},
+			expectOutput:      []string{"Reassigned a@example.com to role viewer", "Deleted role editor"},
+		},
+		{
+			name:        "missing role is an error",
+			args:        []string{"missing", "--force"},
+			expectError: `role "missing" does not exist`,
+		},
+		{
+			name:        "missing reassignment target is an error",
+			args:        []string{"editor", "--force", "--reassign-to", "missing"},
+			expectError: `role "missing" given to --reassign-to does not exist`,
+		},
+		{
+			name:        "protected role is refused",
+			args:        []string{"admin", "--force"},
+			expectError: `role "admin" matches protected_roles in the configuration and cannot be deleted`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			remoteRoles := []models.Role{
+				{ID: "editor-id", Name: "editor", Resources: models.Resources{Allowed: []string{"*"}}, Members: []string{"a@example.com", "b@example.com"}},
+				{ID: "viewer-id", Name: "viewer", Resources: models.Resources{Allowed: []string{"**/read"}}},
+				{ID: "admin-id", Name: "admin", Resources: models.Resources{Allowed: []string{"**/*"}}},
+			}
+			mockCalls := &MockAPICalls{}
+			client := &assignRecordingClient{MockClient: NewMockClient(mockCalls, remoteRoles), assignments: map[string]string{}}
+
+			cmd := NewDeleteCommand(client, models.Config{ProtectedRoles: []string{"adm*"}})
+			var stdout bytes.Buffer
+			cmd.SetOut(&stdout)
+			cmd.SetErr(&stdout)
+			cmd.SetIn(strings.NewReader(tt.input))
+			cmd.SetArgs(tt.args)
+
+			err := cmd.Execute()
+			if tt.expectError != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.expectError) {
+					t.Fatalf("Expected error containing %q, got %v", tt.expectError, err)
+				}
+				if len(mockCalls.DeleteCalls) != 0 {
+					t.Errorf("Expected no delete calls, got %v", mockCalls.DeleteCalls)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no error but got: %v", err)
+			}
+
+			if strings.Join(mockCalls.DeleteCalls, ",") != strings.Join(tt.expectDeletes, ",") {
+				t.Errorf("Expected delete calls %v, got %v", tt.expectDeletes, mockCalls.DeleteCalls)
+			}
+			if len(client.assignments) != len(tt.expectAssignments) {
+				t.Errorf("Expected assignments %v, got %v", tt.expectAssignments, client.assignments)
+			}
+			for member, roleID := range tt.expectAssignments {
+				if client.assignments[member] != roleID {
+					t.Errorf("Expected %s assigned to %s, got %q", member, roleID, client.assignments[member])
+				}
+			}
+			for _, expected := range tt.expectOutput {
+				if !strings.Contains(stdout.String(), expected) {
+					t.Errorf("Expected output to contain %q, got:\n%s", expected, stdout.String())
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,218 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"replbac/internal/api"
+	"replbac/internal/logging"
+	"replbac/internal/models"
+	"replbac/internal/roles"
+	"replbac/internal/sync"
+)
+
+var (
+	diffAgainst string
+	diffSummary bool
+	diffVerbose bool
+	diffDebug   bool
+	diffFold    bool
+	diffNames   bool
+	diffUnified bool
+	diffContext int
+	diffDenies  bool
+	diffMerge   bool
+	diffNoMemb  bool
+)
+
+// diffCmd represents the diff command
+var diffCmd = &cobra.Command{
+	Use:   "diff [directory]",
+	Short: "Show differences between local role files and remote roles",
+	Long: `Diff compares role definitions in local YAML files with the roles in the
+Replicated platform and reports what a sync would create, update, or delete.
+It never makes changes.
+
+Use --against to compare with a saved snapshot of remote roles instead of the
+live API. A snapshot is what pull writes: a directory of role files, or the
+single file written by pull --single. No API token is required in this mode,
+which makes it suitable for offline review or for diffing against a
+known-good baseline kept in version control.
+
+Environment Variables:
+  This command supports all global environment variables.
+  See 'replbac --help' for full environment variable documentation.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return RunDiffCommand(cmd, args, cfg, diffAgainst)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(diffCmd)
+
+	// Diff-specific flags
+	diffCmd.Flags().StringVar(&diffAgainst, "against", "", "compare with a snapshot of remote roles written by pull (a directory or a --single file) instead of the live API")
+	diffCmd.Flags().BoolVar(&diffSummary, "summary-only", false, "show per-role change counts instead of every added or removed entry")
+	diffCmd.Flags().BoolVar(&diffFold, "case-insensitive-names", false, "match local and remote role names regardless of case")
+	diffCmd.Flags().BoolVar(&diffUnified, "unified", false, "show each changed role as a unified diff of its YAML, remote to local")
+	diffCmd.Flags().IntVar(&diffContext, "diff-context", sync.DefaultDiffContext, "number of unchanged lines to show around each change in unified diffs (implies --unified)")
+	diffCmd.Flags().BoolVar(&diffMerge, "merge-duplicates", false, "merge role files that share a role name, unioning their allowed, denied, and members lists")
+	diffCmd.Flags().BoolVar(&diffDenies, "normalize-denies", false, "before comparing, drop denied entries that no allowed entry can reach")
+	diffCmd.Flags().BoolVar(&diffNoMemb, "no-members", false, "compare role definitions only, ignoring members, without reading team members from the API")
+	diffCmd.Flags().BoolVar(&diffNames, "show-names", false, "show each member's name and username from the API next to their email")
+	diffCmd.Flags().BoolVar(&diffVerbose, "verbose", false, "enable info-level logging to stderr (progress and results)")
+	diffCmd.Flags().BoolVar(&diffDebug, "debug", false, "enable debug-level logging to stderr (detailed operation info)")
+}
+
+// RunDiffCommand creates an API client when needed and runs the diff
+func RunDiffCommand(cmd *cobra.Command, args []string, config models.Config, against string) error {
+	// Ensure command output goes to stdout and logs go to stderr (unless already set for testing)
+	if cmd.OutOrStdout() == os.Stderr {
+		cmd.SetOut(os.Stdout)
+	}
+	if cmd.ErrOrStderr() == os.Stdout {
+		cmd.SetErr(os.Stderr)
+	}
+
+	var logger *logging.Logger
+	if diffDebug {
+		logger = logging.NewDebugLogger(cmd.ErrOrStderr())
+	} else {
+		logger = logging.NewLogger(cmd.ErrOrStderr(), diffVerbose)
+	}
+	if getBoolFlag(cmd, "debug-http") {
+		logger.EnableHTTPTrace()
+	}
+	teeLogFile(logger)
+
+	targetDir := "."
+	if len(args) > 0 {
+		targetDir = args[0]
+	}
+
+	if err := ValidateDirectoryAccess(targetDir); err != nil {
+		return HandleFileSystemError(cmd, err, targetDir)
+	}
+
+	// A snapshot comparison never touches the API
+	if against != "" {
+		return RunDiffCommandWithClient(cmd, targetDir, against, nil, logger, config)
+	}
+
+	if err := ValidateConfiguration(config); err != nil {
+		return HandleConfigurationError(cmd, err)
+	}
+
+	logger.Debug("creating API client")
+	client, err := newAPIClient(config, logger, api.DefaultMaxRetries)
+	if err != nil {
+		logger.Error("failed to create API client: %v", err)
+		return fmt.Errorf("failed to create API client: %w", err)
+	}
+
+	return RunDiffCommandWithClient(cmd, targetDir, "", restrictClient(client, config), logger, config)
+}
+
+// RunDiffCommandWithClient compares local roles, after the transforms in config, with
+// either a snapshot file or the roles returned by client. The client is only used when
+// against is empty.
+func RunDiffCommandWithClient(cmd *cobra.Command, targetDir, against string, client api.ClientInterface, logger *logging.Logger, config models.Config) error {
+	unified, context := unifiedDiffOptions(cmd)
+	if context < 0 {
+		return fmt.Errorf("--diff-context must be at least 0, got %d", context)
+	}
+	pipeline, err := roles.NewPipeline(config.Transforms)
+	if err != nil {
+		return HandleConfigurationError(cmd, fmt.Errorf("invalid transforms configuration: %w", err))
+	}
+
+	logger.Debug("loading roles from directory: %s", targetDir)
+	loadResult, err := roles.LoadRolesFromDirectoryWithDetails(targetDir)
+	if err != nil {
+		return fmt.Errorf("failed to load local roles: %w", err)
+	}
+
+	for _, skipped := range loadResult.SkippedFiles {
+		cmd.Printf("Warning: Skipped %s (%s)\n", skipped.Path, skipped.Reason)
+	}
+
+	localRoles, err := resolveDuplicateRoles(cmd, loadResult.Roles)
+	if err != nil {
+		return fmt.Errorf("failed to load local roles: %w", err)
+	}
+
+	if getBoolFlag(cmd, "case-insensitive-names") {
+		if err := roles.ValidateCaseInsensitiveNames(localRoles); err != nil {
+			return fmt.Errorf("failed to load local roles: %w", err)
+		}
+	}
+
+	localRoles, err = transformRoles(cmd, pipeline, localRoles, logger)
+	if err != nil {
+		return err
+	}
+
+	var remoteRoles []models.Role
+	if against != "" {
+		cmd.Printf("Comparing roles in %s against snapshot %s\n", targetDir, against)
+		remoteRoles, err = roles.LoadSnapshot(against)
+		if err != nil {
+			return fmt.Errorf("failed to load snapshot: %w", err)
+		}
+	} else {
+		cmd.Printf("Comparing roles in %s against Replicated API\n", targetDir)
+		remoteRoles, err = getRemoteRoles(client, !getBoolFlag(cmd, "no-members"))
+		if err != nil {
+			return HandleSyncError(cmd, fmt.Errorf("failed to get remote roles: %w", err))
+		}
+	}
+	if getBoolFlag(cmd, "no-members") {
+		logger.Debug("ignoring role members (--no-members)")
+		localRoles = withoutMembers(localRoles)
+		remoteRoles = withoutMembers(remoteRoles)
+	}
+	localRoles, remoteRoles = normalizeDenies(cmd, localRoles, remoteRoles, logger)
+	logger.Debug("comparing %d local roles with %d remote roles", len(localRoles), len(remoteRoles))
+
+	// Roles protected in the configuration are never deleted by sync, so they are not differences
+	opts := compareOptions(cmd, localRoles, remoteRoles, loadResult.Ignore, logger)
+	opts.Protected = config.ProtectedRoles
+	opts.IgnoreResources = config.IgnoreResources
+	opts.Manage = config.Manage
+	plan, err := sync.CompareRolesWithOptions(localRoles, remoteRoles, opts)
+	if err != nil {
+		return fmt.Errorf("failed to compare roles: %w", err)
+	}
+
+	if !plan.HasChanges() {
+		cmd.Println("No differences found")
+		return nil
+	}
+
+	cmd.Printf("Differences: %s\n\n", plan.Summary())
+	if getBoolFlag(cmd, "summary-only") {
+		cmd.Println(sync.DescribePlanSummary(plan, true))
+	} else if unified {
+		description, err := sync.DescribePlanUnified(plan, remoteRoles, context)
+		if err != nil {
+			return fmt.Errorf("failed to render diff: %w", err)
+		}
+		cmd.Println(description)
+	} else {
+		cmd.Println(sync.DescribePlanWithNames(plan, true, memberNames(cmd, remoteRoles)))
+	}
+
+	return nil
+}
+
+// unifiedDiffOptions reports whether unified diffs were requested, either with --unified
+// or by setting --diff-context, and how many lines of context to show
+func unifiedDiffOptions(cmd *cobra.Command) (bool, int) {
+	if cmd.Flags().Lookup("diff-context") == nil {
+		return getBoolFlag(cmd, "unified"), sync.DefaultDiffContext
+	}
+	return getBoolFlag(cmd, "unified") || cmd.Flags().Changed("diff-context"), getIntFlag(cmd, "diff-context")
+}
@@ -0,0 +1,323 @@
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+
+	"replbac/internal/logging"
+	"replbac/internal/models"
+)
+
+// writeSnapshot writes roles to a new directory as pull would and returns its path
+func writeSnapshot(t *testing.T, roles ...models.Role) string {
+	t.Helper()
+	dir := t.TempDir()
+	for _, role := range roles {
+		if err := createTestRoleFile(dir, role); err != nil {
+			t.Fatalf("Failed to write snapshot: %v", err)
+		}
+	}
+	return dir
+}
+
+// TestDiffCommand tests comparing local roles against a snapshot or the API
+func TestDiffCommand(t *testing.T) {
+	tests := []struct {
+		name           string
+		localRoles     []models.Role
+		snapshot       []models.Role
+		against        string // Snapshot path to use instead of writing snapshot
+		mockAPIRoles   []models.Role
+		expectError    bool
+		expectOutput   []string
+		expectAPICalls int
+	}{
+		{
+			name: "snapshot with differences",
+			localRoles: []models.Role{
+				{Name: "admin", Resources: models.Resources{Allowed: []string{"**/*"}}},
+				{Name: "viewer", Resources: models.Resources{Allowed: []string{"kots/app/*/read"}}},
+			},
+			snapshot: []models.Role{
+				{ID: "1", Name: "admin", Resources: models.Resources{Allowed: []string{"kots/app/*"}}},
+				{ID: "2", Name: "legacy", Resources: models.Resources{Allowed: []string{"**/*"}}},
+			},
+			expectOutput: []string{
+				"against snapshot",
+				"1 to create, 1 to update, 1 to delete",
+				"CREATE: viewer",
+				"UPDATE: admin",
+				"+ allowed: **/*",
+				"- allowed: kots/app/*",
+				"DELETE: legacy",
+			},
+			expectAPICalls: 0,
+		},
+		{
+			name: "snapshot matching local roles",
+			localRoles: []models.Role{
+				{Name: "admin", Resources: models.Resources{Allowed: []string{"**/*"}}},
+			},
+			snapshot:       []models.Role{{ID: "1", Name: "admin", Resources: models.Resources{Allowed: []string{"**/*"}}}},
+			expectOutput:   []string{"No differences found"},
+			expectAPICalls: 0,
+		},
+		{
+			name: "missing snapshot",
+			localRoles: []models.Role{
+				{Name: "admin", Resources: models.Resources{Allowed: []string{"**/*"}}},
+			},
+			against:     filepath.Join(os.TempDir(), "replbac-missing-snapshot"),
+			expectError: true,
+		},
+		{
+			name: "live API comparison",
+			localRoles: []models.Role{
+				{Name: "admin", Resources: models.Resources{Allowed: []string{"**/*"}}},
+			},
+			mockAPIRoles:   []models.Role{},
+			expectOutput:   []string{"against Replicated API", "CREATE: admin"},
+			expectAPICalls: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tempDir := t.TempDir()
+			for _, role := range tt.localRoles {
+				if err := createTestRoleFile(tempDir, role); err != nil {
+					t.Fatalf("Failed to create role file: %v", err)
+				}
+			}
+
+			against := tt.against
+			if tt.snapshot != nil {
+				against = writeSnapshot(t, tt.snapshot...)
+			}
+
+			mockCalls := &MockAPICalls{}
+			mockClient := NewMockClient(mockCalls, tt.mockAPIRoles)
+
+			var stdout bytes.Buffer
+			cmd := &cobra.Command{}
+			cmd.SetOut(&stdout)
+			logger := logging.NewLogger(&bytes.Buffer{}, false)
+
+			err := RunDiffCommandWithClient(cmd, tempDir, against, mockClient, logger, models.Config{})
+			if tt.expectError {
+				if err == nil {
+					t.Fatal("Expected error but got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			output := stdout.String()
+			for _, expected := range tt.expectOutput {
+				if !strings.Contains(output, expected) {
+					t.Errorf("Expected output to contain %q, got:\n%s", expected, output)
+				}
+			}
+			if mockCalls.GetCalls != tt.expectAPICalls {
+				t.Errorf("Expected %d API calls, got %d", tt.expectAPICalls, mockCalls.GetCalls)
+			}
+		})
+	}
+}
+
+// TestDiffAgainstSnapshotSkipsTokenValidation tests that only snapshot mode runs without an API token
+func TestDiffAgainstSnapshotSkipsTokenValidation(t *testing.T) {
+	cmd := &cobra.Command{Use: "diff"}
+	cmd.Flags().String("against", "", "snapshot of remote roles")
+
+	if !commandNeedsAPI(cmd) {
+		t.Error("Expected diff against the live API to require configuration")
+	}
+
+	if err := cmd.Flags().Set("against", "snapshot"); err != nil {
+		t.Fatalf("Failed to set against flag: %v", err)
+	}
+	if commandNeedsAPI(cmd) {
+		t.Error("Expected diff against a snapshot not to require configuration")
+	}
+}
+
+// TestDiffSummaryOnly tests that --summary-only renders counts instead of entries
+func TestDiffSummaryOnly(t *testing.T) {
+	tempDir := t.TempDir()
+	if err := createTestRoleFile(tempDir, models.Role{Name: "admin", Resources: models.Resources{Allowed: []string{"a", "b", "c"}}}); err != nil {
+		t.Fatalf("Failed to create role file: %v", err)
+	}
+	snapshot := writeSnapshot(t, models.Role{ID: "1", Name: "admin", Resources: models.Resources{Allowed: []string{"z"}}})
+
+	var stdout bytes.Buffer
+	cmd := &cobra.Command{}
+	cmd.Flags().Bool("summary-only", false, "show counts only")
+	if err := cmd.Flags().Set("summary-only", "true"); err != nil {
+		t.Fatalf("Failed to set summary-only flag: %v", err)
+	}
+	cmd.SetOut(&stdout)
+
+	if err := RunDiffCommandWithClient(cmd, tempDir, snapshot, nil, logging.NewLogger(&bytes.Buffer{}, false), models.Config{}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	output := stdout.String()
+	if !strings.Contains(output, "UPDATE: admin (+3 allowed, -1 allowed)") {
+		t.Errorf("Expected change counts in output, got:\n%s", output)
+	}
+	if strings.Contains(output, "+ allowed:") {
+		t.Errorf("Expected no per-entry lines in summary mode, got:\n%s", output)
+	}
+}
+
+// TestDiffUnified tests that --unified and --diff-context render YAML diffs with the requested context
+func TestDiffUnified(t *testing.T) {
+	tempDir := t.TempDir()
+	if err := createTestRoleFile(tempDir, models.Role{Name: "admin", Resources: models.Resources{Allowed: []string{"a", "b", "c", "d", "e", "f"}, Denied: []string{}}}); err != nil {
+		t.Fatalf("Failed to create role file: %v", err)
+	}
+	snapshot := writeSnapshot(t, models.Role{ID: "1", Name: "admin", Resources: models.Resources{Allowed: []string{"a", "b", "c", "e", "f"}, Denied: []string{}}})
+
+	tests := []struct {
+		name         string
+		flags        map[string]string
+		expectError  string
+		expectOutput []string
+		rejectOutput []string
+	}{
+		{
+			name:         "default context",
+			flags:        map[string]string{"unified": "true"},
+			expectOutput: []string{"--- remote/admin\n+++ local/admin\n@@ -4,6 +4,7 @@\n         - a\n         - b\n         - c\n+        - d\n"},
+		},
+		{
+			name:         "changed lines only",
+			flags:        map[string]string{"diff-context": "0"},
+			expectOutput: []string{"@@ -6,0 +7 @@\n+        - d\n"},
+			rejectOutput: []string{"        - c"},
+		},
+		{
+			name:        "negative context",
+			flags:       map[string]string{"diff-context": "-1"},
+			expectError: "--diff-context must be at least 0, got -1",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout bytes.Buffer
+			cmd := &cobra.Command{}
+			cmd.Flags().Bool("unified", false, "unified diffs")
+			cmd.Flags().Int("diff-context", 3, "context lines")
+			for flag, value := range tt.flags {
+				if err := cmd.Flags().Set(flag, value); err != nil {
+					t.Fatalf("Failed to set %s flag: %v", flag, err)
+				}
+			}
+			cmd.SetOut(&stdout)
+
+			err := RunDiffCommandWithClient(cmd, tempDir, snapshot, nil, logging.NewLogger(&bytes.Buffer{}, false), models.Config{})
+			if tt.expectError != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.expectError) {
+					t.Fatalf("Expected error containing %q, got %v", tt.expectError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			output := stdout.String()
+			for _, expected := range tt.expectOutput {
+				if !strings.Contains(output, expected) {
+					t.Errorf("Expected output to contain %q, got:\n%s", expected, output)
+				}
+			}
+			for _, rejected := range tt.rejectOutput {
+				if strings.Contains(output, rejected) {
+					t.Errorf("Expected output not to contain %q, got:\n%s", rejected, output)
+				}
+			}
+		})
+	}
+}
+
+// memberlessListingClient records whether roles were listed with their members
+type memberlessListingClient struct {
+	*MockClient
+	includeMembers []bool
+}
+
+// GetRolesWithOptions records includeMembers and returns the roles, with members only if requested
+func (c *memberlessListingClient) GetRolesWithOptions(includeMembers bool) ([]models.Role, error) {
+	c.includeMembers = append(c.includeMembers, includeMembers)
+	roles, err := c.MockClient.GetRoles()
+	if err != nil || includeMembers {
+		return roles, err
+	}
+	return withoutMembers(roles), nil
+}
+
+// TestDiffNoMembers tests that --no-members compares resources only and skips reading members
+func TestDiffNoMembers(t *testing.T) {
+	tempDir := t.TempDir()
+	local := models.Role{Name: "admin", Resources: models.Resources{Allowed: []string{"**/*"}}, Members: []string{"alice@example.com"}}
+	if err := createTestRoleFile(tempDir, local); err != nil {
+		t.Fatalf("Failed to create role file: %v", err)
+	}
+	remote := models.Role{ID: "1", Name: "admin", Resources: models.Resources{Allowed: []string{"**/*"}}, Members: []string{"bob@example.com"}}
+
+	client := &memberlessListingClient{MockClient: NewMockClient(&MockAPICalls{}, []models.Role{remote})}
+	var stdout bytes.Buffer
+	cmd := &cobra.Command{}
+	cmd.Flags().Bool("no-members", false, "ignore members")
+	if err := cmd.Flags().Set("no-members", "true"); err != nil {
+		t.Fatalf("Failed to set no-members flag: %v", err)
+	}
+	cmd.SetOut(&stdout)
+
+	if err := RunDiffCommandWithClient(cmd, tempDir, "", client, logging.NewLogger(&bytes.Buffer{}, false), models.Config{}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if len(client.includeMembers) != 1 || client.includeMembers[0] {
+		t.Errorf("Expected roles to be listed once without members, got %v", client.includeMembers)
+	}
+	if !strings.Contains(stdout.String(), "No differences found") {
+		t.Errorf("Expected members to be ignored, got:\n%s", stdout.String())
+	}
+}
+
+// TestDiffProtectedRoles tests that remote roles protected in the configuration, which sync
+// never deletes, are not reported as differences
+func TestDiffProtectedRoles(t *testing.T) {
+	tempDir := t.TempDir()
+	local := models.Role{Name: "admin", Resources: models.Resources{Allowed: []string{"**/*"}}}
+	if err := createTestRoleFile(tempDir, local); err != nil {
+		t.Fatalf("Failed to create role file: %v", err)
+	}
+	remote := []models.Role{
+		{ID: "1", Name: "admin", Resources: models.Resources{Allowed: []string{"**/*"}}},
+		{ID: "2", Name: "break-glass", Resources: models.Resources{Allowed: []string{"**/*"}}},
+	}
+
+	var stdout bytes.Buffer
+	cmd := &cobra.Command{}
+	cmd.SetOut(&stdout)
+
+	config := models.Config{ProtectedRoles: []string{"break-*"}}
+	if err := RunDiffCommandWithClient(cmd, tempDir, "", NewMockClient(&MockAPICalls{}, remote), logging.NewLogger(&bytes.Buffer{}, false), config); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !strings.Contains(stdout.String(), "No differences found") {
+		t.Errorf("Expected the protected role not to be a difference, got:\n%s", stdout.String())
+	}
+}
@@ -0,0 +1,96 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+
+	"replbac/internal/models"
+)
+
+// TestDriftGuardPercentFlag tests that a plan creating or deleting more than
+// --drift-guard-percent of the remote roles is refused unless --force is given
+func TestDriftGuardPercentFlag(t *testing.T) {
+	role := func(i int) models.Role {
+		return models.Role{Name: fmt.Sprintf("role-%d", i), Resources: models.Resources{Allowed: []string{"kots/app/*/read"}, Denied: []string{}}}
+	}
+
+	tests := []struct {
+		name          string
+		args          []string
+		expectError   string
+		expectOutput  string
+		expectDeletes int
+	}{
+		{
+			name:        "plan over the limit is refused",
+			args:        []string{"--drift-guard-percent", "50"},
+			expectError: "the plan creates or deletes 4 role(s), 66% of the 6 remote role(s), more than the --drift-guard-percent limit of 50%",
+		},
+		{
+			name:          "force overrides the guard",
+			args:          []string{"--drift-guard-percent", "50", "--force"},
+			expectOutput:  "continuing because of --force",
+			expectDeletes: 4,
+		},
+		{
+			name:         "dry run only warns",
+			args:         []string{"--drift-guard-percent", "50", "--dry-run"},
+			expectOutput: "Warning: the plan creates or deletes 4 role(s), 66% of the 6 remote role(s)",
+		},
+		{
+			name:          "plan within the limit",
+			args:          []string{"--drift-guard-percent", "70", "--force"},
+			expectDeletes: 4,
+		},
+		{
+			name:          "zero disables the guard",
+			args:          []string{"--force"},
+			expectDeletes: 4,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tempDir := t.TempDir()
+			for i := 0; i < 2; i++ {
+				if err := createTestRoleFile(tempDir, role(i)); err != nil {
+					t.Fatalf("Failed to create role file: %v", err)
+				}
+			}
+			var remote []models.Role
+			for i := 0; i < 6; i++ {
+				remoteRole := role(i)
+				remoteRole.ID = fmt.Sprintf("id-%d", i)
+				remote = append(remote, remoteRole)
+			}
+
+			calls := &MockAPICalls{}
+			cmd := NewSyncCommandWithOptions(NewMockClient(calls, remote), func(cmd *cobra.Command) {
+				cmd.Flags().Int("drift-guard-percent", 0, "abort if too many roles change")
+			})
+			var stdout bytes.Buffer
+			cmd.SetOut(&stdout)
+			cmd.SetErr(&bytes.Buffer{})
+			cmd.SetArgs(append([]string{tempDir, "--delete"}, tt.args...))
+
+			err := cmd.Execute()
+			if tt.expectError != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.expectError) {
+					t.Fatalf("Expected error containing %q, got %v", tt.expectError, err)
+				}
+			} else if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if !strings.Contains(stdout.String(), tt.expectOutput) {
+				t.Errorf("Expected output to contain %q, got:\n%s", tt.expectOutput, stdout.String())
+			}
+			if len(calls.DeleteCalls) != tt.expectDeletes {
+				t.Errorf("Expected %d deletes, got %d", tt.expectDeletes, len(calls.DeleteCalls))
+			}
+		})
+	}
+}
@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
 	"net"
 	"os"
@@ -26,6 +27,14 @@ const (
 	ErrorCategorySync
 )
 
+// Exit codes returned by replbac
+const (
+	ExitCodeFailure       = 1   // Any failure without a more specific code
+	ExitCodeConfiguration = 3   // Invalid configuration or rejected API credentials
+	ExitCodeNetwork       = 4   // The API endpoint could not be reached
+	ExitCodeInterrupted   = 130 // Execution was interrupted, e.g. by Ctrl-C, as shells report for SIGINT
+)
+
 // ErrorContext provides additional context for errors
 type ErrorContext struct {
 	Category     ErrorCategory
@@ -134,6 +143,44 @@ func ValidateDirectoryAccess(path string) error {
 
 // Error type definitions
 
+// exitCodeError attaches a specific process exit code to an error without changing its message
+type exitCodeError struct {
+	code int
+	err  error
+}
+
+func (e *exitCodeError) Error() string {
+	return e.err.Error()
+}
+
+func (e *exitCodeError) Unwrap() error {
+	return e.err
+}
+
+// withExitCode marks err so that the process exits with code
+func withExitCode(code int, err error) error {
+	return &exitCodeError{code: code, err: err}
+}
+
+// ExitCode returns the process exit code for an error returned by a command.
+// A code attached by the command takes precedence. Otherwise configuration errors
+// and API authentication failures exit with ExitCodeConfiguration, and everything
+// else exits with ExitCodeFailure.
+func ExitCode(err error) int {
+	var codeErr *exitCodeError
+	if errors.As(err, &codeErr) {
+		return codeErr.code
+	}
+
+	var authErr *api.AuthError
+	var configErr *ConfigurationError
+	if errors.As(err, &authErr) || errors.As(err, &configErr) {
+		return ExitCodeConfiguration
+	}
+
+	return ExitCodeFailure
+}
+
 type ConfigurationError struct {
 	Field    string
 	Message  string
@@ -180,19 +227,24 @@ type SyncError struct {
 	Message   string
 	Guidance  string
 	Partial   bool
+	Cause     error // Error the sync failed with, if any, which decides the exit code
 }
 
 func (e *SyncError) Error() string {
 	return fmt.Sprintf("sync error: %s", e.Message)
 }
 
+func (e *SyncError) Unwrap() error {
+	return e.Cause
+}
+
 // Error handlers
 
 func HandleConfigurationError(cmd *cobra.Command, err error) error {
 	if configErr, ok := err.(*ConfigurationError); ok {
 		cmd.Printf("Configuration Error: %s\n", configErr.Message)
 		cmd.Printf("Help: %s\n", configErr.Guidance)
-		return fmt.Errorf("invalid configuration: %s", configErr.Message)
+		return withExitCode(ExitCodeConfiguration, fmt.Errorf("invalid configuration: %s", configErr.Message))
 	}
 	return err
 }
@@ -220,8 +272,18 @@ func HandleSyncError(cmd *cobra.Command, err error) error {
 			cmd.Printf("0 operations completed successfully\n")
 			cmd.Printf("Rollback: No changes were applied\n")
 		}
-		cmd.Printf("Help: %s\n", syncErr.Guidance)
-		return fmt.Errorf("sync operation failed: %s", syncErr.Message)
+		guidance := syncErr.Guidance
+		var authErr *api.AuthError
+		if errors.As(syncErr.Cause, &authErr) {
+			guidance = GetErrorRecovery(authErr)
+		}
+		cmd.Printf("Help: %s\n", guidance)
+		failure := fmt.Errorf("sync operation failed: %s", syncErr.Message)
+		// Keep the exit code of the cause, e.g. rejected credentials
+		if code := ExitCode(syncErr.Cause); syncErr.Cause != nil && code != ExitCodeFailure {
+			return withExitCode(code, failure)
+		}
+		return failure
 	}
 
 	// Handle network errors
@@ -272,6 +334,8 @@ func GetErrorRecovery(err error) string {
 		return e.Guidance
 	case *SyncError:
 		return e.Guidance
+	case *api.AuthError:
+		return "Check your REPLICATED_API_TOKEN; it may be expired or lack permissions"
 	default:
 		return ""
 	}
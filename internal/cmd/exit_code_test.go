@@ -0,0 +1,134 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"replbac/internal/api"
+	"replbac/internal/models"
+)
+
+// unauthorizedClient rejects every role listing as the API does for an expired token
+type unauthorizedClient struct {
+	*MockClient
+	statusCode int
+}
+
+// GetRoles fails with an authentication error
+func (u *unauthorizedClient) GetRoles() ([]models.Role, error) {
+	return nil, &api.AuthError{StatusCode: u.statusCode}
+}
+
+func TestExitCode(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		expected int
+	}{
+		{
+			name:     "generic error",
+			err:      errors.New("something failed"),
+			expected: ExitCodeFailure,
+		},
+		{
+			name:     "wrapped authentication error",
+			err:      fmt.Errorf("failed to get remote roles: %w", &api.AuthError{StatusCode: http.StatusUnauthorized}),
+			expected: ExitCodeConfiguration,
+		},
+		{
+			name:     "configuration error",
+			err:      &ConfigurationError{Field: "APIToken", Message: "API token is required"},
+			expected: ExitCodeConfiguration,
+		},
+		{
+			name:     "error marked with an exit code",
+			err:      withExitCode(ExitCodeConfiguration, errors.New("invalid configuration: API token is required")),
+			expected: ExitCodeConfiguration,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if code := ExitCode(tt.err); code != tt.expected {
+				t.Errorf("ExitCode() = %d, want %d", code, tt.expected)
+			}
+		})
+	}
+}
+
+// TestSyncAuthenticationFailure tests that 401 and 403 responses produce token guidance and the configuration exit code
+func TestSyncAuthenticationFailure(t *testing.T) {
+	for _, statusCode := range []int{http.StatusUnauthorized, http.StatusForbidden} {
+		t.Run(http.StatusText(statusCode), func(t *testing.T) {
+			tempDir := t.TempDir()
+			if err := createTestRoleFile(tempDir, models.Role{Name: "viewer", Resources: models.Resources{Allowed: []string{"read"}}}); err != nil {
+				t.Fatalf("Failed to create role file: %v", err)
+			}
+
+			client := &unauthorizedClient{MockClient: NewMockClient(&MockAPICalls{}, nil), statusCode: statusCode}
+			cmd := NewSyncCommandWithOptions(client, nil)
+
+			var stdout, stderr bytes.Buffer
+			cmd.SetOut(&stdout)
+			cmd.SetErr(&stderr)
+			cmd.SetArgs([]string{tempDir})
+
+			err := cmd.Execute()
+			if err == nil {
+				t.Fatal("Expected an authentication error")
+			}
+			if !strings.Contains(err.Error(), "check your REPLICATED_API_TOKEN (it may be expired or lack permissions)") {
+				t.Errorf("Expected token guidance in error, got: %v", err)
+			}
+			if code := ExitCode(err); code != ExitCodeConfiguration {
+				t.Errorf("ExitCode() = %d, want %d", code, ExitCodeConfiguration)
+			}
+		})
+	}
+}
+
+// rejectingWritesClient lists roles but rejects every role creation as the API does when
+// the token expires mid-sync
+type rejectingWritesClient struct {
+	*MockClient
+}
+
+// CreateRole fails with an authentication error
+func (r *rejectingWritesClient) CreateRole(role models.Role) error {
+	return &api.AuthError{StatusCode: http.StatusUnauthorized}
+}
+
+// TestSyncApplyAuthenticationFailure tests that credentials rejected while applying the
+// plan keep the configuration exit code and token guidance
+func TestSyncApplyAuthenticationFailure(t *testing.T) {
+	tempDir := t.TempDir()
+	if err := createTestRoleFile(tempDir, models.Role{Name: "viewer", Resources: models.Resources{Allowed: []string{"read"}}}); err != nil {
+		t.Fatalf("Failed to create role file: %v", err)
+	}
+
+	client := &rejectingWritesClient{MockClient: NewMockClient(&MockAPICalls{}, nil)}
+	cmd := NewSyncCommandWithOptions(client, nil)
+	if err := cmd.Flags().Set("force", "true"); err != nil {
+		t.Fatalf("Failed to set force flag: %v", err)
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd.SetOut(&stdout)
+	cmd.SetErr(&stderr)
+	cmd.SetArgs([]string{tempDir})
+
+	err := cmd.Execute()
+	if err == nil {
+		t.Fatal("Expected an authentication error")
+	}
+	if code := ExitCode(err); code != ExitCodeConfiguration {
+		t.Errorf("ExitCode() = %d, want %d (error: %v)", code, ExitCodeConfiguration, err)
+	}
+	if !strings.Contains(stdout.String(), "Help: Check your REPLICATED_API_TOKEN") {
+		t.Errorf("Expected token guidance in output, got:\n%s", stdout.String())
+	}
+}
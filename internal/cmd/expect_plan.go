@@ -0,0 +1,109 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+
+	"github.com/spf13/cobra"
+
+	"replbac/internal/sync"
+)
+
+// readExpectedPlan reads the operations a plan is expected to contain from filePath, either
+// as a JSON array of operation records or as the JSON lines written by --output json-stream.
+// Only each record's operation and role are used.
+func readExpectedPlan(filePath string) ([]sync.OperationRecord, error) {
+	data, err := os.ReadFile(filePath) // #nosec G304 -- Reading the user-specified expected plan is intended
+	if err != nil {
+		return nil, fmt.Errorf("failed to read expected plan: %w", err)
+	}
+
+	var records []sync.OperationRecord
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '[' {
+		if err := json.Unmarshal(trimmed, &records); err != nil {
+			return nil, fmt.Errorf("failed to parse expected plan %s: %w", filePath, err)
+		}
+	} else {
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		for {
+			var record sync.OperationRecord
+			if err := decoder.Decode(&record); errors.Is(err, io.EOF) {
+				break
+			} else if err != nil {
+				return nil, fmt.Errorf("failed to parse expected plan %s: %w", filePath, err)
+			}
+			records = append(records, record)
+		}
+	}
+
+	for _, record := range records {
+		switch record.Operation {
+		case sync.OperationCreate, sync.OperationUpdate, sync.OperationDelete:
+		default:
+			return nil, fmt.Errorf("invalid operation %q for role %q in expected plan %s: must be create, update, or delete", record.Operation, record.Role, filePath)
+		}
+		if record.Role == "" {
+			return nil, fmt.Errorf("%s operation without a role in expected plan %s", record.Operation, filePath)
+		}
+	}
+	return records, nil
+}
+
+// planOperationKeys returns each record as "operation role", sorted
+func planOperationKeys(records []sync.OperationRecord) []string {
+	keys := make([]string, len(records))
+	for i, record := range records {
+		keys[i] = record.Operation + " " + record.Role
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// checkExpectedPlan compares plan with the operations expected in filePath, ignoring their
+// order, and fails listing the operations that are missing from the plan or not expected
+func checkExpectedPlan(cmd *cobra.Command, filePath string, plan sync.SyncPlan) error {
+	expected, err := readExpectedPlan(filePath)
+	if err != nil {
+		return err
+	}
+
+	remaining := make(map[string]int)
+	for _, key := range planOperationKeys(expected) {
+		remaining[key]++
+	}
+	var unexpected []string
+	planned := planOperationKeys(sync.PlannedOperations(plan))
+	for _, key := range planned {
+		if remaining[key] > 0 {
+			remaining[key]--
+		} else {
+			unexpected = append(unexpected, key)
+		}
+	}
+	var missing []string
+	for _, key := range planOperationKeys(expected) {
+		if remaining[key] > 0 {
+			remaining[key]--
+			missing = append(missing, key)
+		}
+	}
+
+	if len(missing) == 0 && len(unexpected) == 0 {
+		cmd.Printf("Plan matches the expected plan in %s (%d operation(s))\n", filePath, len(planned))
+		return nil
+	}
+
+	cmd.Printf("Plan does not match the expected plan in %s:\n", filePath)
+	for _, key := range missing {
+		cmd.Printf("  - %s (expected, not planned)\n", key)
+	}
+	for _, key := range unexpected {
+		cmd.Printf("  + %s (planned, not expected)\n", key)
+	}
+	return fmt.Errorf("plan does not match --expect-plan %s: %d expected operation(s) missing, %d unexpected", filePath, len(missing), len(unexpected))
+}
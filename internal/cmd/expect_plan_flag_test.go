@@ -0,0 +1,119 @@
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+
+	"replbac/internal/models"
+)
+
+// TestExpectPlanFlag tests that --expect-plan fails, listing the differences, unless the
+// plan has exactly the expected operations in any order
+func TestExpectPlanFlag(t *testing.T) {
+	remote := []models.Role{
+		{ID: "1", Name: "viewer", Resources: models.Resources{Allowed: []string{"**/*"}, Denied: []string{}}},
+		{ID: "2", Name: "legacy", Resources: models.Resources{Allowed: []string{"**/*"}, Denied: []string{}}},
+	}
+
+	tests := []struct {
+		name         string
+		expected     string
+		args         []string
+		expectError  string
+		expectOutput []string
+		expectCalls  int
+	}{
+		{
+			name:         "matching JSON array in another order",
+			expected:     `[{"operation": "delete", "role": "legacy"}, {"operation": "update", "role": "viewer"}, {"operation": "create", "role": "admin"}]`,
+			args:         []string{"--dry-run"},
+			expectOutput: []string{"Plan matches the expected plan in", "(3 operation(s))"},
+		},
+		{
+			name: "matching json-stream lines",
+			expected: `{"operation":"create","role":"admin","status":"planned"}
+{"operation":"update","role":"viewer","status":"planned","reason":"allowed differs"}
+{"operation":"delete","role":"legacy","status":"planned"}
+`,
+			args:         []string{"--dry-run"},
+			expectOutput: []string{"Plan matches the expected plan in"},
+		},
+		{
+			name:        "missing and unexpected operations are listed",
+			expected:    `[{"operation": "create", "role": "admin"}, {"operation": "update", "role": "legacy"}]`,
+			args:        []string{"--dry-run"},
+			expectError: "plan does not match --expect-plan",
+			expectOutput: []string{
+				"  - update legacy (expected, not planned)",
+				"  + delete legacy (planned, not expected)",
+				"  + update viewer (planned, not expected)",
+			},
+		},
+		{
+			name:        "mismatch stops a real sync before anything is applied",
+			expected:    `[{"operation": "create", "role": "admin"}]`,
+			args:        []string{"--force"},
+			expectError: "2 unexpected",
+		},
+		{
+			name:        "matching plan is applied",
+			expected:    `[{"operation": "create", "role": "admin"}, {"operation": "update", "role": "viewer"}, {"operation": "delete", "role": "legacy"}]`,
+			args:        []string{"--force"},
+			expectCalls: 3,
+		},
+		{
+			name:        "invalid operation",
+			expected:    `[{"operation": "rename", "role": "viewer"}]`,
+			args:        []string{"--dry-run"},
+			expectError: `invalid operation "rename" for role "viewer"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tempDir := t.TempDir()
+			for _, name := range []string{"admin", "viewer"} {
+				role := models.Role{Name: name, Resources: models.Resources{Allowed: []string{"kots/app/*/read"}, Denied: []string{}}}
+				if err := createTestRoleFile(tempDir, role); err != nil {
+					t.Fatalf("Failed to create role file: %v", err)
+				}
+			}
+			expectedPath := filepath.Join(t.TempDir(), "expected.json")
+			// #nosec G306 -- Test files need readable permissions
+			if err := os.WriteFile(expectedPath, []byte(tt.expected), 0644); err != nil {
+				t.Fatalf("Failed to write expected plan: %v", err)
+			}
+
+			calls := &MockAPICalls{}
+			cmd := NewSyncCommandWithOptions(NewMockClient(calls, remote), func(cmd *cobra.Command) {
+				cmd.Flags().String("expect-plan", "", "fail unless the plan matches this file")
+			})
+			var stdout bytes.Buffer
+			cmd.SetOut(&stdout)
+			cmd.SetErr(&bytes.Buffer{})
+			cmd.SetArgs(append([]string{tempDir, "--delete", "--expect-plan", expectedPath}, tt.args...))
+
+			err := cmd.Execute()
+			if tt.expectError != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.expectError) {
+					t.Fatalf("Expected error containing %q, got %v", tt.expectError, err)
+				}
+			} else if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			for _, expected := range tt.expectOutput {
+				if !strings.Contains(stdout.String(), expected) {
+					t.Errorf("Expected output to contain %q, got:\n%s", expected, stdout.String())
+				}
+			}
+			if applied := len(calls.CreateCalls) + len(calls.UpdateCalls) + len(calls.DeleteCalls); applied != tt.expectCalls {
+				t.Errorf("Expected %d applied operation(s), got %d", tt.expectCalls, applied)
+			}
+		})
+	}
+}
@@ -0,0 +1,90 @@
+package cmd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+
+	"replbac/internal/models"
+)
+
+// TestExplainFlagBehavior tests that --explain prints the reason for each planned change
+func TestExplainFlagBehavior(t *testing.T) {
+	tests := []struct {
+		name         string
+		explain      bool
+		expectOutput []string
+		rejectOutput []string
+	}{
+		{
+			name:    "reasons shown with --explain",
+			explain: true,
+			expectOutput: []string{
+				"Reasons:",
+				"create admin: no remote role with this name",
+				"update editor: allowed differs (remote missing 'create')",
+				"delete obsolete: no local file",
+			},
+		},
+		{
+			name:         "reasons hidden without --explain",
+			rejectOutput: []string{"Reasons:", "remote missing"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tempDir := t.TempDir()
+			for _, role := range []models.Role{
+				{Name: "admin", Resources: models.Resources{Allowed: []string{"*"}, Denied: []string{}}},
+				{Name: "editor", Resources: models.Resources{Allowed: []string{"read", "create"}, Denied: []string{}}},
+			} {
+				if err := createTestRoleFile(tempDir, role); err != nil {
+					t.Fatalf("Failed to create role file: %v", err)
+				}
+			}
+
+			remoteRoles := []models.Role{
+				{ID: "1", Name: "editor", Resources: models.Resources{Allowed: []string{"read"}, Denied: []string{}}},
+				{ID: "2", Name: "obsolete", Resources: models.Resources{Allowed: []string{"read"}, Denied: []string{}}},
+			}
+
+			cmd := NewSyncCommandWithOptions(NewMockClient(&MockAPICalls{}, remoteRoles), func(cmd *cobra.Command) {
+				cmd.Flags().Bool("explain", false, "show why each role will change")
+			})
+			for _, flag := range []string{"dry-run", "delete"} {
+				if err := cmd.Flags().Set(flag, "true"); err != nil {
+					t.Fatalf("Failed to set %s flag: %v", flag, err)
+				}
+			}
+			if tt.explain {
+				if err := cmd.Flags().Set("explain", "true"); err != nil {
+					t.Fatalf("Failed to set explain flag: %v", err)
+				}
+			}
+
+			var stdout, stderr bytes.Buffer
+			cmd.SetOut(&stdout)
+			cmd.SetErr(&stderr)
+			cmd.SetArgs([]string{tempDir})
+
+			if err := cmd.Execute(); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			output := stdout.String()
+			for _, expected := range tt.expectOutput {
+				if !strings.Contains(output, expected) {
+					t.Errorf("Expected output to contain %q, got:\n%s", expected, output)
+				}
+			}
+			for _, rejected := range tt.rejectOutput {
+				if strings.Contains(output, rejected) {
+					t.Errorf("Expected output not to contain %q, got:\n%s", rejected, output)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,114 @@
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+
+	"replbac/internal/models"
+)
+
+// TestFailOnSkipFlagBehavior tests that --fail-on-skip aborts before contacting the API
+func TestFailOnSkipFlagBehavior(t *testing.T) {
+	tests := []struct {
+		name          string
+		failOnSkip    bool
+		localFiles    map[string]string
+		expectError   bool
+		errorContains []string
+		expectGetCall bool
+		expectCreates int
+	}{
+		{
+			name:       "invalid file skipped by default",
+			failOnSkip: false,
+			localFiles: map[string]string{
+				"valid.yaml":   "name: valid\nresources:\n  allowed: [\"read\"]\n",
+				"invalid.yaml": "resources:\n  allowed: [\"read\"]\n",
+			},
+			expectError:   false,
+			expectGetCall: true,
+			expectCreates: 1,
+		},
+		{
+			name:       "invalid files abort with --fail-on-skip",
+			failOnSkip: true,
+			localFiles: map[string]string{
+				"valid.yaml":     "name: valid\nresources:\n  allowed: [\"read\"]\n",
+				"noname.yaml":    "resources:\n  allowed: [\"read\"]\n",
+				"broken.yaml":    "name: [unclosed\n",
+				"notes.txt":      "not a role file",
+				"another.yml":    "name: another\nresources:\n  allowed: []\n",
+				"empty-file.yml": "",
+			},
+			expectError:   true,
+			errorContains: []string{"3 role file(s) could not be loaded", "noname.yaml (role name is required)", "broken.yaml (failed to parse YAML)", "empty-file.yml (file is empty)"},
+			expectGetCall: false,
+		},
+		{
+			name:       "all valid files sync normally with --fail-on-skip",
+			failOnSkip: true,
+			localFiles: map[string]string{
+				"valid.yaml": "name: valid\nresources:\n  allowed: [\"read\"]\n",
+			},
+			expectError:   false,
+			expectGetCall: true,
+			expectCreates: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tempDir := t.TempDir()
+			for name, content := range tt.localFiles {
+				if err := os.WriteFile(filepath.Join(tempDir, name), []byte(content), 0600); err != nil {
+					t.Fatalf("Failed to write %s: %v", name, err)
+				}
+			}
+
+			mockCalls := &MockAPICalls{}
+			mockClient := NewMockClient(mockCalls, []models.Role{})
+			cmd := NewSyncCommandWithOptions(mockClient, func(cmd *cobra.Command) {
+				cmd.Flags().Bool("fail-on-skip", false, "abort if any role file is invalid")
+			})
+			if tt.failOnSkip {
+				if err := cmd.Flags().Set("fail-on-skip", "true"); err != nil {
+					t.Fatalf("Failed to set fail-on-skip flag: %v", err)
+				}
+			}
+
+			var stdout, stderr bytes.Buffer
+			cmd.SetOut(&stdout)
+			cmd.SetErr(&stderr)
+			cmd.SetArgs([]string{tempDir})
+
+			err := cmd.Execute()
+			if tt.expectError {
+				if err == nil {
+					t.Fatal("Expected error but got none")
+				}
+				for _, expected := range tt.errorContains {
+					if !strings.Contains(err.Error(), expected) {
+						t.Errorf("Expected error to contain %q, got: %v", expected, err)
+					}
+				}
+			} else if err != nil {
+				t.Fatalf("Expected no error but got: %v", err)
+			}
+
+			if tt.expectGetCall && mockCalls.GetCalls == 0 {
+				t.Error("Expected remote roles to be fetched")
+			}
+			if !tt.expectGetCall && mockCalls.GetCalls != 0 {
+				t.Errorf("Expected no API calls, got %d GetRoles calls", mockCalls.GetCalls)
+			}
+			if len(mockCalls.CreateCalls) != tt.expectCreates {
+				t.Errorf("Expected %d create calls, got %d", tt.expectCreates, len(mockCalls.CreateCalls))
+			}
+		})
+	}
+}
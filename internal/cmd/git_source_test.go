@@ -0,0 +1,103 @@
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"replbac/internal/models"
+)
+
+// TestSyncFromGitReference tests that sync loads roles from a git reference and removes the clone afterward
+func TestSyncFromGitReference(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	repo := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(repo, "roles"), 0750); err != nil {
+		t.Fatalf("Failed to create roles directory: %v", err)
+	}
+	if err := createTestRoleFile(filepath.Join(repo, "roles"), models.Role{Name: "admin", Resources: models.Resources{Allowed: []string{"*"}, Denied: []string{}}}); err != nil {
+		t.Fatalf("Failed to create role file: %v", err)
+	}
+	for _, args := range [][]string{
+		{"init", "--quiet"},
+		{"add", "."},
+		{"-c", "user.name=test", "-c", "user.email=test@example.com", "commit", "--quiet", "-m", "roles"},
+		{"tag", "v1.2.3"},
+	} {
+		git := exec.Command("git", args...)
+		git.Dir = repo
+		if output, err := git.CombinedOutput(); err != nil {
+			t.Fatalf("git %s failed: %v\n%s", strings.Join(args, " "), err, output)
+		}
+	}
+
+	tests := []struct {
+		name          string
+		ref           string
+		expectError   string
+		expectOutput  []string
+		expectCreates int
+	}{
+		{
+			name:          "syncs the pinned subdirectory",
+			ref:           "v1.2.3",
+			expectOutput:  []string{"Cloning file://" + repo + " at v1.2.3", "── Creating (1) ──", "  - admin", "Total: 1 role change(s)"},
+			expectCreates: 1,
+		},
+		{
+			name:        "unknown ref",
+			ref:         "v9.9.9",
+			expectError: "failed to clone file://" + repo + " at v9.9.9",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpDir := t.TempDir()
+			t.Setenv("TMPDIR", tmpDir)
+
+			mockCalls := &MockAPICalls{}
+			cmd := NewSyncCommandWithOptions(NewMockClient(mockCalls, []models.Role{}), nil)
+			var stdout bytes.Buffer
+			cmd.SetOut(&stdout)
+			cmd.SetErr(&bytes.Buffer{})
+			cmd.SetArgs([]string{"git::file://" + repo + "//roles?ref=" + tt.ref})
+
+			err := cmd.Execute()
+			if tt.expectError != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.expectError) {
+					t.Fatalf("Expected error containing %q, got %v", tt.expectError, err)
+				}
+				if mockCalls.GetCalls != 0 {
+					t.Errorf("Expected no API calls after a failed clone, got %d", mockCalls.GetCalls)
+				}
+			} else if err != nil {
+				t.Fatalf("Unexpected error: %v\nOutput:\n%s", err, stdout.String())
+			}
+
+			output := stdout.String()
+			for _, expected := range tt.expectOutput {
+				if !strings.Contains(output, expected) {
+					t.Errorf("Expected output to contain %q, got:\n%s", expected, output)
+				}
+			}
+			if len(mockCalls.CreateCalls) != tt.expectCreates {
+				t.Errorf("Expected %d create calls, got %d", tt.expectCreates, len(mockCalls.CreateCalls))
+			}
+
+			entries, err := os.ReadDir(tmpDir)
+			if err != nil {
+				t.Fatalf("Failed to read temp dir: %v", err)
+			}
+			if len(entries) != 0 {
+				t.Errorf("Expected the clone to be removed, found %d entries in %s", len(entries), tmpDir)
+			}
+		})
+	}
+}
@@ -0,0 +1,65 @@
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"replbac/internal/models"
+	"replbac/internal/roles"
+)
+
+// TestIgnoreConfigBehavior tests that roles listed in .replbac.yaml are never created, updated, or deleted
+func TestIgnoreConfigBehavior(t *testing.T) {
+	tempDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(tempDir, roles.DirectoryConfigFile), []byte("ignore:\n  - ui-*\n"), 0600); err != nil {
+		t.Fatalf("Failed to write directory config: %v", err)
+	}
+	for _, role := range []models.Role{
+		{ID: "1", Name: "ui-support", Resources: models.Resources{Allowed: []string{"read"}, Denied: []string{}}},
+		{Name: "viewer", Resources: models.Resources{Allowed: []string{"read"}, Denied: []string{}}},
+	} {
+		if err := createTestRoleFile(tempDir, role); err != nil {
+			t.Fatalf("Failed to create role file: %v", err)
+		}
+	}
+
+	remoteRoles := []models.Role{
+		{ID: "1", Name: "ui-support", Resources: models.Resources{Allowed: []string{"*"}, Denied: []string{}}},
+		{ID: "2", Name: "ui-sales", Resources: models.Resources{Allowed: []string{"*"}, Denied: []string{}}},
+	}
+
+	mockCalls := &MockAPICalls{}
+	cmd := NewSyncCommandWithOptions(NewMockClient(mockCalls, remoteRoles), nil)
+	for _, flag := range []string{"delete", "force"} {
+		if err := cmd.Flags().Set(flag, "true"); err != nil {
+			t.Fatalf("Failed to set %s flag: %v", flag, err)
+		}
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd.SetOut(&stdout)
+	cmd.SetErr(&stderr)
+	cmd.SetArgs([]string{tempDir})
+
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("Expected no error but got: %v", err)
+	}
+
+	if len(mockCalls.UpdateCalls) != 0 || len(mockCalls.DeleteCalls) != 0 {
+		t.Errorf("Expected ignored roles to be left alone, got updates %+v and deletes %v", mockCalls.UpdateCalls, mockCalls.DeleteCalls)
+	}
+	if len(mockCalls.CreateCalls) != 1 || mockCalls.CreateCalls[0].Name != "viewer" {
+		t.Errorf("Expected only viewer to be created, got %+v", mockCalls.CreateCalls)
+	}
+	for _, name := range []string{"ui-sales", "ui-support"} {
+		if !strings.Contains(stdout.String(), "ignoring role "+name+" (configured)") {
+			t.Errorf("Expected output to report ignored role %s, got:\n%s", name, stdout.String())
+		}
+	}
+	if strings.Contains(stdout.String(), "Skipped") {
+		t.Errorf("Expected directory config not to be treated as a role file, got:\n%s", stdout.String())
+	}
+}
@@ -0,0 +1,75 @@
+package cmd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+
+	"replbac/internal/models"
+)
+
+// TestImpactFlagBehavior tests that --diff --impact reports which members gain or lose access
+func TestImpactFlagBehavior(t *testing.T) {
+	remote := []models.Role{
+		{ID: "1", Name: "viewer", Resources: models.Resources{Allowed: []string{"kots/app/*/read"}, Denied: []string{}}, Members: []string{"alice@example.com", "bob@example.com"}},
+		{ID: "2", Name: "admin", Resources: models.Resources{Allowed: []string{"**/*"}, Denied: []string{}}, Members: []string{"carol@example.com"}},
+	}
+	local := []models.Role{
+		{Name: "viewer", Resources: models.Resources{Allowed: []string{"kots/app/*/read"}, Denied: []string{}}, Members: []string{"bob@example.com"}},
+		{Name: "admin", Resources: models.Resources{Allowed: []string{"**/*"}, Denied: []string{}}, Members: []string{"alice@example.com", "carol@example.com"}},
+	}
+
+	tests := []struct {
+		name         string
+		args         []string
+		expectOutput []string
+		rejectOutput []string
+	}{
+		{
+			name:         "impact shown with --diff --impact",
+			args:         []string{"--diff", "--impact"},
+			expectOutput: []string{"Access impact: 1 member(s) affected, 1 gaining access:", "alice@example.com: moving from viewer to admin gains **/*; loses kots/app/*/read"},
+		},
+		{
+			name:         "impact needs --diff",
+			args:         []string{"--dry-run", "--impact"},
+			rejectOutput: []string{"Access impact"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tempDir := t.TempDir()
+			for _, role := range local {
+				if err := createTestRoleFile(tempDir, role); err != nil {
+					t.Fatalf("Failed to create role file: %v", err)
+				}
+			}
+
+			cmd := NewSyncCommandWithOptions(NewMockClient(&MockAPICalls{}, remote), func(cmd *cobra.Command) {
+				cmd.Flags().Bool("impact", false, "show member access impact")
+			})
+			var stdout bytes.Buffer
+			cmd.SetOut(&stdout)
+			cmd.SetErr(&bytes.Buffer{})
+			cmd.SetArgs(append([]string{tempDir}, tt.args...))
+			if err := cmd.Execute(); err != nil {
+				t.Fatalf("Unexpected sync error: %v", err)
+			}
+
+			output := stdout.String()
+			for _, expected := range tt.expectOutput {
+				if !strings.Contains(output, expected) {
+					t.Errorf("Expected output to contain %q, got:\n%s", expected, output)
+				}
+			}
+			for _, rejected := range tt.rejectOutput {
+				if strings.Contains(output, rejected) {
+					t.Errorf("Expected output not to contain %q, got:\n%s", rejected, output)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,72 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"replbac/internal/models"
+)
+
+// cancellingClient cancels a context when its first role is created, as Ctrl-C during a sync would
+type cancellingClient struct {
+	*MockClient
+	cancel context.CancelFunc
+}
+
+// CreateRole creates the role and then cancels the context
+func (c *cancellingClient) CreateRole(role models.Role) error {
+	err := c.MockClient.CreateRole(role)
+	c.cancel()
+	return err
+}
+
+func TestSyncInterrupted(t *testing.T) {
+	tempDir := t.TempDir()
+	for _, name := range []string{"admin", "editor", "viewer"} {
+		role := models.Role{Name: name, Resources: models.Resources{Allowed: []string{"kots/app/*/read"}}}
+		if err := createTestRoleFile(tempDir, role); err != nil {
+			t.Fatalf("Failed to create role file: %v", err)
+		}
+	}
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	mockCalls := &MockAPICalls{}
+	client := &cancellingClient{MockClient: NewMockClient(mockCalls, []models.Role{}), cancel: cancel}
+
+	cmd := NewSyncCommandWithOptions(client, nil)
+	var stdout bytes.Buffer
+	cmd.SetOut(&stdout)
+	cmd.SetErr(&bytes.Buffer{})
+	cmd.SetArgs([]string{tempDir})
+
+	err := cmd.ExecuteContext(ctx)
+	if err == nil {
+		t.Fatal("Expected the interrupted sync to fail")
+	}
+	if code := ExitCode(err); code != ExitCodeInterrupted {
+		t.Errorf("Expected exit code %d, got %d", ExitCodeInterrupted, code)
+	}
+	if !strings.Contains(err.Error(), "interrupted after creating 1 of 3 role(s)") {
+		t.Errorf("Expected the error to report progress, got %v", err)
+	}
+	if len(mockCalls.CreateCalls) != 1 {
+		t.Errorf("Expected sync to stop after the in-flight create, got %d create calls", len(mockCalls.CreateCalls))
+	}
+
+	output := stdout.String()
+	for _, expected := range []string{
+		"Sync interrupted after creating 1 of 3 role(s)",
+		"Progress saved: 1 operation(s) completed; re-run with --resume to continue",
+	} {
+		if !strings.Contains(output, expected) {
+			t.Errorf("Expected output to contain %q, got:\n%s", expected, output)
+		}
+	}
+	if strings.Contains(output, "context canceled") {
+		t.Errorf("Expected no bare context error, got:\n%s", output)
+	}
+}
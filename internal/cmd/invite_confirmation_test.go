@@ -0,0 +1,71 @@
+package cmd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"replbac/internal/models"
+)
+
+// invitingClient is a MockClient that records invitations
+type invitingClient struct {
+	*MockClient
+	invited []string
+}
+
+// InviteUser records the invited email
+func (c *invitingClient) InviteUser(email, policyID string) (*models.InviteUserResponse, error) {
+	c.invited = append(c.invited, email)
+	return c.MockClient.InviteUser(email, policyID)
+}
+
+// TestInviteConfirmation tests that sync asks before inviting members and invites nobody when declined
+func TestInviteConfirmation(t *testing.T) {
+	tests := []struct {
+		name        string
+		args        []string
+		input       string
+		wantPrompt  bool
+		wantInvited bool
+	}{
+		{name: "declining invites nobody", input: "n\n", wantPrompt: true},
+		{name: "confirming invites the new member", input: "y\n", wantPrompt: true, wantInvited: true},
+		{name: "force invites without asking", args: []string{"--force"}, wantInvited: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tempDir := t.TempDir()
+			local := models.Role{Name: "viewer", Resources: models.Resources{Allowed: []string{"kots/app/*/read"}}, Members: []string{"new@example.com"}}
+			if err := createTestRoleFile(tempDir, local); err != nil {
+				t.Fatalf("Failed to create role file: %v", err)
+			}
+			remote := models.Role{ID: "role-1", Name: "viewer", Resources: models.Resources{Allowed: []string{"kots/app/*/read"}}}
+			client := &invitingClient{MockClient: NewMockClient(&MockAPICalls{}, []models.Role{remote})}
+
+			cmd := NewSyncCommandWithOptions(client, nil)
+			var stdout bytes.Buffer
+			cmd.SetOut(&stdout)
+			cmd.SetErr(&bytes.Buffer{})
+			cmd.SetIn(strings.NewReader(tt.input))
+			cmd.SetArgs(append([]string{tempDir}, tt.args...))
+
+			if err := cmd.Execute(); err != nil {
+				t.Fatalf("sync failed: %v\n%s", err, stdout.String())
+			}
+			output := stdout.String()
+
+			prompted := strings.Contains(output, "About to invite 1 new member(s): new@example.com. Continue? (y/N)")
+			if prompted != tt.wantPrompt {
+				t.Errorf("Expected prompt=%v, got:\n%s", tt.wantPrompt, output)
+			}
+			if invited := len(client.invited) == 1; invited != tt.wantInvited {
+				t.Errorf("Expected invited=%v, got invitations %v", tt.wantInvited, client.invited)
+			}
+			if !tt.wantInvited && !strings.Contains(output, "Invitations cancelled by user") {
+				t.Errorf("Expected the cancellation to be reported, got:\n%s", output)
+			}
+		})
+	}
+}
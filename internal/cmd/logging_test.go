@@ -154,6 +154,45 @@ func TestVerboseLogging(t *testing.T) {
 	}
 }
 
+// TestSyncTimingsBreakdown tests the per-phase timing summary printed with --timings
+func TestSyncTimingsBreakdown(t *testing.T) {
+	tempDir := t.TempDir()
+	if err := createTestRoleFile(tempDir, models.Role{Name: "editor", Resources: models.Resources{Allowed: []string{"read"}}}); err != nil {
+		t.Fatalf("Failed to create role file: %v", err)
+	}
+
+	for _, enabled := range []bool{true, false} {
+		mockClient := NewMockClient(&MockAPICalls{}, []models.Role{})
+		cmd := NewSyncCommandWithLogging(mockClient, false)
+		var stdout, stderr bytes.Buffer
+		cmd.SetOut(&stdout)
+		cmd.SetErr(&stderr)
+		cmd.SetArgs([]string{tempDir})
+		if enabled {
+			if err := cmd.Flags().Set("timings", "true"); err != nil {
+				t.Fatalf("Failed to set timings flag: %v", err)
+			}
+		}
+
+		if err := cmd.Execute(); err != nil {
+			t.Fatalf("Command execution failed: %v", err)
+		}
+
+		stderrStr := stderr.String()
+		if !enabled {
+			if strings.Contains(stderrStr, "Timings:") {
+				t.Errorf("Did not expect timing summary without --timings, got:\n%s", stderrStr)
+			}
+			continue
+		}
+		for _, phase := range []string{"load local roles", "fetch remote roles", "compare roles", "execute role changes", "sync execution"} {
+			if !strings.Contains(stderrStr, phase) {
+				t.Errorf("Expected timing summary to include %q, got:\n%s", phase, stderrStr)
+			}
+		}
+	}
+}
+
 // NewSyncCommandWithLogging creates a sync command with logging support
 func NewSyncCommandWithLogging(mockClient *MockClient, verbose bool) *cobra.Command {
 	cmd := &cobra.Command{
@@ -182,6 +221,7 @@ func NewSyncCommandWithLogging(mockClient *MockClient, verbose bool) *cobra.Comm
 	// Add flags
 	cmd.Flags().Bool("dry-run", false, "preview changes without applying them")
 	cmd.Flags().Bool("verbose", verbose, "enable verbose logging")
+	cmd.Flags().Bool("timings", false, "print a per-phase timing breakdown")
 
 	return cmd
 }
@@ -0,0 +1,85 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+
+	"replbac/internal/api"
+)
+
+// loginCmd represents the login command
+var loginCmd = &cobra.Command{
+	Use:   "login",
+	Short: "Store the Replicated API token in the OS keyring",
+	Long: `Login saves a Replicated API token in the OS keyring (macOS Keychain, Windows
+Credential Manager, or the Secret Service on Linux) so that it does not need to
+live in a config file or environment variable.
+
+The token is read from standard input. At a terminal it is prompted for without
+being echoed; otherwise the first line of input is used, for example:
+
+  replbac login < token.txt
+
+Commands read the stored token when run with --credential-store keyring.
+No API calls are made.`,
+	Example: `  replbac login
+  replbac sync --credential-store keyring`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return RunLoginCommand(cmd, api.KeyringCredentials{})
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(loginCmd)
+}
+
+// RunLoginCommand reads an API token from the command's input and saves it in the keyring
+func RunLoginCommand(cmd *cobra.Command, store api.KeyringCredentials) error {
+	token, err := readToken(cmd)
+	if err != nil {
+		return err
+	}
+	if err := store.Store(token); err != nil {
+		return withExitCode(ExitCodeConfiguration, err)
+	}
+
+	cmd.Printf("API token stored in the OS keyring (service %s)\n", api.KeyringService)
+	cmd.Println("Use --credential-store keyring to authenticate with it.")
+	return nil
+}
+
+// readToken reads a single token line, without echo when input is a terminal
+func readToken(cmd *cobra.Command) (string, error) {
+	in := cmd.InOrStdin()
+	if file, ok := in.(*os.File); ok && term.IsTerminal(int(file.Fd())) {
+		cmd.PrintErr("Replicated API token: ")
+		data, err := term.ReadPassword(int(file.Fd()))
+		cmd.PrintErrln()
+		if err != nil {
+			return "", fmt.Errorf("failed to read API token: %w", err)
+		}
+		return checkToken(string(data))
+	}
+
+	line, err := bufio.NewReader(in).ReadString('\n')
+	if err != nil && err != io.EOF {
+		return "", fmt.Errorf("failed to read API token: %w", err)
+	}
+	return checkToken(line)
+}
+
+// checkToken trims a token read from input and rejects an empty one
+func checkToken(token string) (string, error) {
+	token = strings.TrimSpace(token)
+	if token == "" {
+		return "", fmt.Errorf("no API token provided")
+	}
+	return token, nil
+}
@@ -0,0 +1,93 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/zalando/go-keyring"
+
+	"replbac/internal/api"
+	"replbac/internal/config"
+	"replbac/internal/models"
+)
+
+func TestRunLoginCommand(t *testing.T) {
+	keyring.MockInit()
+
+	cmd := &cobra.Command{}
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+	cmd.SetErr(&out)
+	cmd.SetIn(strings.NewReader("  login-token\n"))
+
+	if err := RunLoginCommand(cmd, api.KeyringCredentials{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(out.String(), "API token stored in the OS keyring") {
+		t.Errorf("expected confirmation, got: %s", out.String())
+	}
+
+	token, err := api.KeyringCredentials{}.Token()
+	if err != nil || token != "login-token" {
+		t.Errorf("expected stored token %q, got %q (err %v)", "login-token", token, err)
+	}
+
+	cmd.SetIn(strings.NewReader("\n"))
+	if err := RunLoginCommand(cmd, api.KeyringCredentials{}); err == nil || !strings.Contains(err.Error(), "no API token provided") {
+		t.Errorf("expected error for empty token, got %v", err)
+	}
+}
+
+func TestApplyCredentialStore(t *testing.T) {
+	savedCfg, savedSource := cfg, cfgSource
+	t.Cleanup(func() { cfg, cfgSource = savedCfg, savedSource })
+
+	reset := func() {
+		cfg = models.Config{APIToken: "env-token"}
+		cfgSource = config.Resolution{Sources: map[string]string{"api_token": "env REPLICATED_API_TOKEN"}}
+	}
+
+	t.Run("uses stored token", func(t *testing.T) {
+		keyring.MockInit()
+		if err := (api.KeyringCredentials{}).Store("keyring-token"); err != nil {
+			t.Fatalf("failed to store token: %v", err)
+		}
+		reset()
+
+		cmd := &cobra.Command{}
+		if err := applyCredentialStore(cmd, "keyring"); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if cfg.APIToken != "keyring-token" || cfgSource.Sources["api_token"] != "credential store keyring" {
+			t.Errorf("expected keyring token and source, got %q from %q", cfg.APIToken, cfgSource.Sources["api_token"])
+		}
+	})
+
+	t.Run("falls back when keyring is unavailable", func(t *testing.T) {
+		keyring.MockInitWithError(errors.New("no secret service"))
+		reset()
+
+		cmd := &cobra.Command{}
+		var errOut bytes.Buffer
+		cmd.SetErr(&errOut)
+		if err := applyCredentialStore(cmd, "keyring"); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if cfg.APIToken != "env-token" {
+			t.Errorf("expected fallback to env token, got %q", cfg.APIToken)
+		}
+		if !strings.Contains(errOut.String(), "falling back to config file and environment") {
+			t.Errorf("expected fallback warning, got: %s", errOut.String())
+		}
+	})
+
+	t.Run("rejects unknown store", func(t *testing.T) {
+		reset()
+		if err := applyCredentialStore(&cobra.Command{}, "vault"); err == nil || !strings.Contains(err.Error(), "unknown credential store") {
+			t.Errorf("expected unknown store error, got %v", err)
+		}
+	})
+}
@@ -41,12 +41,60 @@ func GenerateManPage() (string, error) {
 	content.WriteString(".TP\n")
 	content.WriteString("\\fBsync\\fR [\\fIdirectory\\fR]\n")
 	content.WriteString("Synchronize local role files to Replicated API. Reads role definitions from\n")
-	content.WriteString("local YAML files and synchronizes them with the Replicated platform.\n")
+	content.WriteString("local YAML files and synchronizes them with the Replicated platform. A directory\n")
+	content.WriteString("can also be a git reference, \\fBgit::\\fR\\fIURL\\fR[//\\fIsubdir\\fR][?ref=\\fIREF\\fR], which is\n")
+	content.WriteString("shallow-cloned to a temporary directory that is removed after the sync.\n")
 	content.WriteString(".TP\n")
 	content.WriteString("\\fBpull\\fR [\\fIdirectory\\fR]\n")
 	content.WriteString("Pull role definitions from Replicated API to local files. Downloads existing\n")
 	content.WriteString("role definitions and creates local YAML files.\n")
 	content.WriteString(".TP\n")
+	content.WriteString("\\fBdiff\\fR [\\fIdirectory\\fR]\n")
+	content.WriteString("Show differences between local role files and remote roles without making\n")
+	content.WriteString("changes. With \\fB--against\\fR \\fIPATH\\fR, compares against a snapshot of remote roles\n")
+	content.WriteString("written by \\fBpull\\fR, a directory or a \\fB--single\\fR file, instead of the live API. With \\fB--unified\\fR, each changed role is\n")
+	content.WriteString("shown as a unified diff of its YAML; \\fB--diff-context\\fR \\fIN\\fR sets how many unchanged\n")
+	content.WriteString("lines surround each change (default 3, 0 for changed lines only) and implies \\fB--unified\\fR.\n")
+	content.WriteString(".TP\n")
+	content.WriteString("\\fBdelete\\fR \\fIrole-name\\fR\n")
+	content.WriteString("Delete a single remote role by name after confirmation. Assigned members are\n")
+	content.WriteString("listed first and can be moved to another role with \\fB--reassign-to\\fR \\fIROLE\\fR.\n")
+	content.WriteString(".TP\n")
+	content.WriteString("\\fBshow\\fR \\fIrole-name\\fR\n")
+	content.WriteString("Show one role's allowed and denied resources grouped by top-level namespace, and\n")
+	content.WriteString("its members, read from the role files in \\fB--dir\\fR or, with \\fB--remote\\fR, from the\n")
+	content.WriteString("API. \\fB--output json\\fR prints the same information as JSON.\n")
+	content.WriteString(".TP\n")
+	content.WriteString("\\fBpurge\\fR\n")
+	content.WriteString("Delete every remote role except protected ones, for tearing down an environment;\n")
+	content.WriteString("\\fB--prune-members\\fR also removes every team member and invitation outside protected roles. Preview with\n")
+	content.WriteString("\\fB--dry-run\\fR; the real run requires \\fB--confirm-purge\\fR \\fIN\\fR matching the number of removals.\n")
+	content.WriteString(".TP\n")
+	content.WriteString("\\fBwatch-drift\\fR \\fI[directory]\\fR\n")
+	content.WriteString("Compare local role files with the API every \\fB--interval\\fR (default 5m) until\n")
+	content.WriteString("interrupted, printing an alert when drift appears or changes, and also posting it\n")
+	content.WriteString("as JSON to \\fB--webhook\\fR \\fIURL\\fR if given. Never makes changes.\n")
+	content.WriteString(".TP\n")
+	content.WriteString("\\fBassign\\fR \\fB--from\\fR \\fIfile.csv\\fR\n")
+	content.WriteString("Assign members to roles from a CSV of email,role rows, reporting each row's\n")
+	content.WriteString("outcome. Members not on the team fail their row unless \\fB--invite\\fR is given.\n")
+	content.WriteString(".TP\n")
+	content.WriteString("\\fBrender\\fR \\fItemplates-directory\\fR \\fIvalues-file\\fR\n")
+	content.WriteString("Render role templates written with Go template syntax into concrete role\n")
+	content.WriteString("files, once per value set in the values file.\n")
+	content.WriteString(".TP\n")
+	content.WriteString("\\fBping\\fR\n")
+	content.WriteString("Check connectivity and authentication with a single lightweight API request.\n")
+	content.WriteString("Exits with 3 if the API token is rejected and 4 if the endpoint cannot be reached.\n")
+	content.WriteString(".TP\n")
+	content.WriteString("\\fBlogin\\fR\n")
+	content.WriteString("Store the Replicated API token in the OS keyring for use with\n")
+	content.WriteString("\\fB--credential-store\\fR \\fIkeyring\\fR.\n")
+	content.WriteString(".TP\n")
+	content.WriteString("\\fBconfig show\\fR\n")
+	content.WriteString("Print the effective configuration and the source of each value, with the API\n")
+	content.WriteString("token masked. No API calls are made.\n")
+	content.WriteString(".TP\n")
 	content.WriteString("\\fBversion\\fR\n")
 	content.WriteString("Print version information including build details.\n")
 
@@ -63,8 +111,41 @@ func GenerateManPage() (string, error) {
 	content.WriteString("\\fB--confirm\\fR\n")
 	content.WriteString("Automatically confirm destructive operations.\n")
 	content.WriteString(".TP\n")
+	content.WriteString("\\fB--yes, -y\\fR\n")
+	content.WriteString("Answer yes to every confirmation prompt; same as --confirm.\n")
+	content.WriteString(".TP\n")
+	content.WriteString("\\fB--no-prompt\\fR\n")
+	content.WriteString("Alias for --yes.\n")
+	content.WriteString(".TP\n")
 	content.WriteString("\\fB--log-level\\fR \\fILEVEL\\fR\n")
 	content.WriteString("Set log level: debug, info, warn, error.\n")
+	content.WriteString(".TP\n")
+	content.WriteString("\\fB--debug-http\\fR\n")
+	content.WriteString("Log full HTTP requests and responses to stderr, with the API token redacted.\n")
+	content.WriteString(".TP\n")
+	content.WriteString("\\fB--credential-provider\\fR \\fIprovider\\fR\n")
+	content.WriteString("Obtain the API token from a provider such as \\fIenv:VAR\\fR or \\fIfile:PATH\\fR.\n")
+	content.WriteString(".TP\n")
+	content.WriteString("\\fB--credential-store\\fR \\fIstore\\fR\n")
+	content.WriteString("Read the API token saved by \\fBreplbac login\\fR from a credential store (\\fBkeyring\\fR), falling back to the config file and environment.\n")
+	content.WriteString(".TP\n")
+	content.WriteString("\\fB--read-only\\fR\n")
+	content.WriteString("Block every write to the Replicated API, even for commands that normally write (env: REPLBAC_READ_ONLY).\n")
+	content.WriteString(".TP\n")
+	content.WriteString("\\fB--api-version\\fR\n")
+	content.WriteString("Replicated vendor API version for role and invitation requests (default v3); unsupported versions are rejected.\n")
+	content.WriteString(".TP\n")
+	content.WriteString("\\fB--log-file\\fR\n")
+	content.WriteString("Also append log messages to this file, at the same level as stderr.\n")
+	content.WriteString(".TP\n")
+	content.WriteString("\\fB--proxy\\fR \\fIURL\\fR\n")
+	content.WriteString("Proxy URL for API requests, with credentials if required; defaults to HTTPS_PROXY and HTTP_PROXY.\n")
+	content.WriteString(".TP\n")
+	content.WriteString("\\fB--ca-cert\\fR \\fIFILE\\fR\n")
+	content.WriteString("PEM file of certificate authorities to trust for the API, in addition to the system's.\n")
+	content.WriteString(".TP\n")
+	content.WriteString("\\fB--insecure-skip-verify\\fR\n")
+	content.WriteString("Do not verify the API's TLS certificate; insecure, for debugging only.\n")
 	content.WriteString(".SS Sync Command Options\n")
 	content.WriteString(".TP\n")
 	content.WriteString("\\fB--dry-run\\fR\n")
@@ -79,6 +160,105 @@ func GenerateManPage() (string, error) {
 	content.WriteString("\\fB--force\\fR\n")
 	content.WriteString("Skip confirmation prompts (requires --delete).\n")
 	content.WriteString(".TP\n")
+	content.WriteString("\\fB--timings\\fR\n")
+	content.WriteString("Print a per-phase timing breakdown to stderr when the sync completes.\n")
+	content.WriteString(".TP\n")
+	content.WriteString("\\fB--fail-on-skip\\fR\n")
+	content.WriteString("Abort without contacting the API if any role file is invalid.\n")
+	content.WriteString(".TP\n")
+	content.WriteString("\\fB--summary-only\\fR\n")
+	content.WriteString("With --diff, show per-role change counts instead of every added or removed entry. Without --diff it is an error.\n")
+	content.WriteString(".TP\n")
+	content.WriteString("\\fB--soft-delete\\fR\n")
+	content.WriteString("With --delete, disable removed roles instead of deleting them (renamed with a disabled- prefix, all resources denied).\n")
+	content.WriteString(".TP\n")
+	content.WriteString("\\fB--report-file\\fR \\fIFILE\\fR\n")
+	content.WriteString("Append a JSON-lines audit entry (user, host, plan, and applied changes) to \\fIFILE\\fR, even when the sync fails.\n")
+	content.WriteString(".TP\n")
+	content.WriteString("\\fB--warn-broad\\fR\n")
+	content.WriteString("Warn about roles that allow '*' or '**/*' with no denied resources (always on with --dry-run).\n")
+	content.WriteString(".TP\n")
+	content.WriteString("\\fB--strict\\fR\n")
+	content.WriteString("Abort the sync if any role allows '*' or '**/*' with no denied resources.\n")
+	content.WriteString(".TP\n")
+	content.WriteString("\\fB--resume\\fR\n")
+	content.WriteString("Resume an interrupted sync, skipping operations its checkpoint records as completed.\n")
+	content.WriteString(".TP\n")
+	content.WriteString("\\fB--case-insensitive-names\\fR\n")
+	content.WriteString("Match local and remote role names regardless of case, warning when names differ only by case. Two local or two remote roles whose names differ only by case are an error.\n")
+	content.WriteString(".TP\n")
+	content.WriteString("\\fB--prompt-timeout\\fR \\fIduration\\fR\n")
+	content.WriteString("Treat an unanswered confirmation prompt as \"no\" after this long, e.g. 30s (default: wait indefinitely).\n")
+	content.WriteString(".TP\n")
+	content.WriteString("\\fB--no-members\\fR\n")
+	content.WriteString("Sync role definitions only, ignoring members entirely (no assignment, invitations, or member removal).\n")
+	content.WriteString(".TP\n")
+	content.WriteString("\\fB--allow-empty\\fR\n")
+	content.WriteString("Allow \\fB--delete\\fR to run when no role files are found, deleting every remote role.\n")
+	content.WriteString(".TP\n")
+	content.WriteString("\\fB--explain\\fR\n")
+	content.WriteString("Show why each role will be created, updated, or deleted, naming the fields that differ.\n")
+	content.WriteString(".TP\n")
+	content.WriteString("\\fB--skip-members-on-error\\fR\n")
+	content.WriteString("Sync roles but skip member sync with a warning if team members cannot be read (always on when reading them is forbidden).\n")
+	content.WriteString(".TP\n")
+	content.WriteString("\\fB--parallel-files\\fR \\fIn\\fR\n")
+	content.WriteString("Number of role files to parse concurrently when loading large directories (default 1); results are identical to sequential loading.\n")
+	content.WriteString(".TP\n")
+	content.WriteString("\\fB--members-only\\fR\n")
+	content.WriteString("Sync only the membership of roles that already exist remotely, without creating, updating, or deleting roles.\n")
+	content.WriteString(".TP\n")
+	content.WriteString("\\fB--show-names\\fR\n")
+	content.WriteString("With --diff, show each member's name and username from the API next to their email (also accepted by \\fBdiff\\fR).\n")
+	content.WriteString(".TP\n")
+	content.WriteString("\\fB--strict-resources\\fR\n")
+	content.WriteString("Warn about allowed or denied entries that do not follow the Replicated resource grammar, such as \\fBkots/app/read\\fR missing its app segment.\n")
+	content.WriteString(".TP\n")
+	content.WriteString("\\fB--summary-json\\fR\n")
+	content.WriteString("After the normal output, write the result as a single \\fBREPLBAC_RESULT={...}\\fR JSON line to stderr, for scripts.\n")
+	content.WriteString(".TP\n")
+	content.WriteString("\\fB--normalize-denies\\fR\n")
+	content.WriteString("Before comparing, drop denied entries that no allowed entry can reach, so roles differing only in them compare equal (also accepted by \\fBdiff\\fR).\n")
+	content.WriteString(".TP\n")
+	content.WriteString("\\fB--merge-duplicates\\fR\n")
+	content.WriteString("Merge role files that share a role name, unioning their allowed, denied, and members lists (default: duplicate names are an error).\n")
+	content.WriteString(".TP\n")
+	content.WriteString("\\fB--strict-members\\fR\n")
+	content.WriteString("Fail the sync if a role lists a member who is not already on the team, instead of inviting them or skipping them with a warning.\n")
+	content.WriteString(".TP\n")
+	content.WriteString("\\fB--invite-rate\\fR\n")
+	content.WriteString("Send at most this many invitations per second, e.g. 0.5 for one every two seconds (default 1, 0 for no limit).\n")
+	content.WriteString(".TP\n")
+	content.WriteString("\\fB--impact\\fR\n")
+	content.WriteString("With --diff, show how each affected member's access changes, naming the resources they gain or lose.\n")
+	content.WriteString(".TP\n")
+	content.WriteString("\\fB--plan-out\\fR \\fIDIR\\fR\n")
+	content.WriteString("Write the remote roles as they would be after the sync to DIR as role files, without applying changes (implies --dry-run).\n")
+	content.WriteString(".TP\n")
+	content.WriteString("\\fB--slack-webhook\\fR \\fIURL\\fR\n")
+	content.WriteString("Post a summary of the sync to this Slack incoming webhook when it finishes, including when it fails (env: REPLBAC_SLACK_WEBHOOK).\n")
+	content.WriteString(".TP\n")
+	content.WriteString("\\fB--notify-on\\fR \\fIWHEN\\fR\n")
+	content.WriteString("Which runs --slack-webhook posts: apply (default), or dry-run to also post previews.\n")
+	content.WriteString(".TP\n")
+	content.WriteString("\\fB--output\\fR \\fIFORMAT\\fR\n")
+	content.WriteString("Output format: text (default), or json-stream to write each role operation to stdout as a JSON line as it is applied.\n")
+	content.WriteString(".TP\n")
+	content.WriteString("\\fB--verify\\fR\n")
+	content.WriteString("After applying, fetch the remote roles again and fail, listing the differences, if they still differ from the local roles.\n")
+	content.WriteString(".TP\n")
+	content.WriteString("\\fB--max-resources-per-role\\fR \\fIN\\fR\n")
+	content.WriteString("Abort before any change if a role has more allowed and denied entries combined than this (default 1000), warning at 90 percent of it; 0 disables the check.\n")
+	content.WriteString(".TP\n")
+	content.WriteString("\\fB--skipped-out\\fR \\fIFILE\\fR\n")
+	content.WriteString("Write the role files skipped as invalid to this file as a JSON array of path and reason objects, empty if none were skipped.\n")
+	content.WriteString(".TP\n")
+	content.WriteString("\\fB--drift-guard-percent\\fR \\fIN\\fR\n")
+	content.WriteString("Abort before applying if the roles created and deleted exceed this percentage of the remote roles, unless --force is given (default 0, disabled).\n")
+	content.WriteString(".TP\n")
+	content.WriteString("\\fB--expect-plan\\fR \\fIFILE\\fR\n")
+	content.WriteString("Fail before applying unless the plan has exactly the operations in FILE, a JSON array or json-stream lines, in any order.\n")
+	content.WriteString(".TP\n")
 	content.WriteString("\\fB--verbose\\fR\n")
 	content.WriteString("Enable info-level logging to stderr.\n")
 	content.WriteString(".TP\n")
@@ -94,6 +274,33 @@ func GenerateManPage() (string, error) {
 	content.WriteString(".TP\n")
 	content.WriteString("\\fB--diff\\fR\n")
 	content.WriteString("Preview changes with detailed diffs.\n")
+	content.WriteString(".TP\n")
+	content.WriteString("\\fB--no-id-comment\\fR\n")
+	content.WriteString("Omit the warning comment above the managed id field in generated files.\n")
+	content.WriteString(".TP\n")
+	content.WriteString("\\fB--include-members\\fR\n")
+	content.WriteString("Write each role's members to the generated files (default); --include-members=false is the same as --exclude-members.\n")
+	content.WriteString(".TP\n")
+	content.WriteString("\\fB--exclude-members\\fR\n")
+	content.WriteString("Omit the members field from generated files so sync leaves membership alone.\n")
+	content.WriteString(".TP\n")
+	content.WriteString("\\fB--sort\\fR\n")
+	content.WriteString("Write allowed, denied, and members in alphabetical order so repeated pulls produce identical files.\n")
+	content.WriteString(".TP\n")
+	content.WriteString("\\fB--roles-from-api\\fR\n")
+	content.WriteString("Pull only the named, comma-separated roles; fails if any does not exist.\n")
+	content.WriteString(".TP\n")
+	content.WriteString("\\fB--since-file\\fR\n")
+	content.WriteString("Write only roles changed since the export recorded in this index file, overwriting their files, and update the index.\n")
+	content.WriteString(".TP\n")
+	content.WriteString("\\fB--single\\fR \\fIFILE\\fR\n")
+	content.WriteString("Write every role to this one multi-document YAML file instead of one file per role.\n")
+	content.WriteString(".TP\n")
+	content.WriteString("\\fB--filter\\fR \\fIPATTERN\\fR\n")
+	content.WriteString("Pull only roles whose names match this glob pattern, e.g. 'payments-*'; cannot be combined with --since-file.\n")
+	content.WriteString(".TP\n")
+	content.WriteString("\\fB--layout\\fR \\fILAYOUT\\fR\n")
+	content.WriteString("Arrange role files as flat, directly in the output directory (default), or namespace, in a subdirectory per resource namespace.\n")
 
 	// ENVIRONMENT section
 	content.WriteString(".SH ENVIRONMENT\n")
@@ -113,6 +320,12 @@ func GenerateManPage() (string, error) {
 	content.WriteString(".TP\n")
 	content.WriteString("\\fBREPLBAC_LOG_LEVEL\\fR\n")
 	content.WriteString("Log level (debug, info, warn, error).\n")
+	content.WriteString(".TP\n")
+	content.WriteString("\\fBREPLBAC_READ_ONLY\\fR\n")
+	content.WriteString("Block every write to the Replicated API (true/false).\n")
+	content.WriteString(".TP\n")
+	content.WriteString("\\fBREPLBAC_SLACK_WEBHOOK\\fR\n")
+	content.WriteString("Slack incoming webhook URL for \\fBsync\\fR, used when \\fB--slack-webhook\\fR is not given.\n")
 	content.WriteString(".PP\n")
 	content.WriteString("Environment variables have lower precedence than CLI flags but higher than config files.\n")
 
@@ -163,6 +376,11 @@ func GenerateManPage() (string, error) {
 	content.WriteString(".RS\n")
 	content.WriteString("\\fBreplbac pull --dry-run\\fR\n")
 	content.WriteString(".RE\n")
+	content.WriteString(".PP\n")
+	content.WriteString("Render role templates into role files:\n")
+	content.WriteString(".RS\n")
+	content.WriteString("\\fBreplbac render templates values.yaml -o ./roles\\fR\n")
+	content.WriteString(".RE\n")
 
 	// SEE ALSO section
 	content.WriteString(".SH SEE ALSO\n")
@@ -0,0 +1,77 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+
+	"replbac/internal/models"
+)
+
+// TestMaxResourcesPerRoleFlag tests that roles over --max-resources-per-role stop the sync
+// before any change is made, and roles close to it are warned about
+func TestMaxResourcesPerRoleFlag(t *testing.T) {
+	resources := func(n int) []string {
+		list := make([]string, n)
+		for i := range list {
+			list[i] = fmt.Sprintf("kots/app/app-%d/read", i)
+		}
+		return list
+	}
+
+	tests := []struct {
+		name          string
+		allowed       int
+		limit         string
+		expectError   string
+		expectWarning bool
+	}{
+		{name: "role within the limit", allowed: 5, limit: "10"},
+		{name: "role close to the limit is warned about", allowed: 9, limit: "10", expectWarning: true},
+		{name: "role over the limit stops the sync", allowed: 11, limit: "10", expectError: "exceed --max-resources-per-role: role big (from "},
+		{name: "zero disables the check", allowed: 11, limit: "0"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tempDir := t.TempDir()
+			role := models.Role{Name: "big", Resources: models.Resources{Allowed: resources(tt.allowed), Denied: []string{}}}
+			if err := createTestRoleFile(tempDir, role); err != nil {
+				t.Fatalf("Failed to create role file: %v", err)
+			}
+
+			calls := &MockAPICalls{}
+			cmd := NewSyncCommandWithOptions(NewMockClient(calls, nil), func(cmd *cobra.Command) {
+				cmd.Flags().Int("max-resources-per-role", 1000, "resource entry limit per role")
+			})
+			var stdout bytes.Buffer
+			cmd.SetOut(&stdout)
+			cmd.SetErr(&bytes.Buffer{})
+			cmd.SetArgs([]string{tempDir, "--max-resources-per-role", tt.limit})
+
+			err := cmd.Execute()
+			if tt.expectError != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.expectError) || !strings.Contains(err.Error(), "has 11 resource entries") {
+					t.Fatalf("Expected an error naming the role and its count, got %v", err)
+				}
+				if len(calls.CreateCalls) != 0 {
+					t.Errorf("Expected no roles to be created, got %d", len(calls.CreateCalls))
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if len(calls.CreateCalls) != 1 {
+				t.Errorf("Expected the role to be created, got %d creates", len(calls.CreateCalls))
+			}
+			warned := strings.Contains(stdout.String(), "Warning: role big has 9 resource entries, close to the limit of 10")
+			if warned != tt.expectWarning {
+				t.Errorf("Expected warning: %v, got output:\n%s", tt.expectWarning, stdout.String())
+			}
+		})
+	}
+}
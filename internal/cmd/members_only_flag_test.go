@@ -0,0 +1,222 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+
+	"replbac/internal/models"
+)
+
+// teamClient serves a fixed team and records member assignments
+type teamClient struct {
+	*MockClient
+	team        []models.TeamMember
+	assignments map[string]string
+	teamErr     error // Returned when listing the team, if set
+	assignErr   error // Returned when assigning a member to a role, if set
+}
+
+// GetTeamMembers returns the configured team
+func (c *teamClient) GetTeamMembers() ([]models.TeamMember, error) {
+	if c.teamErr != nil {
+		return nil, c.teamErr
+	}
+	return c.team, nil
+}
+
+// AssignMemberRole records the role ID assigned to a member; an empty ID removes the member
+func (c *teamClient) AssignMemberRole(memberEmail, roleID string) error {
+	if c.assignErr != nil && roleID != "" {
+		return c.assignErr
+	}
+	c.assignments[memberEmail] = roleID
+	return nil
+}
+
+// TestMembersOnlyFlagBehavior tests that --members-only applies membership changes without touching role definitions
+func TestMembersOnlyFlagBehavior(t *testing.T) {
+	tests := []struct {
+		name              string
+		flags             []string
+		teamErr           error
+		assignErr         error
+		expectError       string
+		expectAssignments map[string]string
+		expectOutput      []string
+		rejectOutput      []string
+		expectResult      string // Expected in the --summary-json line, if set
+	}{
+		{
+			name:  "applies membership and removes orphans",
+			flags: []string{"members-only", "force", "summary-json"},
+			expectAssignments: map[string]string{
+				"b@example.com": "1",
+				"d@example.com": "",
+			},
+			expectOutput: []string{
+				"Warning: role newrole does not exist remotely; its members are not synced",
+				"Will update members of 2 role(s):",
+				"remove 1 team member(s)",
+				"Sync completed: assigned 1 member(s)",
+			},
+			expectResult: `"status":"success","dry_run":false,"created":0,"updated":2,"deleted":0`,
+		},
+		{
+			name:              "skipped member sync is reported",
+			flags:             []string{"members-only", "force", "skip-members-on-error"},
+			teamErr:           errors.New("service unavailable"),
+			expectAssignments: map[string]string{},
+			expectOutput:      []string{"Warning: member sync skipped", "Sync completed: no members updated"},
+			rejectOutput:      []string{"assigned"},
+		},
+		{
+			name:        "failed assignment is an error",
+			flags:       []string{"members-only", "force"},
+			assignErr:   errors.New("service unavailable"),
+			expectError: "failed to assign member b@example.com to role admin",
+		},
+		{
+			name:              "dry run shows only member differences",
+			flags:             []string{"members-only", "dry-run", "diff", "summary-json"},
+			expectAssignments: map[string]string{},
+			expectOutput:      []string{"UPDATE: admin", "+ members: b@example.com", "- members: b@example.com", "Dry run: Would update 2 role(s)"},
+			rejectOutput:      []string{"allowed: *"},
+			expectResult:      `"status":"success","dry_run":true,"created":0,"updated":2,"deleted":0`,
+		},
+		{
+			name:        "conflicts with --no-members",
+			flags:       []string{"members-only", "no-members"},
+			expectError: "--members-only and --no-members cannot be used together",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tempDir := t.TempDir()
+			for _, role := range []models.Role{
+				{Name: "admin", Resources: models.Resources{Allowed: []string{"*"}, Denied: []string{}}, Members: []string{"a@example.com", "b@example.com"}},
+				{Name: "viewer", Resources: models.Resources{Allowed: []string{"read"}, Denied: []string{}}, Members: []string{}},
+				{Name: "newrole", Resources: models.Resources{Allowed: []string{"read"}, Denied: []string{}}, Members: []string{"c@example.com"}},
+			} {
+				if err := createTestRoleFile(tempDir, role); err != nil {
+					t.Fatalf("Failed to create role file: %v", err)
+				}
+			}
+
+			remoteRoles := []models.Role{
+				{ID: "1", Name: "admin", Resources: models.Resources{Allowed: []string{"read"}, Denied: []string{}}, Members: []string{"a@example.com"}},
+				{ID: "2", Name: "viewer", Resources: models.Resources{Allowed: []string{"read"}, Denied: []string{}}, Members: []string{"b@example.com"}},
+			}
+			mockCalls := &MockAPICalls{}
+			client := &teamClient{
+				MockClient: NewMockClient(mockCalls, remoteRoles),
+				team: []models.TeamMember{
+					{Email: "a@example.com", PolicyID: "1"},
+					{Email: "b@example.com", PolicyID: "2"},
+					{Email: "c@example.com", PolicyID: "2"},
+					{Email: "d@example.com", PolicyID: "2"},
+				},
+				assignments: map[string]string{},
+				teamErr:     tt.teamErr,
+				assignErr:   tt.assignErr,
+			}
+
+			cmd := NewSyncCommandWithOptions(client, func(cmd *cobra.Command) {
+				cmd.Flags().Bool("members-only", false, "sync only membership")
+				cmd.Flags().Bool("no-members", false, "ignore members")
+				cmd.Flags().Bool("skip-members-on-error", false, "skip member sync when team members cannot be listed")
+				cmd.Flags().Bool("summary-json", false, "print a structured result line")
+			})
+			for _, flag := range tt.flags {
+				if err := cmd.Flags().Set(flag, "true"); err != nil {
+					t.Fatalf("Failed to set %s flag: %v", flag, err)
+				}
+			}
+
+			var stdout, stderr bytes.Buffer
+			cmd.SetOut(&stdout)
+			cmd.SetErr(&stderr)
+			cmd.SetArgs([]string{tempDir})
+
+			err := cmd.Execute()
+			if tt.expectError != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.expectError) {
+					t.Fatalf("Expected error containing %q, got %v", tt.expectError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v\nOutput:\n%s", err, stdout.String())
+			}
+
+			if len(mockCalls.CreateCalls)+len(mockCalls.UpdateCalls)+len(mockCalls.DeleteCalls) != 0 {
+				t.Errorf("Expected no role changes, got creates %v, updates %v, deletes %v", mockCalls.CreateCalls, mockCalls.UpdateCalls, mockCalls.DeleteCalls)
+			}
+			if !reflect.DeepEqual(client.assignments, tt.expectAssignments) {
+				t.Errorf("Assignments = %v, want %v", client.assignments, tt.expectAssignments)
+			}
+
+			output := stdout.String()
+			for _, expected := range tt.expectOutput {
+				if !strings.Contains(output, expected) {
+					t.Errorf("Expected output to contain %q, got:\n%s", expected, output)
+				}
+			}
+			for _, rejected := range tt.rejectOutput {
+				if strings.Contains(output, rejected) {
+					t.Errorf("Expected output not to contain %q, got:\n%s", rejected, output)
+				}
+			}
+			if tt.expectResult != "" && !strings.Contains(stderr.String(), tt.expectResult) {
+				t.Errorf("Expected result line to contain %s, got:\n%s", tt.expectResult, stderr.String())
+			}
+		})
+	}
+}
+
+// TestMembersOnlyIgnoresEmailCase tests that --members-only does not reassign members whose
+// emails differ from the remote ones only in case, even when the role's resources differ
+func TestMembersOnlyIgnoresEmailCase(t *testing.T) {
+	tempDir := t.TempDir()
+	local := models.Role{Name: "admin", Resources: models.Resources{Allowed: []string{"*"}, Denied: []string{}}, Members: []string{"Alice@Example.com"}}
+	if err := createTestRoleFile(tempDir, local); err != nil {
+		t.Fatalf("Failed to create role file: %v", err)
+	}
+
+	remoteRoles := []models.Role{
+		{ID: "1", Name: "admin", Resources: models.Resources{Allowed: []string{"read"}, Denied: []string{}}, Members: []string{"alice@example.com"}},
+	}
+	client := &teamClient{
+		MockClient:  NewMockClient(&MockAPICalls{}, remoteRoles),
+		team:        []models.TeamMember{{Email: "alice@example.com", PolicyID: "1"}},
+		assignments: map[string]string{},
+	}
+
+	cmd := NewSyncCommandWithOptions(client, func(cmd *cobra.Command) {
+		cmd.Flags().Bool("members-only", false, "sync only membership")
+	})
+	for _, flag := range []string{"members-only", "force"} {
+		if err := cmd.Flags().Set(flag, "true"); err != nil {
+			t.Fatalf("Failed to set %s flag: %v", flag, err)
+		}
+	}
+	var stdout bytes.Buffer
+	cmd.SetOut(&stdout)
+	cmd.SetErr(&bytes.Buffer{})
+	cmd.SetArgs([]string{tempDir})
+
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(client.assignments) != 0 {
+		t.Errorf("Expected no assignments, got %v", client.assignments)
+	}
+	if !strings.Contains(stdout.String(), "No member changes needed") {
+		t.Errorf("Expected no member changes, got:\n%s", stdout.String())
+	}
+}
@@ -0,0 +1,88 @@
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+
+	"replbac/internal/models"
+)
+
+// TestMergeDuplicates tests that --merge-duplicates combines a role split across files
+func TestMergeDuplicates(t *testing.T) {
+	tests := []struct {
+		name          string
+		merge         bool
+		expectError   string
+		expectAllowed []string
+	}{
+		{
+			name:        "duplicate role names are rejected by default",
+			expectError: `role "editor" is defined in both`,
+		},
+		{
+			name:          "duplicate role names are merged with --merge-duplicates",
+			merge:         true,
+			expectAllowed: []string{"kots/app/*/read", "kots/app/*/write", "kots/app/*/promote"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tempDir := t.TempDir()
+			base := models.Role{Name: "editor", Resources: models.Resources{Allowed: []string{"kots/app/*/read", "kots/app/*/write"}}}
+			if err := createTestRoleFile(tempDir, base); err != nil {
+				t.Fatalf("Failed to create role file: %v", err)
+			}
+			extrasDir := filepath.Join(tempDir, "extras")
+			if err := os.Mkdir(extrasDir, 0755); err != nil {
+				t.Fatalf("Failed to create directory: %v", err)
+			}
+			extras := models.Role{Name: "editor", Resources: models.Resources{Allowed: []string{"kots/app/*/write", "kots/app/*/promote"}}}
+			if err := createTestRoleFile(extrasDir, extras); err != nil {
+				t.Fatalf("Failed to create role file: %v", err)
+			}
+
+			mockCalls := &MockAPICalls{}
+			cmd := NewSyncCommandWithOptions(NewMockClient(mockCalls, []models.Role{}), func(cmd *cobra.Command) {
+				cmd.Flags().Bool("merge-duplicates", false, "merge role files that share a role name")
+			})
+			if tt.merge {
+				if err := cmd.Flags().Set("merge-duplicates", "true"); err != nil {
+					t.Fatalf("Failed to set merge-duplicates flag: %v", err)
+				}
+			}
+			var stdout bytes.Buffer
+			cmd.SetOut(&stdout)
+			cmd.SetErr(&bytes.Buffer{})
+			cmd.SetArgs([]string{tempDir})
+
+			err := cmd.Execute()
+			if tt.expectError != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.expectError) {
+					t.Errorf("Expected error containing %q, got %v", tt.expectError, err)
+				}
+				if len(mockCalls.CreateCalls) != 0 {
+					t.Errorf("Expected no roles to be created, got %v", mockCalls.CreateCalls)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if len(mockCalls.CreateCalls) != 1 {
+				t.Fatalf("Expected one merged role to be created, got %v", mockCalls.CreateCalls)
+			}
+			if allowed := mockCalls.CreateCalls[0].Resources.Allowed; strings.Join(allowed, ",") != strings.Join(tt.expectAllowed, ",") {
+				t.Errorf("Expected allowed %v, got %v", tt.expectAllowed, allowed)
+			}
+			if !strings.Contains(stdout.String(), "merging role editor from") {
+				t.Errorf("Expected the merge to be reported, got:\n%s", stdout.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,122 @@
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"replbac/internal/models"
+)
+
+// TestSyncMultipleDirectories tests that roles from several directories are merged into one sync
+func TestSyncMultipleDirectories(t *testing.T) {
+	tests := []struct {
+		name          string
+		dirRoles      [][]models.Role
+		expectError   bool
+		errorContains string
+		expectCreates []string
+	}{
+		{
+			name: "roles from all directories are created",
+			dirRoles: [][]models.Role{
+				{{Name: "viewer", Resources: models.Resources{Allowed: []string{"read"}}}},
+				{{Name: "admin", Resources: models.Resources{Allowed: []string{"*"}}}},
+			},
+			expectCreates: []string{"viewer", "admin"},
+		},
+		{
+			name: "duplicate role names across directories are rejected",
+			dirRoles: [][]models.Role{
+				{{Name: "admin", Resources: models.Resources{Allowed: []string{"read"}}}},
+				{{Name: "admin", Resources: models.Resources{Allowed: []string{"*"}}}},
+			},
+			expectError:   true,
+			errorContains: `role "admin" is defined in both`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var dirs []string
+			for _, roles := range tt.dirRoles {
+				dir := t.TempDir()
+				for _, role := range roles {
+					if err := createTestRoleFile(dir, role); err != nil {
+						t.Fatalf("Failed to create role file: %v", err)
+					}
+				}
+				dirs = append(dirs, dir)
+			}
+
+			mockCalls := &MockAPICalls{}
+			cmd := NewSyncCommandWithOptions(NewMockClient(mockCalls, []models.Role{}), nil)
+			var stdout, stderr bytes.Buffer
+			cmd.SetOut(&stdout)
+			cmd.SetErr(&stderr)
+			cmd.SetArgs(dirs)
+
+			err := cmd.Execute()
+			if tt.expectError {
+				if err == nil || !strings.Contains(err.Error(), tt.errorContains) {
+					t.Fatalf("Expected error containing %q, got: %v", tt.errorContains, err)
+				}
+				if len(mockCalls.CreateCalls) != 0 {
+					t.Errorf("Expected no roles to be created, got %d create calls", len(mockCalls.CreateCalls))
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no error but got: %v", err)
+			}
+
+			for _, dir := range dirs {
+				if !strings.Contains(stdout.String(), "Loading roles from directory: "+dir) {
+					t.Errorf("Expected output to mention loading %s, got:\n%s", dir, stdout.String())
+				}
+			}
+
+			var created []string
+			for _, role := range mockCalls.CreateCalls {
+				created = append(created, role.Name)
+			}
+			if strings.Join(created, ",") != strings.Join(tt.expectCreates, ",") {
+				t.Errorf("Expected creates %v, got %v", tt.expectCreates, created)
+			}
+		})
+	}
+}
+
+// TestSyncSameDirectoryTwice tests that a directory named more than once, however it is
+// spelled, is loaded once rather than reported as defining its roles twice
+func TestSyncSameDirectoryTwice(t *testing.T) {
+	dir := t.TempDir()
+	if err := createTestRoleFile(dir, models.Role{Name: "viewer", Resources: models.Resources{Allowed: []string{"read"}}}); err != nil {
+		t.Fatalf("Failed to create role file: %v", err)
+	}
+	link := filepath.Join(t.TempDir(), "roles")
+	if err := os.Symlink(dir, link); err != nil {
+		t.Skipf("Symlinks are not supported: %v", err)
+	}
+	other := t.TempDir()
+
+	args := []string{dir, dir + string(filepath.Separator), filepath.Join(dir, "."), link, other, other}
+	if dirs := syncDirectories(args); !reflect.DeepEqual(dirs, []string{dir, other}) {
+		t.Errorf("syncDirectories(%v) = %v, want %v", args, dirs, []string{dir, other})
+	}
+
+	mockCalls := &MockAPICalls{}
+	cmd := NewSyncCommandWithOptions(NewMockClient(mockCalls, []models.Role{}), nil)
+	cmd.SetOut(&bytes.Buffer{})
+	cmd.SetErr(&bytes.Buffer{})
+	cmd.SetArgs([]string{dir, dir, link})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(mockCalls.CreateCalls) != 1 {
+		t.Errorf("Expected viewer to be created once, got %v", mockCalls.CreateCalls)
+	}
+}
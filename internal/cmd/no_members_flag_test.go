@@ -0,0 +1,94 @@
+package cmd
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/spf13/cobra"
+
+	"replbac/internal/models"
+)
+
+// TestNoMembersFlagBehavior tests that --no-members leaves membership out of the sync
+func TestNoMembersFlagBehavior(t *testing.T) {
+	tests := []struct {
+		name          string
+		noMembers     bool
+		localRoles    []models.Role
+		expectUpdates int
+	}{
+		{
+			name:      "member-only difference is an update by default",
+			noMembers: false,
+			localRoles: []models.Role{
+				{ID: "1", Name: "admin", Resources: models.Resources{Allowed: []string{"*"}, Denied: []string{}}, Members: []string{"new@example.com"}},
+			},
+			expectUpdates: 1,
+		},
+		{
+			name:      "member-only difference is ignored with --no-members",
+			noMembers: true,
+			localRoles: []models.Role{
+				{ID: "1", Name: "admin", Resources: models.Resources{Allowed: []string{"*"}, Denied: []string{}}, Members: []string{"new@example.com"}},
+			},
+			expectUpdates: 0,
+		},
+		{
+			name:      "resource changes are still synced with --no-members",
+			noMembers: true,
+			localRoles: []models.Role{
+				{ID: "1", Name: "admin", Resources: models.Resources{Allowed: []string{"*"}, Denied: []string{"billing/*"}}, Members: []string{"new@example.com"}},
+			},
+			expectUpdates: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tempDir := t.TempDir()
+			for _, role := range tt.localRoles {
+				if err := createTestRoleFile(tempDir, role); err != nil {
+					t.Fatalf("Failed to create role file: %v", err)
+				}
+			}
+
+			remoteRoles := []models.Role{
+				{ID: "1", Name: "admin", Resources: models.Resources{Allowed: []string{"*"}, Denied: []string{}}, Members: []string{"existing@example.com"}},
+			}
+
+			mockCalls := &MockAPICalls{}
+			cmd := NewSyncCommandWithOptions(NewMockClient(mockCalls, remoteRoles), func(cmd *cobra.Command) {
+				cmd.Flags().Bool("no-members", false, "ignore role members")
+			})
+			flags := []string{"force"}
+			if tt.noMembers {
+				flags = append(flags, "no-members")
+			}
+			for _, flag := range flags {
+				if err := cmd.Flags().Set(flag, "true"); err != nil {
+					t.Fatalf("Failed to set %s flag: %v", flag, err)
+				}
+			}
+
+			var stdout, stderr bytes.Buffer
+			cmd.SetOut(&stdout)
+			cmd.SetErr(&stderr)
+			cmd.SetArgs([]string{tempDir})
+
+			if err := cmd.Execute(); err != nil {
+				t.Fatalf("Expected no error but got: %v", err)
+			}
+
+			if len(mockCalls.UpdateCalls) != tt.expectUpdates {
+				t.Errorf("Expected %d update calls, got %+v", tt.expectUpdates, mockCalls.UpdateCalls)
+			}
+			if tt.noMembers {
+				for _, role := range mockCalls.UpdateCalls {
+					if len(role.Members) > 0 {
+						t.Errorf("Expected update of %s to carry no members, got %v", role.Name, role.Members)
+					}
+				}
+			}
+		})
+	}
+}
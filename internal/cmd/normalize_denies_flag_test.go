@@ -0,0 +1,75 @@
+package cmd
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/spf13/cobra"
+
+	"replbac/internal/models"
+)
+
+// TestNormalizeDenies tests that --normalize-denies stops unreachable denies from causing updates
+func TestNormalizeDenies(t *testing.T) {
+	tests := []struct {
+		name          string
+		normalize     bool
+		localDenied   []string
+		expectUpdates int
+	}{
+		{
+			name:          "unreachable deny causes an update by default",
+			localDenied:   []string{"team/members/write"},
+			expectUpdates: 1,
+		},
+		{
+			name:        "unreachable deny ignored with --normalize-denies",
+			normalize:   true,
+			localDenied: []string{"team/members/write"},
+		},
+		{
+			name:          "reachable deny still causes an update with --normalize-denies",
+			normalize:     true,
+			localDenied:   []string{"kots/app/123/delete"},
+			expectUpdates: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tempDir := t.TempDir()
+			local := models.Role{Name: "editor", Resources: models.Resources{
+				Allowed: []string{"kots/app/*/read", "kots/app/*/delete"},
+				Denied:  tt.localDenied,
+			}}
+			if err := createTestRoleFile(tempDir, local); err != nil {
+				t.Fatalf("Failed to create role file: %v", err)
+			}
+			// The API has dropped every deny from the remote role
+			remote := models.Role{Name: "editor", Resources: models.Resources{
+				Allowed: []string{"kots/app/*/read", "kots/app/*/delete"},
+			}}
+
+			mockCalls := &MockAPICalls{}
+			cmd := NewSyncCommandWithOptions(NewMockClient(mockCalls, []models.Role{remote}), func(cmd *cobra.Command) {
+				cmd.Flags().Bool("normalize-denies", false, "drop unreachable denied entries before comparing")
+			})
+			if tt.normalize {
+				if err := cmd.Flags().Set("normalize-denies", "true"); err != nil {
+					t.Fatalf("Failed to set normalize-denies flag: %v", err)
+				}
+			}
+			var stdout bytes.Buffer
+			cmd.SetOut(&stdout)
+			cmd.SetErr(&bytes.Buffer{})
+			cmd.SetArgs([]string{tempDir})
+
+			if err := cmd.Execute(); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if len(mockCalls.UpdateCalls) != tt.expectUpdates {
+				t.Errorf("Expected %d update calls, got %d; output:\n%s", tt.expectUpdates, len(mockCalls.UpdateCalls), stdout.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,67 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+
+	"replbac/internal/models"
+)
+
+// TestParallelFilesFlagBehavior tests that --parallel-files loads every role and rejects invalid values
+func TestParallelFilesFlagBehavior(t *testing.T) {
+	tests := []struct {
+		name          string
+		value         string
+		expectError   string
+		expectCreates int
+	}{
+		{name: "sequential by default", expectCreates: 20},
+		{name: "parallel loading creates every role", value: "4", expectCreates: 20},
+		{name: "zero is rejected", value: "0", expectError: "--parallel-files must be at least 1, got 0"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tempDir := t.TempDir()
+			for i := 0; i < 20; i++ {
+				role := models.Role{Name: fmt.Sprintf("role-%02d", i), Resources: models.Resources{Allowed: []string{"read"}, Denied: []string{}}}
+				if err := createTestRoleFile(tempDir, role); err != nil {
+					t.Fatalf("Failed to create role file: %v", err)
+				}
+			}
+
+			mockCalls := &MockAPICalls{}
+			cmd := NewSyncCommandWithOptions(NewMockClient(mockCalls, []models.Role{}), func(cmd *cobra.Command) {
+				cmd.Flags().Int("parallel-files", 1, "number of role files to parse concurrently")
+			})
+			if tt.value != "" {
+				if err := cmd.Flags().Set("parallel-files", tt.value); err != nil {
+					t.Fatalf("Failed to set parallel-files flag: %v", err)
+				}
+			}
+
+			var stdout, stderr bytes.Buffer
+			cmd.SetOut(&stdout)
+			cmd.SetErr(&stderr)
+			cmd.SetArgs([]string{tempDir})
+
+			err := cmd.Execute()
+			if tt.expectError != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.expectError) {
+					t.Fatalf("Expected error containing %q, got %v", tt.expectError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if len(mockCalls.CreateCalls) != tt.expectCreates {
+				t.Errorf("Expected %d creates, got %d", tt.expectCreates, len(mockCalls.CreateCalls))
+			}
+		})
+	}
+}
@@ -0,0 +1,108 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"replbac/internal/api"
+	"replbac/internal/logging"
+	"replbac/internal/models"
+)
+
+var (
+	pingVerbose bool
+	pingDebug   bool
+)
+
+// pingCmd represents the ping command
+var pingCmd = &cobra.Command{
+	Use:   "ping",
+	Short: "Check connectivity and authentication with the Replicated API",
+	Long: `Ping makes a single lightweight authenticated request to the Replicated API
+to confirm that the endpoint is reachable and the API token is accepted.
+It is a faster and safer check than running a dry-run sync.
+
+On success it prints the number of team members and pending invites the token
+can see. The team's name is not shown: none of the API endpoints replbac uses,
+the team members and policies endpoints, return it.
+
+The exit code identifies the kind of failure:
+  1  the endpoint responded with an unexpected error
+  3  the API token was rejected
+  4  the endpoint could not be reached
+
+Environment Variables:
+  This command supports all global environment variables.
+  See 'replbac --help' for full environment variable documentation.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return RunPingCommand(cmd, cfg)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(pingCmd)
+
+	pingCmd.Flags().BoolVar(&pingVerbose, "verbose", false, "enable info-level logging to stderr (progress and results)")
+	pingCmd.Flags().BoolVar(&pingDebug, "debug", false, "enable debug-level logging to stderr (detailed operation info)")
+}
+
+// RunPingCommand creates an API client and checks that it can authenticate
+func RunPingCommand(cmd *cobra.Command, config models.Config) error {
+	if cmd.OutOrStdout() == os.Stderr {
+		cmd.SetOut(os.Stdout)
+	}
+
+	var logger *logging.Logger
+	if pingDebug {
+		logger = logging.NewDebugLogger(cmd.ErrOrStderr())
+	} else {
+		logger = logging.NewLogger(cmd.ErrOrStderr(), pingVerbose)
+	}
+	if getBoolFlag(cmd, "debug-http") {
+		logger.EnableHTTPTrace()
+	}
+	teeLogFile(logger)
+
+	// A health check should answer quickly, so failures are reported without retrying
+	client, err := newAPIClient(config, logger, 0)
+	if err != nil {
+		return withExitCode(ExitCodeConfiguration, fmt.Errorf("failed to create API client: %w", err))
+	}
+
+	return RunPingCommandWithClient(cmd, models.ReplicatedAPIEndpoint, restrictClient(client, config))
+}
+
+// RunPingCommandWithClient checks connectivity and authentication using client. The team
+// is described by its member count, since the team members endpoint does not return the
+// team's name.
+func RunPingCommandWithClient(cmd *cobra.Command, endpoint string, client api.ClientInterface) error {
+	members, err := client.GetTeamMembers()
+	if err != nil {
+		return pingError(endpoint, err)
+	}
+
+	cmd.Printf("OK: authenticated to %s\n", endpoint)
+	cmd.Printf("Team: %d member(s) and pending invite(s)\n", len(members))
+	return nil
+}
+
+// pingError distinguishes authentication, network, and endpoint failures and
+// attaches the matching exit code
+func pingError(endpoint string, err error) error {
+	var authErr *api.AuthError
+	if errors.As(err, &authErr) {
+		return withExitCode(ExitCodeConfiguration, err)
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return withExitCode(ExitCodeNetwork, fmt.Errorf("network error: cannot reach %s: %w", endpoint, err))
+	}
+
+	return withExitCode(ExitCodeFailure, fmt.Errorf("endpoint error: unexpected response from %s: %w", endpoint, err))
+}
@@ -0,0 +1,103 @@
+package cmd
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+
+	"replbac/internal/api"
+	"replbac/internal/logging"
+)
+
+func TestRunPingCommandWithClient(t *testing.T) {
+	tests := []struct {
+		name         string
+		handler      http.HandlerFunc
+		unreachable  bool
+		expectCode   int
+		expectError  string
+		expectOutput string
+	}{
+		{
+			name: "authenticated",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != "/v1/team/members" {
+					t.Errorf("unexpected request path %s", r.URL.Path)
+				}
+				_, _ = w.Write([]byte(`[{"id":"1","email":"a@example.com"},{"id":"2","email":"b@example.com"}]`))
+			},
+			expectOutput: "Team: 2 member(s)",
+		},
+		{
+			name: "rejected token",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusUnauthorized)
+				_, _ = w.Write([]byte(`{"error":"invalid token"}`))
+			},
+			expectCode:  ExitCodeConfiguration,
+			expectError: "authentication failed",
+		},
+		{
+			name: "unexpected endpoint response",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusNotFound)
+			},
+			expectCode:  ExitCodeFailure,
+			expectError: "endpoint error",
+		},
+		{
+			name:        "unreachable endpoint",
+			unreachable: true,
+			expectCode:  ExitCodeNetwork,
+			expectError: "network error",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(tt.handler)
+			endpoint := server.URL
+			if tt.unreachable {
+				server.Close()
+			} else {
+				defer server.Close()
+			}
+
+			client, err := api.NewClientWithRetry(endpoint, "test-token", logging.NewLogger(io.Discard, false), 0)
+			if err != nil {
+				t.Fatalf("Failed to create client: %v", err)
+			}
+
+			var stdout bytes.Buffer
+			cmd := &cobra.Command{}
+			cmd.SetOut(&stdout)
+
+			err = RunPingCommandWithClient(cmd, endpoint, client)
+			if tt.expectError != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.expectError) {
+					t.Fatalf("Expected error containing %q, got %v", tt.expectError, err)
+				}
+				if code := ExitCode(err); code != tt.expectCode {
+					t.Errorf("ExitCode() = %d, want %d", code, tt.expectCode)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no error but got: %v", err)
+			}
+
+			output := stdout.String()
+			if !strings.Contains(output, "OK: authenticated to "+endpoint) {
+				t.Errorf("Expected success message, got:\n%s", output)
+			}
+			if !strings.Contains(output, tt.expectOutput) {
+				t.Errorf("Expected output to contain %q, got:\n%s", tt.expectOutput, output)
+			}
+		})
+	}
+}
@@ -0,0 +1,113 @@
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+
+	"replbac/internal/models"
+	"replbac/internal/roles"
+)
+
+// TestPlanOutFlagBehavior tests that --plan-out writes the projected remote roles and applies nothing
+func TestPlanOutFlagBehavior(t *testing.T) {
+	remote := []models.Role{
+		{ID: "1", Name: "viewer", Resources: models.Resources{Allowed: []string{"kots/app/*/read"}, Denied: []string{}}},
+		{ID: "2", Name: "legacy", Resources: models.Resources{Allowed: []string{"**/*"}, Denied: []string{}}},
+	}
+	local := []models.Role{
+		{Name: "viewer", Resources: models.Resources{Allowed: []string{"kots/app/*/read", "team/support-issues/read"}, Denied: []string{}}},
+		{Name: "support", Resources: models.Resources{Allowed: []string{"team/support-issues/read"}, Denied: []string{}}},
+	}
+
+	tests := []struct {
+		name          string
+		args          []string
+		expectedFiles []string
+	}{
+		{
+			name:          "remote roles not in local files are kept without --delete",
+			expectedFiles: []string{"legacy.yaml", "support.yaml", "viewer.yaml"},
+		},
+		{
+			name:          "deleted roles are absent with --delete",
+			args:          []string{"--delete"},
+			expectedFiles: []string{"support.yaml", "viewer.yaml"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tempDir := t.TempDir()
+			for _, role := range local {
+				if err := createTestRoleFile(tempDir, role); err != nil {
+					t.Fatalf("Failed to create role file: %v", err)
+				}
+			}
+			outDir := filepath.Join(t.TempDir(), "proposed")
+
+			calls := &MockAPICalls{}
+			cmd := NewSyncCommandWithOptions(NewMockClient(calls, remote), func(cmd *cobra.Command) {
+				cmd.Flags().String("plan-out", "", "write projected remote roles")
+			})
+			var stdout bytes.Buffer
+			cmd.SetOut(&stdout)
+			cmd.SetErr(&bytes.Buffer{})
+			cmd.SetArgs(append([]string{tempDir, "--plan-out", outDir, "--force"}, tt.args...))
+			if err := cmd.Execute(); err != nil {
+				t.Fatalf("Unexpected sync error: %v", err)
+			}
+
+			if len(calls.CreateCalls)+len(calls.UpdateCalls)+len(calls.DeleteCalls) > 0 {
+				t.Errorf("Expected no API changes, got %+v", calls)
+			}
+			if !strings.Contains(stdout.String(), "projected remote role(s) to "+outDir) {
+				t.Errorf("Expected output to report the projected roles, got:\n%s", stdout.String())
+			}
+
+			entries, err := os.ReadDir(outDir)
+			if err != nil {
+				t.Fatalf("Failed to read plan output directory: %v", err)
+			}
+			var files []string
+			for _, entry := range entries {
+				files = append(files, entry.Name())
+			}
+			if strings.Join(files, ",") != strings.Join(tt.expectedFiles, ",") {
+				t.Errorf("Expected files %v, got %v", tt.expectedFiles, files)
+			}
+
+			viewer, err := roles.ReadRoleFile(filepath.Join(outDir, "viewer.yaml"))
+			if err != nil {
+				t.Fatalf("Failed to read projected role: %v", err)
+			}
+			if len(viewer.Resources.Allowed) != 2 {
+				t.Errorf("Expected the projected viewer role to have the local resources, got %v", viewer.Resources.Allowed)
+			}
+		})
+	}
+}
+
+// TestPlanOutRejectsNonEmptyDirectory tests that --plan-out does not mix with files already in the directory
+func TestPlanOutRejectsNonEmptyDirectory(t *testing.T) {
+	tempDir := t.TempDir()
+	outDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(outDir, "stale.yaml"), []byte("name: stale\n"), 0600); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	cmd := NewSyncCommandWithOptions(NewMockClient(&MockAPICalls{}, nil), func(cmd *cobra.Command) {
+		cmd.Flags().String("plan-out", "", "write projected remote roles")
+	})
+	cmd.SetOut(&bytes.Buffer{})
+	cmd.SetErr(&bytes.Buffer{})
+	cmd.SetArgs([]string{tempDir, "--plan-out", outDir})
+	err := cmd.Execute()
+	if err == nil || !strings.Contains(err.Error(), "is not empty") {
+		t.Errorf("Expected an error about the non-empty directory, got %v", err)
+	}
+}
@@ -0,0 +1,58 @@
+package cmd
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"replbac/internal/models"
+)
+
+// TestPlanSections tests that the sync plan is printed in one section per operation,
+// each sorted by role name, followed by the total
+func TestPlanSections(t *testing.T) {
+	tempDir := t.TempDir()
+	for _, role := range []models.Role{
+		{Name: "zeta", Resources: models.Resources{Allowed: []string{"kots/app/*/read"}, Denied: []string{}}},
+		{Name: "alpha", Resources: models.Resources{Allowed: []string{"kots/app/*/read"}, Denied: []string{}}},
+		{Name: "viewer", Resources: models.Resources{Allowed: []string{"kots/app/*/read"}, Denied: []string{}}},
+	} {
+		if err := createTestRoleFile(tempDir, role); err != nil {
+			t.Fatalf("Failed to create role file: %v", err)
+		}
+	}
+
+	mock := NewMockClient(&MockAPICalls{}, []models.Role{
+		{ID: "1", Name: "viewer", Resources: models.Resources{Allowed: []string{"**/*"}, Denied: []string{}}},
+		{ID: "2", Name: "old-b", Resources: models.Resources{Allowed: []string{"**/*"}, Denied: []string{}}},
+		{ID: "3", Name: "old-a", Resources: models.Resources{Allowed: []string{"**/*"}, Denied: []string{}}},
+	})
+	cmd := NewSyncCommandWithOptions(mock, nil)
+	var stdout bytes.Buffer
+	cmd.SetOut(&stdout)
+	cmd.SetErr(&bytes.Buffer{})
+	cmd.SetArgs([]string{tempDir, "--dry-run", "--delete"})
+
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	// Created and updated roles name the file they are from
+	from := func(name string) string {
+		return " (from " + filepath.Join(tempDir, name+".yaml") + ")"
+	}
+	expected := `── Creating (2) ──
+  - alpha` + from("alpha") + `
+  - zeta` + from("zeta") + `
+── Updating (1) ──
+  - viewer` + from("viewer") + `
+── Deleting (2) ──
+  - old-a
+  - old-b
+Total: 5 role change(s)
+`
+	if !strings.Contains(stdout.String(), expected) {
+		t.Errorf("Expected output to contain:\n%s\ngot:\n%s", expected, stdout.String())
+	}
+}
@@ -0,0 +1,90 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"replbac/internal/models"
+)
+
+// promptsApproved reports whether confirmation prompts are answered yes without asking,
+// because of the command's --force or the confirm setting, which the global --confirm,
+// --yes, and --no-prompt flags and REPLBAC_CONFIRM turn on
+func promptsApproved(force bool, config models.Config) bool {
+	return force || config.Confirm
+}
+
+// confirmOperation is how every command asks before a destructive or outward-facing
+// operation. If approved, the operation is confirmed without printing anything;
+// otherwise notice, if any, is printed and question is asked with askConfirmation,
+// within the command's --prompt-timeout.
+func confirmOperation(cmd *cobra.Command, approved bool, notice, question, operation string) (bool, error) {
+	if approved {
+		return true, nil
+	}
+	if notice != "" {
+		cmd.Print(notice)
+	}
+	return askConfirmation(cmd, question, operation, getDurationFlag(cmd, "prompt-timeout"))
+}
+
+// promptAnswer is a line read from the confirmation prompt
+type promptAnswer struct {
+	line string
+	err  error
+}
+
+// askConfirmation prints question and reports whether the user answered yes. When the
+// command's input is not a terminal the prompt is refused with an error naming the
+// operation, so that an unattended run never blocks waiting for an answer. A positive
+// timeout treats no answer within that time as "no".
+func askConfirmation(cmd *cobra.Command, question, operation string, timeout time.Duration) (bool, error) {
+	in := cmd.InOrStdin()
+	if !isInteractive(in) {
+		return false, fmt.Errorf("%s require --yes or --force in non-interactive mode", operation)
+	}
+
+	cmd.Print(question)
+
+	answers := make(chan promptAnswer, 1)
+	go func() {
+		line, err := bufio.NewReader(in).ReadString('\n')
+		answers <- promptAnswer{line: line, err: err}
+	}()
+
+	var expired <-chan time.Time
+	if timeout > 0 {
+		timer := time.NewTimer(timeout)
+		defer timer.Stop()
+		expired = timer.C
+	}
+
+	select {
+	case answer := <-answers:
+		if answer.err != nil {
+			return false, fmt.Errorf("failed to read confirmation: %w", answer.err)
+		}
+		response := strings.ToLower(strings.TrimSpace(answer.line))
+		return response == "y" || response == "yes", nil
+	case <-expired:
+		cmd.Printf("\nNo response within %s; treating as no\n", timeout)
+		return false, nil
+	}
+}
+
+// isInteractive reports whether input is a terminal. Readers other than files, such as
+// those supplied by tests, are treated as interactive.
+func isInteractive(in io.Reader) bool {
+	file, ok := in.(*os.File)
+	if !ok {
+		return true
+	}
+	info, err := file.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
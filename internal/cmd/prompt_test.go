@@ -0,0 +1,132 @@
+package cmd
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"replbac/internal/models"
+)
+
+func TestAskConfirmation(t *testing.T) {
+	tests := []struct {
+		name            string
+		input           func(t *testing.T) io.Reader
+		timeout         time.Duration
+		expectConfirmed bool
+		expectError     string
+		expectOutput    string
+	}{
+		{
+			name:            "yes is confirmed",
+			input:           func(t *testing.T) io.Reader { return strings.NewReader("yes\n") },
+			expectConfirmed: true,
+		},
+		{
+			name:  "anything else is declined",
+			input: func(t *testing.T) io.Reader { return strings.NewReader("n\n") },
+		},
+		{
+			name: "non-interactive input is refused",
+			input: func(t *testing.T) io.Reader {
+				reader, writer, err := os.Pipe()
+				if err != nil {
+					t.Fatalf("Failed to create pipe: %v", err)
+				}
+				t.Cleanup(func() {
+					_ = reader.Close()
+					_ = writer.Close()
+				})
+				return reader
+			},
+			expectError: "deletions require --yes or --force in non-interactive mode",
+		},
+		{
+			name: "timeout is treated as no",
+			input: func(t *testing.T) io.Reader {
+				reader, writer := io.Pipe()
+				t.Cleanup(func() { _ = writer.Close() })
+				return reader
+			},
+			timeout:      10 * time.Millisecond,
+			expectOutput: "No response within 10ms; treating as no",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := &cobra.Command{}
+			var stdout bytes.Buffer
+			cmd.SetOut(&stdout)
+			cmd.SetIn(tt.input(t))
+
+			confirmed, err := askConfirmation(cmd, "Continue? (y/N): ", "deletions", tt.timeout)
+			if tt.expectError != "" {
+				if err == nil || err.Error() != tt.expectError {
+					t.Fatalf("Expected error %q, got %v", tt.expectError, err)
+				}
+				if stdout.Len() != 0 {
+					t.Errorf("Expected no prompt for non-interactive input, got %q", stdout.String())
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if confirmed != tt.expectConfirmed {
+				t.Errorf("confirmed = %v, want %v", confirmed, tt.expectConfirmed)
+			}
+			if tt.expectOutput != "" && !strings.Contains(stdout.String(), tt.expectOutput) {
+				t.Errorf("Expected output to contain %q, got %q", tt.expectOutput, stdout.String())
+			}
+		})
+	}
+}
+
+func TestConfirmOperation(t *testing.T) {
+	t.Run("approved operations are confirmed without asking", func(t *testing.T) {
+		reader, writer, err := os.Pipe()
+		if err != nil {
+			t.Fatalf("Failed to create pipe: %v", err)
+		}
+		defer func() {
+			_ = reader.Close()
+			_ = writer.Close()
+		}()
+
+		cmd := &cobra.Command{}
+		var stdout bytes.Buffer
+		cmd.SetOut(&stdout)
+		cmd.SetIn(reader)
+
+		for _, approved := range []bool{promptsApproved(true, models.Config{}), promptsApproved(false, models.Config{Confirm: true})} {
+			confirmed, err := confirmOperation(cmd, approved, "Notice\n", "Continue? (y/N): ", "deletions")
+			if err != nil || !confirmed {
+				t.Errorf("Expected approval without a prompt, got confirmed=%v err=%v", confirmed, err)
+			}
+		}
+		if stdout.Len() != 0 {
+			t.Errorf("Expected nothing to be printed, got %q", stdout.String())
+		}
+	})
+
+	t.Run("other operations print the notice and ask", func(t *testing.T) {
+		cmd := &cobra.Command{}
+		var stdout bytes.Buffer
+		cmd.SetOut(&stdout)
+		cmd.SetIn(strings.NewReader("y\n"))
+
+		confirmed, err := confirmOperation(cmd, promptsApproved(false, models.Config{}), "Notice\n", "Continue? (y/N): ", "deletions")
+		if err != nil || !confirmed {
+			t.Errorf("Expected the answer to confirm, got confirmed=%v err=%v", confirmed, err)
+		}
+		if stdout.String() != "Notice\nContinue? (y/N): " {
+			t.Errorf("Expected the notice and question, got %q", stdout.String())
+		}
+	})
+}
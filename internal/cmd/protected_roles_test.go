@@ -0,0 +1,60 @@
+package cmd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+
+	"replbac/internal/logging"
+	"replbac/internal/models"
+)
+
+// TestProtectedRolesNeverDeleted tests that roles protected in the configuration survive sync --delete
+func TestProtectedRolesNeverDeleted(t *testing.T) {
+	tempDir := t.TempDir()
+	if err := createTestRoleFile(tempDir, models.Role{Name: "admin", Resources: models.Resources{Allowed: []string{"*"}, Denied: []string{}}}); err != nil {
+		t.Fatalf("Failed to create role file: %v", err)
+	}
+
+	remoteRoles := []models.Role{
+		{ID: "1", Name: "admin", Resources: models.Resources{Allowed: []string{"*"}, Denied: []string{}}},
+		{ID: "2", Name: "platform-admin", Resources: models.Resources{Allowed: []string{"*"}, Denied: []string{}}},
+		{ID: "3", Name: "team-sales", Resources: models.Resources{Allowed: []string{"read"}, Denied: []string{}}},
+		{ID: "4", Name: "legacy", Resources: models.Resources{Allowed: []string{"read"}, Denied: []string{}}},
+	}
+
+	mockCalls := &MockAPICalls{}
+	mockClient := NewMockClient(mockCalls, remoteRoles)
+	cmd := &cobra.Command{
+		Use: "sync",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			config := models.Config{
+				APIToken:       "test-token",
+				LogLevel:       "info",
+				ProtectedRoles: []string{"platform-admin", "team-*"},
+			}
+			logger := logging.NewLogger(cmd.ErrOrStderr(), false)
+			return RunSyncCommandWithLogging(cmd, args, mockClient, false, false, true, true, true, logger, config)
+		},
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd.SetOut(&stdout)
+	cmd.SetErr(&stderr)
+	cmd.SetArgs([]string{tempDir})
+
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("Expected no error but got: %v", err)
+	}
+
+	if len(mockCalls.DeleteCalls) != 1 || mockCalls.DeleteCalls[0] != "legacy" {
+		t.Errorf("Expected only legacy to be deleted, got %v", mockCalls.DeleteCalls)
+	}
+	for _, expected := range []string{"protected role platform-admin not deleted", "protected role team-sales not deleted"} {
+		if !strings.Contains(stdout.String(), expected) {
+			t.Errorf("Expected output to contain %q, got:\n%s", expected, stdout.String())
+		}
+	}
+}
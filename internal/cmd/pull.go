@@ -3,8 +3,11 @@ package cmd
 import (
 	"fmt"
 	"os"
+	"path"
 	"path/filepath"
+	"sort"
 	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 
@@ -15,11 +18,26 @@ import (
 )
 
 var (
-	pullForce   bool
-	pullDryRun  bool
-	pullDiff    bool
-	pullVerbose bool
-	pullDebug   bool
+	pullForce       bool
+	pullDryRun      bool
+	pullDiff        bool
+	pullVerbose     bool
+	pullDebug       bool
+	pullNoIDComment bool
+	pullInclMem     bool
+	pullExclMem     bool
+	pullSort        bool
+	pullRoles       []string
+	pullSince       string
+	pullSingle      string
+	pullFilter      string
+	pullLayout      string
+)
+
+// Layouts of the files pull writes, chosen with --layout
+const (
+	pullLayoutFlat      = "flat"      // Every role file directly in the output directory
+	pullLayoutNamespace = "namespace" // Each role file in a directory named after its resources
 )
 
 // pullCmd represents the pull command
@@ -39,6 +57,18 @@ The pull operation will:
 Use --dry-run to preview what files would be created or updated.
 Use --diff to see detailed differences when files would be changed.
 Use --force to overwrite existing files.
+Use --roles-from-api to pull only the named roles, e.g. --roles-from-api admin,viewer;
+pull fails if any of them does not exist.
+Use --filter to pull only roles whose names match a glob pattern, e.g.
+--filter 'payments-*', to start a working directory for part of an account.
+Use --since-file for incremental backups: only roles whose content changed
+since the export recorded in that file are written, overwriting their files,
+and roles deleted remotely since are noted in it.
+Use --single to write every role into one multi-document YAML file instead of
+one file per role; sync and diff read each document as a role.
+Use --layout namespace to group role files into a subdirectory per resource
+namespace, the first segment of each role's resources, e.g. kots/admin.yaml;
+sync and diff find role files in subdirectories.
 
 Environment Variables:
   This command supports all global environment variables.
@@ -58,6 +88,16 @@ func init() {
 	pullCmd.Flags().BoolVar(&pullForce, "force", false, "overwrite existing files")
 	pullCmd.Flags().BoolVar(&pullDryRun, "dry-run", false, "preview changes without applying them")
 	pullCmd.Flags().BoolVar(&pullDiff, "diff", false, "preview changes with detailed diffs (implies --dry-run)")
+	pullCmd.Flags().BoolVar(&pullNoIDComment, "no-id-comment", false, "omit the warning comment above the managed id field in generated files")
+	pullCmd.Flags().BoolVar(&pullInclMem, "include-members", true, "write each role's members to the generated files (default)")
+	pullCmd.Flags().BoolVar(&pullExclMem, "exclude-members", false, "omit members from the generated files so sync leaves membership alone")
+	pullCmd.MarkFlagsMutuallyExclusive("include-members", "exclude-members")
+	pullCmd.Flags().BoolVar(&pullSort, "sort", false, "write allowed, denied, and members in alphabetical order so repeated pulls produce identical files")
+	pullCmd.Flags().StringSliceVar(&pullRoles, "roles-from-api", nil, "pull only these roles, by name (comma-separated, e.g. admin,viewer)")
+	pullCmd.Flags().StringVar(&pullFilter, "filter", "", "pull only roles whose names match this glob pattern (e.g. 'payments-*')")
+	pullCmd.Flags().StringVar(&pullLayout, "layout", pullLayoutFlat, "arrange role files as flat, in the output directory, or namespace, in a subdirectory per resource namespace")
+	pullCmd.Flags().StringVar(&pullSingle, "single", "", "write all roles to this one multi-document YAML file instead of a file per role")
+	pullCmd.Flags().StringVar(&pullSince, "since-file", "", "write only roles changed since the export recorded in this index file, and update it")
 	pullCmd.Flags().BoolVar(&pullVerbose, "verbose", false, "enable info-level logging to stderr (progress and results)")
 	pullCmd.Flags().BoolVar(&pullDebug, "debug", false, "enable debug-level logging to stderr (detailed operation info)")
 }
@@ -90,12 +130,20 @@ func RunPullCommand(cmd *cobra.Command, args []string, config models.Config, dry
 	} else {
 		logger = logging.NewLogger(cmd.ErrOrStderr(), pullVerbose)
 	}
+	if getBoolFlag(cmd, "debug-http") {
+		logger.EnableHTTPTrace()
+	}
+	teeLogFile(logger)
 
 	// Determine target directory
 	targetDir := "."
 	if len(args) > 0 {
 		targetDir = args[0]
 	}
+	single := getStringFlag(cmd, "single")
+	if single != "" && len(args) > 0 {
+		return fmt.Errorf("--single names the output file and cannot be combined with a directory")
+	}
 
 	if dryRun {
 		if diff {
@@ -105,7 +153,11 @@ func RunPullCommand(cmd *cobra.Command, args []string, config models.Config, dry
 		}
 	}
 
-	cmd.Printf("Pulling role files in directory: %s\n", targetDir)
+	if single != "" {
+		cmd.Printf("Pulling roles into file: %s\n", single)
+	} else {
+		cmd.Printf("Pulling role files in directory: %s\n", targetDir)
+	}
 	logger.Debug("starting pull operation in directory: %s", targetDir)
 
 	if force && !dryRun {
@@ -115,25 +167,56 @@ func RunPullCommand(cmd *cobra.Command, args []string, config models.Config, dry
 
 	// Create API client
 	logger.Debug("creating API client")
-	client, err := api.NewClient(models.ReplicatedAPIEndpoint, config.APIToken, logger)
+	client, err := newAPIClient(config, logger, api.DefaultMaxRetries)
 	if err != nil {
 		logger.Error("failed to create API client: %v", err)
 		return fmt.Errorf("failed to create API client: %w", err)
 	}
 
-	return RunPullCommandWithClient(cmd, targetDir, dryRun, diff, force, client)
+	return RunPullCommandWithClient(cmd, targetDir, dryRun, diff, force, restrictClient(client, config))
 }
 
 // RunPullCommandWithClient implements pull with dependency injection for testing
 func RunPullCommandWithClient(cmd *cobra.Command, outputDir string, dryRun, diff, force bool, client api.ClientInterface) error {
+	sinceFile := getStringFlag(cmd, "since-file")
+	if sinceFile != "" && len(getStringSliceFlag(cmd, "roles-from-api")) > 0 {
+		// The index must cover every remote role, or the others would be noted as deleted
+		return fmt.Errorf("--since-file cannot be combined with --roles-from-api")
+	}
+	filter := getStringFlag(cmd, "filter")
+	if filter != "" {
+		if _, err := path.Match(filter, ""); err != nil {
+			return fmt.Errorf("invalid --filter pattern %q: %w", filter, err)
+		}
+		if sinceFile != "" {
+			// As with --roles-from-api, the roles left out would be noted as deleted
+			return fmt.Errorf("--since-file cannot be combined with --filter")
+		}
+	}
+	layout := getStringFlag(cmd, "layout")
+	if layout == "" {
+		layout = pullLayoutFlat
+	}
+	if layout != pullLayoutFlat && layout != pullLayoutNamespace {
+		return fmt.Errorf("invalid --layout %q: must be %s or %s", layout, pullLayoutFlat, pullLayoutNamespace)
+	}
+	single := getStringFlag(cmd, "single")
+	if single != "" && layout != pullLayoutFlat {
+		return fmt.Errorf("--single writes one file and cannot be combined with --layout %s", layout)
+	}
+	if sinceFile != "" && single != "" {
+		// An incremental pull writes only changed roles, which would drop the rest from the file
+		return fmt.Errorf("--since-file cannot be combined with --single")
+	}
+
 	// Fetch roles from API
-	apiRoles, err := client.GetRoles()
+	apiRoles, err := fetchPullRoles(cmd, client)
 	if err != nil {
 		cmd.Printf("Failed to fetch roles from API: %v\n", err)
 		return fmt.Errorf("failed to fetch roles from API: %w", err)
 	}
 
-	if len(apiRoles) == 0 {
+	if len(apiRoles) == 0 && sinceFile == "" {
 		cmd.Println("No roles found in API")
 		cmd.Println("Pull completed: no files created")
 		return nil
@@ -141,8 +224,55 @@ func RunPullCommandWithClient(cmd *cobra.Command, outputDir string, dryRun, diff
 
 	cmd.Printf("Downloaded %d role(s) from API\n", len(apiRoles))
 
+	if filter != "" {
+		apiRoles = filterRolesByName(apiRoles, filter)
+		if len(apiRoles) == 0 {
+			cmd.Printf("No roles match --filter %s\n", filter)
+			cmd.Println("Pull completed: no files created")
+			return nil
+		}
+		cmd.Printf("%d role(s) match --filter %s\n", len(apiRoles), filter)
+	}
+
+	// With --since-file, only roles changed since the last export are written
+	remoteRoles := apiRoles
+	var index roles.ExportIndex
+	if sinceFile != "" {
+		if pullOmitsMembers(cmd) {
+			// Member changes alone do not change the files, so they should not count
+			remoteRoles = make([]models.Role, len(apiRoles))
+			for i, role := range apiRoles {
+				role.Members = nil
+				remoteRoles[i] = role
+			}
+		}
+		index, err = roles.LoadExportIndex(sinceFile)
+		if err != nil {
+			return err
+		}
+		changes := index.Compare(remoteRoles)
+		if len(changes.Unchanged) > 0 {
+			cmd.Printf("%d role(s) unchanged since the last export\n", len(changes.Unchanged))
+		}
+		for _, name := range changes.Deleted {
+			cmd.Printf("Role %s was deleted remotely since the last export (noted in %s)\n", name, sinceFile)
+		}
+		apiRoles = changes.Changed
+		// The files of changed roles hold their previous export
+		force = true
+	}
+
 	// Initialize result tracking
 	result := PullResult{Total: len(apiRoles), DryRun: dryRun}
+	writeOpts := roles.WriteOptions{
+		OmitIDComment: getBoolFlag(cmd, "no-id-comment"),
+		OmitMembers:   pullOmitsMembers(cmd),
+		SortLists:     getBoolFlag(cmd, "sort"),
+	}
+
+	if single != "" {
+		return pullSingleFile(cmd, single, apiRoles, writeOpts, dryRun, diff, force)
+	}
 
 	// Create output directory if it doesn't exist (unless dry-run)
 	if !dryRun {
@@ -152,28 +282,59 @@ func RunPullCommandWithClient(cmd *cobra.Command, outputDir string, dryRun, diff
 	}
 
 	// Process role files
+	existingFiles := existingRoleFiles(outputDir)
 	for _, role := range apiRoles {
 		fileName := fmt.Sprintf("%s.yaml", role.Name)
+		if layout == pullLayoutNamespace {
+			fileName = filepath.Join(resourceNamespace(role), fileName)
+		}
 		filePath := filepath.Join(outputDir, fileName)
 
+		// A role already pulled elsewhere under the directory keeps one file: the flat layout
+		// updates it where it is, and the namespace layout moves it to its namespace
+		existingPath := filePath
+		if found, ok := existingFiles[role.Name]; ok && found != filePath {
+			if _, err := os.Stat(filePath); os.IsNotExist(err) {
+				existingPath = found
+				if layout == pullLayoutFlat {
+					filePath = found
+					if relative, err := filepath.Rel(outputDir, found); err == nil {
+						fileName = relative
+					}
+				}
+			}
+		}
+
 		// Check if file exists
 		existingContent := ""
-		if _, err := os.Stat(filePath); err == nil {
+		if _, err := os.Stat(existingPath); err == nil {
 			// File exists
 			// #nosec G304 -- Reading user file to check for conflicts is expected behavior
-			if existingBytes, readErr := os.ReadFile(filePath); readErr == nil {
+			if existingBytes, readErr := os.ReadFile(existingPath); readErr == nil {
 				existingContent = string(existingBytes)
 			}
+			// Keep fields the user added for their own tooling, and the manage mode, which
+			// the API does not store
+			if existingRole, readErr := roles.ReadRoleFile(existingPath); readErr == nil {
+				role.Extra = existingRole.Extra
+				role.Manage = existingRole.Manage
+			}
 
 			if force || dryRun {
 				// Generate new content
-				newContent, err := roles.GenerateRoleYAML(role)
+				newContent, err := roles.GenerateRoleYAMLWithOptions(role, writeOpts)
 				if err != nil {
 					return fmt.Errorf("failed to generate YAML for role %s: %w", role.Name, err)
 				}
 
 				if dryRun {
-					if existingContent != newContent {
+					if existingPath != filePath {
+						cmd.Printf("Would move %s to %s\n", existingPath, filePath)
+						if diff && existingContent != newContent {
+							showDiff(cmd, existingContent, newContent)
+						}
+						result.WouldUpdate++
+					} else if existingContent != newContent {
 						if diff {
 							cmd.Printf("Would update %s\n", filePath)
 							showDiff(cmd, existingContent, newContent)
@@ -187,15 +348,26 @@ func RunPullCommandWithClient(cmd *cobra.Command, outputDir string, dryRun, diff
 					}
 				} else {
 					// Actually update the file
-					if err := roles.WriteRoleFile(role, filePath); err != nil {
+					if err := roles.WriteRoleFileWithOptions(role, filePath, writeOpts); err != nil {
 						return fmt.Errorf("failed to write role file %s: %w", fileName, err)
 					}
-					cmd.Printf("Overwrote %s\n", filePath)
+					if existingPath != filePath {
+						if err := os.Remove(existingPath); err != nil {
+							return fmt.Errorf("failed to remove %s after moving role %s: %w", existingPath, role.Name, err)
+						}
+						cmd.Printf("Moved %s to %s\n", existingPath, filePath)
+					} else {
+						cmd.Printf("Overwrote %s\n", filePath)
+					}
 					result.Overwritten++
 				}
 			} else {
 				// Skip existing file
-				cmd.Printf("Skipped %s (file already exists)\n", fileName)
+				if existingPath != filePath {
+					cmd.Printf("Skipped %s (role already in %s)\n", fileName, existingPath)
+				} else {
+					cmd.Printf("Skipped %s (file already exists)\n", fileName)
+				}
 				result.Skipped++
 			}
 		} else if os.IsNotExist(err) {
@@ -205,7 +377,7 @@ func RunPullCommandWithClient(cmd *cobra.Command, outputDir string, dryRun, diff
 				result.WouldCreate++
 			} else {
 				// Create it
-				if err := roles.WriteRoleFile(role, filePath); err != nil {
+				if err := roles.WriteRoleFileWithOptions(role, filePath, writeOpts); err != nil {
 					return fmt.Errorf("failed to write role file %s: %w", fileName, err)
 				}
 				cmd.Printf("Created %s\n", filePath)
@@ -217,6 +389,19 @@ func RunPullCommandWithClient(cmd *cobra.Command, outputDir string, dryRun, diff
 		}
 	}
 
+	if sinceFile != "" {
+		if !dryRun {
+			index.Update(remoteRoles, time.Now().UTC().Format(time.RFC3339))
+			if err := index.Save(sinceFile); err != nil {
+				return err
+			}
+		}
+		if len(apiRoles) == 0 {
+			cmd.Println("Pull completed: no roles changed since the last export")
+			return nil
+		}
+	}
+
 	// Display completion message
 	if dryRun {
 		if result.WouldCreate > 0 && result.WouldUpdate > 0 {
@@ -239,6 +424,113 @@ func RunPullCommandWithClient(cmd *cobra.Command, outputDir string, dryRun, diff
 	return nil
 }
 
+// fetchPullRoles returns the roles to pull: every remote role or, with --roles-from-api,
+// only the named roles, each of which must exist. GetRole does not read members, so
+// unless they are excluded they are taken from the team listing, as GetRoles does.
+func fetchPullRoles(cmd *cobra.Command, client api.ClientInterface) ([]models.Role, error) {
+	names := getStringSliceFlag(cmd, "roles-from-api")
+	if len(names) == 0 {
+		return client.GetRoles()
+	}
+
+	named := make([]models.Role, 0, len(names))
+	seen := make(map[string]bool, len(names))
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		if name == "" || seen[name] {
+			continue
+		}
+		seen[name] = true
+		role, err := client.GetRole(name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get role %s: %w", name, err)
+		}
+		named = append(named, role)
+	}
+	if pullOmitsMembers(cmd) {
+		return named, nil
+	}
+
+	members, err := client.GetTeamMembers()
+	if err != nil {
+		// Continue without member data rather than failing, as a full pull does
+		cmd.PrintErrf("Warning: failed to fetch team members (files will not include members): %v\n", err)
+		return named, nil
+	}
+	for i := range named {
+		named[i].Members = nil
+		named[i].MemberDetails = nil
+		for _, member := range members {
+			if member.PolicyID != "" && member.PolicyID == named[i].ID {
+				named[i].Members = append(named[i].Members, member.ID)
+				named[i].MemberDetails = append(named[i].MemberDetails, member)
+			}
+		}
+	}
+	return named, nil
+}
+
+// unscopedNamespace is the directory --layout namespace uses for roles whose resources
+// have no namespace to group them by
+const unscopedNamespace = "_unscoped"
+
+// resourceNamespace returns the directory --layout namespace writes a role to: the first
+// segment of its alphabetically first allowed resource, or denied resource if it allows
+// none, e.g. kots for kots/app/*/read. Roles without resources, and roles whose first
+// segment is a wildcard, as in **/*, go to unscopedNamespace.
+func resourceNamespace(role models.Role) string {
+	resources := role.Resources.Allowed
+	if len(resources) == 0 {
+		resources = role.Resources.Denied
+	}
+	if len(resources) == 0 {
+		return unscopedNamespace
+	}
+	first := resources[0]
+	for _, resource := range resources[1:] {
+		if resource < first {
+			first = resource
+		}
+	}
+	segment := strings.SplitN(first, "/", 2)[0]
+	if segment == "" || segment == "." || segment == ".." || strings.ContainsAny(segment, "*?[]\\") {
+		return unscopedNamespace
+	}
+	return segment
+}
+
+// existingRoleFiles maps the name of each role already in a file of its own under dir to
+// that file, so a re-pull finds roles wherever an earlier pull or the user put them. Files
+// holding several roles are left out, as are all files if dir cannot be read.
+func existingRoleFiles(dir string) map[string]string {
+	result, err := roles.LoadRolesFromDirectoryWithDetails(dir)
+	if err != nil {
+		return nil
+	}
+	rolesInFile := make(map[string]int)
+	for _, role := range result.Roles {
+		rolesInFile[role.SourceFile]++
+	}
+	files := make(map[string]string, len(result.Roles))
+	for _, role := range result.Roles {
+		if role.SourceFile != "" && rolesInFile[role.SourceFile] == 1 {
+			files[role.Name] = filepath.Clean(role.SourceFile)
+		}
+	}
+	return files
+}
+
+// filterRolesByName returns the roles whose names match the glob pattern, which must be valid
+func filterRolesByName(roleList []models.Role, pattern string) []models.Role {
+	matched := make([]models.Role, 0, len(roleList))
+	for _, role := range roleList {
+		if ok, _ := path.Match(pattern, role.Name); ok {
+			matched = append(matched, role)
+		}
+	}
+	return matched
+}
+
 // showDiff displays a simple diff between old and new content
 func showDiff(cmd *cobra.Command, oldContent, newContent string) {
 	oldLines := strings.Split(oldContent, "\n")
@@ -269,3 +561,76 @@ func showDiff(cmd *cobra.Command, oldContent, newContent string) {
 		}
 	}
 }
+
+// pullSingleFile writes pulled roles, sorted by name, to filePath as one multi-document
+// YAML file. An existing file is only rewritten with force, keeping its mode, and keeps
+// the extra fields of the roles it already holds.
+func pullSingleFile(cmd *cobra.Command, filePath string, pulled []models.Role, opts roles.WriteOptions, dryRun, diff, force bool) error {
+	pulled = append([]models.Role{}, pulled...)
+	sort.Slice(pulled, func(i, j int) bool { return pulled[i].Name < pulled[j].Name })
+
+	existingContent := ""
+	existingBytes, err := os.ReadFile(filePath) // #nosec G304 -- Reading user-provided output file is expected behavior
+	exists := err == nil
+	if exists {
+		existingContent = string(existingBytes)
+		if existingRoles, readErr := roles.ReadRoleDocuments(filePath); readErr == nil {
+			extra := make(map[string]map[string]interface{}, len(existingRoles))
+			manage := make(map[string]string, len(existingRoles))
+			for _, role := range existingRoles {
+				extra[role.Name] = role.Extra
+				manage[role.Name] = role.Manage
+			}
+			for i := range pulled {
+				pulled[i].Extra = extra[pulled[i].Name]
+				pulled[i].Manage = manage[pulled[i].Name]
+			}
+		}
+	} else if !os.IsNotExist(err) {
+		return fmt.Errorf("failed to check file %s: %w", filePath, err)
+	}
+
+	content, err := roles.GenerateRolesYAML(pulled, opts)
+	if err != nil {
+		return fmt.Errorf("failed to generate YAML: %w", err)
+	}
+
+	if dryRun {
+		switch {
+		case !exists:
+			cmd.Printf("Would create %s with %d role(s)\n", filePath, len(pulled))
+		case existingContent == content:
+			cmd.Printf("Would skip %s (no changes)\n", filePath)
+		default:
+			cmd.Printf("Would update %s with %d role(s)\n", filePath, len(pulled))
+			if diff {
+				showDiff(cmd, existingContent, content)
+			}
+		}
+		cmd.Println("Pull completed (dry-run)")
+		return nil
+	}
+
+	if exists && !force {
+		cmd.Printf("Skipped %s (file already exists)\n", filePath)
+		cmd.Println("Pull completed: use --force to overwrite the file")
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(filePath), 0750); err != nil {
+		return fmt.Errorf("failed to create output directory: %w", err)
+	}
+	if err := os.WriteFile(filePath, []byte(content), 0600); err != nil {
+		return fmt.Errorf("failed to write %s: %w", filePath, err)
+	}
+	cmd.Printf("Pull completed: wrote %d role(s) to %s\n", len(pulled), filePath)
+	return nil
+}
+
+// pullOmitsMembers reports whether pull leaves members out of the files it writes, with
+// --exclude-members or --include-members=false
+func pullOmitsMembers(cmd *cobra.Command) bool {
+	if cmd.Flags().Lookup("include-members") != nil && !getBoolFlag(cmd, "include-members") {
+		return true
+	}
+	return getBoolFlag(cmd, "exclude-members")
+}
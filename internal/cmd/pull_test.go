@@ -4,12 +4,14 @@ import (
 	"bytes"
 	"os"
 	"path/filepath"
+	"reflect"
 	"strings"
 	"testing"
 
 	"github.com/spf13/cobra"
 
 	"replbac/internal/models"
+	"replbac/internal/roles"
 )
 
 // TestPullCommand tests the complete pull command workflow with new flags
@@ -62,6 +64,64 @@ func TestPullCommand(t *testing.T) {
 				}
 			},
 		},
+		{
+			name: "pull role with ID - includes id warning comment",
+			args: []string{},
+			mockAPIRoles: []models.Role{
+				{ID: "role-1", Name: "admin", Resources: models.Resources{Allowed: []string{"*"}, Denied: []string{}}},
+			},
+			expectOutput: []string{"Created admin.yaml"},
+			expectFiles: map[string]string{
+				"admin.yaml": "# WARNING: The 'id' field is managed by the Replicated API and should not be modified manually.\n# Changing the ID will cause sync operations to fail.\n\nid: role-1\nname: admin\nresources:\n    allowed:\n        - '*'\n    denied: []\n",
+			},
+		},
+		{
+			name:  "pull role with ID and --no-id-comment - keeps id without comment",
+			args:  []string{},
+			flags: map[string]string{"no-id-comment": "true"},
+			mockAPIRoles: []models.Role{
+				{ID: "role-1", Name: "admin", Resources: models.Resources{Allowed: []string{"*"}, Denied: []string{}}},
+			},
+			expectOutput: []string{"Created admin.yaml"},
+			expectFiles: map[string]string{
+				"admin.yaml": "id: role-1\nname: admin\nresources:\n    allowed:\n        - '*'\n    denied: []\n",
+			},
+		},
+		{
+			name: "pull role with members - includes members by default",
+			args: []string{},
+			mockAPIRoles: []models.Role{
+				{Name: "admin", Resources: models.Resources{Allowed: []string{"*"}, Denied: []string{}}, Members: []string{"admin@example.com"}},
+			},
+			expectOutput: []string{"Created admin.yaml"},
+			expectFiles: map[string]string{
+				"admin.yaml": "name: admin\nresources:\n    allowed:\n        - '*'\n    denied: []\nmembers:\n    - admin@example.com\n",
+			},
+		},
+		{
+			name:  "pull role with members and --exclude-members - omits members key",
+			args:  []string{},
+			flags: map[string]string{"exclude-members": "true"},
+			mockAPIRoles: []models.Role{
+				{Name: "admin", Resources: models.Resources{Allowed: []string{"*"}, Denied: []string{}}, Members: []string{"admin@example.com"}},
+			},
+			expectOutput: []string{"Created admin.yaml"},
+			expectFiles: map[string]string{
+				"admin.yaml": "name: admin\nresources:\n    allowed:\n        - '*'\n    denied: []\n",
+			},
+		},
+		{
+			name:  "pull role with members and --include-members=false - omits members key",
+			args:  []string{},
+			flags: map[string]string{"include-members": "false"},
+			mockAPIRoles: []models.Role{
+				{Name: "admin", Resources: models.Resources{Allowed: []string{"*"}, Denied: []string{}}, Members: []string{"admin@example.com"}},
+			},
+			expectOutput: []string{"Created admin.yaml"},
+			expectFiles: map[string]string{
+				"admin.yaml": "name: admin\nresources:\n    allowed:\n        - '*'\n    denied: []\n",
+			},
+		},
 		{
 			name: "pull with custom directory argument",
 			args: []string{"custom-dir"},
@@ -144,6 +204,21 @@ func TestPullCommand(t *testing.T) {
 				"admin.yaml": "name: admin\nresources:\n    allowed:\n        - '*'\n    denied: []\n",
 			},
 		},
+		{
+			name:  "pull with force - keeps unrecognized fields of existing file",
+			args:  []string{},
+			flags: map[string]string{"force": "true"},
+			mockAPIRoles: []models.Role{
+				{Name: "admin", Resources: models.Resources{Allowed: []string{"*"}, Denied: []string{}}},
+			},
+			existingFiles: map[string]string{
+				"admin.yaml": "name: admin\nowner: platform-team\nresources:\n  allowed: [\"old\"]\n",
+			},
+			expectOutput: []string{"Overwrote admin.yaml"},
+			expectFiles: map[string]string{
+				"admin.yaml": "name: admin\nresources:\n    allowed:\n        - '*'\n    denied: []\nowner: platform-team\n",
+			},
+		},
 		{
 			name:  "pull with dry-run flag - shows what would be done",
 			args:  []string{},
@@ -191,6 +266,96 @@ func TestPullCommand(t *testing.T) {
 				"admin.yaml": "name: admin\nresources:\n  allowed: [\"read\"]\n  denied: []\n",
 			},
 		},
+		{
+			name:  "pull named roles - writes only those files",
+			args:  []string{},
+			flags: map[string]string{"roles-from-api": "viewer,editor"},
+			mockAPIRoles: []models.Role{
+				{Name: "admin", Resources: models.Resources{Allowed: []string{"*"}, Denied: []string{}}},
+				{Name: "editor", Resources: models.Resources{Allowed: []string{"write"}, Denied: []string{}}},
+				{Name: "viewer", Resources: models.Resources{Allowed: []string{"read"}, Denied: []string{}}},
+			},
+			expectOutput: []string{"Downloaded 2 role(s) from API", "Created viewer.yaml", "Created editor.yaml"},
+			expectFiles: map[string]string{
+				"viewer.yaml": "name: viewer\nresources:\n    allowed:\n        - read\n    denied: []\n",
+				"editor.yaml": "name: editor\nresources:\n    allowed:\n        - write\n    denied: []\n",
+			},
+			expectNoFiles: []string{"admin.yaml"},
+			validateAPICallsFunc: func(t *testing.T, calls *MockAPICalls) {
+				if calls.GetCalls != 0 {
+					t.Errorf("Expected no GetRoles calls when pulling named roles, got %d", calls.GetCalls)
+				}
+			},
+		},
+		{
+			name:  "pull named roles with force - overwrites existing file",
+			args:  []string{},
+			flags: map[string]string{"roles-from-api": "admin", "force": "true"},
+			mockAPIRoles: []models.Role{
+				{Name: "admin", Resources: models.Resources{Allowed: []string{"*"}, Denied: []string{}}},
+			},
+			existingFiles: map[string]string{"admin.yaml": "name: admin\n"},
+			expectOutput:  []string{"Overwrote admin.yaml"},
+			expectFiles: map[string]string{
+				"admin.yaml": "name: admin\nresources:\n    allowed:\n        - '*'\n    denied: []\n",
+			},
+		},
+		{
+			name:  "pull named role that does not exist - fails without writing",
+			args:  []string{},
+			flags: map[string]string{"roles-from-api": "admin,ghost"},
+			mockAPIRoles: []models.Role{
+				{Name: "admin", Resources: models.Resources{Allowed: []string{"*"}, Denied: []string{}}},
+			},
+			expectError:   true,
+			expectOutput:  []string{"role not found: ghost"},
+			expectNoFiles: []string{"admin.yaml", "ghost.yaml"},
+		},
+		{
+			name:  "pull with filter - writes only matching roles and counts them",
+			args:  []string{},
+			flags: map[string]string{"filter": "payments-*"},
+			mockAPIRoles: []models.Role{
+				{Name: "admin", Resources: models.Resources{Allowed: []string{"*"}, Denied: []string{}}},
+				{Name: "payments-viewer", Resources: models.Resources{Allowed: []string{"read"}, Denied: []string{}}},
+				{Name: "payments-admin", Resources: models.Resources{Allowed: []string{"*"}, Denied: []string{}}},
+			},
+			existingFiles: map[string]string{"payments-admin.yaml": "name: payments-admin\n"},
+			expectOutput: []string{
+				"Downloaded 3 role(s) from API",
+				"2 role(s) match --filter payments-*",
+				"Created",
+				"Skipped payments-admin.yaml (file already exists)",
+				"Pull completed: 1 created, 1 skipped",
+			},
+			expectFiles: map[string]string{
+				"payments-viewer.yaml": "name: payments-viewer\nresources:\n    allowed:\n        - read\n    denied: []\n",
+				"payments-admin.yaml":  "name: payments-admin\n",
+			},
+			expectNoFiles: []string{"admin.yaml"},
+		},
+		{
+			name:  "pull with filter matching nothing - no files created",
+			args:  []string{},
+			flags: map[string]string{"filter": "billing-*"},
+			mockAPIRoles: []models.Role{
+				{Name: "admin", Resources: models.Resources{Allowed: []string{"*"}, Denied: []string{}}},
+				{Name: "viewer", Resources: models.Resources{Allowed: []string{"read"}, Denied: []string{}}},
+			},
+			expectOutput: []string{
+				"No roles match --filter billing-*",
+				"Pull completed: no files created",
+			},
+			expectNoFiles: []string{"admin.yaml", "viewer.yaml"},
+		},
+		{
+			name:          "pull with invalid filter - fails without writing",
+			args:          []string{},
+			flags:         map[string]string{"filter": "payments-["},
+			mockAPIRoles:  []models.Role{{Name: "admin", Resources: models.Resources{Allowed: []string{"*"}, Denied: []string{}}}},
+			expectError:   true,
+			expectNoFiles: []string{"admin.yaml"},
+		},
 		{
 			name:         "pull empty API - no files created",
 			args:         []string{},
@@ -357,9 +522,242 @@ func NewPullCommand(mockClient *MockClient) *cobra.Command {
 	cmd.Flags().Bool("dry-run", false, "preview changes without applying them")
 	cmd.Flags().Bool("diff", false, "preview changes with detailed diffs (implies --dry-run)")
 	cmd.Flags().Bool("force", false, "overwrite existing files")
+	cmd.Flags().Bool("no-id-comment", false, "omit the id warning comment")
+	cmd.Flags().Bool("include-members", true, "write members to generated files")
+	cmd.Flags().Bool("exclude-members", false, "omit members from generated files")
+	cmd.Flags().Bool("sort", false, "sort lists in generated files")
+	cmd.Flags().StringSlice("roles-from-api", nil, "pull only these roles, by name")
+	cmd.Flags().String("filter", "", "pull only roles whose names match this glob pattern")
+	cmd.Flags().String("layout", "flat", "arrange role files as flat or namespace")
 	cmd.Flags().Bool("verbose", false, "enable verbose logging")
 
 	return cmd
 }
 
 // This function is now implemented in pull.go and uses api.ClientInterface
+
+// TestPullLayoutNamespace tests that --layout namespace writes each role under its first
+// resource segment, and that sync then finds every role in the grouped directories
+func TestPullLayoutNamespace(t *testing.T) {
+	remote := []models.Role{
+		{ID: "1", Name: "release-manager", Resources: models.Resources{Allowed: []string{"kots/app/*/release/**", "kots/app/*/channel/**"}, Denied: []string{}}},
+		{ID: "2", Name: "team-viewer", Resources: models.Resources{Allowed: []string{"team/members/read"}, Denied: []string{}}},
+		{ID: "3", Name: "deny-billing", Resources: models.Resources{Allowed: []string{}, Denied: []string{"billing/**"}}},
+		{ID: "4", Name: "admin", Resources: models.Resources{Allowed: []string{"**/*"}, Denied: []string{}}},
+	}
+	outputDir := t.TempDir()
+
+	cmd := NewPullCommand(NewMockClient(&MockAPICalls{}, remote))
+	cmd.SetOut(&bytes.Buffer{})
+	cmd.SetErr(&bytes.Buffer{})
+	cmd.SetArgs([]string{outputDir, "--layout", "namespace"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	for _, file := range []string{"kots/release-manager.yaml", "team/team-viewer.yaml", "billing/deny-billing.yaml", "_unscoped/admin.yaml"} {
+		if _, err := os.Stat(filepath.Join(outputDir, file)); err != nil {
+			t.Errorf("Expected %s to be written: %v", file, err)
+		}
+	}
+	if entries, err := filepath.Glob(filepath.Join(outputDir, "*.yaml")); err != nil || len(entries) != 0 {
+		t.Errorf("Expected no role files at the top level, got %v (%v)", entries, err)
+	}
+
+	syncCmd := NewSyncCommandWithOptions(NewMockClient(&MockAPICalls{}, remote), nil)
+	var stdout bytes.Buffer
+	syncCmd.SetOut(&stdout)
+	syncCmd.SetErr(&bytes.Buffer{})
+	syncCmd.SetArgs([]string{outputDir, "--dry-run", "--delete"})
+	if err := syncCmd.Execute(); err != nil {
+		t.Fatalf("Unexpected sync error: %v", err)
+	}
+	if !strings.Contains(stdout.String(), "No changes needed") {
+		t.Errorf("Expected sync to find every pulled role, got:\n%s", stdout.String())
+	}
+}
+
+// TestPullLayoutRepull tests that re-pulling a role whose namespace changed moves its file,
+// leaving one file per role, and that a flat re-pull updates files where they are
+func TestPullLayoutRepull(t *testing.T) {
+	outputDir := t.TempDir()
+	pull := func(remote []models.Role, args ...string) string {
+		t.Helper()
+		cmd := NewPullCommand(NewMockClient(&MockAPICalls{}, remote))
+		var stdout bytes.Buffer
+		cmd.SetOut(&stdout)
+		cmd.SetErr(&bytes.Buffer{})
+		cmd.SetArgs(append([]string{outputDir}, args...))
+		if err := cmd.Execute(); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		return stdout.String()
+	}
+	roleFiles := func() []string {
+		t.Helper()
+		var files []string
+		err := filepath.WalkDir(outputDir, func(path string, entry os.DirEntry, err error) error {
+			if err == nil && !entry.IsDir() {
+				relative, _ := filepath.Rel(outputDir, path)
+				files = append(files, filepath.ToSlash(relative))
+			}
+			return err
+		})
+		if err != nil {
+			t.Fatalf("Failed to list role files: %v", err)
+		}
+		return files
+	}
+
+	viewer := models.Role{ID: "1", Name: "viewer", Resources: models.Resources{Allowed: []string{"kots/app/*/read"}, Denied: []string{}}}
+	pull([]models.Role{viewer}, "--layout", "namespace")
+
+	viewer.Resources.Allowed = []string{"team/members/read"}
+	if output := pull([]models.Role{viewer}, "--layout", "namespace", "--dry-run"); !strings.Contains(output, "Would move") {
+		t.Errorf("Expected the dry run to report the move, got:\n%s", output)
+	}
+	if files := roleFiles(); !reflect.DeepEqual(files, []string{"kots/viewer.yaml"}) {
+		t.Errorf("Expected the dry run to leave the files alone, got %v", files)
+	}
+
+	pull([]models.Role{viewer}, "--layout", "namespace", "--force")
+	if files := roleFiles(); !reflect.DeepEqual(files, []string{"team/viewer.yaml"}) {
+		t.Errorf("Expected the role to move to its new namespace, got %v", files)
+	}
+
+	viewer.Resources.Allowed = append(viewer.Resources.Allowed, "team/settings/read")
+	pull([]models.Role{viewer}, "--force")
+	if files := roleFiles(); !reflect.DeepEqual(files, []string{"team/viewer.yaml"}) {
+		t.Errorf("Expected a flat re-pull to update the file in place, got %v", files)
+	}
+	if role, err := roles.ReadRoleFile(filepath.Join(outputDir, "team", "viewer.yaml")); err != nil || len(role.Resources.Allowed) != 2 {
+		t.Errorf("Expected the file to be updated, got %+v (%v)", role, err)
+	}
+}
+
+// TestPullLayoutInvalid tests that an unknown --layout fails before anything is written
+func TestPullLayoutInvalid(t *testing.T) {
+	outputDir := t.TempDir()
+	cmd := NewPullCommand(NewMockClient(&MockAPICalls{}, []models.Role{{Name: "admin", Resources: models.Resources{Allowed: []string{"*"}}}}))
+	cmd.SetOut(&bytes.Buffer{})
+	cmd.SetErr(&bytes.Buffer{})
+	cmd.SetArgs([]string{outputDir, "--layout", "nested"})
+	err := cmd.Execute()
+	if err == nil || !strings.Contains(err.Error(), `invalid --layout "nested": must be flat or namespace`) {
+		t.Fatalf("Expected invalid layout error, got %v", err)
+	}
+	if entries, _ := os.ReadDir(outputDir); len(entries) != 0 {
+		t.Errorf("Expected nothing written, got %v", entries)
+	}
+}
+
+// TestPullSinceFile tests that --since-file writes only roles changed since the last pull and notes deletions
+func TestPullSinceFile(t *testing.T) {
+	outputDir := t.TempDir()
+	indexPath := filepath.Join(outputDir, ".last-export")
+
+	run := func(remote []models.Role, flags ...string) string {
+		t.Helper()
+		cmd := NewPullCommand(NewMockClient(&MockAPICalls{}, remote))
+		cmd.Flags().String("since-file", "", "index file of the last export")
+		var stdout bytes.Buffer
+		cmd.SetOut(&stdout)
+		cmd.SetErr(&bytes.Buffer{})
+		cmd.SetArgs(append([]string{outputDir, "--since-file", indexPath}, flags...))
+		if err := cmd.Execute(); err != nil {
+			t.Fatalf("pull failed: %v\n%s", err, stdout.String())
+		}
+		return stdout.String()
+	}
+
+	admin := models.Role{Name: "admin", Resources: models.Resources{Allowed: []string{"*"}, Denied: []string{}}}
+	viewer := models.Role{Name: "viewer", Resources: models.Resources{Allowed: []string{"read"}, Denied: []string{}}}
+
+	output := run([]models.Role{admin, viewer})
+	if !strings.Contains(output, "Created") || strings.Contains(output, "unchanged") {
+		t.Errorf("Expected the first pull to write every role, got:\n%s", output)
+	}
+
+	// An edit to a file is kept when its role has not changed remotely
+	adminPath := filepath.Join(outputDir, "admin.yaml")
+	// #nosec G306 -- Test files need readable permissions
+	if err := os.WriteFile(adminPath, []byte("# edited\nname: admin\n"), 0644); err != nil {
+		t.Fatalf("Failed to edit admin.yaml: %v", err)
+	}
+	viewer.Resources.Allowed = []string{"read", "list"}
+
+	output = run([]models.Role{admin, viewer}, "--dry-run")
+	if !strings.Contains(output, "Would update") {
+		t.Errorf("Expected a dry run to preview the changed role, got:\n%s", output)
+	}
+
+	output = run([]models.Role{viewer})
+	for _, expected := range []string{
+		"Overwrote " + filepath.Join(outputDir, "viewer.yaml"),
+		"Role admin was deleted remotely since the last export",
+	} {
+		if !strings.Contains(output, expected) {
+			t.Errorf("Expected output to contain %q, got:\n%s", expected, output)
+		}
+	}
+	// #nosec G304 -- Reading test file path is expected behavior in tests
+	if content, err := os.ReadFile(adminPath); err != nil || string(content) != "# edited\nname: admin\n" {
+		t.Errorf("Expected admin.yaml to be left alone, got %q (%v)", content, err)
+	}
+	// #nosec G304 -- Reading test file path is expected behavior in tests
+	if index, err := os.ReadFile(indexPath); err != nil || !strings.Contains(string(index), `"admin"`) || !strings.Contains(string(index), `"deleted"`) {
+		t.Errorf("Expected the index to note admin as deleted, got %s (%v)", index, err)
+	}
+
+	output = run([]models.Role{viewer})
+	if !strings.Contains(output, "Pull completed: no roles changed since the last export") {
+		t.Errorf("Expected nothing to be written, got:\n%s", output)
+	}
+}
+
+func TestPullSingle(t *testing.T) {
+	rolesDir := t.TempDir()
+	singlePath := filepath.Join(rolesDir, "roles.yaml")
+	remote := []models.Role{
+		{ID: "id-viewer", Name: "viewer", Resources: models.Resources{Allowed: []string{"kots/app/*/read"}, Denied: []string{}}},
+		{ID: "id-admin", Name: "admin", Resources: models.Resources{Allowed: []string{"**/*"}, Denied: []string{"kots/app/*/delete"}}},
+	}
+	mockClient := NewMockClient(&MockAPICalls{}, remote)
+
+	cmd := NewPullCommand(mockClient)
+	cmd.Flags().String("single", "", "write all roles to one file")
+	var stdout bytes.Buffer
+	cmd.SetOut(&stdout)
+	cmd.SetErr(&bytes.Buffer{})
+	cmd.SetArgs([]string{"--single", singlePath})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("pull failed: %v\n%s", err, stdout.String())
+	}
+	if !strings.Contains(stdout.String(), "wrote 2 role(s) to "+singlePath) {
+		t.Errorf("Expected both roles to be written to one file, got:\n%s", stdout.String())
+	}
+
+	// #nosec G304 -- Reading test file path is expected behavior in tests
+	content, err := os.ReadFile(singlePath)
+	if err != nil {
+		t.Fatalf("Failed to read %s: %v", singlePath, err)
+	}
+	if count := strings.Count(string(content), "# WARNING: The 'id' field"); count != 2 {
+		t.Errorf("Expected an id comment on each of 2 documents, got %d:\n%s", count, content)
+	}
+	if strings.Index(string(content), "name: admin") > strings.Index(string(content), "name: viewer") {
+		t.Errorf("Expected documents sorted by role name, got:\n%s", content)
+	}
+
+	syncCmd := NewSyncCommand(mockClient)
+	stdout.Reset()
+	syncCmd.SetOut(&stdout)
+	syncCmd.SetErr(&bytes.Buffer{})
+	syncCmd.SetArgs([]string{rolesDir})
+	if err := syncCmd.Execute(); err != nil {
+		t.Fatalf("sync failed: %v\n%s", err, stdout.String())
+	}
+	if !strings.Contains(stdout.String(), "No changes needed") {
+		t.Errorf("Expected syncing the pulled file to need no changes, got:\n%s", stdout.String())
+	}
+}
@@ -0,0 +1,197 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/spf13/cobra"
+
+	"replbac/internal/api"
+	"replbac/internal/logging"
+	"replbac/internal/models"
+	"replbac/internal/sync"
+)
+
+var (
+	purgeDryRun  bool
+	purgeMembers bool
+	purgeConfirm int
+	purgeVerbose bool
+	purgeDebug   bool
+)
+
+// purgeCmd represents the purge command
+var purgeCmd = &cobra.Command{
+	Use:   "purge",
+	Short: "Delete every remote role, for tearing down an environment",
+	Long: `Purge deletes every role in the Replicated team, for decommissioning an
+environment. Unlike sync --delete, it does not read any role files.
+
+Roles matching protected_roles in the configuration are kept, along with
+their members. With --prune-members, every other team member is also
+removed and every other pending invitation cancelled.
+
+Always run with --dry-run first. It lists everything that would be removed
+and the count to pass to --confirm-purge. The real run refuses to start
+unless --confirm-purge matches the number of roles, members, and
+invitations it would remove, so a purge cannot be run by accident or against
+a team that differs from the one previewed. --confirm and REPLBAC_CONFIRM do
+not skip this check.
+
+Environment Variables:
+  This command supports all global environment variables.
+  See 'replbac --help' for full environment variable documentation.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return RunPurgeCommand(cmd, cfg, purgeDryRun)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(purgeCmd)
+
+	purgeCmd.Flags().BoolVar(&purgeDryRun, "dry-run", false, "list everything that would be removed without removing it")
+	purgeCmd.Flags().BoolVar(&purgeMembers, "prune-members", false, "also remove every team member and cancel every pending invitation, except those of protected roles")
+	purgeCmd.Flags().IntVar(&purgeConfirm, "confirm-purge", 0, "the number of removals shown by --dry-run, required to run the purge")
+	purgeCmd.Flags().BoolVar(&purgeVerbose, "verbose", false, "enable info-level logging to stderr (progress and results)")
+	purgeCmd.Flags().BoolVar(&purgeDebug, "debug", false, "enable debug-level logging to stderr (detailed operation info)")
+}
+
+// RunPurgeCommand creates an API client and purges the team's roles
+func RunPurgeCommand(cmd *cobra.Command, config models.Config, dryRun bool) error {
+	if cmd.OutOrStdout() == os.Stderr {
+		cmd.SetOut(os.Stdout)
+	}
+
+	var logger *logging.Logger
+	if purgeDebug {
+		logger = logging.NewDebugLogger(cmd.ErrOrStderr())
+	} else {
+		logger = logging.NewLogger(cmd.ErrOrStderr(), purgeVerbose)
+	}
+	if getBoolFlag(cmd, "debug-http") {
+		logger.EnableHTTPTrace()
+	}
+	teeLogFile(logger)
+
+	client, err := newAPIClient(config, logger, api.DefaultMaxRetries)
+	if err != nil {
+		logger.Error("failed to create API client: %v", err)
+		return fmt.Errorf("failed to create API client: %w", err)
+	}
+
+	return RunPurgeCommandWithClient(cmd, restrictClient(client, config), config, dryRun, logger)
+}
+
+// RunPurgeCommandWithClient deletes every unprotected remote role using client, and with
+// --prune-members every team member and invitation outside protected roles, once --confirm-purge matches the
+// number of removals
+func RunPurgeCommandWithClient(cmd *cobra.Command, client api.ClientInterface, config models.Config, dryRun bool, logger *logging.Logger) error {
+	// Members are read separately with --prune-members, so roles are listed without them
+	remoteRoles, err := getRemoteRoles(client, false)
+	if err != nil {
+		return fmt.Errorf("failed to get remote roles: %w", err)
+	}
+
+	opts := sync.CompareOptions{Protected: config.ProtectedRoles}
+	var doomed, kept []string
+	keptRoleIDs := make(map[string]string) // ID -> name of each kept role
+	for _, role := range remoteRoles {
+		if opts.Protects(role.Name) {
+			kept = append(kept, role.Name)
+			keptRoleIDs[role.ID] = role.Name
+		} else {
+			doomed = append(doomed, role.Name)
+		}
+	}
+	sort.Strings(doomed)
+	sort.Strings(kept)
+
+	deletions := &sync.MemberDeletions{}
+	var keptMembers []string
+	if getBoolFlag(cmd, "prune-members") {
+		members, err := client.GetTeamMembers()
+		if err != nil {
+			return fmt.Errorf("failed to get team members: %w", err)
+		}
+		for _, member := range members {
+			// Members of kept roles stay with them
+			if roleName, ok := keptRoleIDs[member.PolicyID]; ok && member.PolicyID != "" {
+				keptMembers = append(keptMembers, fmt.Sprintf("member %s of protected role %s not removed", member.Email, roleName))
+				continue
+			}
+			if member.IsPendingInvite() {
+				deletions.OrphanedInvites = append(deletions.OrphanedInvites, member.Email)
+			} else {
+				deletions.OrphanedUsers = append(deletions.OrphanedUsers, member.Email)
+			}
+		}
+		sort.Strings(deletions.OrphanedUsers)
+		sort.Strings(deletions.OrphanedInvites)
+	}
+
+	total := len(doomed) + len(deletions.OrphanedUsers) + len(deletions.OrphanedInvites)
+	if total == 0 {
+		cmd.Println("Nothing to purge")
+		return nil
+	}
+
+	if len(doomed) > 0 {
+		cmd.Printf("Roles to delete (%d):\n", len(doomed))
+		for _, name := range doomed {
+			cmd.Printf("  - %s\n", name)
+		}
+	}
+	if len(deletions.OrphanedUsers) > 0 {
+		cmd.Printf("Team members to remove (%d):\n", len(deletions.OrphanedUsers))
+		for _, email := range deletions.OrphanedUsers {
+			cmd.Printf("  - %s\n", email)
+		}
+	}
+	if len(deletions.OrphanedInvites) > 0 {
+		cmd.Printf("Invitations to cancel (%d):\n", len(deletions.OrphanedInvites))
+		for _, email := range deletions.OrphanedInvites {
+			cmd.Printf("  - %s\n", email)
+		}
+	}
+	for _, name := range kept {
+		cmd.Printf("protected role %s not deleted\n", name)
+	}
+	sort.Strings(keptMembers)
+	for _, message := range keptMembers {
+		cmd.Println(message)
+	}
+
+	if dryRun {
+		cmd.Printf("\nDRY RUN: nothing was removed. To purge, run again with --confirm-purge %d\n", total)
+		return nil
+	}
+
+	if confirmed := getIntFlag(cmd, "confirm-purge"); confirmed != total {
+		return fmt.Errorf("refusing to purge: --confirm-purge must be %d, the number of removals listed above (got %d); preview them with --dry-run", total, confirmed)
+	}
+
+	// Remove members first, so no role is deleted while members still hold it
+	if len(deletions.OrphanedUsers) > 0 || len(deletions.OrphanedInvites) > 0 {
+		memberClient, ok := client.(sync.APIClientWithMembers)
+		if !ok {
+			return fmt.Errorf("client does not support member operations")
+		}
+		executor := sync.NewExecutorWithMembersAndInvite(memberClient, logger, true)
+		if err := executor.DeleteMembersAndInvites(deletions); err != nil {
+			return fmt.Errorf("failed to delete members and invites: %w", err)
+		}
+		cmd.Printf("Removed %d member(s) and cancelled %d invitation(s)\n", len(deletions.OrphanedUsers), len(deletions.OrphanedInvites))
+	}
+
+	for _, name := range doomed {
+		if err := client.DeleteRole(name); err != nil {
+			return fmt.Errorf("failed to delete role '%s': %w", name, err)
+		}
+		cmd.Printf("Deleted role %s\n", name)
+	}
+
+	cmd.Printf("\nPurge completed: deleted %d role(s)\n", len(doomed))
+	return nil
+}
@@ -0,0 +1,159 @@
+package cmd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+
+	"replbac/internal/logging"
+	"replbac/internal/models"
+)
+
+// teamRecordingClient serves team members and records member removals and cancelled invites
+type teamRecordingClient struct {
+	*MockClient
+	members   []models.TeamMember
+	removed   []string
+	cancelled []string
+}
+
+// GetTeamMembers returns the configured team members
+func (c *teamRecordingClient) GetTeamMembers() ([]models.TeamMember, error) {
+	return c.members, nil
+}
+
+// AssignMemberRole records members removed by assigning them no role
+func (c *teamRecordingClient) AssignMemberRole(memberEmail, roleID string) error {
+	if roleID == "" {
+		c.removed = append(c.removed, memberEmail)
+	}
+	return nil
+}
+
+// DeleteInvite records cancelled invitations
+func (c *teamRecordingClient) DeleteInvite(email string) error {
+	c.cancelled = append(c.cancelled, email)
+	return nil
+}
+
+// NewPurgeCommand creates a purge command that uses the given client and configuration
+func NewPurgeCommand(client *teamRecordingClient, config models.Config) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:  "purge",
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			dryRun, _ := cmd.Flags().GetBool("dry-run")
+			logger := logging.NewLogger(cmd.ErrOrStderr(), false)
+			return RunPurgeCommandWithClient(cmd, client, config, dryRun, logger)
+		},
+	}
+
+	cmd.Flags().Bool("dry-run", false, "list everything that would be removed")
+	cmd.Flags().Bool("prune-members", false, "also remove members and invitations")
+	cmd.Flags().Int("confirm-purge", 0, "the number of removals")
+
+	return cmd
+}
+
+func TestPurgeCommand(t *testing.T) {
+	tests := []struct {
+		name            string
+		args            []string
+		expectError     string
+		expectDeletes   []string
+		expectRemoved   []string
+		expectCancelled []string
+		expectOutput    []string
+	}{
+		{
+			name:         "dry run lists roles and the confirmation count",
+			args:         []string{"--dry-run"},
+			expectOutput: []string{"Roles to delete (2):", "  - editor", "  - viewer", "protected role platform-admin not deleted", "--confirm-purge 2"},
+		},
+		{
+			name: "dry run with --prune-members lists members and invitations",
+			args: []string{"--dry-run", "--prune-members"},
+			expectOutput: []string{
+				"Team members to remove (1):", "  - a@example.com", "Invitations to cancel (1):", "  - b@example.com", "--confirm-purge 4",
+				"member c@example.com of protected role platform-admin not removed",
+				"member d@example.com of protected role platform-admin not removed",
+			},
+		},
+		{
+			name:        "refuses without confirmation",
+			expectError: "--confirm-purge must be 2",
+		},
+		{
+			name:        "refuses with a mismatched count",
+			args:        []string{"--prune-members", "--confirm-purge", "2"},
+			expectError: "--confirm-purge must be 4",
+		},
+		{
+			name:          "deletes unprotected roles when confirmed",
+			args:          []string{"--confirm-purge", "2"},
+			expectDeletes: []string{"editor", "viewer"},
+			expectOutput:  []string{"Deleted role editor", "Purge completed: deleted 2 role(s)"},
+		},
+		{
+			name:            "removes members and invitations when confirmed",
+			args:            []string{"--prune-members", "--confirm-purge", "4"},
+			expectDeletes:   []string{"editor", "viewer"},
+			expectRemoved:   []string{"a@example.com"},
+			expectCancelled: []string{"b@example.com"},
+			expectOutput:    []string{"Removed 1 member(s) and cancelled 1 invitation(s)"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			remoteRoles := []models.Role{
+				{ID: "viewer-id", Name: "viewer", Resources: models.Resources{Allowed: []string{"**/read"}}},
+				{ID: "admin-id", Name: "platform-admin", Resources: models.Resources{Allowed: []string{"*"}}},
+				{ID: "editor-id", Name: "editor", Resources: models.Resources{Allowed: []string{"*"}}},
+			}
+			mockCalls := &MockAPICalls{}
+			client := &teamRecordingClient{
+				MockClient: NewMockClient(mockCalls, remoteRoles),
+				members: []models.TeamMember{
+					{ID: "1", Email: "a@example.com", Status: "active"},
+					{Email: "b@example.com", Status: "pending"},
+					{ID: "3", Email: "c@example.com", Status: "active", PolicyID: "admin-id"},
+					{Email: "d@example.com", Status: "pending", PolicyID: "admin-id"},
+				},
+			}
+			config := models.Config{APIToken: "test-token", Confirm: true, ProtectedRoles: []string{"platform-*"}}
+
+			cmd := NewPurgeCommand(client, config)
+			var stdout bytes.Buffer
+			cmd.SetOut(&stdout)
+			cmd.SetErr(&stdout)
+			cmd.SetArgs(tt.args)
+
+			err := cmd.Execute()
+			if tt.expectError != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.expectError) {
+					t.Fatalf("Expected error containing %q, got %v", tt.expectError, err)
+				}
+			} else if err != nil {
+				t.Fatalf("Expected no error but got: %v", err)
+			}
+
+			if strings.Join(mockCalls.DeleteCalls, ",") != strings.Join(tt.expectDeletes, ",") {
+				t.Errorf("Expected delete calls %v, got %v", tt.expectDeletes, mockCalls.DeleteCalls)
+			}
+			if strings.Join(client.removed, ",") != strings.Join(tt.expectRemoved, ",") {
+				t.Errorf("Expected removed members %v, got %v", tt.expectRemoved, client.removed)
+			}
+			if strings.Join(client.cancelled, ",") != strings.Join(tt.expectCancelled, ",") {
+				t.Errorf("Expected cancelled invitations %v, got %v", tt.expectCancelled, client.cancelled)
+			}
+			for _, expected := range tt.expectOutput {
+				if !strings.Contains(stdout.String(), expected) {
+					t.Errorf("Expected output to contain %q, got:\n%s", expected, stdout.String())
+				}
+			}
+		})
+	}
+}
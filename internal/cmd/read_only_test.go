@@ -0,0 +1,52 @@
+package cmd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"replbac/internal/models"
+)
+
+// TestReadOnlySync tests that a read-only configuration blocks writes even without --dry-run
+func TestReadOnlySync(t *testing.T) {
+	tests := []struct {
+		name          string
+		readOnly      bool
+		expectError   string
+		expectCreates int
+	}{
+		{name: "writes allowed by default", expectCreates: 1},
+		{name: "read-only blocks writes", readOnly: true, expectError: "cannot create role admin: writes to the Replicated API are blocked in read-only mode"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tempDir := t.TempDir()
+			if err := createTestRoleFile(tempDir, models.Role{Name: "admin", Resources: models.Resources{Allowed: []string{"*"}, Denied: []string{}}}); err != nil {
+				t.Fatalf("Failed to create role file: %v", err)
+			}
+
+			mockCalls := &MockAPICalls{}
+			client := restrictClient(NewMockClient(mockCalls, []models.Role{}), models.Config{ReadOnly: tt.readOnly})
+			cmd := NewSyncCommandWithOptions(client, nil)
+
+			var stdout, stderr bytes.Buffer
+			cmd.SetOut(&stdout)
+			cmd.SetErr(&stderr)
+			cmd.SetArgs([]string{tempDir})
+
+			err := cmd.Execute()
+			if tt.expectError != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.expectError) {
+					t.Fatalf("Expected error containing %q, got %v\nOutput:\n%s", tt.expectError, err, stdout.String())
+				}
+			} else if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if len(mockCalls.CreateCalls) != tt.expectCreates {
+				t.Errorf("Expected %d create calls, got %d", tt.expectCreates, len(mockCalls.CreateCalls))
+			}
+		})
+	}
+}
@@ -0,0 +1,104 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+
+	"replbac/internal/roles"
+)
+
+var (
+	renderOutput string
+	renderForce  bool
+	renderDryRun bool
+)
+
+// renderCmd represents the render command
+var renderCmd = &cobra.Command{
+	Use:   "render <templates-directory> <values-file>",
+	Short: "Render role templates into role files",
+	Long: `Render generates concrete role files from role templates and a values file,
+so that many near-identical roles can be kept as a few templates plus data.
+
+Templates are role files using Go template syntax, e.g. "name: {{.App}}-admin".
+Every template is rendered once per value set. The values file is either a
+list of value sets:
+
+  - App: billing
+  - App: shipping
+
+or a mapping whose list values are expanded into every combination:
+
+  App: [billing, shipping]
+  Env: [prod, staging]
+
+Rendered roles are written to the output directory as <name>.yaml, ready for
+sync. Existing files are preserved unless --force is used.`,
+	Args: cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return RunRenderCommand(cmd, args[0], args[1], renderOutput, renderForce, renderDryRun)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(renderCmd)
+
+	// Render-specific flags
+	renderCmd.Flags().StringVarP(&renderOutput, "output", "o", ".", "directory to write rendered role files to")
+	renderCmd.Flags().BoolVar(&renderForce, "force", false, "overwrite existing files")
+	renderCmd.Flags().BoolVar(&renderDryRun, "dry-run", false, "preview the files that would be written without writing them")
+}
+
+// RunRenderCommand renders the templates in templateDir with the value sets in valuesFile
+// and writes the resulting role files to outputDir
+func RunRenderCommand(cmd *cobra.Command, templateDir, valuesFile, outputDir string, force, dryRun bool) error {
+	valueSets, err := roles.LoadValueSets(valuesFile)
+	if err != nil {
+		return err
+	}
+
+	rendered, err := roles.RenderTemplates(templateDir, valueSets)
+	if err != nil {
+		return err
+	}
+
+	if dryRun {
+		cmd.Printf("DRY-RUN: Showing what would be done\n")
+	} else if err := os.MkdirAll(outputDir, 0750); err != nil {
+		return fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	written, skipped := 0, 0
+	for _, role := range rendered {
+		fileName := fmt.Sprintf("%s.yaml", role.Name)
+		filePath := filepath.Join(outputDir, fileName)
+
+		if _, err := os.Stat(filePath); err == nil && !force {
+			cmd.Printf("Skipped %s (file already exists)\n", fileName)
+			skipped++
+			continue
+		} else if err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to check file %s: %w", fileName, err)
+		}
+
+		if dryRun {
+			cmd.Printf("Would write %s\n", fileName)
+		} else {
+			if err := roles.WriteRoleFile(role, filePath); err != nil {
+				return fmt.Errorf("failed to write role file %s: %w", fileName, err)
+			}
+			cmd.Printf("Wrote %s\n", fileName)
+		}
+		written++
+	}
+
+	if dryRun {
+		cmd.Printf("Would render %d role(s) to %s (%d skipped)\n", written, outputDir, skipped)
+	} else {
+		cmd.Printf("Rendered %d role(s) to %s (%d skipped)\n", written, outputDir, skipped)
+	}
+	return nil
+}
@@ -0,0 +1,106 @@
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+
+	"replbac/internal/roles"
+)
+
+func TestRunRenderCommand(t *testing.T) {
+	templateDir := t.TempDir()
+	template := "name: \"{{.App}}-admin\"\nresources:\n  allowed: [\"kots/app/{{.App}}/**\"]\n  denied: []\n"
+	if err := os.WriteFile(filepath.Join(templateDir, "admin.yaml"), []byte(template), 0600); err != nil {
+		t.Fatalf("Failed to write template: %v", err)
+	}
+	valuesFile := filepath.Join(t.TempDir(), "values.yaml")
+	if err := os.WriteFile(valuesFile, []byte("App: [billing, shipping]\n"), 0600); err != nil {
+		t.Fatalf("Failed to write values file: %v", err)
+	}
+
+	tests := []struct {
+		name           string
+		existing       bool
+		force          bool
+		dryRun         bool
+		expectOutput   []string
+		expectWritten  bool
+		expectOriginal bool
+	}{
+		{
+			name:          "writes rendered roles",
+			expectOutput:  []string{"Wrote billing-admin.yaml", "Wrote shipping-admin.yaml", "Rendered 2 role(s)"},
+			expectWritten: true,
+		},
+		{
+			name:           "dry run writes nothing",
+			dryRun:         true,
+			expectOutput:   []string{"Would write billing-admin.yaml", "Would render 2 role(s)"},
+			expectWritten:  false,
+			expectOriginal: false,
+		},
+		{
+			name:           "existing files are preserved",
+			existing:       true,
+			expectOutput:   []string{"Skipped billing-admin.yaml (file already exists)", "Rendered 1 role(s)"},
+			expectWritten:  true,
+			expectOriginal: true,
+		},
+		{
+			name:          "existing files are overwritten with force",
+			existing:      true,
+			force:         true,
+			expectOutput:  []string{"Wrote billing-admin.yaml", "Rendered 2 role(s)"},
+			expectWritten: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			outputDir := filepath.Join(t.TempDir(), "roles")
+			billingFile := filepath.Join(outputDir, "billing-admin.yaml")
+			if tt.existing {
+				if err := os.MkdirAll(outputDir, 0750); err != nil {
+					t.Fatalf("Failed to create output directory: %v", err)
+				}
+				if err := os.WriteFile(billingFile, []byte("name: billing-admin\nresources:\n  allowed: [\"*\"]\n  denied: []\n"), 0600); err != nil {
+					t.Fatalf("Failed to write existing file: %v", err)
+				}
+			}
+
+			cmd := &cobra.Command{}
+			var stdout bytes.Buffer
+			cmd.SetOut(&stdout)
+
+			if err := RunRenderCommand(cmd, templateDir, valuesFile, outputDir, tt.force, tt.dryRun); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			for _, expected := range tt.expectOutput {
+				if !strings.Contains(stdout.String(), expected) {
+					t.Errorf("Expected output to contain %q, got:\n%s", expected, stdout.String())
+				}
+			}
+
+			role, err := roles.ReadRoleFile(billingFile)
+			if !tt.expectWritten {
+				if err == nil {
+					t.Errorf("Expected no file to be written in dry-run mode")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Failed to read rendered file: %v", err)
+			}
+			preserved := len(role.Resources.Allowed) == 1 && role.Resources.Allowed[0] == "*"
+			if preserved != tt.expectOriginal {
+				t.Errorf("Expected original file preserved = %v, got role %+v", tt.expectOriginal, role)
+			}
+		})
+	}
+}
@@ -0,0 +1,63 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/spf13/cobra"
+
+	"replbac/internal/models"
+	"replbac/internal/report"
+)
+
+// TestReportFileRecordsPartialFailure tests that --report-file records what completed before a failure
+func TestReportFileRecordsPartialFailure(t *testing.T) {
+	tempDir := t.TempDir()
+	for _, role := range []models.Role{
+		{Name: "a-viewer", Resources: models.Resources{Allowed: []string{"read"}}},
+		{Name: "failing", Resources: models.Resources{Allowed: []string{"read"}}},
+	} {
+		if err := createTestRoleFile(tempDir, role); err != nil {
+			t.Fatalf("Failed to create role file: %v", err)
+		}
+	}
+	reportFile := filepath.Join(t.TempDir(), "changes.log")
+
+	cmd := NewSyncCommandWithOptions(NewMockClient(&MockAPICalls{}, []models.Role{}), func(cmd *cobra.Command) {
+		cmd.Flags().String("report-file", "", "append an audit entry to this file")
+	})
+	if err := cmd.Flags().Set("report-file", reportFile); err != nil {
+		t.Fatalf("Failed to set report-file flag: %v", err)
+	}
+	var stdout, stderr bytes.Buffer
+	cmd.SetOut(&stdout)
+	cmd.SetErr(&stderr)
+	cmd.SetArgs([]string{tempDir})
+
+	if err := cmd.Execute(); err == nil {
+		t.Fatal("Expected sync to fail when creating the failing role")
+	}
+
+	data, err := os.ReadFile(reportFile)
+	if err != nil {
+		t.Fatalf("Expected report file to be written: %v", err)
+	}
+	var entry report.Entry
+	if err := json.Unmarshal(bytes.TrimSpace(data), &entry); err != nil {
+		t.Fatalf("Report file is not a JSON line: %v\n%s", err, data)
+	}
+
+	if entry.Status != report.StatusFailed || entry.Error == "" {
+		t.Errorf("Expected failed status with an error, got status %q error %q", entry.Status, entry.Error)
+	}
+	if !reflect.DeepEqual(entry.Plan.Create, []string{"a-viewer", "failing"}) {
+		t.Errorf("Expected planned creates [a-viewer failing], got %v", entry.Plan.Create)
+	}
+	if !reflect.DeepEqual(entry.Outcome.Created, []string{"a-viewer"}) {
+		t.Errorf("Expected only a-viewer to be recorded as created, got %v", entry.Outcome.Created)
+	}
+}
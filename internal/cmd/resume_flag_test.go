@@ -0,0 +1,110 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+
+	"replbac/internal/models"
+)
+
+// flakyClient fails the first attempt to create each listed role
+type flakyClient struct {
+	*MockClient
+	failOnce map[string]bool
+}
+
+// CreateRole fails once for roles in failOnce, then delegates to the mock client
+func (f *flakyClient) CreateRole(role models.Role) error {
+	if f.failOnce[role.Name] {
+		delete(f.failOnce, role.Name)
+		return fmt.Errorf("temporary API error")
+	}
+	return f.MockClient.CreateRole(role)
+}
+
+// TestResumeFlagBehavior tests resuming a sync that failed part-way through
+func TestResumeFlagBehavior(t *testing.T) {
+	tests := []struct {
+		name          string
+		changeLocal   bool
+		expectMessage string
+	}{
+		{
+			name:          "resumes when the remaining work matches the checkpoint",
+			expectMessage: "Resuming sync: skipping 1 operation(s) already completed",
+		},
+		{
+			name:          "ignores a stale checkpoint",
+			changeLocal:   true,
+			expectMessage: "Checkpoint does not match the current plan",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("XDG_CACHE_HOME", t.TempDir())
+			t.Setenv("HOME", t.TempDir())
+
+			tempDir := t.TempDir()
+			for _, name := range []string{"a-role", "b-role", "c-role"} {
+				if err := createTestRoleFile(tempDir, models.Role{Name: name, Resources: models.Resources{Allowed: []string{"read"}}}); err != nil {
+					t.Fatalf("Failed to create role file: %v", err)
+				}
+			}
+
+			mockCalls := &MockAPICalls{}
+			client := &flakyClient{MockClient: NewMockClient(mockCalls, []models.Role{}), failOnce: map[string]bool{"b-role": true}}
+			run := func(resume bool) (string, error) {
+				cmd := NewSyncCommandWithOptions(client, func(cmd *cobra.Command) {
+					cmd.Flags().Bool("resume", false, "resume an interrupted sync")
+				})
+				if resume {
+					if err := cmd.Flags().Set("resume", "true"); err != nil {
+						t.Fatalf("Failed to set resume flag: %v", err)
+					}
+				}
+				var stdout, stderr bytes.Buffer
+				cmd.SetOut(&stdout)
+				cmd.SetErr(&stderr)
+				cmd.SetArgs([]string{tempDir})
+				err := cmd.Execute()
+				return stdout.String(), err
+			}
+
+			output, err := run(false)
+			if err == nil {
+				t.Fatal("Expected the first sync to fail")
+			}
+			if !strings.Contains(output, "re-run with --resume to continue") {
+				t.Errorf("Expected a resume hint after the failure, got:\n%s", output)
+			}
+
+			if tt.changeLocal {
+				if err := createTestRoleFile(tempDir, models.Role{Name: "c-role", Resources: models.Resources{Allowed: []string{"write"}}}); err != nil {
+					t.Fatalf("Failed to update role file: %v", err)
+				}
+			}
+
+			output, err = run(true)
+			if err != nil {
+				t.Fatalf("Expected the resumed sync to succeed, got: %v", err)
+			}
+			if !strings.Contains(output, tt.expectMessage) {
+				t.Errorf("Expected output to contain %q, got:\n%s", tt.expectMessage, output)
+			}
+
+			// The checkpoint is removed once the sync completes
+			output, err = run(true)
+			if err != nil {
+				t.Fatalf("Unexpected error on final run: %v", err)
+			}
+			if !strings.Contains(output, "No checkpoint found") {
+				t.Errorf("Expected the checkpoint to be removed after success, got:\n%s", output)
+			}
+		})
+	}
+}
@@ -0,0 +1,232 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"replbac/internal/api"
+	"replbac/internal/logging"
+	"replbac/internal/models"
+	"replbac/internal/roles"
+	"replbac/internal/sync"
+)
+
+var (
+	showDir    string
+	showRemote bool
+	showOutput string
+)
+
+// showCmd represents the show command
+var showCmd = &cobra.Command{
+	Use:   "show <role-name>",
+	Short: "Show one role's resources grouped by namespace, and its members",
+	Long: `Show prints a single role for review: its allowed and denied resources grouped
+by top-level namespace (the part before the first "/", e.g. kots or team), and
+its members.
+
+The role is read from the role files in --dir (default: the current directory),
+with any inherited resources resolved. Use --remote to read it from the
+Replicated API instead. Use --output json for a machine-readable form.
+
+Environment Variables:
+  This command supports all global environment variables.
+  See 'replbac --help' for full environment variable documentation.`,
+	Args: cobra.ExactArgs(1),
+	ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) > 0 {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		if getBoolFlag(cmd, "remote") {
+			return RemoteRoleNameCompletion(cmd, args, toComplete)
+		}
+		return RoleNameCompletion(cmd, args, toComplete)
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return RunShowCommand(cmd, args[0], cfg)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(showCmd)
+
+	showCmd.Flags().StringVar(&showDir, "dir", ".", "directory of role files to read the role from")
+	showCmd.Flags().BoolVar(&showRemote, "remote", false, "read the role from the Replicated API instead of the role files")
+	showCmd.Flags().StringVarP(&showOutput, "output", "o", "text", "output format: text or json")
+}
+
+// RunShowCommand prints one role, read from the API with --remote or from the role files otherwise
+func RunShowCommand(cmd *cobra.Command, roleName string, config models.Config) error {
+	if cmd.OutOrStdout() == os.Stderr {
+		cmd.SetOut(os.Stdout)
+	}
+	if !getBoolFlag(cmd, "remote") {
+		return RunShowCommandWithClient(cmd, roleName, nil)
+	}
+
+	logger := logging.NewLogger(cmd.ErrOrStderr(), false)
+	if getBoolFlag(cmd, "debug-http") {
+		logger.EnableHTTPTrace()
+	}
+	teeLogFile(logger)
+
+	client, err := newAPIClient(config, logger, api.DefaultMaxRetries)
+	if err != nil {
+		return fmt.Errorf("failed to create API client: %w", err)
+	}
+	return RunShowCommandWithClient(cmd, roleName, client)
+}
+
+// RunShowCommandWithClient prints one role, read from client with --remote or from the
+// role files in --dir otherwise
+func RunShowCommandWithClient(cmd *cobra.Command, roleName string, client api.ClientInterface) error {
+	output := getStringFlag(cmd, "output")
+	if output == "" {
+		output = "text"
+	}
+	if output != "text" && output != "json" {
+		return fmt.Errorf("invalid --output %q: must be text or json", output)
+	}
+
+	var role models.Role
+	var source string
+	if getBoolFlag(cmd, "remote") {
+		// GetRole only carries names and IDs, so the role is found among the full listing
+		remoteRoles, err := client.GetRoles()
+		if err != nil {
+			return fmt.Errorf("failed to get remote roles: %w", err)
+		}
+		found, exists := findRole(remoteRoles, roleName)
+		if !exists {
+			return fmt.Errorf("role %q does not exist", roleName)
+		}
+		role, source = found, "Replicated API"
+	} else {
+		dir := getStringFlag(cmd, "dir")
+		if dir == "" {
+			dir = "."
+		}
+		localRoles, err := roles.LoadRolesFromDirectory(dir)
+		if err != nil {
+			return fmt.Errorf("failed to load roles from %s: %w", dir, err)
+		}
+		found, exists := findRole(localRoles, roleName)
+		if !exists {
+			return fmt.Errorf("role %q is not defined in %s", roleName, dir)
+		}
+		role, source = found, found.SourceFile
+	}
+
+	if output == "json" {
+		return printRoleJSON(cmd, role, source)
+	}
+	printRole(cmd, role, source)
+	return nil
+}
+
+// resourceGroup is a role's resources under one top-level namespace
+type resourceGroup struct {
+	Namespace string
+	Resources []string
+}
+
+// groupResources groups resources by the namespace before their first "/", in sorted
+// namespace order, keeping each group's resources in the order given
+func groupResources(resources []string) []resourceGroup {
+	byNamespace := make(map[string][]string)
+	for _, resource := range resources {
+		namespace, _, _ := strings.Cut(resource, "/")
+		byNamespace[namespace] = append(byNamespace[namespace], resource)
+	}
+
+	groups := make([]resourceGroup, 0, len(byNamespace))
+	for namespace, grouped := range byNamespace {
+		groups = append(groups, resourceGroup{Namespace: namespace, Resources: grouped})
+	}
+	sort.Slice(groups, func(i, j int) bool { return groups[i].Namespace < groups[j].Namespace })
+	return groups
+}
+
+// printRole writes a role as plain text, with its resources grouped by namespace
+func printRole(cmd *cobra.Command, role models.Role, source string) {
+	cmd.Printf("Role: %s\n", role.Name)
+	if role.ID != "" {
+		cmd.Printf("ID: %s\n", role.ID)
+	}
+	if source != "" {
+		cmd.Printf("Source: %s\n", source)
+	}
+
+	for _, section := range []struct {
+		title     string
+		resources []string
+	}{
+		{"Allowed", role.Resources.Allowed},
+		{"Denied", role.Resources.Denied},
+	} {
+		cmd.Printf("\n%s (%d):\n", section.title, len(section.resources))
+		if len(section.resources) == 0 {
+			cmd.Println("  (none)")
+		}
+		for _, group := range groupResources(section.resources) {
+			cmd.Printf("  %s:\n", group.Namespace)
+			for _, resource := range group.Resources {
+				cmd.Printf("    %s\n", resource)
+			}
+		}
+	}
+
+	cmd.Printf("\nMembers (%d):\n", len(role.Members))
+	if len(role.Members) == 0 {
+		cmd.Println("  (none)")
+	}
+	names := sync.MemberNames([]models.Role{role})
+	for _, member := range role.Members {
+		if label, found := names[member]; found {
+			cmd.Printf("  %s\n", label)
+		} else {
+			cmd.Printf("  %s\n", member)
+		}
+	}
+}
+
+// printRoleJSON writes a role as indented JSON, with its resources grouped by namespace
+func printRoleJSON(cmd *cobra.Command, role models.Role, source string) error {
+	grouped := func(resources []string) map[string][]string {
+		groups := make(map[string][]string)
+		for _, group := range groupResources(resources) {
+			groups[group.Namespace] = group.Resources
+		}
+		return groups
+	}
+	members := role.Members
+	if members == nil {
+		members = []string{}
+	}
+
+	data, err := json.MarshalIndent(struct {
+		Name    string              `json:"name"`
+		ID      string              `json:"id,omitempty"`
+		Source  string              `json:"source,omitempty"`
+		Allowed map[string][]string `json:"allowed"`
+		Denied  map[string][]string `json:"denied"`
+		Members []string            `json:"members"`
+	}{
+		Name:    role.Name,
+		ID:      role.ID,
+		Source:  source,
+		Allowed: grouped(role.Resources.Allowed),
+		Denied:  grouped(role.Resources.Denied),
+		Members: members,
+	}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode role: %w", err)
+	}
+	cmd.Println(string(data))
+	return nil
+}
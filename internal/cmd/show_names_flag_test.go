@@ -0,0 +1,88 @@
+package cmd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+
+	"replbac/internal/logging"
+	"replbac/internal/models"
+)
+
+// namedRemoteRoles returns remote roles carrying the member details the API client correlates
+func namedRemoteRoles() []models.Role {
+	return []models.Role{{
+		ID:            "1",
+		Name:          "admin",
+		Resources:     models.Resources{Allowed: []string{"*"}, Denied: []string{}},
+		Members:       []string{"alice@example.com"},
+		MemberDetails: []models.TeamMember{{ID: "alice@example.com", Email: "alice@example.com", Name: "Alice Smith", Username: "alice", PolicyID: "1"}},
+	}}
+}
+
+// TestShowNamesFlagBehavior tests that --show-names labels members with their names in sync and diff output
+func TestShowNamesFlagBehavior(t *testing.T) {
+	tests := []struct {
+		name         string
+		showNames    bool
+		expectOutput []string
+		rejectOutput []string
+	}{
+		{
+			name:         "bare emails by default",
+			expectOutput: []string{"- members: alice@example.com"},
+			rejectOutput: []string{"Alice Smith"},
+		},
+		{
+			name:         "names shown with --show-names",
+			showNames:    true,
+			expectOutput: []string{"- members: alice@example.com (Alice Smith, @alice)", "+ members: bob@example.com"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tempDir := t.TempDir()
+			local := models.Role{Name: "admin", Resources: models.Resources{Allowed: []string{"*"}, Denied: []string{}}, Members: []string{"bob@example.com"}}
+			if err := createTestRoleFile(tempDir, local); err != nil {
+				t.Fatalf("Failed to create role file: %v", err)
+			}
+
+			check := func(command, output string) {
+				for _, expected := range tt.expectOutput {
+					if !strings.Contains(output, expected) {
+						t.Errorf("%s: expected output to contain %q, got:\n%s", command, expected, output)
+					}
+				}
+				for _, rejected := range tt.rejectOutput {
+					if strings.Contains(output, rejected) {
+						t.Errorf("%s: expected output not to contain %q, got:\n%s", command, rejected, output)
+					}
+				}
+			}
+
+			syncCmd := NewSyncCommandWithOptions(NewMockClient(&MockAPICalls{}, namedRemoteRoles()), func(cmd *cobra.Command) {
+				cmd.Flags().Bool("show-names", tt.showNames, "show member names")
+			})
+			var stdout bytes.Buffer
+			syncCmd.SetOut(&stdout)
+			syncCmd.SetErr(&bytes.Buffer{})
+			syncCmd.SetArgs([]string{tempDir, "--dry-run", "--diff"})
+			if err := syncCmd.Execute(); err != nil {
+				t.Fatalf("Unexpected sync error: %v", err)
+			}
+			check("sync", stdout.String())
+
+			diffCmd := &cobra.Command{}
+			diffCmd.Flags().Bool("show-names", tt.showNames, "show member names")
+			stdout.Reset()
+			diffCmd.SetOut(&stdout)
+			if err := RunDiffCommandWithClient(diffCmd, tempDir, "", NewMockClient(&MockAPICalls{}, namedRemoteRoles()), logging.NewLogger(&bytes.Buffer{}, false), models.Config{}); err != nil {
+				t.Fatalf("Unexpected diff error: %v", err)
+			}
+			check("diff", stdout.String())
+		})
+	}
+}
@@ -0,0 +1,104 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+
+	"replbac/internal/models"
+)
+
+// newTestShowCommand returns a command with the show flags, for calling RunShowCommandWithClient
+func newTestShowCommand(args ...string) (*cobra.Command, *bytes.Buffer) {
+	cmd := &cobra.Command{}
+	cmd.Flags().String("dir", ".", "directory of role files")
+	cmd.Flags().Bool("remote", false, "read the role from the API")
+	cmd.Flags().StringP("output", "o", "text", "output format")
+	if err := cmd.Flags().Parse(args); err != nil {
+		panic(err)
+	}
+	var stdout bytes.Buffer
+	cmd.SetOut(&stdout)
+	return cmd, &stdout
+}
+
+func TestShowCommand(t *testing.T) {
+	dir := t.TempDir()
+	content := "name: admin\nresources:\n  allowed:\n    - team/members/read\n    - kots/app/*/read\n    - kots/app/*/write\n" +
+		"  denied:\n    - team/members/write\nmembers:\n  - alice@example.com\n"
+	if err := os.WriteFile(filepath.Join(dir, "admin.yaml"), []byte(content), 0600); err != nil {
+		t.Fatalf("Failed to write role file: %v", err)
+	}
+
+	cmd, stdout := newTestShowCommand("--dir", dir)
+	if err := RunShowCommandWithClient(cmd, "admin", nil); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected := strings.Join([]string{
+		"Role: admin",
+		"Source: " + filepath.Join(dir, "admin.yaml"),
+		"",
+		"Allowed (3):",
+		"  kots:",
+		"    kots/app/*/read",
+		"    kots/app/*/write",
+		"  team:",
+		"    team/members/read",
+		"",
+		"Denied (1):",
+		"  team:",
+		"    team/members/write",
+		"",
+		"Members (1):",
+		"  alice@example.com",
+		"",
+	}, "\n")
+	if stdout.String() != expected {
+		t.Errorf("Expected:\n%s\ngot:\n%s", expected, stdout.String())
+	}
+
+	cmd, _ = newTestShowCommand("--dir", dir)
+	if err := RunShowCommandWithClient(cmd, "viewer", nil); err == nil || !strings.Contains(err.Error(), `role "viewer" is not defined`) {
+		t.Errorf("Expected an error for an undefined role, got %v", err)
+	}
+}
+
+func TestShowCommand_RemoteJSON(t *testing.T) {
+	remote := []models.Role{{
+		ID:        "policy-1",
+		Name:      "viewer",
+		Resources: models.Resources{Allowed: []string{"kots/app/*/read", "**/*"}},
+	}}
+
+	cmd, stdout := newTestShowCommand("--remote", "--output", "json")
+	if err := RunShowCommandWithClient(cmd, "viewer", NewMockClient(&MockAPICalls{}, remote)); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	var shown struct {
+		Name    string              `json:"name"`
+		ID      string              `json:"id"`
+		Allowed map[string][]string `json:"allowed"`
+		Denied  map[string][]string `json:"denied"`
+		Members []string            `json:"members"`
+	}
+	if err := json.Unmarshal(stdout.Bytes(), &shown); err != nil {
+		t.Fatalf("Expected JSON output, got %v:\n%s", err, stdout.String())
+	}
+	expectedAllowed := map[string][]string{"**": {"**/*"}, "kots": {"kots/app/*/read"}}
+	if shown.Name != "viewer" || shown.ID != "policy-1" || !reflect.DeepEqual(shown.Allowed, expectedAllowed) ||
+		len(shown.Denied) != 0 || shown.Members == nil {
+		t.Errorf("Unexpected JSON output:\n%s", stdout.String())
+	}
+
+	cmd, _ = newTestShowCommand("--remote", "--output", "yaml")
+	if err := RunShowCommandWithClient(cmd, "viewer", NewMockClient(&MockAPICalls{}, remote)); err == nil || !strings.Contains(err.Error(), "must be text or json") {
+		t.Errorf("Expected an error for an unknown output format, got %v", err)
+	}
+}
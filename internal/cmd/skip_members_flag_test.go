@@ -0,0 +1,101 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+
+	"replbac/internal/api"
+	"replbac/internal/models"
+)
+
+// unreadableMembersClient fails to list team members with a configured error
+type unreadableMembersClient struct {
+	*MockClient
+	listErr error
+}
+
+// GetTeamMembers returns the configured error
+func (u *unreadableMembersClient) GetTeamMembers() ([]models.TeamMember, error) {
+	return nil, u.listErr
+}
+
+// TestSkipMembersOnErrorFlagBehavior tests that role sync completes when team members cannot be read
+func TestSkipMembersOnErrorFlagBehavior(t *testing.T) {
+	tests := []struct {
+		name          string
+		listErr       error
+		skipOnError   bool
+		expectError   string
+		expectWarning bool
+	}{
+		{
+			name:          "forbidden member listing is skipped automatically",
+			listErr:       &api.AuthError{StatusCode: http.StatusForbidden},
+			expectWarning: true,
+		},
+		{
+			name:        "other member listing failures fail the sync",
+			listErr:     errors.New("connection reset"),
+			expectError: "failed to get team members",
+		},
+		{
+			name:          "other member listing failures are skipped with --skip-members-on-error",
+			listErr:       errors.New("connection reset"),
+			skipOnError:   true,
+			expectWarning: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tempDir := t.TempDir()
+			role := models.Role{Name: "admin", Resources: models.Resources{Allowed: []string{"*"}, Denied: []string{}}, Members: []string{"a@example.com"}}
+			if err := createTestRoleFile(tempDir, role); err != nil {
+				t.Fatalf("Failed to create role file: %v", err)
+			}
+
+			mockCalls := &MockAPICalls{}
+			client := &unreadableMembersClient{MockClient: NewMockClient(mockCalls, []models.Role{}), listErr: tt.listErr}
+			cmd := NewSyncCommandWithOptions(client, func(cmd *cobra.Command) {
+				cmd.Flags().Bool("skip-members-on-error", false, "skip member sync if team members cannot be read")
+			})
+			if tt.skipOnError {
+				if err := cmd.Flags().Set("skip-members-on-error", "true"); err != nil {
+					t.Fatalf("Failed to set skip-members-on-error flag: %v", err)
+				}
+			}
+
+			var stdout, stderr bytes.Buffer
+			cmd.SetOut(&stdout)
+			cmd.SetErr(&stderr)
+			cmd.SetArgs([]string{tempDir})
+
+			err := cmd.Execute()
+			if len(mockCalls.CreateCalls) != 1 {
+				t.Errorf("Expected the role to be created before member sync, got %d creates", len(mockCalls.CreateCalls))
+			}
+			if tt.expectError != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.expectError) {
+					t.Fatalf("Expected error containing %q, got %v", tt.expectError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			output := stdout.String()
+			if tt.expectWarning && !strings.Contains(output, "Warning: member sync skipped: failed to get team members") {
+				t.Errorf("Expected member sync warning, got:\n%s", output)
+			}
+			if !strings.Contains(output, "Sync completed") {
+				t.Errorf("Expected sync to complete, got:\n%s", output)
+			}
+		})
+	}
+}
@@ -0,0 +1,147 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"replbac/internal/models"
+	"replbac/internal/roles"
+)
+
+// TestSkippedOutFlag tests that skipped role files are written to --skipped-out and
+// included in the --summary-json result
+func TestSkippedOutFlag(t *testing.T) {
+	tests := []struct {
+		name          string
+		invalid       bool
+		failOnSkip    bool
+		expectError   bool
+		expectSkipped int
+	}{
+		{
+			name:          "invalid file is listed",
+			invalid:       true,
+			expectSkipped: 1,
+		},
+		{
+			name:          "listed even when the sync aborts on it",
+			invalid:       true,
+			failOnSkip:    true,
+			expectError:   true,
+			expectSkipped: 1,
+		},
+		{
+			name: "empty array when nothing is skipped",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tempDir := t.TempDir()
+			role := models.Role{Name: "viewer", Resources: models.Resources{Allowed: []string{"kots/app/*/read"}}}
+			if err := createTestRoleFile(tempDir, role); err != nil {
+				t.Fatalf("Failed to create role file: %v", err)
+			}
+			invalidPath := filepath.Join(tempDir, "broken.yaml")
+			if tt.invalid {
+				// #nosec G306 -- Test files need readable permissions
+				if err := os.WriteFile(invalidPath, []byte("name: [unclosed\n"), 0644); err != nil {
+					t.Fatalf("Failed to create invalid file: %v", err)
+				}
+			}
+			outPath := filepath.Join(t.TempDir(), "skipped.json")
+
+			cmd := NewSyncCommandWithOptions(NewMockClient(&MockAPICalls{}, []models.Role{}), func(cmd *cobra.Command) {
+				cmd.Flags().String("skipped-out", "", "write skipped files as JSON")
+				cmd.Flags().Bool("summary-json", false, "print a structured result line")
+				cmd.Flags().Bool("fail-on-skip", false, "abort if any role file is invalid")
+			})
+			var stdout, stderr bytes.Buffer
+			cmd.SetOut(&stdout)
+			cmd.SetErr(&stderr)
+			args := []string{tempDir, "--skipped-out", outPath, "--summary-json"}
+			if tt.failOnSkip {
+				args = append(args, "--fail-on-skip")
+			}
+			cmd.SetArgs(args)
+
+			err := cmd.Execute()
+			if tt.expectError != (err != nil) {
+				t.Fatalf("Expected error: %v, got: %v", tt.expectError, err)
+			}
+
+			data, err := os.ReadFile(outPath) // #nosec G304 -- Reading test output file
+			if err != nil {
+				t.Fatalf("Expected --skipped-out file to be written: %v", err)
+			}
+			var written []roles.SkippedFile
+			if err := json.Unmarshal(data, &written); err != nil {
+				t.Fatalf("Failed to parse %s: %v\n%s", outPath, err, data)
+			}
+			if written == nil || len(written) != tt.expectSkipped {
+				t.Fatalf("Expected %d skipped file(s) as a JSON array, got:\n%s", tt.expectSkipped, data)
+			}
+			if tt.invalid && (written[0].Path != invalidPath || written[0].Reason == "") {
+				t.Errorf("Expected %s with a reason, got %+v", invalidPath, written[0])
+			}
+
+			var summary resultSummary
+			for _, line := range strings.Split(stderr.String(), "\n") {
+				if strings.HasPrefix(line, resultLinePrefix) {
+					if err := json.Unmarshal([]byte(strings.TrimPrefix(line, resultLinePrefix)), &summary); err != nil {
+						t.Fatalf("Failed to parse result line %q: %v", line, err)
+					}
+				}
+			}
+			if len(summary.Skipped) != tt.expectSkipped {
+				t.Errorf("Expected %d skipped file(s) in the result, got %+v", tt.expectSkipped, summary.Skipped)
+			}
+		})
+	}
+}
+
+// slowRolesClient takes a while to list roles and records when the listing has finished
+type slowRolesClient struct {
+	*MockClient
+	finished atomic.Bool
+}
+
+// GetRoles lists the roles after a delay
+func (c *slowRolesClient) GetRoles() ([]models.Role, error) {
+	time.Sleep(50 * time.Millisecond)
+	defer c.finished.Store(true)
+	return c.MockClient.GetRoles()
+}
+
+// TestSkippedOutWriteFailureWaitsForFetch tests that a sync failing to write --skipped-out
+// does not return while the remote roles are still being fetched
+func TestSkippedOutWriteFailureWaitsForFetch(t *testing.T) {
+	tempDir := t.TempDir()
+	if err := createTestRoleFile(tempDir, models.Role{Name: "viewer", Resources: models.Resources{Allowed: []string{"kots/app/*/read"}}}); err != nil {
+		t.Fatalf("Failed to create role file: %v", err)
+	}
+
+	client := &slowRolesClient{MockClient: NewMockClient(&MockAPICalls{}, []models.Role{})}
+	cmd := NewSyncCommandWithOptions(client, func(cmd *cobra.Command) {
+		cmd.Flags().String("skipped-out", "", "write skipped files as JSON")
+	})
+	cmd.SetOut(&bytes.Buffer{})
+	cmd.SetErr(&bytes.Buffer{})
+	// A directory cannot be written as the output file
+	cmd.SetArgs([]string{tempDir, "--skipped-out", t.TempDir()})
+
+	if err := cmd.Execute(); err == nil || !strings.Contains(err.Error(), "failed to write skipped files") {
+		t.Fatalf("Expected a write error, got %v", err)
+	}
+	if !client.finished.Load() {
+		t.Error("Expected the remote role fetch to finish before the command returned")
+	}
+}
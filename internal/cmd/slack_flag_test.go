@@ -0,0 +1,197 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+
+	"replbac/internal/logging"
+	"replbac/internal/models"
+	"replbac/internal/report"
+)
+
+// TestSlackWebhookFlagBehavior tests which sync runs --slack-webhook reports, and how
+func TestSlackWebhookFlagBehavior(t *testing.T) {
+	tests := []struct {
+		name        string
+		roles       []string
+		args        []string
+		expectError bool
+		expectText  string // Empty when no notification is expected
+		expectColor string
+	}{
+		{
+			name:        "applied sync is reported",
+			roles:       []string{"viewer", "editor"},
+			expectText:  "replbac sync succeeded: 2 created, 0 updated, 0 deleted",
+			expectColor: "good",
+		},
+		{
+			name:  "dry run is not reported by default",
+			roles: []string{"viewer"},
+			args:  []string{"--dry-run"},
+		},
+		{
+			name:        "dry run is reported with --notify-on dry-run",
+			roles:       []string{"viewer"},
+			args:        []string{"--dry-run", "--notify-on", "dry-run"},
+			expectText:  "replbac sync dry run succeeded: would create 1, update 0, delete 0 role(s)",
+			expectColor: "#439FE0",
+		},
+		{
+			name:        "failed sync is reported",
+			roles:       []string{"editor", "failing"},
+			expectError: true,
+			expectText:  "replbac sync failed: 1 created, 0 updated, 0 deleted",
+			expectColor: "danger",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var messages []report.SlackMessage
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				var message report.SlackMessage
+				if err := json.NewDecoder(r.Body).Decode(&message); err != nil {
+					t.Errorf("Failed to decode Slack message: %v", err)
+				}
+				messages = append(messages, message)
+			}))
+			defer server.Close()
+
+			tempDir := t.TempDir()
+			for _, name := range tt.roles {
+				role := models.Role{Name: name, Resources: models.Resources{Allowed: []string{"kots/app/*/read"}}}
+				if err := createTestRoleFile(tempDir, role); err != nil {
+					t.Fatalf("Failed to create role file: %v", err)
+				}
+			}
+
+			cmd := NewSyncCommandWithOptions(NewMockClient(&MockAPICalls{}, nil), func(cmd *cobra.Command) {
+				cmd.Flags().String("slack-webhook", "", "post a sync summary to Slack")
+				cmd.Flags().String("notify-on", "apply", "which runs to report")
+			})
+			cmd.SetOut(&bytes.Buffer{})
+			cmd.SetErr(&bytes.Buffer{})
+			cmd.SetArgs(append([]string{tempDir, "--slack-webhook", server.URL}, tt.args...))
+			err := cmd.Execute()
+			if tt.expectError != (err != nil) {
+				t.Fatalf("Expected error: %v, got: %v", tt.expectError, err)
+			}
+
+			if tt.expectText == "" {
+				if len(messages) != 0 {
+					t.Errorf("Expected no notification, got %+v", messages)
+				}
+				return
+			}
+			if len(messages) != 1 {
+				t.Fatalf("Expected one notification, got %d", len(messages))
+			}
+			message := messages[0]
+			if message.Text != tt.expectText {
+				t.Errorf("Text = %q, want %q", message.Text, tt.expectText)
+			}
+			if len(message.Attachments) != 1 || message.Attachments[0].Color != tt.expectColor {
+				t.Errorf("Expected one attachment colored %q, got %+v", tt.expectColor, message.Attachments)
+			}
+			if tt.expectError && !strings.Contains(message.Attachments[0].Text, "failing") {
+				t.Errorf("Expected the attachment to name the error, got %q", message.Attachments[0].Text)
+			}
+		})
+	}
+}
+
+// TestSlackWebhookEnv tests that the webhook can be given in REPLBAC_SLACK_WEBHOOK instead
+// of on the command line
+func TestSlackWebhookEnv(t *testing.T) {
+	notified := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		notified++
+	}))
+	defer server.Close()
+	t.Setenv(report.SlackWebhookEnv, server.URL)
+
+	tempDir := t.TempDir()
+	if err := createTestRoleFile(tempDir, models.Role{Name: "viewer", Resources: models.Resources{Allowed: []string{"kots/app/*/read"}}}); err != nil {
+		t.Fatalf("Failed to create role file: %v", err)
+	}
+	cmd := NewSyncCommandWithOptions(NewMockClient(&MockAPICalls{}, nil), func(cmd *cobra.Command) {
+		cmd.Flags().String("slack-webhook", "", "post a sync summary to Slack")
+		cmd.Flags().String("notify-on", "apply", "which runs to report")
+	})
+	cmd.SetOut(&bytes.Buffer{})
+	cmd.SetErr(&bytes.Buffer{})
+	cmd.SetArgs([]string{tempDir})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if notified != 1 {
+		t.Errorf("Expected one notification to the webhook from %s, got %d", report.SlackWebhookEnv, notified)
+	}
+}
+
+// TestSlackWebhookFlagValidation tests that bad --slack-webhook and --notify-on values fail before syncing
+func TestSlackWebhookFlagValidation(t *testing.T) {
+	tests := map[string][]string{
+		"must be an HTTP or HTTPS URL": {"--slack-webhook", "hooks.slack.com/services/x"},
+		"must be apply or dry-run":     {"--slack-webhook", "https://hooks.slack.com/services/x", "--notify-on", "always"},
+	}
+	for errorContains, args := range tests {
+		calls := &MockAPICalls{}
+		cmd := NewSyncCommandWithOptions(NewMockClient(calls, nil), func(cmd *cobra.Command) {
+			cmd.Flags().String("slack-webhook", "", "post a sync summary to Slack")
+			cmd.Flags().String("notify-on", "apply", "which runs to report")
+		})
+		cmd.SetOut(&bytes.Buffer{})
+		cmd.SetErr(&bytes.Buffer{})
+		cmd.SetArgs(append([]string{t.TempDir()}, args...))
+		err := cmd.Execute()
+		if err == nil || !strings.Contains(err.Error(), errorContains) {
+			t.Errorf("Expected error containing %q, got %v", errorContains, err)
+		}
+		if err != nil && strings.Contains(err.Error(), "services/x") {
+			t.Errorf("Expected the webhook URL to be left out of the error, got %v", err)
+		}
+		if calls.GetCalls != 0 {
+			t.Errorf("Expected no API calls for %v, got %d", args, calls.GetCalls)
+		}
+	}
+}
+
+// TestSlackWebhookUsesProxy tests that the Slack notification connects through the
+// configured proxy, as API requests do
+func TestSlackWebhookUsesProxy(t *testing.T) {
+	var proxied []string
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proxied = append(proxied, r.URL.Host)
+	}))
+	defer proxy.Close()
+
+	tempDir := t.TempDir()
+	if err := createTestRoleFile(tempDir, models.Role{Name: "viewer", Resources: models.Resources{Allowed: []string{"kots/app/*/read"}}}); err != nil {
+		t.Fatalf("Failed to create role file: %v", err)
+	}
+	cmd := &cobra.Command{}
+	cmd.Flags().String("slack-webhook", "", "post a sync summary to Slack")
+	if err := cmd.Flags().Set("slack-webhook", "http://hooks.slack.invalid/services/T000/B000/secret"); err != nil {
+		t.Fatalf("Failed to set slack-webhook flag: %v", err)
+	}
+	var stderr bytes.Buffer
+	cmd.SetOut(&bytes.Buffer{})
+	cmd.SetErr(&stderr)
+
+	config := models.Config{APIToken: "test-token", Proxy: proxy.URL}
+	logger := logging.NewLogger(&bytes.Buffer{}, false)
+	if err := RunSyncCommandWithLogging(cmd, []string{tempDir}, NewMockClient(&MockAPICalls{}, nil), false, false, false, false, false, logger, config); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(proxied) != 1 || proxied[0] != "hooks.slack.invalid" {
+		t.Errorf("Expected the notification to go through the proxy, got %v (stderr: %s)", proxied, stderr.String())
+	}
+}
@@ -0,0 +1,104 @@
+package cmd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+
+	"replbac/internal/models"
+)
+
+// TestSoftDeleteFlagBehavior tests that --soft-delete disables roles instead of deleting them
+func TestSoftDeleteFlagBehavior(t *testing.T) {
+	tempDir := t.TempDir()
+	if err := createTestRoleFile(tempDir, models.Role{Name: "viewer", Resources: models.Resources{Allowed: []string{"read"}}}); err != nil {
+		t.Fatalf("Failed to create role file: %v", err)
+	}
+
+	remoteRoles := []models.Role{
+		{ID: "1", Name: "legacy", Resources: models.Resources{Allowed: []string{"*"}}},
+		// A previously soft-deleted copy of viewer must not block creating it again
+		{ID: "2", Name: "disabled-viewer", Resources: models.Resources{Allowed: []string{}, Denied: []string{"**/*"}}},
+	}
+
+	mockCalls := &MockAPICalls{}
+	cmd := NewSyncCommandWithOptions(NewMockClient(mockCalls, remoteRoles), func(cmd *cobra.Command) {
+		cmd.Flags().Bool("soft-delete", false, "disable removed roles instead of deleting them")
+	})
+	for _, flag := range []string{"delete", "force", "soft-delete"} {
+		if err := cmd.Flags().Set(flag, "true"); err != nil {
+			t.Fatalf("Failed to set %s flag: %v", flag, err)
+		}
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd.SetOut(&stdout)
+	cmd.SetErr(&stderr)
+	cmd.SetArgs([]string{tempDir})
+
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("Expected no error but got: %v", err)
+	}
+
+	if len(mockCalls.DeleteCalls) != 0 {
+		t.Errorf("Expected no delete calls with --soft-delete, got %v", mockCalls.DeleteCalls)
+	}
+	if len(mockCalls.CreateCalls) != 1 || mockCalls.CreateCalls[0].Name != "viewer" {
+		t.Errorf("Expected viewer to be created, got %+v", mockCalls.CreateCalls)
+	}
+	if len(mockCalls.UpdateCalls) != 1 {
+		t.Fatalf("Expected one update call, got %+v", mockCalls.UpdateCalls)
+	}
+	disabled := mockCalls.UpdateCalls[0]
+	if disabled.ID != "1" || disabled.Name != "disabled-legacy" || len(disabled.Resources.Allowed) != 0 {
+		t.Errorf("Expected legacy to be disabled in place, got %+v", disabled)
+	}
+	if !strings.Contains(stdout.String(), "legacy (soft-delete as disabled-legacy)") {
+		t.Errorf("Expected plan output to describe the soft delete, got:\n%s", stdout.String())
+	}
+}
+
+// TestSoftDeleteRequiresConfirmation tests that --soft-delete without --force asks before
+// disabling roles, and leaves them alone when the answer is no
+func TestSoftDeleteRequiresConfirmation(t *testing.T) {
+	tempDir := t.TempDir()
+	if err := createTestRoleFile(tempDir, models.Role{Name: "viewer", Resources: models.Resources{Allowed: []string{"read"}}}); err != nil {
+		t.Fatalf("Failed to create role file: %v", err)
+	}
+
+	remoteRoles := []models.Role{
+		{ID: "1", Name: "viewer", Resources: models.Resources{Allowed: []string{"read"}}},
+		{ID: "2", Name: "legacy", Resources: models.Resources{Allowed: []string{"*"}}},
+	}
+
+	mockCalls := &MockAPICalls{}
+	cmd := NewSyncCommandWithOptions(NewMockClient(mockCalls, remoteRoles), func(cmd *cobra.Command) {
+		cmd.Flags().Bool("soft-delete", false, "disable removed roles instead of deleting them")
+	})
+	for _, flag := range []string{"delete", "soft-delete"} {
+		if err := cmd.Flags().Set(flag, "true"); err != nil {
+			t.Fatalf("Failed to set %s flag: %v", flag, err)
+		}
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd.SetOut(&stdout)
+	cmd.SetErr(&stderr)
+	cmd.SetIn(strings.NewReader("n\n"))
+	cmd.SetArgs([]string{tempDir})
+
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("Expected no error but got: %v", err)
+	}
+
+	if len(mockCalls.UpdateCalls) != 0 || len(mockCalls.DeleteCalls) != 0 {
+		t.Errorf("Expected no changes after declining, got updates %+v, deletes %v", mockCalls.UpdateCalls, mockCalls.DeleteCalls)
+	}
+	for _, expected := range []string{"will disable 1 role(s)", "Operation cancelled by user"} {
+		if !strings.Contains(stdout.String(), expected) {
+			t.Errorf("Expected output to contain %q, got:\n%s", expected, stdout.String())
+		}
+	}
+}
@@ -0,0 +1,53 @@
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"replbac/internal/models"
+)
+
+// TestPullSortFlagDeterministicOutput tests that two pulls of the same remote state produce
+// identical files with --sort, even when the API returns lists in a different order
+func TestPullSortFlagDeterministicOutput(t *testing.T) {
+	pull := func(role models.Role) []byte {
+		outputDir := t.TempDir()
+		cmd := NewPullCommand(NewMockClient(&MockAPICalls{}, []models.Role{role}))
+		if err := cmd.Flags().Set("sort", "true"); err != nil {
+			t.Fatalf("Failed to set sort flag: %v", err)
+		}
+
+		var stdout bytes.Buffer
+		cmd.SetOut(&stdout)
+		cmd.SetErr(&stdout)
+		cmd.SetArgs([]string{outputDir})
+		if err := cmd.Execute(); err != nil {
+			t.Fatalf("Pull failed: %v", err)
+		}
+
+		content, err := os.ReadFile(filepath.Join(outputDir, role.Name+".yaml"))
+		if err != nil {
+			t.Fatalf("Failed to read pulled file: %v", err)
+		}
+		return content
+	}
+
+	first := pull(models.Role{
+		ID:        "role-1",
+		Name:      "support",
+		Resources: models.Resources{Allowed: []string{"team/read", "kots/app/*/read"}, Denied: []string{"kots/app/*/write", "kots/app/*/delete"}},
+		Members:   []string{"zoe@example.com", "adam@example.com"},
+	})
+	second := pull(models.Role{
+		ID:        "role-1",
+		Name:      "support",
+		Resources: models.Resources{Allowed: []string{"kots/app/*/read", "team/read"}, Denied: []string{"kots/app/*/delete", "kots/app/*/write"}},
+		Members:   []string{"adam@example.com", "zoe@example.com"},
+	})
+
+	if !bytes.Equal(first, second) {
+		t.Errorf("Expected identical files from both pulls, got:\n%s\nvs\n%s", first, second)
+	}
+}
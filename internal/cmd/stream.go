@@ -0,0 +1,28 @@
+package cmd
+
+import (
+	"encoding/json"
+	"io"
+
+	"replbac/internal/sync"
+)
+
+// operationStream writes each role operation as its own JSON object on its own line
+// (NDJSON) as soon as it is known, so consumers of very large plans can process them
+// incrementally and nothing is buffered
+type operationStream struct {
+	encoder *json.Encoder
+	err     error // First write error, after which nothing more is written
+}
+
+// newOperationStream creates a stream writing to w
+func newOperationStream(w io.Writer) *operationStream {
+	return &operationStream{encoder: json.NewEncoder(w)}
+}
+
+// Write emits record as one line
+func (s *operationStream) Write(record sync.OperationRecord) {
+	if s.err == nil {
+		s.err = s.encoder.Encode(record)
+	}
+}
@@ -0,0 +1,99 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+
+	"replbac/internal/models"
+	"replbac/internal/sync"
+)
+
+// TestJSONStreamOutput tests that --output json-stream writes stdout as NDJSON, one
+// independently parseable record per role operation
+func TestJSONStreamOutput(t *testing.T) {
+	remote := []models.Role{
+		{ID: "1", Name: "viewer", Resources: models.Resources{Allowed: []string{"kots/app/*/read"}, Denied: []string{}}},
+		{ID: "2", Name: "legacy", Resources: models.Resources{Allowed: []string{"**/*"}, Denied: []string{}}},
+	}
+
+	tests := []struct {
+		name        string
+		local       []string
+		args        []string
+		expectError bool
+		expected    []sync.OperationRecord
+	}{
+		{
+			name:  "applied operations",
+			local: []string{"admin", "viewer"},
+			args:  []string{"--delete", "--force"},
+			expected: []sync.OperationRecord{
+				{Operation: "create", Role: "admin", Status: "applied"},
+				{Operation: "update", Role: "viewer", Status: "applied", Reason: "allowed differs (remote missing 'kots/app/*/write')"},
+				{Operation: "delete", Role: "legacy", Status: "applied"},
+			},
+		},
+		{
+			name:  "dry run reports planned operations",
+			local: []string{"admin"},
+			args:  []string{"--dry-run"},
+			expected: []sync.OperationRecord{
+				{Operation: "create", Role: "admin", Status: "planned"},
+			},
+		},
+		{
+			name:        "failed operation is the last record",
+			local:       []string{"admin", "failing"},
+			expectError: true,
+			expected: []sync.OperationRecord{
+				{Operation: "create", Role: "admin", Status: "applied"},
+				{Operation: "create", Role: "failing", Status: "failed", Error: "failed to create role 'failing': API error"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tempDir := t.TempDir()
+			for _, name := range tt.local {
+				role := models.Role{Name: name, Resources: models.Resources{Allowed: []string{"kots/app/*/read", "kots/app/*/write"}, Denied: []string{}}}
+				if err := createTestRoleFile(tempDir, role); err != nil {
+					t.Fatalf("Failed to create role file: %v", err)
+				}
+			}
+
+			cmd := NewSyncCommandWithOptions(NewMockClient(&MockAPICalls{}, append([]models.Role{}, remote...)), func(cmd *cobra.Command) {
+				cmd.Flags().String("output", "text", "output format")
+			})
+			var stdout, stderr bytes.Buffer
+			cmd.SetOut(&stdout)
+			cmd.SetErr(&stderr)
+			cmd.SetArgs(append([]string{tempDir, "--output", "json-stream"}, tt.args...))
+			err := cmd.Execute()
+			if tt.expectError != (err != nil) {
+				t.Fatalf("Expected error: %v, got: %v", tt.expectError, err)
+			}
+
+			lines := strings.Split(strings.TrimSuffix(stdout.String(), "\n"), "\n")
+			if len(lines) != len(tt.expected) {
+				t.Fatalf("Expected %d lines, got %d:\n%s", len(tt.expected), len(lines), stdout.String())
+			}
+			for i, line := range lines {
+				var record sync.OperationRecord
+				if err := json.Unmarshal([]byte(line), &record); err != nil {
+					t.Fatalf("Line %d is not a JSON object: %q: %v", i+1, line, err)
+				}
+				if record != tt.expected[i] {
+					t.Errorf("Line %d = %+v, want %+v", i+1, record, tt.expected[i])
+				}
+			}
+			if !strings.Contains(stderr.String(), "Synchronizing roles from directory") {
+				t.Errorf("Expected the human-readable output on stderr, got:\n%s", stderr.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,53 @@
+package cmd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+
+	"replbac/internal/models"
+)
+
+// TestStrictResourcesWarnings tests that --strict-resources warns about nonconforming entries without blocking
+func TestStrictResourcesWarnings(t *testing.T) {
+	for _, enabled := range []bool{false, true} {
+		tempDir := t.TempDir()
+		role := models.Role{Name: "viewer", Resources: models.Resources{Allowed: []string{"kots/app/read", "kots/app/*/read"}}}
+		if err := createTestRoleFile(tempDir, role); err != nil {
+			t.Fatalf("Failed to create role file: %v", err)
+		}
+
+		mockCalls := &MockAPICalls{}
+		cmd := NewSyncCommandWithOptions(NewMockClient(mockCalls, []models.Role{}), func(cmd *cobra.Command) {
+			cmd.Flags().Bool("strict-resources", false, "check resources against the resource grammar")
+		})
+		if enabled {
+			if err := cmd.Flags().Set("strict-resources", "true"); err != nil {
+				t.Fatalf("Failed to set strict-resources flag: %v", err)
+			}
+		}
+		var stdout bytes.Buffer
+		cmd.SetOut(&stdout)
+		cmd.SetErr(&bytes.Buffer{})
+		cmd.SetArgs([]string{tempDir})
+
+		if err := cmd.Execute(); err != nil {
+			t.Fatalf("Expected warnings not to block the sync, got: %v", err)
+		}
+
+		output := stdout.String()
+		hasWarning := strings.Contains(output, "Warning: role viewer: allowed resource 'kots/app/read'") &&
+			strings.Contains(output, "did you mean 'kots/app/*/read'?")
+		if hasWarning != enabled {
+			t.Errorf("Expected warning: %v, got output:\n%s", enabled, output)
+		}
+		if strings.Contains(output, "'kots/app/*/read' does not") {
+			t.Errorf("Did not expect a warning for a conforming resource, got:\n%s", output)
+		}
+		if len(mockCalls.CreateCalls) != 1 {
+			t.Errorf("Expected 1 create call, got %d", len(mockCalls.CreateCalls))
+		}
+	}
+}
@@ -0,0 +1,111 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+
+	"replbac/internal/models"
+)
+
+// TestSummaryJSON tests that --summary-json ends stderr with a parseable REPLBAC_RESULT line
+func TestSummaryJSON(t *testing.T) {
+	tests := []struct {
+		name           string
+		flags          []string
+		failing        bool
+		expectStatus   string
+		expectCreated  int
+		expectDryRun   bool
+		expectNoFooter bool
+	}{
+		{
+			name:          "successful sync",
+			flags:         []string{"summary-json"},
+			expectStatus:  "success",
+			expectCreated: 2,
+		},
+		{
+			name:          "dry run reports planned changes",
+			flags:         []string{"summary-json", "dry-run"},
+			expectStatus:  "success",
+			expectCreated: 2,
+			expectDryRun:  true,
+		},
+		{
+			name:         "failed sync",
+			flags:        []string{"summary-json"},
+			failing:      true,
+			expectStatus: "error",
+		},
+		{
+			name:           "no footer without the flag",
+			expectNoFooter: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tempDir := t.TempDir()
+			for _, name := range []string{"viewer", "editor"} {
+				role := models.Role{Name: name, Resources: models.Resources{Allowed: []string{"kots/app/*/read"}}}
+				if err := createTestRoleFile(tempDir, role); err != nil {
+					t.Fatalf("Failed to create role file: %v", err)
+				}
+			}
+
+			client := &MockClient{calls: &MockAPICalls{}, roles: []models.Role{}, shouldError: tt.failing}
+			cmd := NewSyncCommandWithOptions(client, func(cmd *cobra.Command) {
+				cmd.Flags().Bool("summary-json", false, "print a structured result line")
+			})
+			for _, flag := range tt.flags {
+				if err := cmd.Flags().Set(flag, "true"); err != nil {
+					t.Fatalf("Failed to set %s flag: %v", flag, err)
+				}
+			}
+			var stdout, stderr bytes.Buffer
+			cmd.SetOut(&stdout)
+			cmd.SetErr(&stderr)
+			cmd.SetArgs([]string{tempDir})
+
+			err := cmd.Execute()
+			if tt.failing != (err != nil) {
+				t.Fatalf("Expected error: %v, got: %v", tt.failing, err)
+			}
+			if !strings.Contains(stdout.String(), "Synchronizing roles from directory") {
+				t.Errorf("Expected the human-readable output to be unchanged, got:\n%s", stdout.String())
+			}
+
+			var resultLines []string
+			for _, line := range strings.Split(stderr.String(), "\n") {
+				if strings.HasPrefix(line, "REPLBAC_RESULT=") {
+					resultLines = append(resultLines, line)
+				}
+			}
+			if tt.expectNoFooter {
+				if strings.Contains(stderr.String(), "REPLBAC_RESULT=") {
+					t.Errorf("Did not expect a result line, got:\n%s", stderr.String())
+				}
+				return
+			}
+			if len(resultLines) != 1 {
+				t.Fatalf("Expected exactly one result line, got:\n%s", stderr.String())
+			}
+			line := resultLines[0]
+
+			var summary resultSummary
+			if err := json.Unmarshal([]byte(strings.TrimPrefix(line, "REPLBAC_RESULT=")), &summary); err != nil {
+				t.Fatalf("Failed to parse result line %q: %v", line, err)
+			}
+			if summary.Status != tt.expectStatus || summary.Created != tt.expectCreated || summary.DryRun != tt.expectDryRun {
+				t.Errorf("Unexpected result: %+v", summary)
+			}
+			if tt.failing && summary.Error == "" {
+				t.Errorf("Expected the error message in the result, got %+v", summary)
+			}
+		})
+	}
+}
@@ -0,0 +1,64 @@
+package cmd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+
+	"replbac/internal/models"
+)
+
+// TestSyncSummaryOnlyRequiresDiff tests that sync rejects --summary-only without --diff
+// rather than silently ignoring it
+func TestSyncSummaryOnlyRequiresDiff(t *testing.T) {
+	tests := []struct {
+		name        string
+		flags       []string
+		expectError string
+	}{
+		{
+			name:  "with --diff",
+			flags: []string{"dry-run", "diff", "summary-only"},
+		},
+		{
+			name:        "without --diff",
+			flags:       []string{"dry-run", "summary-only"},
+			expectError: "--summary-only requires --diff",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tempDir := t.TempDir()
+			role := models.Role{Name: "viewer", Resources: models.Resources{Allowed: []string{"kots/app/*/read"}, Denied: []string{}}}
+			if err := createTestRoleFile(tempDir, role); err != nil {
+				t.Fatalf("Failed to create role file: %v", err)
+			}
+
+			cmd := NewSyncCommandWithOptions(NewMockClient(&MockAPICalls{}, []models.Role{}), func(cmd *cobra.Command) {
+				cmd.Flags().Bool("summary-only", false, "show per-role change counts")
+			})
+			for _, flag := range tt.flags {
+				if err := cmd.Flags().Set(flag, "true"); err != nil {
+					t.Fatalf("Failed to set %s flag: %v", flag, err)
+				}
+			}
+			cmd.SetOut(&bytes.Buffer{})
+			cmd.SetErr(&bytes.Buffer{})
+			cmd.SetArgs([]string{tempDir})
+
+			err := cmd.Execute()
+			if tt.expectError == "" {
+				if err != nil {
+					t.Fatalf("Unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.expectError) {
+				t.Fatalf("Expected error containing %q, got %v", tt.expectError, err)
+			}
+		})
+	}
+}
@@ -1,16 +1,25 @@
 package cmd
 
 import (
-	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"net/http"
+	"net/url"
 	"os"
+	"path/filepath"
+	"sort"
 	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 
 	"replbac/internal/api"
 	"replbac/internal/logging"
 	"replbac/internal/models"
+	"replbac/internal/report"
 	"replbac/internal/roles"
 	"replbac/internal/sync"
 )
@@ -21,17 +30,62 @@ var (
 	syncDelete   bool
 	syncForce    bool
 	syncNoInvite bool
+	syncTimings  bool
+	syncFailSkip bool
+	syncSummary  bool
+	syncSoftDel  bool
+	syncReport   string
+	syncBroad    bool
+	syncStrict   bool
+	syncResume   bool
+	syncFoldCase bool
+	syncPromptTO time.Duration
+	syncNoMember bool
+	syncEmptyOK  bool
+	syncExplain  bool
+	syncSkipMemb bool
+	syncParallel int
+	syncMembOnly bool
+	syncShowName bool
+	syncImpact   bool
+	syncPlanOut  string
+	syncSlack    string
+	syncNotifyOn string
+	syncOutput   string
+	syncVerify   bool
+	syncMaxRes   int
+	syncDriftPct int
+	syncStrictRs bool
+	syncSumJSON  bool
+	syncSkipOut  string
+	syncExpectPl string
+	syncNormDeny bool
+	syncMergeDup bool
+	syncStrictMb bool
+	syncInvRate  float64
 	verbose      bool
 	debug        bool
 )
 
+// DefaultInviteRate is how many invitations per second sync and assign send at most,
+// so that onboarding a large team does not flood people with emails
+const DefaultInviteRate = 1.0
+
 // syncCmd represents the sync command
 var syncCmd = &cobra.Command{
-	Use:   "sync [directory]",
+	Use:   "sync [directory...]",
 	Short: "Synchronize local role files to Replicated API",
 	Long: `Sync reads role definitions from local YAML files and synchronizes them
 with the Replicated platform. By default, it will process all YAML files
-in the current directory recursively.
+in the current directory recursively. Multiple directories can be given
+and their roles are merged into a single set. A role name defined in more
+than one file is an error, unless --merge-duplicates is given to combine the
+files' allowed, denied, and members lists into one role.
+
+A directory can also be a git reference, git::URL[//subdir][?ref=REF], such
+as git::https://github.com/org/repo//roles?ref=v1.2.3. The repository is
+shallow-cloned at the given branch or tag into a temporary directory, which
+is removed when the sync finishes.
 
 The sync operation will:
 • Read all role YAML files from the specified directory
@@ -50,7 +104,7 @@ for enhanced reporting with detailed diffs showing exactly what will change.
 Environment Variables:
   This command supports all global environment variables. 
   See 'replbac --help' for full environment variable documentation.`,
-	Args: cobra.MaximumNArgs(1),
+	Args: cobra.ArbitraryArgs,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		// If diff is enabled, enable dry-run too
 		effectiveDryRun := syncDryRun || syncDiff
@@ -68,7 +122,40 @@ func init() {
 	syncCmd.Flags().BoolVar(&syncDiff, "diff", false, "preview changes with detailed diffs (implies --dry-run)")
 	syncCmd.Flags().BoolVar(&syncDelete, "delete", false, "delete remote roles not present in local files (default: false)")
 	syncCmd.Flags().BoolVar(&syncForce, "force", false, "skip confirmation prompts (requires --delete)")
+	syncCmd.Flags().BoolVar(&syncSoftDel, "soft-delete", false, "disable removed roles instead of deleting them: rename with a disabled- prefix and deny all resources (requires --delete)")
 	syncCmd.Flags().BoolVar(&syncNoInvite, "no-invite", false, "disable automatic invitation of missing members (default: auto-invite enabled)")
+	syncCmd.Flags().BoolVar(&syncStrictMb, "strict-members", false, "fail the sync if a role lists a member who is not already on the team, instead of inviting them")
+	syncCmd.Flags().Float64Var(&syncInvRate, "invite-rate", DefaultInviteRate, "send at most this many invitations per second (0 for no limit)")
+	syncCmd.Flags().BoolVar(&syncExplain, "explain", false, "show why each role will be created, updated, or deleted, naming the fields that differ")
+	syncCmd.Flags().BoolVar(&syncSummary, "summary-only", false, "with --diff, show per-role change counts instead of every added or removed entry")
+	syncCmd.Flags().BoolVar(&syncFailSkip, "fail-on-skip", false, "abort without contacting the API if any role file is invalid (default: skip invalid files)")
+	syncCmd.Flags().BoolVar(&syncBroad, "warn-broad", false, "warn about roles that allow '*' or '**/*' with no denied resources (always on with --dry-run)")
+	syncCmd.Flags().BoolVar(&syncStrictRs, "strict-resources", false, "warn about allowed or denied entries that do not follow the Replicated resource grammar, such as kots/app/read")
+	syncCmd.Flags().BoolVar(&syncStrict, "strict", false, "abort the sync if any role allows '*' or '**/*' with no denied resources")
+	syncCmd.Flags().BoolVar(&syncResume, "resume", false, "resume an interrupted sync, skipping operations its checkpoint records as completed")
+	syncCmd.Flags().BoolVar(&syncFoldCase, "case-insensitive-names", false, "match local and remote role names regardless of case")
+	syncCmd.Flags().DurationVar(&syncPromptTO, "prompt-timeout", 0, "treat an unanswered confirmation prompt as \"no\" after this long, e.g. 30s (default: wait indefinitely)")
+	syncCmd.Flags().BoolVar(&syncEmptyOK, "allow-empty", false, "allow --delete to run when no role files are found, deleting every remote role")
+	syncCmd.Flags().BoolVar(&syncSkipMemb, "skip-members-on-error", false, "sync roles but skip member sync with a warning if team members cannot be read (always on when reading them is forbidden)")
+	syncCmd.Flags().BoolVar(&syncMembOnly, "members-only", false, "sync only the membership of roles that already exist remotely, without creating, updating, or deleting roles")
+	syncCmd.Flags().BoolVar(&syncShowName, "show-names", false, "with --diff, show each member's name and username from the API next to their email")
+	syncCmd.Flags().BoolVar(&syncImpact, "impact", false, "with --diff, show how each affected member's access changes, naming the resources they gain or lose")
+	syncCmd.Flags().BoolVar(&syncNormDeny, "normalize-denies", false, "before comparing, drop denied entries that no allowed entry can reach, so roles differing only in them compare equal")
+	syncCmd.Flags().BoolVar(&syncMergeDup, "merge-duplicates", false, "merge role files that share a role name, unioning their allowed, denied, and members lists (default: duplicate names are an error)")
+	syncCmd.Flags().BoolVar(&syncNoMember, "no-members", false, "sync role definitions only, ignoring members entirely (no assignment, invites, or member removal)")
+	syncCmd.Flags().IntVar(&syncMaxRes, "max-resources-per-role", roles.DefaultMaxResourcesPerRole, "abort before any change is made if a role has more allowed and denied entries combined than this, warning at 90% of it (0 disables the check)")
+	syncCmd.Flags().IntVar(&syncDriftPct, "drift-guard-percent", 0, "abort before applying if the roles created and deleted exceed this percentage of the remote roles, unless --force is given (0 disables the check)")
+	syncCmd.Flags().IntVar(&syncParallel, "parallel-files", 1, "number of role files to parse concurrently, for large directories")
+	syncCmd.Flags().BoolVar(&syncSumJSON, "summary-json", false, "after the normal output, print the result as a single REPLBAC_RESULT={...} JSON line to stderr for scripts")
+	syncCmd.Flags().StringVar(&syncSkipOut, "skipped-out", "", "write the role files skipped as invalid to this file as a JSON array of {path, reason}, empty if none were skipped")
+	syncCmd.Flags().StringVar(&syncExpectPl, "expect-plan", "", "fail before applying unless the plan has exactly the operations in this file, a JSON array or json-stream lines of {operation, role}, in any order")
+	syncCmd.Flags().StringVar(&syncPlanOut, "plan-out", "", "write the remote roles as they would be after the sync to this directory as role files, without applying changes (implies --dry-run)")
+	syncCmd.Flags().BoolVar(&syncVerify, "verify", false, "after applying, fetch the remote roles again and fail if they still differ from the local roles")
+	syncCmd.Flags().StringVar(&syncOutput, "output", "text", "output format: text, or json-stream to write each role operation to stdout as a JSON line")
+	syncCmd.Flags().StringVar(&syncSlack, "slack-webhook", "", "post a summary of the sync to this Slack incoming webhook URL when it finishes or fails (env: REPLBAC_SLACK_WEBHOOK)")
+	syncCmd.Flags().StringVar(&syncNotifyOn, "notify-on", "apply", "which runs --slack-webhook reports: apply, or dry-run to also report previews")
+	syncCmd.Flags().StringVar(&syncReport, "report-file", "", "append a JSON line describing the planned and applied changes to this file")
+	syncCmd.Flags().BoolVar(&syncTimings, "timings", false, "print a per-phase timing breakdown to stderr when the sync completes")
 	syncCmd.Flags().BoolVar(&verbose, "verbose", false, "enable info-level logging to stderr (progress and results)")
 	syncCmd.Flags().BoolVar(&debug, "debug", false, "enable debug-level logging to stderr (detailed operation info)")
 }
@@ -99,6 +186,10 @@ func RunSyncCommand(cmd *cobra.Command, args []string, config models.Config, dry
 	} else {
 		logger = logging.NewLogger(cmd.ErrOrStderr(), verbose)
 	}
+	if getBoolFlag(cmd, "debug-http") {
+		logger.EnableHTTPTrace()
+	}
+	teeLogFile(logger)
 
 	// Pre-flight validation with logging
 	logger.Debug("validating configuration")
@@ -107,60 +198,189 @@ func RunSyncCommand(cmd *cobra.Command, args []string, config models.Config, dry
 		return HandleConfigurationError(cmd, err)
 	}
 
-	// Determine target directory
-	targetDir := "."
-	if len(args) > 0 {
-		targetDir = args[0]
-	}
-
-	logger.Debug("validating directory access: %s", targetDir)
-	// Validate directory access
-	if err := ValidateDirectoryAccess(targetDir); err != nil {
-		logger.Error("directory access validation failed: %v", err)
-		return HandleFileSystemError(cmd, err, targetDir)
+	// Validate access to every target directory
+	for _, targetDir := range syncDirectories(args) {
+		if roles.IsGitSource(targetDir) {
+			continue
+		}
+		logger.Debug("validating directory access: %s", targetDir)
+		if err := ValidateDirectoryAccess(targetDir); err != nil {
+			logger.Error("directory access validation failed: %v", err)
+			return HandleFileSystemError(cmd, err, targetDir)
+		}
 	}
 
 	// Create API client
 	logger.Debug("creating API client")
-	client, err := api.NewClient(models.ReplicatedAPIEndpoint, config.APIToken, logger)
+	client, err := newAPIClient(config, logger, api.DefaultMaxRetries)
 	if err != nil {
 		logger.Error("failed to create API client: %v", err)
 		return HandleConfigurationError(cmd, fmt.Errorf("failed to create API client: %w", err))
 	}
 
 	// Use the enhanced logging version
-	return RunSyncCommandWithLogging(cmd, args, client, dryRun, diff, delete, force, autoInvite, logger, config)
+	return RunSyncCommandWithLogging(cmd, args, restrictClient(client, config), dryRun, diff, delete, force, autoInvite, logger, config)
 }
 
 // RunSyncCommandWithLogging implements sync with enhanced logging and user feedback
-func RunSyncCommandWithLogging(cmd *cobra.Command, args []string, client api.ClientInterface, dryRun bool, diff bool, delete bool, force bool, autoInvite bool, logger *logging.Logger, config models.Config) error {
-	// Determine roles directory
-	targetDir := "."
-	if len(args) > 0 {
-		targetDir = args[0]
+func RunSyncCommandWithLogging(cmd *cobra.Command, args []string, client api.ClientInterface, dryRun bool, diff bool, delete bool, force bool, autoInvite bool, logger *logging.Logger, config models.Config) (retErr error) {
+	// Determine roles directories
+	targetDirs := syncDirectories(args)
+
+	// Writing the projected remote state is a preview, so nothing is applied
+	planOut := getStringFlag(cmd, "plan-out")
+	if planOut != "" {
+		dryRun = true
 	}
 
-	cmd.Printf("Synchronizing roles from directory: %s\n", targetDir)
-	logger.Debug("sync operation starting: target directory: %s, dry-run: %v", targetDir, dryRun)
+	// With json-stream output, stdout carries only the operation records and the
+	// human-readable output moves to stderr
+	var stream *operationStream
+	switch output := getStringFlag(cmd, "output"); output {
+	case "", "text":
+	case "json-stream":
+		stream = newOperationStream(cmd.OutOrStdout())
+		cmd.SetOut(cmd.ErrOrStderr())
+		defer func() {
+			if stream.err != nil {
+				logger.Error("failed to write operation stream: %v", stream.err)
+				if retErr == nil {
+					retErr = fmt.Errorf("failed to write operation stream: %w", stream.err)
+				}
+			}
+		}()
+	default:
+		return fmt.Errorf("invalid --output %q: must be text or json-stream", output)
+	}
+
+	// Post a summary of real applies to Slack, and of dry runs too if requested
+	slackWebhook := getStringFlag(cmd, "slack-webhook")
+	if slackWebhook == "" {
+		slackWebhook = strings.TrimSpace(os.Getenv(report.SlackWebhookEnv))
+	}
+	notify := false
+	var slackClient *http.Client
+	if slackWebhook != "" {
+		// The URL is a secret, so it is not repeated in the error
+		if parsed, err := url.Parse(slackWebhook); err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") {
+			return errors.New("--slack-webhook must be an HTTP or HTTPS URL")
+		}
+		notifyOn := getStringFlag(cmd, "notify-on")
+		if notifyOn != "" && notifyOn != "apply" && notifyOn != "dry-run" {
+			return fmt.Errorf("invalid --notify-on %q: must be apply or dry-run", notifyOn)
+		}
+		notify = !dryRun || notifyOn == "dry-run"
+
+		// Post through the same proxy and with the same trusted certificates as the API
+		transport, err := api.NewTransport(transportOptions(config))
+		if err != nil {
+			return HandleConfigurationError(cmd, fmt.Errorf("failed to configure Slack notifications: %w", err))
+		}
+		slackClient = &http.Client{Transport: transport}
+	}
+
+	// Record an audit entry describing this run, including failed runs, and append it
+	// to the report file and post it to Slack
+	var auditEntry *report.Entry
+	reportFile := getStringFlag(cmd, "report-file")
+	if reportFile != "" || notify {
+		auditEntry = report.NewEntry(targetDirs, dryRun)
+		defer func() {
+			auditEntry.Finish(retErr)
+			if notify {
+				if err := report.NotifySlack(slackClient, slackWebhook, auditEntry); err != nil {
+					cmd.PrintErrf("Warning: failed to send sync notification to Slack: %v\n", err)
+					logger.Warn("Slack notification failed: %v", err)
+				}
+			}
+			if reportFile == "" {
+				return
+			}
+			if err := report.Append(reportFile, auditEntry); err != nil {
+				logger.Error("failed to write report file: %v", err)
+				if retErr == nil {
+					retErr = err
+				}
+			}
+		}()
+	}
+
+	// Finish with a single machine-readable line, registered after the report so it
+	// reflects a failure to write the report file
+	var result sync.ExecutionResult
+	var skipped []roles.SkippedFile
+	cancelled := false
+	if getBoolFlag(cmd, "summary-json") {
+		defer func() {
+			writeResultLine(cmd, newResultSummary(result, skipped, dryRun, cancelled, retErr), logger)
+		}()
+	}
+
+	if len(targetDirs) == 1 {
+		cmd.Printf("Synchronizing roles from directory: %s\n", targetDirs[0])
+	} else {
+		cmd.Printf("Synchronizing roles from directories: %s\n", strings.Join(targetDirs, ", "))
+	}
+	logger.Debug("sync operation starting: target directories: %s, dry-run: %v", strings.Join(targetDirs, ", "), dryRun)
 
 	if dryRun {
 		cmd.Println("DRY RUN: No changes will be applied")
 		logger.Debug("running in dry-run mode")
 	}
 
-	// Load local roles
-	logger.Debug("loading roles from directory: %s", targetDir)
+	membersOnly := getBoolFlag(cmd, "members-only")
+	if membersOnly && getBoolFlag(cmd, "no-members") {
+		return fmt.Errorf("--members-only and --no-members cannot be used together")
+	}
+	if getBoolFlag(cmd, "summary-only") && !diff {
+		return fmt.Errorf("--summary-only requires --diff")
+	}
 
-	loadResult, err := roles.LoadRolesFromDirectoryWithDetails(targetDir)
+	// Build the configured transforms before anything is loaded so mistakes fail fast
+	pipeline, err := roles.NewPipeline(config.Transforms)
+	if err != nil {
+		return HandleConfigurationError(cmd, fmt.Errorf("invalid transforms configuration: %w", err))
+	}
+
+	// Check out git references so their roles load like any other directory
+	roleDirs, removeCheckouts, err := checkoutGitSources(cmd, targetDirs, logger)
+	if err != nil {
+		logger.Error("failed to check out git reference: %v", err)
+		return err
+	}
+	defer removeCheckouts()
+
+	// Fetch remote roles while local roles load; with --fail-on-skip the fetch waits
+	// until the local files are known to be valid so that no API call is made otherwise
+	failOnSkip := getBoolFlag(cmd, "fail-on-skip")
+	// Local roles are not loaded yet, so members are skipped only when they are ignored entirely
+	includeMembers := !getBoolFlag(cmd, "no-members")
+	var waitForRemoteRoles func() ([]models.Role, error)
+	if !failOnSkip {
+		waitForRemoteRoles = fetchRemoteRoles(client, includeMembers, logger)
+	}
+
+	// Load local roles
+	var loadResult *roles.LoadResult
+	var failedDir string
+	err = logger.TimedOperation("load local roles", func() error {
+		var err error
+		loadResult, failedDir, err = loadRolesFromDirectories(cmd, roleDirs, logger)
+		return err
+	})
 	if err != nil {
 		logger.Error("failed to load roles from directory: %v", err)
+		// Let the in-flight fetch finish so nothing outlives the command
+		if waitForRemoteRoles != nil {
+			_, _ = waitForRemoteRoles()
+		}
 		if strings.Contains(err.Error(), "permission denied") {
 			permErr := &PermissionError{
-				Path:     targetDir,
+				Path:     failedDir,
 				Message:  "permission denied",
 				Guidance: "Check directory permissions and ensure read access",
 			}
-			return HandleFileSystemError(cmd, permErr, targetDir)
+			return HandleFileSystemError(cmd, permErr, failedDir)
 		}
 		return fmt.Errorf("failed to load local roles: %w", err)
 	}
@@ -180,29 +400,140 @@ func RunSyncCommandWithLogging(cmd *cobra.Command, args []string, client api.Cli
 		cmd.Printf("Help: Check your YAML files for proper formatting and structure\n")
 	}
 
-	localRoles := loadResult.Roles
+	// The structured list names each file with its directory, so CI can point at it;
+	// with several directories the paths already include it
+	skipped = make([]roles.SkippedFile, 0, len(loadResult.SkippedFiles))
+	for _, file := range loadResult.SkippedFiles {
+		if len(roleDirs) == 1 {
+			file.Path = filepath.Join(roleDirs[0], file.Path)
+		}
+		skipped = append(skipped, file)
+	}
+	if skippedOut := getStringFlag(cmd, "skipped-out"); skippedOut != "" {
+		if err := writeSkippedFiles(skippedOut, skipped); err != nil {
+			if waitForRemoteRoles != nil {
+				_, _ = waitForRemoteRoles()
+			}
+			return err
+		}
+	}
+
+	// Abort before contacting the API if strict loading was requested
+	if failOnSkip && len(loadResult.SkippedFiles) > 0 {
+		logger.Error("aborting sync: %d role file(s) were skipped", len(loadResult.SkippedFiles))
+		return skippedFilesError(loadResult.SkippedFiles)
+	}
+
+	// An empty local set with --delete would delete every remote role, which almost
+	// always means sync was pointed at the wrong directory
+	if delete && len(loadResult.Roles) == 0 && !getBoolFlag(cmd, "allow-empty") {
+		if waitForRemoteRoles != nil {
+			_, _ = waitForRemoteRoles()
+		}
+		logger.Error("aborting sync: no roles loaded and deletions are enabled")
+		return fmt.Errorf("no role files found in %s; refusing to delete all remote roles (pass --allow-empty to override)", strings.Join(targetDirs, ", "))
+	}
+
+	localRoles, err := transformRoles(cmd, pipeline, loadResult.Roles, logger)
+	if err != nil {
+		if waitForRemoteRoles != nil {
+			_, _ = waitForRemoteRoles()
+		}
+		return err
+	}
+
+	// Roles with more resource entries than the API accepts would fail mid-sync with an
+	// unclear server error, so they stop the sync here
+	if err := checkRoleSizes(cmd, localRoles, getIntFlag(cmd, "max-resources-per-role"), logger); err != nil {
+		if waitForRemoteRoles != nil {
+			_, _ = waitForRemoteRoles()
+		}
+		return err
+	}
 
 	// Get remote roles with progress feedback
 	if len(localRoles) > 0 {
 		logger.Debug("synchronizing with remote API")
 	}
-	logger.Debug("fetching remote roles from API")
+	if waitForRemoteRoles == nil {
+		waitForRemoteRoles = fetchRemoteRoles(client, includeMembers, logger)
+	}
 
-	remoteRoles, err := client.GetRoles()
+	remoteRoles, err := waitForRemoteRoles()
 	if err != nil {
 		logger.Error("failed to fetch remote roles: %v", err)
 		return HandleSyncError(cmd, fmt.Errorf("failed to get remote roles: %w", err))
 	}
 
 	logger.Debug("fetched %d remote roles", len(remoteRoles))
+
+	// With --no-members, membership is left out of both the comparison and the executed
+	// plan, so the standard Executor is used and no members are assigned, invited, or removed
+	if getBoolFlag(cmd, "no-members") {
+		logger.Debug("ignoring role members (--no-members)")
+		localRoles = withoutMembers(localRoles)
+		remoteRoles = withoutMembers(remoteRoles)
+	}
+	localRoles, remoteRoles = normalizeDenies(cmd, localRoles, remoteRoles, logger)
+
+	// Lint local roles for catch-all grants; on by default in dry-run, blocking only with --strict
+	strict := getBoolFlag(cmd, "strict")
+	if dryRun || strict || getBoolFlag(cmd, "warn-broad") {
+		grants := roles.FindBroadGrants(localRoles)
+		for _, grant := range grants {
+			cmd.Printf("Warning: %s\n", grant)
+			logger.Warn("broad grant: role %s allows %s with no denied resources", grant.Role, grant.Wildcard)
+		}
+		if strict && len(grants) > 0 {
+			logger.Error("aborting sync: %d role(s) grant unrestricted wildcards", len(grants))
+			return fmt.Errorf("aborting sync because %d role(s) grant unrestricted wildcards (--strict)", len(grants))
+		}
+	}
+
+	// Check resource entries against the resource grammar; these only warn, since the
+	// grammar may lag behind resources the API has added
+	if getBoolFlag(cmd, "strict-resources") {
+		for _, issue := range roles.FindResourceIssues(localRoles) {
+			cmd.Printf("Warning: %s\n", issue)
+			logger.Warn("nonconforming resource: role %s %s %s", issue.Role, issue.Field, issue.Resource)
+		}
+	}
+
+	// Roles disabled by an earlier soft delete are treated as not present
+	softDelete := getBoolFlag(cmd, "soft-delete")
+	activeRemoteRoles := remoteRoles
+	if softDelete {
+		activeRemoteRoles = sync.ActiveRoles(remoteRoles)
+		logger.Debug("ignoring %d soft-deleted remote roles", len(remoteRoles)-len(activeRemoteRoles))
+	}
+
 	logger.Debug("comparing roles")
 
-	// Compare roles and generate sync plan
-	plan, err := sync.CompareRoles(localRoles, remoteRoles)
+	// Compare roles and generate sync plan; roles protected in the configuration are never deleted
+	opts := compareOptions(cmd, localRoles, activeRemoteRoles, loadResult.Ignore, logger)
+	opts.Protected = config.ProtectedRoles
+	opts.IgnoreResources = config.IgnoreResources
+	opts.Manage = config.Manage
+	var plan sync.SyncPlan
+	err = logger.TimedOperation("compare roles", func() error {
+		var err error
+		plan, err = sync.CompareRolesWithOptions(localRoles, activeRemoteRoles, opts)
+		return err
+	})
 	if err != nil {
 		logger.Error("failed to compare roles: %v", err)
 		return fmt.Errorf("failed to compare roles: %w", err)
 	}
+	if membersOnly {
+		result, err = syncMembersOnly(cmd, client, plan, localRoles, activeRemoteRoles, opts, dryRun, diff, promptsApproved(force, config), autoInvite, inviteConfirmation(cmd, promptsApproved(force, config), logger), auditEntry, logger)
+		return err
+	}
+	if delete {
+		for _, name := range sync.ProtectedRoles(localRoles, activeRemoteRoles, opts) {
+			cmd.Printf("protected role %s not deleted\n", name)
+			logger.Debug("role %s matches a protected_roles pattern in the configuration", name)
+		}
+	}
 
 	// Remove deletions from plan if delete flag is not set
 	if !delete && len(plan.Deletes) > 0 {
@@ -210,12 +541,61 @@ func RunSyncCommandWithLogging(cmd *cobra.Command, args []string, client api.Cli
 		plan.Deletes = []string{} // Clear deletions
 	}
 
+	// Disable roles instead of deleting them when soft delete is requested
+	softDeletes := 0
+	if softDelete && len(plan.Deletes) > 0 {
+		logger.Debug("converting %d deletions to soft deletes", len(plan.Deletes))
+		softDeletes = len(plan.Deletes)
+		plan, err = sync.ApplySoftDelete(plan, remoteRoles)
+		if err != nil {
+			logger.Error("failed to plan soft deletes: %v", err)
+			return fmt.Errorf("failed to plan soft deletes: %w", err)
+		}
+	}
+
 	logger.Debug("plan generated: %d creates, %d updates, %d deletes", len(plan.Creates), len(plan.Updates), len(plan.Deletes))
+	if auditEntry != nil {
+		auditEntry.RecordPlan(plan)
+	}
+
+	// Fail before anything is applied if the plan differs from the one the caller expects
+	if expectPlan := getStringFlag(cmd, "expect-plan"); expectPlan != "" {
+		if err := checkExpectedPlan(cmd, expectPlan, plan); err != nil {
+			return err
+		}
+	}
+
+	// Checkpoint progress so an interrupted sync can be resumed with --resume
+	var checkpoint *sync.Checkpoint
+	if checkpointPath, err := syncCheckpointPath(targetDirs); err != nil {
+		logger.Debug("checkpointing disabled: %v", err)
+	} else {
+		var completed []string
+		if getBoolFlag(cmd, "resume") {
+			completed, err = resumableOperations(cmd, checkpointPath, plan, logger)
+			if err != nil {
+				return err
+			}
+		}
+		if !dryRun {
+			checkpoint = sync.NewCheckpoint(checkpointPath, plan, completed)
+		}
+	}
+
+	if planOut != "" {
+		projected := sync.ProjectRemote(remoteRoles, plan, rolesHaveMembers(localRoles))
+		if err := writeProjectedRoles(cmd, planOut, projected); err != nil {
+			return err
+		}
+	}
 
 	// Display plan summary
 	if !plan.HasChanges() {
 		cmd.Println("No changes needed")
 		logger.Debug("no changes needed - plan has no changes")
+		if err := checkpoint.Remove(); err != nil {
+			logger.Warn("%v", err)
+		}
 		return nil
 	}
 
@@ -223,53 +603,47 @@ func RunSyncCommandWithLogging(cmd *cobra.Command, args []string, client api.Cli
 	logger.Debug("sync plan: %s", plan.Summary())
 
 	// Display detailed plan
-	if len(plan.Creates) > 0 {
-		cmd.Printf("Will create %d role(s):\n", len(plan.Creates))
-		for _, role := range plan.Creates {
-			cmd.Printf("  - %s\n", role.Name)
-			logger.Debug("will create role: %s", role.Name)
-		}
-	}
+	printPlanSections(cmd, plan, logger)
 
-	if len(plan.Updates) > 0 {
-		cmd.Printf("Will update %d role(s):\n", len(plan.Updates))
-		for _, update := range plan.Updates {
-			cmd.Printf("  - %s\n", update.Name)
-			logger.Debug("will update role: %s", update.Name)
+	if getBoolFlag(cmd, "explain") {
+		cmd.Println("Reasons:")
+		for _, explanation := range sync.ExplainPlan(plan) {
+			cmd.Printf("  %s\n", explanation)
 		}
 	}
 
-	if len(plan.Deletes) > 0 {
-		cmd.Printf("Will delete %d role(s):\n", len(plan.Deletes))
-		for _, roleName := range plan.Deletes {
-			cmd.Printf("  - %s\n", roleName)
-			logger.Debug("will delete role: %s", roleName)
-		}
+	if diff && getBoolFlag(cmd, "impact") {
+		printAccessImpact(cmd, sync.AccessImpact(plan, remoteRoles, rolesHaveMembers(localRoles)))
 	}
 
-	// Ask for confirmation if deletions are planned and not in dry-run mode and not forced
-	if len(plan.Deletes) > 0 && !dryRun && !config.Confirm && !force {
-		cmd.Printf("\nThis operation will permanently delete %d role(s) from the API.\n", len(plan.Deletes))
-		cmd.Print("Do you want to continue? (y/N): ")
+	if err := checkDriftGuard(cmd, plan, len(activeRemoteRoles), getIntFlag(cmd, "drift-guard-percent"), dryRun, force, logger); err != nil {
+		return err
+	}
 
-		reader := bufio.NewReader(os.Stdin)
-		response, err := reader.ReadString('\n')
+	// Ask for confirmation if deletions or soft deletes are planned and not in dry-run mode
+	// and not approved
+	if (len(plan.Deletes) > 0 || softDeletes > 0) && !dryRun {
+		notice := fmt.Sprintf("\nThis operation will permanently delete %d role(s) from the API.\n", len(plan.Deletes))
+		if softDeletes > 0 {
+			notice = fmt.Sprintf("\nThis operation will disable %d role(s), renaming them with the %s prefix and denying all resources.\n", softDeletes, sync.SoftDeletePrefix)
+		}
+		confirmed, err := confirmOperation(cmd, promptsApproved(force, config), notice, "Do you want to continue? (y/N): ", "deletions")
 		if err != nil {
-			return fmt.Errorf("failed to read confirmation: %w", err)
+			return err
 		}
-
-		response = strings.ToLower(strings.TrimSpace(response))
-		if response != "y" && response != "yes" {
+		if !confirmed {
 			cmd.Println("Operation cancelled by user")
 			logger.Debug("sync operation cancelled by user")
+			if auditEntry != nil {
+				auditEntry.MarkCancelled()
+			}
+			cancelled = true
 			return nil
 		}
-		logger.Debug("user confirmed deletion operation")
+		logger.Debug("deletion operation confirmed")
 	}
 
 	// Execute sync plan with timing
-	var result sync.ExecutionResult
-
 	err = logger.TimedOperation("sync execution", func() error {
 		// Check if any roles have members to determine which executor to use
 		hasMembers := rolesHaveMembers(localRoles)
@@ -284,6 +658,17 @@ func RunSyncCommandWithLogging(cmd *cobra.Command, args []string, client api.Cli
 					result = executor.ExecutePlanDryRun(plan)
 				}
 			} else {
+				executor.SetRemoteRoles(activeRemoteRoles)
+				executor.SetUnmanagedRoles(sync.UnmanagedRoles(localRoles, activeRemoteRoles, plan, opts))
+				executor.SetCheckpoint(checkpoint)
+				executor.SetContext(cmd.Context())
+				executor.SetSkipMembersOnError(getBoolFlag(cmd, "skip-members-on-error"))
+				executor.SetStrictMembers(getBoolFlag(cmd, "strict-members"))
+				executor.SetInviteRate(getFloat64Flag(cmd, "invite-rate"))
+				executor.SetInviteConfirmation(inviteConfirmation(cmd, promptsApproved(force, config), logger))
+				if stream != nil {
+					executor.SetOperationObserver(stream.Write)
+				}
 				result = executor.ExecutePlanWithLocalRoles(plan, localRoles)
 			}
 		} else {
@@ -296,27 +681,74 @@ func RunSyncCommandWithLogging(cmd *cobra.Command, args []string, client api.Cli
 					result = executor.ExecutePlanDryRun(plan)
 				}
 			} else {
+				executor.SetCheckpoint(checkpoint)
+				executor.SetContext(cmd.Context())
+				if stream != nil {
+					executor.SetOperationObserver(stream.Write)
+				}
 				result = executor.ExecutePlan(plan)
 			}
 		}
 		return result.Error
 	})
+	if dryRun && stream != nil {
+		for _, record := range sync.PlannedOperations(plan) {
+			stream.Write(record)
+		}
+	}
+	if auditEntry != nil {
+		auditEntry.RecordExecution(result)
+	}
 
 	if err != nil {
+		if checkpoint != nil && len(checkpoint.Completed) > 0 {
+			cmd.Printf("Progress saved: %d operation(s) completed; re-run with --resume to continue\n", len(checkpoint.Completed))
+		}
+		if interrupted := interruptedError(cmd, err); interrupted != nil {
+			return interrupted
+		}
 		syncErr := &SyncError{
 			Operation: "role synchronization",
 			Message:   err.Error(),
 			Guidance:  "Check your API credentials and network connection",
 			Partial:   true,
+			Cause:     err,
 		}
 		return HandleSyncError(cmd, syncErr)
 	}
 
+	if err := checkpoint.Remove(); err != nil {
+		logger.Warn("%v", err)
+	}
+
+	if result.MembersSkipped != nil {
+		cmd.Printf("\nWarning: member sync skipped: %v\n", result.MembersSkipped)
+		cmd.Println("Roles were synced, but no members were assigned, invited, or removed.")
+	}
+
 	// Handle member deletions if needed
 	if !dryRun && result.MemberDeletions != nil && (len(result.MemberDeletions.OrphanedUsers) > 0 || len(result.MemberDeletions.OrphanedInvites) > 0) {
-		if err := confirmAndDeleteMembers(cmd, client, result.MemberDeletions, force, logger); err != nil {
+		deleted, err := confirmAndDeleteMembers(cmd, client, result.MemberDeletions, promptsApproved(force, config), logger)
+		if err != nil {
 			return fmt.Errorf("failed to handle member deletions: %w", err)
 		}
+		if deleted && auditEntry != nil {
+			auditEntry.RecordMemberDeletions(result.MemberDeletions)
+		}
+	}
+
+	// Confirm the remote roles now match the local roles before reporting success
+	if !dryRun && getBoolFlag(cmd, "verify") {
+		if err := verifySync(cmd, client, localRoles, opts, delete, softDelete, logger); err != nil {
+			return err
+		}
+	}
+
+	// Render counts instead of full resource lists when requested
+	if diff && getBoolFlag(cmd, "summary-only") {
+		result.DetailedInfo = sync.DescribePlanSummary(plan, rolesHaveMembers(localRoles))
+	} else if names := memberNames(cmd, remoteRoles); diff && names != nil && result.DetailedInfo != "" {
+		result.DetailedInfo = sync.DescribePlanWithNames(plan, rolesHaveMembers(localRoles), names)
 	}
 
 	// Display execution summary
@@ -327,6 +759,10 @@ func RunSyncCommandWithLogging(cmd *cobra.Command, args []string, client api.Cli
 	}
 	logger.Debug("sync operation completed successfully")
 
+	if getBoolFlag(cmd, "timings") {
+		_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Timings: %s\n", logger.TimingSummary())
+	}
+
 	return nil
 }
 
@@ -362,6 +798,10 @@ func RunSyncCommandWithClientAndInvite(cmd *cobra.Command, args []string, client
 	} else {
 		logger = logging.NewLogger(cmd.ErrOrStderr(), verbose)
 	}
+	if getBoolFlag(cmd, "debug-http") {
+		logger.EnableHTTPTrace()
+	}
+	teeLogFile(logger)
 	// Determine roles directory
 	targetDir := "."
 	if len(args) > 0 {
@@ -460,6 +900,8 @@ func RunSyncCommandWithClientAndInvite(cmd *cobra.Command, args []string, client
 		if dryRun {
 			result = executor.ExecutePlanDryRun(plan)
 		} else {
+			executor.SetRemoteRoles(remoteRoles)
+			executor.SetUnmanagedRoles(sync.UnmanagedRoles(localRoles, remoteRoles, plan, sync.CompareOptions{}))
 			result = executor.ExecutePlanWithLocalRoles(plan, localRoles)
 		}
 	} else {
@@ -479,13 +921,14 @@ func RunSyncCommandWithClientAndInvite(cmd *cobra.Command, args []string, client
 			Message:   result.Error.Error(),
 			Guidance:  "Check your API credentials and network connection",
 			Partial:   true, // Since execution failed, no operations completed
+			Cause:     result.Error,
 		}
 		return HandleSyncError(cmd, syncErr)
 	}
 
 	// Handle member deletions if needed
 	if !dryRun && result.MemberDeletions != nil && (len(result.MemberDeletions.OrphanedUsers) > 0 || len(result.MemberDeletions.OrphanedInvites) > 0) {
-		if err := confirmAndDeleteMembers(cmd, client, result.MemberDeletions, force, logger); err != nil {
+		if _, err := confirmAndDeleteMembers(cmd, client, result.MemberDeletions, force, logger); err != nil {
 			return fmt.Errorf("failed to handle member deletions: %w", err)
 		}
 	}
@@ -496,6 +939,435 @@ func RunSyncCommandWithClientAndInvite(cmd *cobra.Command, args []string, client
 	return nil
 }
 
+// fetchRemoteRoles starts fetching remote roles in the background and returns a function
+// that waits for and returns the result. Team members are only read if includeMembers is true.
+func fetchRemoteRoles(client api.ClientInterface, includeMembers bool, logger *logging.Logger) func() ([]models.Role, error) {
+	type fetchResult struct {
+		roles []models.Role
+		err   error
+	}
+	done := make(chan fetchResult, 1)
+
+	logger.Debug("fetching remote roles from API")
+	go func() {
+		var result fetchResult
+		result.err = logger.TimedOperation("fetch remote roles", func() error {
+			var err error
+			result.roles, err = getRemoteRoles(client, includeMembers)
+			return err
+		})
+		done <- result
+	}()
+
+	return func() ([]models.Role, error) {
+		result := <-done
+		return result.roles, result.err
+	}
+}
+
+// getRemoteRoles lists remote roles, skipping the team members request when includeMembers
+// is false and the client supports it. Callers that skip members must not rely on them,
+// since a client that cannot skip them still returns them.
+func getRemoteRoles(client api.ClientInterface, includeMembers bool) ([]models.Role, error) {
+	if lister, ok := client.(api.RoleLister); ok && !includeMembers {
+		return lister.GetRolesWithOptions(false)
+	}
+	return client.GetRoles()
+}
+
+// syncCheckpointPath returns where progress syncing dirs is checkpointed. Checkpoints live in
+// the user cache directory, keyed by the absolute role directories, so they never end up
+// in a roles repository.
+func syncCheckpointPath(dirs []string) (string, error) {
+	cacheDir, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("no cache directory available: %w", err)
+	}
+
+	absDirs := make([]string, 0, len(dirs))
+	for _, dir := range dirs {
+		absDir, err := filepath.Abs(dir)
+		if err != nil {
+			return "", fmt.Errorf("failed to resolve %s: %w", dir, err)
+		}
+		absDirs = append(absDirs, absDir)
+	}
+
+	sum := sha256.Sum256([]byte(strings.Join(absDirs, "\n")))
+	return filepath.Join(cacheDir, "replbac", "checkpoints", hex.EncodeToString(sum[:8])+".json"), nil
+}
+
+// resumableOperations returns the operations completed by an interrupted sync when its
+// checkpoint matches plan. The plan is freshly compared against remote state, so a match
+// confirms that the recorded operations took effect and only the rest remain.
+func resumableOperations(cmd *cobra.Command, checkpointPath string, plan sync.SyncPlan, logger *logging.Logger) ([]string, error) {
+	checkpoint, err := sync.LoadCheckpoint(checkpointPath)
+	if err != nil {
+		logger.Error("failed to load checkpoint: %v", err)
+		return nil, fmt.Errorf("failed to resume sync: %w", err)
+	}
+
+	switch {
+	case checkpoint == nil:
+		cmd.Println("No checkpoint found; running a full sync")
+		return nil, nil
+	case !checkpoint.Matches(plan):
+		cmd.Println("Checkpoint does not match the current plan; running a full sync")
+		logger.Debug("checkpoint plan hash %s does not match current plan", checkpoint.PlanHash)
+		return nil, nil
+	}
+
+	cmd.Printf("Resuming sync: skipping %d operation(s) already completed\n", len(checkpoint.Completed))
+	for _, operation := range checkpoint.Completed {
+		logger.Debug("already completed: %s", operation)
+	}
+	return checkpoint.Completed, nil
+}
+
+// syncDirectories returns the role directories named on the command line, defaulting to the
+// current directory. A directory named more than once, including through another spelling
+// of its path or a symlink, is kept only the first time, so its roles are not loaded twice.
+func syncDirectories(args []string) []string {
+	if len(args) == 0 {
+		return []string{"."}
+	}
+	seen := make(map[string]bool, len(args))
+	dirs := make([]string, 0, len(args))
+	for _, arg := range args {
+		key := filepath.Clean(arg)
+		if resolved, err := filepath.EvalSymlinks(key); err == nil {
+			if absolute, err := filepath.Abs(resolved); err == nil {
+				key = absolute
+			}
+		}
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		dirs = append(dirs, arg)
+	}
+	return dirs
+}
+
+// transformRoles applies the configured transforms to the loaded local roles
+func transformRoles(cmd *cobra.Command, pipeline *roles.Pipeline, localRoles []models.Role, logger *logging.Logger) ([]models.Role, error) {
+	if names := pipeline.Names(); len(names) > 0 {
+		cmd.Printf("Applying transforms: %s\n", strings.Join(names, ", "))
+		logger.Debug("applying %d transform(s) to %d local role(s)", len(names), len(localRoles))
+	}
+	transformed, err := pipeline.Apply(localRoles)
+	if err != nil {
+		logger.Error("failed to transform local roles: %v", err)
+		return nil, fmt.Errorf("failed to transform local roles: %w", err)
+	}
+	return transformed, nil
+}
+
+// checkoutGitSources clones each git reference among dirs (see roles.ParseGitSource) and
+// returns dirs with each reference replaced by its checked-out roles directory. The
+// returned function removes every clone and is safe to call when an error is returned.
+func checkoutGitSources(cmd *cobra.Command, dirs []string, logger *logging.Logger) ([]string, func(), error) {
+	var cleanups []func()
+	removeAll := func() {
+		for _, cleanup := range cleanups {
+			cleanup()
+		}
+	}
+
+	resolved := make([]string, len(dirs))
+	for i, dir := range dirs {
+		if !roles.IsGitSource(dir) {
+			resolved[i] = dir
+			continue
+		}
+
+		source, err := roles.ParseGitSource(dir)
+		if err != nil {
+			removeAll()
+			return nil, func() {}, err
+		}
+		cmd.Printf("Cloning %s\n", source)
+		logger.Debug("cloning %s (subdirectory %q)", source.URL, source.Subdir)
+		checkoutDir, cleanup, err := source.Checkout()
+		if err != nil {
+			removeAll()
+			return nil, func() {}, err
+		}
+		cleanups = append(cleanups, cleanup)
+		resolved[i] = checkoutDir
+	}
+
+	return resolved, removeAll, nil
+}
+
+// loadRolesFromDirectories loads roles from each directory in turn and merges them into a single result.
+// A role name defined in more than one file is an error unless --merge-duplicates is given. On failure the offending directory is returned
+// alongside the error so callers can report it.
+func loadRolesFromDirectories(cmd *cobra.Command, dirs []string, logger *logging.Logger) (*roles.LoadResult, string, error) {
+	merged := &roles.LoadResult{
+		Roles:        []models.Role{},
+		SkippedFiles: []roles.SkippedFile{},
+	}
+	opts := roles.LoadOptions{Parallelism: getIntFlag(cmd, "parallel-files")}
+	if cmd.Flags().Changed("parallel-files") && opts.Parallelism < 1 {
+		return nil, "", fmt.Errorf("--parallel-files must be at least 1, got %d", opts.Parallelism)
+	}
+
+	for _, dir := range dirs {
+		if len(dirs) > 1 {
+			cmd.Printf("Loading roles from directory: %s\n", dir)
+		}
+		logger.Debug("loading roles from directory: %s", dir)
+
+		result, err := roles.LoadRolesFromDirectoryWithOptions(dir, opts)
+		if err != nil {
+			return nil, dir, err
+		}
+
+		merged.Roles = append(merged.Roles, result.Roles...)
+		merged.Ignore = append(merged.Ignore, result.Ignore...)
+
+		for _, skipped := range result.SkippedFiles {
+			if len(dirs) > 1 {
+				skipped.Path = filepath.Join(dir, skipped.Path)
+			}
+			merged.SkippedFiles = append(merged.SkippedFiles, skipped)
+		}
+	}
+
+	localRoles, err := resolveDuplicateRoles(cmd, merged.Roles)
+	if err != nil {
+		return nil, "", err
+	}
+	merged.Roles = localRoles
+
+	if getBoolFlag(cmd, "case-insensitive-names") {
+		if err := roles.ValidateCaseInsensitiveNames(merged.Roles); err != nil {
+			return nil, "", err
+		}
+	}
+
+	return merged, "", nil
+}
+
+// resolveDuplicateRoles merges roles that share a name when --merge-duplicates is given,
+// and otherwise returns an error naming the files that define the first duplicate
+func resolveDuplicateRoles(cmd *cobra.Command, localRoles []models.Role) ([]models.Role, error) {
+	if !getBoolFlag(cmd, "merge-duplicates") {
+		return localRoles, roles.ValidateUniqueNames(localRoles)
+	}
+	merged, err := roles.MergeDuplicates(localRoles)
+	if err != nil {
+		return nil, err
+	}
+	files := make(map[string]int)
+	for _, role := range localRoles {
+		files[role.Name]++
+	}
+	for _, role := range merged {
+		if files[role.Name] > 1 {
+			cmd.Printf("merging role %s from %s\n", role.Name, role.SourceFile)
+		}
+	}
+	return merged, nil
+}
+
+// compareOptions builds role matching options from the command's flags and the ignore
+// patterns configured for the roles directories. It reports each ignored role and, with
+// --case-insensitive-names, each local role matched to a remote role whose name differs
+// only by case.
+func compareOptions(cmd *cobra.Command, local, remote []models.Role, ignore []string, logger *logging.Logger) sync.CompareOptions {
+	opts := sync.CompareOptions{
+		CaseInsensitiveNames: getBoolFlag(cmd, "case-insensitive-names"),
+		Ignore:               ignore,
+	}
+
+	for _, name := range sync.IgnoredRoles(local, remote, opts) {
+		cmd.Printf("ignoring role %s (configured)\n", name)
+		logger.Debug("role %s matches an ignore pattern in %s", name, roles.DirectoryConfigFile)
+	}
+
+	if opts.CaseInsensitiveNames {
+		for _, match := range sync.CaseInsensitiveMatches(local, remote) {
+			cmd.Printf("Warning: matching local role %q to remote role %q (names differ only by case)\n", match.Local, match.Remote)
+			logger.Warn("case-insensitive match: local role %s, remote role %s", match.Local, match.Remote)
+		}
+	}
+	return opts
+}
+
+// skippedFilesError builds an error listing every skipped role file and the reason it was skipped
+func skippedFilesError(skipped []roles.SkippedFile) error {
+	details := make([]string, 0, len(skipped))
+	for _, file := range skipped {
+		details = append(details, fmt.Sprintf("%s (%s)", file.Path, file.Reason))
+	}
+	return fmt.Errorf("aborting sync because %d role file(s) could not be loaded: %s", len(skipped), strings.Join(details, "; "))
+}
+
+// getBoolFlag returns the value of a boolean flag, or false if the command does not define it
+func getBoolFlag(cmd *cobra.Command, name string) bool {
+	if cmd.Flags().Lookup(name) == nil {
+		return false
+	}
+	value, _ := cmd.Flags().GetBool(name)
+	return value
+}
+
+// getStringFlag returns the value of a string flag, or "" if the command does not define it
+func getStringFlag(cmd *cobra.Command, name string) string {
+	if cmd.Flags().Lookup(name) == nil {
+		return ""
+	}
+	value, _ := cmd.Flags().GetString(name)
+	return value
+}
+
+// getIntFlag returns the value of an integer flag, or zero if the command does not define it
+func getIntFlag(cmd *cobra.Command, name string) int {
+	if cmd.Flags().Lookup(name) == nil {
+		return 0
+	}
+	value, _ := cmd.Flags().GetInt(name)
+	return value
+}
+
+// getStringSliceFlag returns the value of a string slice flag, or nil if the command does not define it
+func getStringSliceFlag(cmd *cobra.Command, name string) []string {
+	if cmd.Flags().Lookup(name) == nil {
+		return nil
+	}
+	value, _ := cmd.Flags().GetStringSlice(name)
+	return value
+}
+
+// getFloat64Flag returns the value of a float64 flag, or zero if the command does not define it
+func getFloat64Flag(cmd *cobra.Command, name string) float64 {
+	if cmd.Flags().Lookup(name) == nil {
+		return 0
+	}
+	value, _ := cmd.Flags().GetFloat64(name)
+	return value
+}
+
+// getDurationFlag returns the value of a duration flag, or zero if the command does not define it
+func getDurationFlag(cmd *cobra.Command, name string) time.Duration {
+	if cmd.Flags().Lookup(name) == nil {
+		return 0
+	}
+	value, _ := cmd.Flags().GetDuration(name)
+	return value
+}
+
+// syncMembersOnly applies only the membership of local roles that already exist remotely,
+// leaving every role definition untouched (--members-only). Resource differences in plan
+// are ignored; local roles missing from the remote are reported and skipped. The result
+// counts the roles whose members were updated, for --summary-json.
+func syncMembersOnly(cmd *cobra.Command, client api.ClientInterface, plan sync.SyncPlan, localRoles, remoteRoles []models.Role, opts sync.CompareOptions, dryRun, diff, approved, autoInvite bool, confirmInvites func([]string) (bool, error), auditEntry *report.Entry, logger *logging.Logger) (sync.ExecutionResult, error) {
+	missing := make(map[string]bool)
+	for _, role := range plan.Creates {
+		missing[role.Name] = true
+	}
+	existing := make([]models.Role, 0, len(localRoles))
+	for _, role := range localRoles {
+		switch {
+		case missing[role.Name]:
+			cmd.Printf("Warning: role %s does not exist remotely; its members are not synced\n", role.Name)
+		case !opts.Ignores(role.Name):
+			existing = append(existing, role)
+		}
+	}
+
+	// Keep only membership differences, showing resources as unchanged
+	memberPlan := sync.SyncPlan{Creates: []models.Role{}, Updates: []sync.RoleUpdate{}, Deletes: []string{}}
+	for _, update := range plan.Updates {
+		if sync.MembersEqual(update.Local.Members, update.Remote.Members) {
+			continue
+		}
+		update.Local.Resources = update.Remote.Resources
+		changes := sync.ComputeRoleChanges(update.Remote, update.Local)
+		update.Changes = &changes
+		memberPlan.Updates = append(memberPlan.Updates, update)
+	}
+	if auditEntry != nil {
+		auditEntry.RecordPlan(memberPlan)
+	}
+
+	if !memberPlan.HasChanges() {
+		cmd.Println("No member changes needed")
+		return sync.ExecutionResult{DryRun: dryRun}, nil
+	}
+
+	cmd.Printf("Will update members of %d role(s):\n", len(memberPlan.Updates))
+	for _, update := range memberPlan.Updates {
+		cmd.Printf("  - %s\n", update.Name)
+	}
+
+	if dryRun {
+		result := sync.ExecutionResult{Updated: len(memberPlan.Updates), DryRun: true}
+		if diff {
+			result.DetailedInfo = sync.DescribePlanWithNames(memberPlan, true, memberNames(cmd, remoteRoles))
+			cmd.Printf("\nSync completed: %s\n", result.DetailedSummary())
+		} else {
+			cmd.Printf("\nSync completed: %s\n", result.Summary())
+		}
+		return result, nil
+	}
+
+	memberClient, ok := client.(sync.APIClientWithMembers)
+	if !ok {
+		return sync.ExecutionResult{}, fmt.Errorf("client does not support member operations")
+	}
+	executor := sync.NewExecutorWithMembersAndInvite(memberClient, logger, autoInvite)
+	executor.SetSkipMembersOnError(getBoolFlag(cmd, "skip-members-on-error"))
+	executor.SetStrictMembers(getBoolFlag(cmd, "strict-members"))
+	executor.SetInviteRate(getFloat64Flag(cmd, "invite-rate"))
+	executor.SetInviteConfirmation(confirmInvites)
+	executor.SetContext(cmd.Context())
+	executor.SetRemoteRoles(remoteRoles)
+	// Members-only sync never deletes roles, so members of roles missing locally are kept
+	executor.SetUnmanagedRoles(sync.UnmanagedRoles(localRoles, remoteRoles, sync.SyncPlan{}, opts))
+	result := executor.ExecuteMembersOnly(existing, localRoles)
+	if auditEntry != nil {
+		auditEntry.RecordExecution(result)
+	}
+	if interrupted := interruptedError(cmd, result.Error); interrupted != nil {
+		return result, interrupted
+	}
+	if result.Error != nil {
+		message := result.Error.Error()
+		if result.Members != (sync.MemberCounts{}) {
+			message = fmt.Sprintf("%s (%s before the failure)", message, result.Members.Summary())
+		}
+		return result, HandleSyncError(cmd, &SyncError{
+			Operation: "member synchronization",
+			Message:   message,
+			Guidance:  "Check your API credentials and network connection",
+			Partial:   true,
+			Cause:     result.Error,
+		})
+	}
+	if result.MembersSkipped != nil {
+		cmd.Printf("\nWarning: member sync skipped: %v\n", result.MembersSkipped)
+		cmd.Println("\nSync completed: no members updated")
+		return result, nil
+	}
+
+	if result.MemberDeletions != nil && (len(result.MemberDeletions.OrphanedUsers) > 0 || len(result.MemberDeletions.OrphanedInvites) > 0) {
+		deleted, err := confirmAndDeleteMembers(cmd, client, result.MemberDeletions, approved, logger)
+		if err != nil {
+			return result, fmt.Errorf("failed to handle member deletions: %w", err)
+		}
+		if deleted && auditEntry != nil {
+			auditEntry.RecordMemberDeletions(result.MemberDeletions)
+		}
+	}
+
+	result.Updated = len(memberPlan.Updates)
+	cmd.Printf("\nSync completed: %s\n", result.Members.Summary())
+	return result, nil
+}
+
 // rolesHaveMembers checks if any of the provided roles have member assignments
 func rolesHaveMembers(roles []models.Role) bool {
 	for _, role := range roles {
@@ -506,8 +1378,249 @@ func rolesHaveMembers(roles []models.Role) bool {
 	return false
 }
 
-// confirmAndDeleteMembers prompts for confirmation and deletes orphaned members/invites
-func confirmAndDeleteMembers(cmd *cobra.Command, client api.ClientInterface, deletions *sync.MemberDeletions, force bool, logger *logging.Logger) error {
+// checkRoleSizes warns about roles with at least 90% of limit resource entries and returns
+// an error listing every role with more than limit. A limit of zero disables the check.
+func checkRoleSizes(cmd *cobra.Command, localRoles []models.Role, limit int, logger *logging.Logger) error {
+	if limit <= 0 {
+		return nil
+	}
+	var oversized []string
+	for _, role := range localRoles {
+		count := len(role.Resources.Allowed) + len(role.Resources.Denied)
+		if err := roles.ValidateRoleSize(role, limit); err != nil {
+			oversized = append(oversized, err.Error())
+		} else if count*10 >= limit*9 {
+			cmd.Printf("Warning: role %s has %d resource entries, close to the limit of %d\n", role.Name, count, limit)
+			logger.Warn("role %s has %d of at most %d resource entries", role.Name, count, limit)
+		}
+	}
+	if len(oversized) == 0 {
+		return nil
+	}
+	logger.Error("aborting sync: %d role(s) exceed the resource limit", len(oversized))
+	return fmt.Errorf("aborting sync because %d role(s) exceed --max-resources-per-role: %s", len(oversized), strings.Join(oversized, "; "))
+}
+
+// checkDriftGuard refuses a plan whose creates and deletes, soft deletes included, exceed
+// percent of the remote roles, which usually means sync was pointed at the wrong
+// directory. A dry run only warns, and force applies the plan anyway. A percent of zero
+// disables the check, as does an empty remote, which has nothing to protect.
+func checkDriftGuard(cmd *cobra.Command, plan sync.SyncPlan, remoteCount, percent int, dryRun, force bool, logger *logging.Logger) error {
+	if percent < 0 {
+		return fmt.Errorf("--drift-guard-percent must not be negative, got %d", percent)
+	}
+	if percent == 0 || remoteCount == 0 {
+		return nil
+	}
+	changes := len(plan.Creates) + len(plan.Deletes)
+	for _, update := range plan.Updates {
+		if update.Local.Name != update.Name {
+			changes++
+		}
+	}
+	if changes*100 <= percent*remoteCount {
+		return nil
+	}
+
+	message := fmt.Sprintf("the plan creates or deletes %d role(s), %d%% of the %d remote role(s), more than the --drift-guard-percent limit of %d%%",
+		changes, changes*100/remoteCount, remoteCount, percent)
+	switch {
+	case dryRun:
+		cmd.Printf("Warning: %s; the sync would be refused without --force\n", message)
+		return nil
+	case force:
+		cmd.Printf("Warning: %s; continuing because of --force\n", message)
+		logger.Warn("drift guard overridden with --force: %s", message)
+		return nil
+	}
+	logger.Error("aborting sync: %s", message)
+	return fmt.Errorf("aborting sync because %s; check that the role directory is the intended one, or re-run with --force if the change is expected", message)
+}
+
+// verifySync fetches the remote roles again and compares them with the local roles as the
+// sync did, failing with the residual differences if the sync did not converge, e.g.
+// because the API normalized a role or silently dropped part of a change
+func verifySync(cmd *cobra.Command, client api.ClientInterface, localRoles []models.Role, opts sync.CompareOptions, delete, softDelete bool, logger *logging.Logger) error {
+	logger.Debug("verifying remote roles after sync")
+	// Membership is only synced when the role files list members
+	includeMembers := rolesHaveMembers(localRoles)
+	remoteRoles, err := fetchRemoteRoles(client, includeMembers, logger)()
+	if err != nil {
+		return HandleSyncError(cmd, fmt.Errorf("failed to get remote roles for verification: %w", err))
+	}
+	if !includeMembers {
+		remoteRoles = withoutMembers(remoteRoles)
+	}
+	_, remoteRoles = normalizeDenies(cmd, nil, remoteRoles, logger)
+	if softDelete {
+		remoteRoles = sync.ActiveRoles(remoteRoles)
+	}
+
+	residual, err := sync.CompareRolesWithOptions(localRoles, remoteRoles, opts)
+	if err != nil {
+		return fmt.Errorf("failed to compare roles for verification: %w", err)
+	}
+	if !delete {
+		residual.Deletes = []string{}
+	}
+	if !residual.HasChanges() {
+		cmd.Println("Verified: remote roles match the local roles")
+		return nil
+	}
+
+	explanations := sync.ExplainPlan(residual)
+	cmd.Printf("Verification failed: %d difference(s) remain between the local and remote roles:\n", len(explanations))
+	for _, explanation := range explanations {
+		cmd.Printf("  %s\n", explanation)
+	}
+	logger.Error("verification failed: %d difference(s) remain after sync", len(explanations))
+	return HandleSyncError(cmd, &SyncError{
+		Operation: "sync verification",
+		Message:   fmt.Sprintf("verification failed: %d difference(s) remain after sync", len(explanations)),
+		Guidance:  "The API may store roles differently from the role files; run 'replbac diff' to review the differences",
+	})
+}
+
+// writeProjectedRoles writes the remote roles as they would be after a sync to dir, one
+// file per role as pull writes them. The directory must be empty or not exist, so that
+// files of deleted roles cannot be left over from an earlier projection.
+func writeProjectedRoles(cmd *cobra.Command, dir string, projected []models.Role) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to read plan output directory: %w", err)
+	}
+	if len(entries) > 0 {
+		return fmt.Errorf("plan output directory %s is not empty; remove it or choose another", dir)
+	}
+
+	writeOpts := roles.WriteOptions{OmitMembers: getBoolFlag(cmd, "no-members")}
+	for _, role := range projected {
+		filePath := filepath.Join(dir, role.Name+".yaml")
+		if err := roles.WriteRoleFileWithOptions(role, filePath, writeOpts); err != nil {
+			return fmt.Errorf("failed to write projected role %s: %w", role.Name, err)
+		}
+	}
+	cmd.Printf("Wrote %d projected remote role(s) to %s\n", len(projected), dir)
+	return nil
+}
+
+// printPlanSections lists a plan's operations in one section per kind, each sorted by
+// role name and naming the file each created or updated role is from, followed by the
+// total. Only the display is sorted; the plan still runs in
+// its own order.
+func printPlanSections(cmd *cobra.Command, plan sync.SyncPlan, logger *logging.Logger) {
+	creates := make([]string, 0, len(plan.Creates))
+	for _, role := range plan.Creates {
+		creates = append(creates, role.Name+role.Origin())
+		logger.Debug("will create role: %s", role.Name)
+	}
+	updates := make([]string, 0, len(plan.Updates))
+	for _, update := range plan.Updates {
+		if update.Local.Name != update.Name {
+			updates = append(updates, fmt.Sprintf("%s (soft-delete as %s)", update.Name, update.Local.Name))
+		} else {
+			updates = append(updates, update.Name+update.Local.Origin())
+		}
+		logger.Debug("will update role: %s", update.Name)
+	}
+	deletes := append([]string(nil), plan.Deletes...)
+	for _, roleName := range deletes {
+		logger.Debug("will delete role: %s", roleName)
+	}
+
+	for _, section := range []struct {
+		title string
+		names []string
+	}{
+		{"Creating", creates},
+		{"Updating", updates},
+		{"Deleting", deletes},
+	} {
+		if len(section.names) == 0 {
+			continue
+		}
+		sort.Strings(section.names)
+		cmd.Printf("── %s (%d) ──\n", section.title, len(section.names))
+		for _, name := range section.names {
+			cmd.Printf("  - %s\n", name)
+		}
+	}
+	cmd.Printf("Total: %d role change(s)\n", len(creates)+len(updates)+len(deletes))
+}
+
+// printAccessImpact lists the members whose access a sync changes, counting those who
+// gain access so a security review can start with them
+func printAccessImpact(cmd *cobra.Command, impacts []sync.MemberImpact) {
+	if len(impacts) == 0 {
+		cmd.Println("Access impact: no member's access changes")
+		return
+	}
+	escalations := 0
+	for _, impact := range impacts {
+		if impact.Escalates() {
+			escalations++
+		}
+	}
+	cmd.Printf("Access impact: %d member(s) affected, %d gaining access:\n", len(impacts), escalations)
+	for _, impact := range impacts {
+		cmd.Printf("  %s\n", impact)
+	}
+}
+
+// memberNames returns display labels for the members of remoteRoles when --show-names
+// is set, or nil to show bare emails
+func memberNames(cmd *cobra.Command, remoteRoles []models.Role) map[string]string {
+	if !getBoolFlag(cmd, "show-names") {
+		return nil
+	}
+	return sync.MemberNames(remoteRoles)
+}
+
+// normalizeDenies drops unreachable denied entries from both sides with --normalize-denies,
+// so that denies the API normalizes away do not cause an update on every sync
+func normalizeDenies(cmd *cobra.Command, localRoles, remoteRoles []models.Role, logger *logging.Logger) ([]models.Role, []models.Role) {
+	if !getBoolFlag(cmd, "normalize-denies") {
+		return localRoles, remoteRoles
+	}
+	logger.Debug("dropping denied entries no allowed entry can reach (--normalize-denies)")
+	return roles.NormalizeDenies(localRoles), roles.NormalizeDenies(remoteRoles)
+}
+
+// withoutMembers returns copies of roles with their member lists cleared
+func withoutMembers(roles []models.Role) []models.Role {
+	stripped := make([]models.Role, len(roles))
+	for i, role := range roles {
+		role.Members = nil
+		role.MemberDetails = nil
+		stripped[i] = role
+	}
+	return stripped
+}
+
+// inviteConfirmation returns a prompt listing the members about to be invited, for
+// ExecutorWithMembers.SetInviteConfirmation, or nil when prompts are approved, as they
+// are for deletions
+func inviteConfirmation(cmd *cobra.Command, approved bool, logger *logging.Logger) func([]string) (bool, error) {
+	if approved {
+		return nil
+	}
+	return func(emails []string) (bool, error) {
+		question := fmt.Sprintf("\nAbout to invite %d new member(s): %s. Continue? (y/N): ", len(emails), strings.Join(emails, ", "))
+		confirmed, err := confirmOperation(cmd, false, "", question, "invitations")
+		if err != nil {
+			return false, err
+		}
+		if !confirmed {
+			cmd.Println("Invitations cancelled by user; members not on the team were skipped")
+			logger.Debug("invitations cancelled by user")
+		}
+		return confirmed, nil
+	}
+}
+
+// confirmAndDeleteMembers prompts for confirmation, unless approved, and deletes orphaned
+// members/invites, reporting whether the deletions were carried out
+func confirmAndDeleteMembers(cmd *cobra.Command, client api.ClientInterface, deletions *sync.MemberDeletions, approved bool, logger *logging.Logger) (bool, error) {
 	totalDeletions := len(deletions.OrphanedUsers) + len(deletions.OrphanedInvites)
 
 	// Show what will be deleted
@@ -525,38 +1638,117 @@ func confirmAndDeleteMembers(cmd *cobra.Command, client api.ClientInterface, del
 		}
 	}
 
-	// Ask for confirmation if not forced
-	if !force {
-		cmd.Printf("\nDo you want to continue with these %d deletion(s)? (y/N): ", totalDeletions)
-
-		reader := bufio.NewReader(os.Stdin)
-		response, err := reader.ReadString('\n')
-		if err != nil {
-			return fmt.Errorf("failed to read confirmation: %w", err)
-		}
-
-		response = strings.ToLower(strings.TrimSpace(response))
-		if response != "y" && response != "yes" {
-			cmd.Println("Member deletion cancelled by user")
-			logger.Debug("member deletion operation cancelled by user")
-			return nil
-		}
-		logger.Debug("user confirmed member deletion operation")
+	question := fmt.Sprintf("\nDo you want to continue with these %d deletion(s)? (y/N): ", totalDeletions)
+	confirmed, err := confirmOperation(cmd, approved, "", question, "member deletions")
+	if err != nil {
+		return false, err
+	}
+	if !confirmed {
+		cmd.Println("Member deletion cancelled by user")
+		logger.Debug("member deletion operation cancelled by user")
+		return false, nil
 	}
+	logger.Debug("member deletion operation confirmed")
 
 	// Perform the deletions
 	memberClient, ok := client.(sync.APIClientWithMembers)
 	if !ok {
-		return fmt.Errorf("client does not support member operations")
+		return false, fmt.Errorf("client does not support member operations")
 	}
 
 	executor := sync.NewExecutorWithMembersAndInvite(memberClient, logger, true)
 	if err := executor.DeleteMembersAndInvites(deletions); err != nil {
-		return fmt.Errorf("failed to delete members and invites: %w", err)
+		return false, fmt.Errorf("failed to delete members and invites: %w", err)
 	}
 
 	cmd.Printf("Successfully removed %d member(s) and cancelled %d invitation(s)\n",
 		len(deletions.OrphanedUsers), len(deletions.OrphanedInvites))
 
+	return true, nil
+}
+
+// interruptedError reports an execution stopped by cancellation, such as Ctrl-C, with the
+// work completed so far instead of a bare context error. It returns nil for other errors.
+func interruptedError(cmd *cobra.Command, err error) error {
+	var interrupted *sync.InterruptedError
+	if !errors.As(err, &interrupted) {
+		return nil
+	}
+	cmd.Printf("\nSync %s\n", interrupted)
+	return withExitCode(ExitCodeInterrupted, fmt.Errorf("sync %w", interrupted))
+}
+
+// resultLinePrefix starts the line --summary-json writes, so scripts can find it among other output
+const resultLinePrefix = "REPLBAC_RESULT="
+
+// resultSummary is the structured result of a sync written by --summary-json
+type resultSummary struct {
+	Status         string   `json:"status"` // "success", "cancelled", or "error"
+	DryRun         bool     `json:"dry_run"`
+	Created        int      `json:"created"`
+	Updated        int      `json:"updated"`
+	Deleted        int      `json:"deleted"`
+	MembersInvited []string `json:"members_invited"`
+	MembersSkipped string   `json:"members_skipped,omitempty"`
+	Error          string   `json:"error,omitempty"`
+
+	// Skipped lists the role files skipped as invalid, with why
+	Skipped []roles.SkippedFile `json:"skipped"`
+}
+
+// newResultSummary describes the outcome of a sync run that skipped the given role files
+// and returned err
+func newResultSummary(result sync.ExecutionResult, skipped []roles.SkippedFile, dryRun bool, cancelled bool, err error) resultSummary {
+	summary := resultSummary{
+		Status:         "success",
+		DryRun:         dryRun,
+		Created:        result.Created,
+		Updated:        result.Updated,
+		Deleted:        result.Deleted,
+		MembersInvited: result.InvitedMembers,
+		Skipped:        skipped,
+	}
+	if summary.MembersInvited == nil {
+		summary.MembersInvited = []string{}
+	}
+	if summary.Skipped == nil {
+		summary.Skipped = []roles.SkippedFile{}
+	}
+	if result.MembersSkipped != nil {
+		summary.MembersSkipped = result.MembersSkipped.Error()
+	}
+	switch {
+	case err != nil:
+		summary.Status = "error"
+		summary.Error = err.Error()
+	case cancelled:
+		summary.Status = "cancelled"
+	}
+	return summary
+}
+
+// writeSkippedFiles writes the skipped role files to filePath as a JSON array, empty if
+// none were skipped, for CI to turn into annotations on each file
+func writeSkippedFiles(filePath string, skipped []roles.SkippedFile) error {
+	if skipped == nil {
+		skipped = []roles.SkippedFile{}
+	}
+	data, err := json.MarshalIndent(skipped, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode skipped files: %w", err)
+	}
+	if err := os.WriteFile(filePath, append(data, '\n'), 0600); err != nil {
+		return fmt.Errorf("failed to write skipped files to %s: %w", filePath, err)
+	}
 	return nil
 }
+
+// writeResultLine writes summary to stderr as a single REPLBAC_RESULT= line
+func writeResultLine(cmd *cobra.Command, summary resultSummary, logger *logging.Logger) {
+	data, err := json.Marshal(summary)
+	if err != nil {
+		logger.Error("failed to encode sync result: %v", err)
+		return
+	}
+	_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "%s%s\n", resultLinePrefix, data)
+}
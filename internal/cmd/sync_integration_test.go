@@ -11,6 +11,8 @@ import (
 
 	"github.com/spf13/cobra"
 
+	"replbac/internal/api"
+	"replbac/internal/logging"
 	"replbac/internal/models"
 )
 
@@ -701,3 +703,39 @@ func NewSyncCommand(mockClient *MockClient) *cobra.Command {
 
 	return cmd
 }
+
+// NewSyncCommandWithOptions creates a test sync command that runs the full
+// RunSyncCommandWithLogging path. The standard sync flags are always registered;
+// addFlags can register any additional flags a test needs.
+func NewSyncCommandWithOptions(mockClient api.ClientInterface, addFlags func(cmd *cobra.Command)) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "sync [directory...]",
+		Short: "Synchronize local role files to Replicated API",
+		Args:  cobra.ArbitraryArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			dryRun, _ := cmd.Flags().GetBool("dry-run")
+			diff, _ := cmd.Flags().GetBool("diff")
+			delete, _ := cmd.Flags().GetBool("delete")
+			force, _ := cmd.Flags().GetBool("force")
+			noInvite, _ := cmd.Flags().GetBool("no-invite")
+
+			logger := logging.NewLogger(cmd.ErrOrStderr(), false)
+			config := models.Config{
+				APIToken: "test-token",
+				LogLevel: "info",
+			}
+			return RunSyncCommandWithLogging(cmd, args, mockClient, dryRun || diff, diff, delete, force, !noInvite, logger, config)
+		},
+	}
+
+	cmd.Flags().Bool("dry-run", false, "preview changes without applying them")
+	cmd.Flags().Bool("diff", false, "preview changes with detailed diffs")
+	cmd.Flags().Bool("delete", false, "delete remote roles not present in local files")
+	cmd.Flags().Bool("force", false, "skip confirmation prompts")
+	cmd.Flags().Bool("no-invite", false, "disable automatic invitation of missing members")
+	if addFlags != nil {
+		addFlags(cmd)
+	}
+
+	return cmd
+}
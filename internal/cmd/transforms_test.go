@@ -0,0 +1,105 @@
+package cmd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+
+	"replbac/internal/logging"
+	"replbac/internal/models"
+)
+
+// TestSyncTransforms tests that configured transforms shape the local roles that sync compares and applies
+func TestSyncTransforms(t *testing.T) {
+	tests := []struct {
+		name         string
+		transforms   []models.TransformConfig
+		args         []string
+		expectError  string
+		expectOutput []string
+		expectCreate []models.Role
+	}{
+		{
+			name:       "transform effects appear in the diff",
+			transforms: []models.TransformConfig{{Name: "ensure-deny", Resources: []string{"admin/secrets/**"}}},
+			args:       []string{"--diff"},
+			expectOutput: []string{
+				"Applying transforms: ensure-deny",
+				"UPDATE: viewer",
+				"+ denied: admin/secrets/**",
+			},
+		},
+		{
+			name: "transformed roles are applied",
+			transforms: []models.TransformConfig{
+				{Name: "add-prefix", Prefix: "team-"},
+				{Name: "ensure-deny", Resources: []string{"admin/secrets/**"}},
+			},
+			expectCreate: []models.Role{{Name: "team-viewer", Resources: models.Resources{Allowed: []string{"read"}, Denied: []string{"admin/secrets/**"}}}},
+		},
+		{
+			name:        "unknown transform fails before loading",
+			transforms:  []models.TransformConfig{{Name: "shout"}},
+			expectError: `invalid transforms configuration: unknown transform "shout"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tempDir := t.TempDir()
+			if err := createTestRoleFile(tempDir, models.Role{Name: "viewer", Resources: models.Resources{Allowed: []string{"read"}, Denied: []string{}}}); err != nil {
+				t.Fatalf("Failed to create role file: %v", err)
+			}
+
+			mockCalls := &MockAPICalls{}
+			client := NewMockClient(mockCalls, []models.Role{{ID: "1", Name: "viewer", Resources: models.Resources{Allowed: []string{"read"}, Denied: []string{}}}})
+			cmd := &cobra.Command{
+				Use: "sync",
+				RunE: func(cmd *cobra.Command, args []string) error {
+					diff, _ := cmd.Flags().GetBool("diff")
+					logger := logging.NewLogger(cmd.ErrOrStderr(), false)
+					config := models.Config{APIToken: "test-token", LogLevel: "info", Transforms: tt.transforms}
+					return RunSyncCommandWithLogging(cmd, args, client, diff, diff, false, true, true, logger, config)
+				},
+			}
+			cmd.Flags().Bool("diff", false, "preview changes with detailed diffs")
+
+			var stdout bytes.Buffer
+			cmd.SetOut(&stdout)
+			cmd.SetErr(&bytes.Buffer{})
+			cmd.SetArgs(append([]string{tempDir}, tt.args...))
+
+			err := cmd.Execute()
+			if tt.expectError != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.expectError) {
+					t.Fatalf("Expected error containing %q, got %v", tt.expectError, err)
+				}
+				if mockCalls.GetCalls != 0 {
+					t.Errorf("Expected no API calls, got %d", mockCalls.GetCalls)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v\nOutput:\n%s", err, stdout.String())
+			}
+
+			output := stdout.String()
+			for _, expected := range tt.expectOutput {
+				if !strings.Contains(output, expected) {
+					t.Errorf("Expected output to contain %q, got:\n%s", expected, output)
+				}
+			}
+			if len(mockCalls.CreateCalls) != len(tt.expectCreate) {
+				t.Fatalf("Expected creates %v, got %v", tt.expectCreate, mockCalls.CreateCalls)
+			}
+			for i, want := range tt.expectCreate {
+				got := mockCalls.CreateCalls[i]
+				if got.Name != want.Name || strings.Join(got.Resources.Denied, ",") != strings.Join(want.Resources.Denied, ",") {
+					t.Errorf("Create %d = %+v, want %+v", i, got, want)
+				}
+			}
+		})
+	}
+}
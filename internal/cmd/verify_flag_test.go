@@ -0,0 +1,110 @@
+package cmd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+
+	"replbac/internal/api"
+	"replbac/internal/models"
+)
+
+// normalizingClient stores updated roles without their denied resources, as an API
+// that silently normalizes roles would
+type normalizingClient struct {
+	*MockClient
+}
+
+// UpdateRole stores the role with its denied resources dropped
+func (c normalizingClient) UpdateRole(role models.Role) error {
+	role.Resources.Denied = []string{}
+	return c.MockClient.UpdateRole(role)
+}
+
+// TestVerifyFlagBehavior tests that --verify re-fetches the remote roles after applying
+// and fails when they still differ from the local roles
+func TestVerifyFlagBehavior(t *testing.T) {
+	local := []models.Role{
+		{Name: "viewer", Resources: models.Resources{Allowed: []string{"kots/app/*/read"}, Denied: []string{"kots/app/*/write"}}},
+		{Name: "support", Resources: models.Resources{Allowed: []string{"team/support-issues/read"}, Denied: []string{}}},
+	}
+
+	tests := []struct {
+		name         string
+		normalizing  bool
+		args         []string
+		expectError  string
+		expectOutput []string
+		expectGets   int
+	}{
+		{
+			name:         "converged sync is verified",
+			args:         []string{"--verify"},
+			expectOutput: []string{"Verified: remote roles match the local roles", "Sync completed"},
+			expectGets:   2,
+		},
+		{
+			name:         "residual differences fail verification",
+			normalizing:  true,
+			args:         []string{"--verify"},
+			expectError:  "verification failed: 1 difference(s) remain after sync",
+			expectOutput: []string{"Verification failed: 1 difference(s) remain", "update viewer: denied differs (remote missing 'kots/app/*/write')"},
+			expectGets:   2,
+		},
+		{
+			name:        "no verification without the flag",
+			normalizing: true,
+			expectGets:  1,
+		},
+		{
+			name:       "no verification in a dry run",
+			args:       []string{"--verify", "--dry-run"},
+			expectGets: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tempDir := t.TempDir()
+			for _, role := range local {
+				if err := createTestRoleFile(tempDir, role); err != nil {
+					t.Fatalf("Failed to create role file: %v", err)
+				}
+			}
+
+			calls := &MockAPICalls{}
+			mock := NewMockClient(calls, []models.Role{
+				{ID: "1", Name: "viewer", Resources: models.Resources{Allowed: []string{"kots/app/*/read"}, Denied: []string{}}},
+			})
+			var client api.ClientInterface = mock
+			if tt.normalizing {
+				client = normalizingClient{mock}
+			}
+			cmd := NewSyncCommandWithOptions(client, func(cmd *cobra.Command) {
+				cmd.Flags().Bool("verify", false, "verify remote roles after applying")
+			})
+			var stdout bytes.Buffer
+			cmd.SetOut(&stdout)
+			cmd.SetErr(&bytes.Buffer{})
+			cmd.SetArgs(append([]string{tempDir}, tt.args...))
+
+			err := cmd.Execute()
+			if tt.expectError == "" && err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if tt.expectError != "" && (err == nil || !strings.Contains(err.Error(), tt.expectError)) {
+				t.Fatalf("Expected error containing %q, got %v", tt.expectError, err)
+			}
+			for _, expected := range tt.expectOutput {
+				if !strings.Contains(stdout.String(), expected) {
+					t.Errorf("Expected output to contain %q, got:\n%s", expected, stdout.String())
+				}
+			}
+			if calls.GetCalls != tt.expectGets {
+				t.Errorf("Expected %d fetches of the remote roles, got %d", tt.expectGets, calls.GetCalls)
+			}
+		})
+	}
+}
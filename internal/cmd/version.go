@@ -1,7 +1,14 @@
 package cmd
 
 import (
+	"fmt"
+	"runtime"
+	godebug "runtime/debug"
+	"strings"
+
 	"github.com/spf13/cobra"
+
+	"replbac/internal/models"
 )
 
 var (
@@ -11,6 +18,15 @@ var (
 	BuildDate = "unknown"
 )
 
+// versionInfo describes the running build
+type versionInfo struct {
+	Version     string
+	GitCommit   string
+	BuildDate   string
+	GoVersion   string
+	APIEndpoint string
+}
+
 // versionCmd represents the version command
 var versionCmd = &cobra.Command{
 	Use:   "version",
@@ -20,12 +36,76 @@ var versionCmd = &cobra.Command{
 Environment Variables:
   See 'replbac --help' for full environment variable documentation.`,
 	Run: func(cmd *cobra.Command, args []string) {
-		cmd.Printf("replbac version %s\n", Version)
-		cmd.Printf("Git commit: %s\n", GitCommit)
-		cmd.Printf("Built: %s\n", BuildDate)
+		cmd.Print(currentVersionInfo().String())
 	},
 }
 
 func init() {
 	rootCmd.AddCommand(versionCmd)
+
+	// Support `replbac --version` with the same output as the version command
+	info := currentVersionInfo()
+	rootCmd.Version = info.Version
+	rootCmd.SetVersionTemplate(info.String())
+}
+
+// currentVersionInfo combines ldflags-injected values with the build information
+// embedded by the Go toolchain, which fills in anything ldflags left unset
+func currentVersionInfo() versionInfo {
+	info := versionInfo{
+		Version:     Version,
+		GitCommit:   GitCommit,
+		BuildDate:   BuildDate,
+		GoVersion:   runtime.Version(),
+		APIEndpoint: models.ReplicatedAPIEndpoint,
+	}
+
+	buildInfo, ok := godebug.ReadBuildInfo()
+	if !ok {
+		return info
+	}
+	return applyBuildInfo(info, buildInfo)
+}
+
+// applyBuildInfo fills default version fields from Go build information
+func applyBuildInfo(info versionInfo, buildInfo *godebug.BuildInfo) versionInfo {
+	if buildInfo.GoVersion != "" {
+		info.GoVersion = buildInfo.GoVersion
+	}
+	if info.Version == "dev" && buildInfo.Main.Version != "" && buildInfo.Main.Version != "(devel)" {
+		info.Version = buildInfo.Main.Version
+	}
+
+	fromVCS, modified := false, false
+	for _, setting := range buildInfo.Settings {
+		switch setting.Key {
+		case "vcs.revision":
+			if info.GitCommit == "unknown" {
+				info.GitCommit = setting.Value
+				fromVCS = true
+			}
+		case "vcs.time":
+			if info.BuildDate == "unknown" {
+				info.BuildDate = setting.Value
+			}
+		case "vcs.modified":
+			modified = setting.Value == "true"
+		}
+	}
+	if modified && fromVCS {
+		info.GitCommit += "-dirty"
+	}
+
+	return info
+}
+
+// String formats version information for display
+func (v versionInfo) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "replbac version %s\n", v.Version)
+	fmt.Fprintf(&b, "Git commit: %s\n", v.GitCommit)
+	fmt.Fprintf(&b, "Built: %s\n", v.BuildDate)
+	fmt.Fprintf(&b, "Go version: %s\n", v.GoVersion)
+	fmt.Fprintf(&b, "API endpoint: %s\n", v.APIEndpoint)
+	return b.String()
 }
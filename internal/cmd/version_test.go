@@ -0,0 +1,56 @@
+package cmd
+
+import (
+	godebug "runtime/debug"
+	"strings"
+	"testing"
+)
+
+func TestApplyBuildInfo(t *testing.T) {
+	buildInfo := &godebug.BuildInfo{
+		GoVersion: "go1.21.0",
+		Main:      godebug.Module{Version: "v1.2.3"},
+		Settings: []godebug.BuildSetting{
+			{Key: "vcs.revision", Value: "abc123"},
+			{Key: "vcs.time", Value: "2024-01-02T03:04:05Z"},
+			{Key: "vcs.modified", Value: "true"},
+		},
+	}
+
+	tests := []struct {
+		name string
+		info versionInfo
+		want versionInfo
+	}{
+		{
+			name: "build info fills unset ldflags values",
+			info: versionInfo{Version: "dev", GitCommit: "unknown", BuildDate: "unknown"},
+			want: versionInfo{Version: "v1.2.3", GitCommit: "abc123-dirty", BuildDate: "2024-01-02T03:04:05Z", GoVersion: "go1.21.0"},
+		},
+		{
+			name: "ldflags values take precedence",
+			info: versionInfo{Version: "v2.0.0", GitCommit: "def456", BuildDate: "2025-01-01"},
+			want: versionInfo{Version: "v2.0.0", GitCommit: "def456", BuildDate: "2025-01-01", GoVersion: "go1.21.0"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := applyBuildInfo(tt.info, buildInfo); got != tt.want {
+				t.Errorf("applyBuildInfo() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestVersionInfoString(t *testing.T) {
+	output := currentVersionInfo().String()
+	for _, expected := range []string{"replbac version", "Git commit:", "Built:", "Go version: go", "API endpoint: https://api.replicated.com"} {
+		if !strings.Contains(output, expected) {
+			t.Errorf("Expected version output to contain %q, got:\n%s", expected, output)
+		}
+	}
+	if strings.Contains(strings.ToLower(output), "token") {
+		t.Errorf("Version output must not mention the API token, got:\n%s", output)
+	}
+}
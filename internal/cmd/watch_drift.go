@@ -0,0 +1,237 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"replbac/internal/api"
+	"replbac/internal/logging"
+	"replbac/internal/models"
+	"replbac/internal/roles"
+	"replbac/internal/sync"
+)
+
+// DefaultDriftInterval is how often watch-drift compares local roles with the API
+const DefaultDriftInterval = 5 * time.Minute
+
+var (
+	driftInterval time.Duration
+	driftWebhook  string
+	driftVerbose  bool
+	driftDebug    bool
+)
+
+// watchDriftCmd represents the watch-drift command
+var watchDriftCmd = &cobra.Command{
+	Use:   "watch-drift [directory]",
+	Short: "Poll the API and alert when remote roles drift from local role files",
+	Long: `Watch-drift compares local role files with the roles in the Replicated
+platform every --interval, as 'replbac diff' would, and reports drift: roles
+changed, added, or removed out-of-band, for example in the Replicated UI. It
+never makes changes, and runs until interrupted.
+
+An alert is printed when drift first appears and again only when it changes,
+so persistent drift is not reported on every poll. When the remote roles match
+the local files again, that is reported once as well.
+
+With --webhook, each drift alert is also sent as a JSON POST to the given URL.
+The body's "text" field holds the full alert, so it can be posted directly to
+a Slack incoming webhook; "summary", "directory", and "detected_at" are also
+set. A failed webhook is reported as a warning and the alert is sent again after
+the next poll.
+
+Environment Variables:
+  This command supports all global environment variables.
+  See 'replbac --help' for full environment variable documentation.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return RunWatchDriftCommand(cmd, args, cfg)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(watchDriftCmd)
+
+	watchDriftCmd.Flags().DurationVar(&driftInterval, "interval", DefaultDriftInterval, "how often to compare local roles with the API")
+	watchDriftCmd.Flags().StringVar(&driftWebhook, "webhook", "", "also POST each drift alert as JSON to this URL")
+	watchDriftCmd.Flags().BoolVar(&driftVerbose, "verbose", false, "enable info-level logging to stderr (progress and results)")
+	watchDriftCmd.Flags().BoolVar(&driftDebug, "debug", false, "enable debug-level logging to stderr (detailed operation info)")
+}
+
+// driftAlert is the JSON body posted to the --webhook URL
+type driftAlert struct {
+	Text       string `json:"text"`
+	Summary    string `json:"summary"`
+	Directory  string `json:"directory"`
+	DetectedAt string `json:"detected_at"`
+}
+
+// RunWatchDriftCommand creates an API client and watches for drift until the command's context is cancelled
+func RunWatchDriftCommand(cmd *cobra.Command, args []string, config models.Config) error {
+	if cmd.OutOrStdout() == os.Stderr {
+		cmd.SetOut(os.Stdout)
+	}
+
+	var logger *logging.Logger
+	if driftDebug {
+		logger = logging.NewDebugLogger(cmd.ErrOrStderr())
+	} else {
+		logger = logging.NewLogger(cmd.ErrOrStderr(), driftVerbose)
+	}
+	if getBoolFlag(cmd, "debug-http") {
+		logger.EnableHTTPTrace()
+	}
+	teeLogFile(logger)
+
+	targetDir := "."
+	if len(args) > 0 {
+		targetDir = args[0]
+	}
+	if err := ValidateDirectoryAccess(targetDir); err != nil {
+		return HandleFileSystemError(cmd, err, targetDir)
+	}
+
+	client, err := newAPIClient(config, logger, api.DefaultMaxRetries)
+	if err != nil {
+		logger.Error("failed to create API client: %v", err)
+		return fmt.Errorf("failed to create API client: %w", err)
+	}
+
+	// Watching only ever reads
+	return RunWatchDriftCommandWithClient(cmd, targetDir, api.NewReadOnlyClient(client), config, logger)
+}
+
+// RunWatchDriftCommandWithClient compares the roles in targetDir with those returned by
+// client every --interval, printing an alert, and posting it to --webhook, whenever the
+// drift differs from the last check. It returns nil once the command's context is
+// cancelled. A failure on the first check is returned, since it usually means the
+// directory or configuration is wrong; later failures are reported and watching continues.
+func RunWatchDriftCommandWithClient(cmd *cobra.Command, targetDir string, client api.ClientInterface, config models.Config, logger *logging.Logger) error {
+	interval := getDurationFlag(cmd, "interval")
+	if interval <= 0 {
+		return fmt.Errorf("--interval must be greater than 0, got %s", interval)
+	}
+	webhook := getStringFlag(cmd, "webhook")
+	if webhook != "" {
+		if parsed, err := url.Parse(webhook); err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") {
+			return fmt.Errorf("--webhook must be an HTTP or HTTPS URL, got %q", webhook)
+		}
+	}
+	pipeline, err := roles.NewPipeline(config.Transforms)
+	if err != nil {
+		return HandleConfigurationError(cmd, fmt.Errorf("invalid transforms configuration: %w", err))
+	}
+
+	ctx := cmd.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	cmd.Printf("Watching roles in %s for drift every %s\n", targetDir, interval)
+	lastAlert := ""
+	for checks := 0; ; checks++ {
+		plan, err := checkDrift(targetDir, client, pipeline, config, logger)
+		if err != nil {
+			if checks == 0 {
+				return err
+			}
+			cmd.PrintErrf("Warning: drift check failed: %v\n", err)
+		} else {
+			alert := ""
+			if plan.HasChanges() {
+				alert = fmt.Sprintf("Drift detected in %s: %s\n\n%s", targetDir, plan.Summary(), sync.DescribePlan(plan, true))
+			}
+
+			switch {
+			case alert == lastAlert:
+				logger.Debug("drift unchanged since the last check")
+			case alert == "":
+				cmd.Printf("%s: drift resolved, remote roles match %s\n", time.Now().Format(time.RFC3339), targetDir)
+			default:
+				now := time.Now()
+				cmd.Printf("%s: %s\n", now.Format(time.RFC3339), alert)
+				if webhook != "" {
+					if err := postDriftAlert(ctx, webhook, driftAlert{
+						Text:       alert,
+						Summary:    plan.Summary(),
+						Directory:  targetDir,
+						DetectedAt: now.Format(time.RFC3339),
+					}); err != nil {
+						cmd.PrintErrf("Warning: failed to send drift alert to webhook: %v\n", err)
+						logger.Warn("webhook delivery failed: %v", err)
+						// Send the same alert again next time rather than dropping it
+						alert = lastAlert
+					}
+				}
+			}
+			lastAlert = alert
+		}
+
+		select {
+		case <-ctx.Done():
+			cmd.Println("Stopped watching for drift")
+			return nil
+		case <-time.After(interval):
+		}
+	}
+}
+
+// checkDrift loads and transforms the local roles in targetDir and compares them with the
+// remote roles, returning the plan a sync would need to remove the drift
+func checkDrift(targetDir string, client api.ClientInterface, pipeline *roles.Pipeline, config models.Config, logger *logging.Logger) (sync.SyncPlan, error) {
+	loadResult, err := roles.LoadRolesFromDirectoryWithDetails(targetDir)
+	if err != nil {
+		return sync.SyncPlan{}, fmt.Errorf("failed to load local roles: %w", err)
+	}
+	localRoles, err := pipeline.Apply(loadResult.Roles)
+	if err != nil {
+		return sync.SyncPlan{}, fmt.Errorf("failed to transform local roles: %w", err)
+	}
+
+	remoteRoles, err := client.GetRoles()
+	if err != nil {
+		return sync.SyncPlan{}, fmt.Errorf("failed to get remote roles: %w", err)
+	}
+	logger.Debug("comparing %d local roles with %d remote roles", len(localRoles), len(remoteRoles))
+
+	opts := sync.CompareOptions{Ignore: loadResult.Ignore, Protected: config.ProtectedRoles, IgnoreResources: config.IgnoreResources, Manage: config.Manage}
+	plan, err := sync.CompareRolesWithOptions(localRoles, remoteRoles, opts)
+	if err != nil {
+		return sync.SyncPlan{}, fmt.Errorf("failed to compare roles: %w", err)
+	}
+	return plan, nil
+}
+
+// postDriftAlert sends alert as a JSON POST to webhook, failing on any non-2xx response
+func postDriftAlert(ctx context.Context, webhook string, alert driftAlert) error {
+	body, err := json.Marshal(alert)
+	if err != nil {
+		return fmt.Errorf("failed to encode alert: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook returned %s", resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,134 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+
+	"replbac/internal/logging"
+	"replbac/internal/models"
+)
+
+// driftSequenceClient returns the next set of remote roles on each poll and cancels the
+// context during the last poll
+type driftSequenceClient struct {
+	*MockClient
+	polls  [][]models.Role
+	cancel context.CancelFunc
+}
+
+// GetRoles returns the roles for the current poll
+func (c *driftSequenceClient) GetRoles() ([]models.Role, error) {
+	roles := c.polls[0]
+	c.polls = c.polls[1:]
+	if len(c.polls) == 0 {
+		c.cancel()
+	}
+	return roles, nil
+}
+
+// NewWatchDriftCommand creates a watch-drift command that uses the given client
+func NewWatchDriftCommand(client *driftSequenceClient) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:  "watch-drift",
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			logger := logging.NewLogger(cmd.ErrOrStderr(), false)
+			return RunWatchDriftCommandWithClient(cmd, args[0], client, models.Config{}, logger)
+		},
+	}
+
+	cmd.Flags().Duration("interval", DefaultDriftInterval, "how often to compare")
+	cmd.Flags().String("webhook", "", "webhook URL")
+
+	return cmd
+}
+
+func TestWatchDrift(t *testing.T) {
+	tempDir := t.TempDir()
+	local := models.Role{Name: "viewer", Resources: models.Resources{Allowed: []string{"kots/app/*/read"}}}
+	if err := createTestRoleFile(tempDir, local); err != nil {
+		t.Fatalf("Failed to create role file: %v", err)
+	}
+
+	edited := models.Role{Name: "viewer", Resources: models.Resources{Allowed: []string{"kots/app/*/read", "kots/app/*/write"}}}
+	added := models.Role{Name: "ui-admin", Resources: models.Resources{Allowed: []string{"**/*"}}}
+
+	var alerts []driftAlert
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var alert driftAlert
+		if err := json.NewDecoder(r.Body).Decode(&alert); err != nil {
+			t.Errorf("Failed to decode webhook body: %v", err)
+		}
+		alerts = append(alerts, alert)
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	client := &driftSequenceClient{
+		MockClient: NewMockClient(&MockAPICalls{}, nil),
+		polls: [][]models.Role{
+			{edited},
+			{edited},
+			{edited, added},
+			{local},
+			{local},
+			{edited},
+			{edited},
+		},
+		cancel: cancel,
+	}
+
+	cmd := NewWatchDriftCommand(client)
+	var stdout bytes.Buffer
+	cmd.SetOut(&stdout)
+	cmd.SetErr(&bytes.Buffer{})
+	cmd.SetArgs([]string{tempDir, "--interval", "1ms", "--webhook", server.URL})
+
+	if err := cmd.ExecuteContext(ctx); err != nil {
+		t.Fatalf("Expected watching to stop cleanly, got %v", err)
+	}
+
+	output := stdout.String()
+	if count := strings.Count(output, "Drift detected"); count != 3 {
+		t.Errorf("Expected 3 drift alerts (repeats debounced), got %d:\n%s", count, output)
+	}
+	if count := strings.Count(output, "drift resolved"); count != 1 {
+		t.Errorf("Expected drift to be reported resolved once, got %d:\n%s", count, output)
+	}
+	if !strings.Contains(output, "Stopped watching for drift") {
+		t.Errorf("Expected a stop message, got:\n%s", output)
+	}
+
+	summaries := make([]string, 0, len(alerts))
+	for _, alert := range alerts {
+		summaries = append(summaries, alert.Summary)
+		if alert.Directory != tempDir || !strings.HasPrefix(alert.Text, "Drift detected") || alert.DetectedAt == "" {
+			t.Errorf("Unexpected webhook alert: %+v", alert)
+		}
+	}
+	expected := []string{"1 to update", "1 to update, 1 to delete", "1 to update"}
+	if strings.Join(summaries, "; ") != strings.Join(expected, "; ") {
+		t.Errorf("Webhook summaries = %v, want %v", summaries, expected)
+	}
+}
+
+func TestWatchDriftRejectsInvalidInterval(t *testing.T) {
+	client := &driftSequenceClient{MockClient: NewMockClient(&MockAPICalls{}, nil), cancel: func() {}}
+	cmd := NewWatchDriftCommand(client)
+	cmd.SetOut(&bytes.Buffer{})
+	cmd.SetErr(&bytes.Buffer{})
+	cmd.SetArgs([]string{t.TempDir(), "--interval", "0s"})
+
+	err := cmd.Execute()
+	if err == nil || !strings.Contains(err.Error(), "--interval must be greater than 0") {
+		t.Errorf("Expected an interval error, got %v", err)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"path"
 	"path/filepath"
 	"runtime"
 	"strconv"
@@ -11,6 +12,7 @@ import (
 
 	"gopkg.in/yaml.v3"
 
+	"replbac/internal/api"
 	"replbac/internal/models"
 )
 
@@ -21,31 +23,128 @@ var validLogLevels = map[string]bool{
 	"error": true,
 }
 
+// SourceDefault is the source reported for settings that keep their default value
+const SourceDefault = "default"
+
+// Resolution records where the effective configuration came from
+type Resolution struct {
+	File    string            // Configuration file that was loaded, or empty if none
+	Sources map[string]string // Source of each setting, keyed by its configuration file field name
+}
+
+// newResolution returns a resolution in which every setting has its default value
+func newResolution() Resolution {
+	return Resolution{Sources: map[string]string{
+		"api_token":            SourceDefault,
+		"api_version":          SourceDefault,
+		"ca_cert":              SourceDefault,
+		"confirm":              SourceDefault,
+		"ignore_resources":     SourceDefault,
+		"insecure_skip_verify": SourceDefault,
+		"log_level":            SourceDefault,
+		"manage":               SourceDefault,
+		"protected_roles":      SourceDefault,
+		"proxy":                SourceDefault,
+		"read_only":            SourceDefault,
+		"transforms":           SourceDefault,
+	}}
+}
+
+// recordFile notes a configuration file as the source of every setting it sets
+func (r *Resolution) recordFile(configPath string, fileConfig models.Config) {
+	r.File = configPath
+	for _, field := range setFields(fileConfig) {
+		r.Sources[field] = "config file " + configPath
+	}
+}
+
+// recordEnv notes the environment variable each setting was read from
+func (r *Resolution) recordEnv(variables map[string]string) {
+	for field, name := range variables {
+		r.Sources[field] = "environment variable " + name
+	}
+}
+
+// setFields returns the field names of the settings that mergeConfigs takes from config
+func setFields(config models.Config) []string {
+	var fields []string
+	if config.APIToken != "" {
+		fields = append(fields, "api_token")
+	}
+	if config.LogLevel != "" {
+		fields = append(fields, "log_level")
+	}
+	if config.Confirm {
+		fields = append(fields, "confirm")
+	}
+	if len(config.ProtectedRoles) > 0 {
+		fields = append(fields, "protected_roles")
+	}
+	if len(config.IgnoreResources) > 0 {
+		fields = append(fields, "ignore_resources")
+	}
+	if config.Manage != "" {
+		fields = append(fields, "manage")
+	}
+	if config.ReadOnly {
+		fields = append(fields, "read_only")
+	}
+	if config.APIVersion != "" {
+		fields = append(fields, "api_version")
+	}
+	if len(config.Transforms) > 0 {
+		fields = append(fields, "transforms")
+	}
+	if config.Proxy != "" {
+		fields = append(fields, "proxy")
+	}
+	if config.CACert != "" {
+		fields = append(fields, "ca_cert")
+	}
+	if config.InsecureSkipVerify {
+		fields = append(fields, "insecure_skip_verify")
+	}
+	return fields
+}
+
 // LoadConfig loads configuration from multiple sources with proper precedence:
 // 1. Environment variables (highest priority)
 // 2. Configuration file
 // 3. Default values (lowest priority)
 func LoadConfig(configPath string) (models.Config, error) {
+	config, _, err := ResolveConfig(configPath)
+	return config, err
+}
+
+// ResolveConfig loads configuration like LoadConfig and also reports where each setting came from
+func ResolveConfig(configPath string) (models.Config, Resolution, error) {
 	// Start with default configuration
 	config := models.Config{
 		LogLevel: "info",
 		Confirm:  false,
 	}
+	resolution := newResolution()
 
 	// Load from config file if provided
 	if configPath != "" {
+		// A path given explicitly must exist; unlike the default locations, it is not optional
+		if _, err := os.Stat(configPath); errors.Is(err, os.ErrNotExist) {
+			return models.Config{}, Resolution{}, fmt.Errorf("config file %s does not exist", configPath)
+		}
 		fileConfig, err := loadFromFile(configPath)
 		if err != nil {
-			return models.Config{}, fmt.Errorf("failed to load config file: %w", err)
+			return models.Config{}, Resolution{}, fmt.Errorf("failed to load config file: %w", err)
 		}
 		mergeConfigs(&config, &fileConfig)
+		resolution.recordFile(configPath, fileConfig)
 	}
 
 	// Load from environment variables (highest priority)
-	envConfig := loadFromEnv()
+	envConfig, envVariables := loadFromEnv()
 	mergeConfigs(&config, &envConfig)
+	resolution.recordEnv(envVariables)
 
-	return config, nil
+	return config, resolution, nil
 }
 
 // GetDefaultConfigPaths returns platform-specific default configuration file paths
@@ -87,19 +186,28 @@ func GetDefaultConfigPaths() []string {
 
 // LoadConfigWithDefaults loads configuration from multiple sources, checking default paths if no explicit path provided
 func LoadConfigWithDefaults(defaultPaths []string) (models.Config, error) {
+	config, _, err := ResolveConfigWithDefaults(defaultPaths)
+	return config, err
+}
+
+// ResolveConfigWithDefaults loads configuration like LoadConfigWithDefaults and also
+// reports where each setting came from
+func ResolveConfigWithDefaults(defaultPaths []string) (models.Config, Resolution, error) {
 	// Start with default configuration
 	config := models.Config{
 		LogLevel: "info",
 		Confirm:  false,
 	}
+	resolution := newResolution()
 
 	// Check if config path is specified via environment variable
 	if configPath := os.Getenv("REPLBAC_CONFIG"); configPath != "" {
 		fileConfig, err := loadFromFile(configPath)
 		if err != nil {
-			return models.Config{}, fmt.Errorf("failed to load config from REPLBAC_CONFIG path: %w", err)
+			return models.Config{}, Resolution{}, fmt.Errorf("failed to load config from REPLBAC_CONFIG path: %w", err)
 		}
 		mergeConfigs(&config, &fileConfig)
+		resolution.recordFile(configPath, fileConfig)
 	} else {
 		// Try to load from default paths
 		if len(defaultPaths) == 0 {
@@ -115,16 +223,18 @@ func LoadConfigWithDefaults(defaultPaths []string) (models.Config, error) {
 					continue
 				}
 				mergeConfigs(&config, &fileConfig)
+				resolution.recordFile(configPath, fileConfig)
 				break // Use first found config file
 			}
 		}
 	}
 
 	// Load from environment variables (highest priority)
-	envConfig := loadFromEnv()
+	envConfig, envVariables := loadFromEnv()
 	mergeConfigs(&config, &envConfig)
+	resolution.recordEnv(envVariables)
 
-	return config, nil
+	return config, resolution, nil
 }
 
 // loadFromFile loads configuration from a YAML or JSON file
@@ -145,30 +255,44 @@ func loadFromFile(configPath string) (models.Config, error) {
 	default:
 		return config, fmt.Errorf("unsupported config file format: %s (only YAML is supported)", ext)
 	}
+	config.APIToken = strings.TrimSpace(config.APIToken)
 
 	return config, nil
 }
 
-// loadFromEnv loads configuration from environment variables
-func loadFromEnv() models.Config {
+// loadFromEnv loads configuration from environment variables, also returning the
+// name of the variable each setting was read from, keyed by field name
+func loadFromEnv() (models.Config, map[string]string) {
 	var config models.Config
+	variables := make(map[string]string)
 
 	// Check REPLICATED_API_TOKEN first (for compatibility with replicated CLI)
-	if val := os.Getenv("REPLICATED_API_TOKEN"); val != "" {
+	// Tokens are trimmed, since values set from files often end in a newline
+	if val := strings.TrimSpace(os.Getenv("REPLICATED_API_TOKEN")); val != "" {
 		config.APIToken = val
-	} else if val := os.Getenv("REPLBAC_API_TOKEN"); val != "" {
+		variables["api_token"] = "REPLICATED_API_TOKEN"
+	} else if val := strings.TrimSpace(os.Getenv("REPLBAC_API_TOKEN")); val != "" {
 		config.APIToken = val
+		variables["api_token"] = "REPLBAC_API_TOKEN"
 	}
 	if val := os.Getenv("REPLBAC_LOG_LEVEL"); val != "" {
 		config.LogLevel = val
+		variables["log_level"] = "REPLBAC_LOG_LEVEL"
 	}
 	if val := os.Getenv("REPLBAC_CONFIRM"); val != "" {
-		if confirm, err := strconv.ParseBool(val); err == nil {
+		if confirm, err := strconv.ParseBool(val); err == nil && confirm {
 			config.Confirm = confirm
+			variables["confirm"] = "REPLBAC_CONFIRM"
+		}
+	}
+	if val := os.Getenv("REPLBAC_READ_ONLY"); val != "" {
+		if readOnly, err := strconv.ParseBool(val); err == nil && readOnly {
+			config.ReadOnly = readOnly
+			variables["read_only"] = "REPLBAC_READ_ONLY"
 		}
 	}
 
-	return config
+	return config, variables
 }
 
 // mergeConfigs merges source config into target, only overriding non-zero values
@@ -184,6 +308,33 @@ func mergeConfigs(target, source *models.Config) {
 	if source.Confirm {
 		target.Confirm = source.Confirm
 	}
+	if len(source.ProtectedRoles) > 0 {
+		target.ProtectedRoles = source.ProtectedRoles
+	}
+	if len(source.IgnoreResources) > 0 {
+		target.IgnoreResources = source.IgnoreResources
+	}
+	if source.Manage != "" {
+		target.Manage = source.Manage
+	}
+	if source.ReadOnly {
+		target.ReadOnly = source.ReadOnly
+	}
+	if source.APIVersion != "" {
+		target.APIVersion = source.APIVersion
+	}
+	if len(source.Transforms) > 0 {
+		target.Transforms = source.Transforms
+	}
+	if source.Proxy != "" {
+		target.Proxy = source.Proxy
+	}
+	if source.CACert != "" {
+		target.CACert = source.CACert
+	}
+	if source.InsecureSkipVerify {
+		target.InsecureSkipVerify = source.InsecureSkipVerify
+	}
 }
 
 // ValidateConfig validates the configuration and returns an error if invalid
@@ -198,6 +349,31 @@ func ValidateConfig(config models.Config) error {
 		return errors.New("invalid log level")
 	}
 
+	// Validate protected role patterns
+	for _, pattern := range config.ProtectedRoles {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid protected role pattern %q: %w", pattern, err)
+		}
+	}
+
+	if err := models.ValidateManage(config.Manage); err != nil {
+		return err
+	}
+
+	// Validate API version
+	if config.APIVersion != "" {
+		if err := api.ValidateAPIVersion(config.APIVersion); err != nil {
+			return err
+		}
+	}
+
+	// Validate proxy URL
+	if config.Proxy != "" {
+		if err := api.ValidateProxyURL(config.Proxy); err != nil {
+			return err
+		}
+	}
+
 	// Note: API endpoint is now hardcoded to models.ReplicatedAPIEndpoint
 
 	return nil
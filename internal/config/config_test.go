@@ -51,6 +51,72 @@ confirm: true`,
 				Confirm:  true,
 			},
 		},
+		{
+			name:       "loads protected roles from YAML config file",
+			configFile: "config.yaml",
+			configContent: `api_token: yaml-token
+protected_roles:
+  - platform-admin
+  - team-*`,
+			expectedConfig: models.Config{
+				APIToken:       "yaml-token",
+				LogLevel:       "info",
+				ProtectedRoles: []string{"platform-admin", "team-*"},
+			},
+		},
+		{
+			name:       "loads ignored resources from YAML config file",
+			configFile: "config.yaml",
+			configContent: `api_token: yaml-token
+ignore_resources:
+  - kots/app/*/platform-managed`,
+			expectedConfig: models.Config{
+				APIToken:        "yaml-token",
+				LogLevel:        "info",
+				IgnoreResources: []string{"kots/app/*/platform-managed"},
+			},
+		},
+		{
+			name:       "loads manage mode from YAML config file",
+			configFile: "config.yaml",
+			configContent: `api_token: yaml-token
+manage: denied-only`,
+			expectedConfig: models.Config{
+				APIToken: "yaml-token",
+				LogLevel: "info",
+				Manage:   models.ManageDeniedOnly,
+			},
+		},
+		{
+			name:       "loads transforms from YAML config file",
+			configFile: "config.yaml",
+			configContent: `api_token: yaml-token
+transforms:
+  - name: ensure-deny
+    resources:
+      - admin/secrets/**
+  - name: add-prefix
+    prefix: team-`,
+			expectedConfig: models.Config{
+				APIToken: "yaml-token",
+				LogLevel: "info",
+				Transforms: []models.TransformConfig{
+					{Name: "ensure-deny", Resources: []string{"admin/secrets/**"}},
+					{Name: "add-prefix", Prefix: "team-"},
+				},
+			},
+		},
+		{
+			name:       "loads read-only mode from YAML config file",
+			configFile: "config.yaml",
+			configContent: `api_token: yaml-token
+read_only: true`,
+			expectedConfig: models.Config{
+				APIToken: "yaml-token",
+				LogLevel: "info",
+				ReadOnly: true,
+			},
+		},
 		{
 			name: "environment variables override config file",
 			envVars: map[string]string{
@@ -154,6 +220,15 @@ confirm: true`,
 			if config.Confirm != tt.expectedConfig.Confirm {
 				t.Errorf("Confirm = %v, want %v", config.Confirm, tt.expectedConfig.Confirm)
 			}
+			if strings.Join(config.ProtectedRoles, ",") != strings.Join(tt.expectedConfig.ProtectedRoles, ",") {
+				t.Errorf("ProtectedRoles = %v, want %v", config.ProtectedRoles, tt.expectedConfig.ProtectedRoles)
+			}
+			if strings.Join(config.IgnoreResources, ",") != strings.Join(tt.expectedConfig.IgnoreResources, ",") {
+				t.Errorf("IgnoreResources = %v, want %v", config.IgnoreResources, tt.expectedConfig.IgnoreResources)
+			}
+			if config.Manage != tt.expectedConfig.Manage {
+				t.Errorf("Manage = %v, want %v", config.Manage, tt.expectedConfig.Manage)
+			}
 		})
 	}
 }
@@ -189,6 +264,46 @@ func TestValidateConfig(t *testing.T) {
 			expectError: true,
 			errorMsg:    "invalid log level",
 		},
+		{
+			name: "invalid protected role pattern",
+			config: models.Config{
+				APIToken:       "valid-token",
+				LogLevel:       "info",
+				ProtectedRoles: []string{"team-["},
+			},
+			expectError: true,
+			errorMsg:    `invalid protected role pattern "team-[": syntax error in pattern`,
+		},
+		{
+			name: "unknown manage mode",
+			config: models.Config{
+				APIToken: "valid-token",
+				LogLevel: "info",
+				Manage:   "allowed-only",
+			},
+			expectError: true,
+			errorMsg:    `invalid manage mode "allowed-only": must be all or denied-only`,
+		},
+		{
+			name: "unsupported API version",
+			config: models.Config{
+				APIToken:   "valid-token",
+				LogLevel:   "info",
+				APIVersion: "v9",
+			},
+			expectError: true,
+			errorMsg:    `unsupported API version "v9" (supported: v3)`,
+		},
+		{
+			name: "unsupported proxy scheme",
+			config: models.Config{
+				APIToken: "valid-token",
+				LogLevel: "info",
+				Proxy:    "ftp://proxy.example.com",
+			},
+			expectError: true,
+			errorMsg:    `invalid proxy URL "ftp://proxy.example.com": scheme must be http, https, or socks5`,
+		},
 	}
 
 	for _, tt := range tests {
@@ -373,3 +488,70 @@ func cleanupEnv() {
 		_ = os.Unsetenv(env)
 	}
 }
+
+func TestResolveConfigSources(t *testing.T) {
+	tmpDir := t.TempDir()
+	configPath := filepath.Join(tmpDir, "config.yaml")
+	if err := os.WriteFile(configPath, []byte("api_token: yaml-token\nlog_level: warn\nprotected_roles:\n  - platform-admin\n"), 0600); err != nil {
+		t.Fatalf("Failed to create config file: %v", err)
+	}
+
+	t.Setenv("REPLICATED_API_TOKEN", "")
+	t.Setenv("REPLBAC_API_TOKEN", "env-token")
+	t.Setenv("REPLBAC_LOG_LEVEL", "")
+	t.Setenv("REPLBAC_CONFIRM", "")
+	t.Setenv("REPLBAC_READ_ONLY", "true")
+
+	config, resolution, err := ResolveConfig(configPath)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if config.APIToken != "env-token" || config.LogLevel != "warn" || !config.ReadOnly {
+		t.Errorf("Unexpected config: %+v", config)
+	}
+	if resolution.File != configPath {
+		t.Errorf("File = %q, want %q", resolution.File, configPath)
+	}
+
+	expected := map[string]string{
+		"api_token":       "environment variable REPLBAC_API_TOKEN",
+		"log_level":       "config file " + configPath,
+		"confirm":         SourceDefault,
+		"protected_roles": "config file " + configPath,
+		"read_only":       "environment variable REPLBAC_READ_ONLY",
+	}
+	for field, source := range expected {
+		if resolution.Sources[field] != source {
+			t.Errorf("Sources[%s] = %q, want %q", field, resolution.Sources[field], source)
+		}
+	}
+}
+
+func TestLoadConfigTrimsAPIToken(t *testing.T) {
+	tmpDir := t.TempDir()
+	configPath := filepath.Join(tmpDir, "config.yaml")
+	if err := os.WriteFile(configPath, []byte("api_token: \"yaml-token\\n\"\n"), 0600); err != nil {
+		t.Fatalf("Failed to create config file: %v", err)
+	}
+	t.Setenv("REPLICATED_API_TOKEN", "")
+	t.Setenv("REPLBAC_API_TOKEN", "")
+
+	config, err := LoadConfig(configPath)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if config.APIToken != "yaml-token" {
+		t.Errorf("APIToken from config file = %q, want %q", config.APIToken, "yaml-token")
+	}
+
+	t.Setenv("REPLICATED_API_TOKEN", " \n")
+	t.Setenv("REPLBAC_API_TOKEN", "env-token\n")
+	config, err = LoadConfig(configPath)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if config.APIToken != "env-token" {
+		t.Errorf("APIToken from environment = %q, want %q", config.APIToken, "env-token")
+	}
+}
@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"io"
 	"regexp"
+	"strings"
+	"sync"
 	"time"
 )
 
@@ -22,6 +24,21 @@ type Logger struct {
 	output  io.Writer
 	level   LogLevel
 	verbose bool
+
+	// outputMu serializes writes so concurrent operations can share a logger
+	outputMu sync.Mutex
+
+	// httpTrace enables full request/response logging, independent of level
+	httpTrace bool
+
+	timingsMu sync.Mutex
+	timings   []OperationTiming
+}
+
+// OperationTiming records how long a named operation took
+type OperationTiming struct {
+	Operation string
+	Duration  time.Duration
 }
 
 // NewLogger creates a new logger instance that outputs to stderr by default
@@ -48,6 +65,14 @@ func NewDebugLogger(output io.Writer) *Logger {
 	}
 }
 
+// AddOutput makes the logger also write every message it logs to w, at the same level,
+// for example to keep a log file alongside stderr
+func (l *Logger) AddOutput(w io.Writer) {
+	l.outputMu.Lock()
+	defer l.outputMu.Unlock()
+	l.output = io.MultiWriter(l.output, w)
+}
+
 // Debug logs debug-level messages (only shown in verbose mode)
 func (l *Logger) Debug(msg string, args ...interface{}) {
 	if l.level <= DebugLevel {
@@ -76,6 +101,23 @@ func (l *Logger) Error(msg string, args ...interface{}) {
 	}
 }
 
+// Trace logs HTTP trace messages (only shown when HTTP tracing is enabled)
+func (l *Logger) Trace(msg string, args ...interface{}) {
+	if l.httpTrace {
+		l.log("TRACE", msg, args...)
+	}
+}
+
+// EnableHTTPTrace turns on full HTTP request/response logging
+func (l *Logger) EnableHTTPTrace() {
+	l.httpTrace = true
+}
+
+// HTTPTraceEnabled returns whether HTTP request/response logging is enabled
+func (l *Logger) HTTPTraceEnabled() bool {
+	return l.httpTrace
+}
+
 // TimedOperation tracks and logs the duration of an operation
 func (l *Logger) TimedOperation(operation string, fn func() error) error {
 	l.Info("starting %s", operation)
@@ -83,6 +125,7 @@ func (l *Logger) TimedOperation(operation string, fn func() error) error {
 
 	err := fn()
 	duration := time.Since(start)
+	l.recordTiming(operation, duration)
 
 	if err != nil {
 		l.Error("%s failed after %v: %v", operation, duration, err)
@@ -93,6 +136,32 @@ func (l *Logger) TimedOperation(operation string, fn func() error) error {
 	return err
 }
 
+// recordTiming stores the duration of a completed operation for later reporting
+func (l *Logger) recordTiming(operation string, duration time.Duration) {
+	l.timingsMu.Lock()
+	defer l.timingsMu.Unlock()
+	l.timings = append(l.timings, OperationTiming{Operation: operation, Duration: duration})
+}
+
+// Timings returns the durations of all operations run through TimedOperation, in completion order
+func (l *Logger) Timings() []OperationTiming {
+	l.timingsMu.Lock()
+	defer l.timingsMu.Unlock()
+	timings := make([]OperationTiming, len(l.timings))
+	copy(timings, l.timings)
+	return timings
+}
+
+// TimingSummary returns a one-line breakdown of operation durations, e.g. "fetch remote roles 1.2s, compare roles 3ms"
+func (l *Logger) TimingSummary() string {
+	timings := l.Timings()
+	parts := make([]string, 0, len(timings))
+	for _, timing := range timings {
+		parts = append(parts, fmt.Sprintf("%s %v", timing.Operation, timing.Duration.Round(time.Millisecond)))
+	}
+	return strings.Join(parts, ", ")
+}
+
 // log formats and writes log messages with sensitive data sanitization
 func (l *Logger) log(level, msg string, args ...interface{}) {
 	timestamp := time.Now().Format("15:04:05")
@@ -103,6 +172,8 @@ func (l *Logger) log(level, msg string, args ...interface{}) {
 	// Then sanitize the complete formatted message
 	sanitizedMsg := l.sanitizeString(formattedMsg)
 
+	l.outputMu.Lock()
+	defer l.outputMu.Unlock()
 	_, _ = fmt.Fprintf(l.output, "[%s] %s %s\n", level, timestamp, sanitizedMsg)
 }
 
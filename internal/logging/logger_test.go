@@ -2,6 +2,7 @@ package logging
 
 import (
 	"bytes"
+	"errors"
 	"strings"
 	"testing"
 )
@@ -187,3 +188,45 @@ func TestVerboseMode(t *testing.T) {
 		t.Error("Error message should appear in verbose mode")
 	}
 }
+
+func TestAddOutput(t *testing.T) {
+	var stderr, file bytes.Buffer
+	logger := NewLogger(&stderr, true)
+	logger.AddOutput(&file)
+
+	logger.Debug("debug message")
+	logger.Info("info message with token=secret123")
+
+	for name, output := range map[string]string{"stderr": stderr.String(), "file": file.String()} {
+		if strings.Contains(output, "debug message") {
+			t.Errorf("Debug message should not reach %s at info level", name)
+		}
+		if !strings.Contains(output, "info message") {
+			t.Errorf("Info message should reach %s, got %q", name, output)
+		}
+		if strings.Contains(output, "secret123") {
+			t.Errorf("Sensitive data should be redacted in %s, got %q", name, output)
+		}
+	}
+}
+
+func TestTimedOperationRecordsTimings(t *testing.T) {
+	var buf bytes.Buffer
+	logger := NewLogger(&buf, false)
+
+	_ = logger.TimedOperation("load local roles", func() error { return nil })
+	_ = logger.TimedOperation("fetch remote roles", func() error { return errors.New("boom") })
+
+	timings := logger.Timings()
+	if len(timings) != 2 {
+		t.Fatalf("Expected 2 recorded timings, got %d", len(timings))
+	}
+	if timings[0].Operation != "load local roles" || timings[1].Operation != "fetch remote roles" {
+		t.Errorf("Timings not recorded in completion order: %+v", timings)
+	}
+
+	summary := logger.TimingSummary()
+	if !strings.HasPrefix(summary, "load local roles ") || !strings.Contains(summary, ", fetch remote roles ") {
+		t.Errorf("Unexpected timing summary: %q", summary)
+	}
+}
@@ -1,33 +1,240 @@
 package models
 
 import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
+	"sort"
+	"strings"
 )
 
 // Constants for hardcoded values
 const (
 	// ReplicatedAPIEndpoint is the hardcoded Replicated API endpoint
 	ReplicatedAPIEndpoint = "https://api.replicated.com"
+
+	// DefaultAPIVersion is the vendor API version used unless api_version selects another
+	DefaultAPIVersion = "v3"
+)
+
+// Manage modes, set per role with a role file's manage field or for every role with the
+// manage configuration setting
+const (
+	// ManageAll has replbac own both the allowed and denied resources of a role. It is
+	// the default.
+	ManageAll = "all"
+
+	// ManageDeniedOnly has replbac enforce only a role's denied resources. The allowed
+	// resources of an existing remote role are left as they are, for roles whose allows
+	// are managed by another tool.
+	ManageDeniedOnly = "denied-only"
 )
 
+// ValidateManage returns an error if mode is not a manage mode; empty means ManageAll
+func ValidateManage(mode string) error {
+	switch mode {
+	case "", ManageAll, ManageDeniedOnly:
+		return nil
+	}
+	return fmt.Errorf("invalid manage mode %q: must be %s or %s", mode, ManageAll, ManageDeniedOnly)
+}
+
 // Resources represents the allowed and denied resources for a role
 type Resources struct {
 	Allowed []string `yaml:"allowed" json:"allowed"`
 	Denied  []string `yaml:"denied" json:"denied"`
 }
 
+// Normalized returns a copy of the resources with nil lists replaced by empty ones.
+// Roles read from files and from the API are normalized this way, so a missing list
+// and an empty one look the same to comparisons and in structured output.
+func (r Resources) Normalized() Resources {
+	if r.Allowed == nil {
+		r.Allowed = []string{}
+	}
+	if r.Denied == nil {
+		r.Denied = []string{}
+	}
+	return r
+}
+
 // Role represents a role as stored in local YAML files
 type Role struct {
 	ID        string    `yaml:"id,omitempty" json:"id,omitempty"`
 	Name      string    `yaml:"name" json:"name"`
 	Resources Resources `yaml:"resources" json:"resources"`
 	Members   []string  `yaml:"members,omitempty" json:"members,omitempty"`
+
+	// Labels is arbitrary metadata for the user's own tooling. The API does not interpret
+	// it; it is kept in the policy definition under LabelsDefinitionKey.
+	Labels map[string]string `yaml:"labels,omitempty" json:"labels,omitempty"`
+
+	// Inherits names a base role whose allowed and denied resources are merged into this
+	// role's when role files are loaded. It only exists in files; the API sees the merge.
+	Inherits string `yaml:"inherits,omitempty" json:"-"`
+
+	// Manage is the role's manage mode, ManageAll or ManageDeniedOnly, overriding the
+	// manage configuration setting. Empty uses the setting. It only exists in files.
+	Manage string `yaml:"manage,omitempty" json:"-"`
+
+	// SourceFile is the path of the file the role was loaded from, if any. It is
+	// only used to point at the file in messages and is not part of the role's content.
+	SourceFile string `yaml:"-" json:"-"`
+
+	// MemberDetails holds the team member records behind Members when the role was
+	// read from the API. Files keep plain emails, so it is only used for display.
+	MemberDetails []TeamMember `yaml:"-" json:"-"`
+
+	// Extra holds top-level keys of the role's file that replbac does not recognize, such
+	// as metadata for other tooling, so that rewriting the file keeps them. They are not
+	// sent to the API and do not affect comparisons.
+	Extra map[string]interface{} `yaml:"-" json:"-"`
+}
+
+// Origin returns " (from <file>)" naming the file the role was loaded from, or an
+// empty string if the role did not come from a file, for appending to messages
+func (r Role) Origin() string {
+	if r.SourceFile == "" {
+		return ""
+	}
+	return fmt.Sprintf(" (from %s)", r.SourceFile)
+}
+
+// LabelPairs returns the role's labels as sorted "key=value" strings, for comparing and
+// displaying them like the role's other lists
+func (r Role) LabelPairs() []string {
+	pairs := make([]string, 0, len(r.Labels))
+	for key, value := range r.Labels {
+		pairs = append(pairs, key+"="+value)
+	}
+	sort.Strings(pairs)
+	return pairs
+}
+
+// ContentHash returns a deterministic hash of the role's meaningful content: its name,
+// allowed and denied resources, members, and labels. The API-managed ID is ignored, as are
+// slice ordering, nil-vs-empty differences, and the case of member emails, so two roles
+// that compare equal during sync always produce the same hash.
+func (r Role) ContentHash() string {
+	canonical := struct {
+		Name    string   `json:"name"`
+		Allowed []string `json:"allowed"`
+		Denied  []string `json:"denied"`
+		Members []string `json:"members"`
+		// Left out when empty, so hashes of unlabeled roles are unchanged
+		Labels map[string]string `json:"labels,omitempty"`
+	}{
+		Name:    r.Name,
+		Allowed: sortedCopy(r.Resources.Allowed),
+		Denied:  sortedCopy(r.Resources.Denied),
+		Members: sortedCopy(NormalizeEmails(r.Members)),
+		Labels:  r.Labels,
+	}
+
+	// Marshaling strings, string slices, and a string map cannot fail
+	data, _ := json.Marshal(canonical)
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// NormalizeEmail returns the form of a member email used to compare it: trimmed and
+// lowercased. Emails are still shown and sent to the API as written.
+func NormalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+// NormalizeEmails returns the normalized form of each email, as NormalizeEmail does
+func NormalizeEmails(emails []string) []string {
+	normalized := make([]string, len(emails))
+	for i, email := range emails {
+		normalized[i] = NormalizeEmail(email)
+	}
+	return normalized
+}
+
+// sortedCopy returns a sorted copy of values, treating nil as empty
+func sortedCopy(values []string) []string {
+	sorted := make([]string, len(values))
+	copy(sorted, values)
+	sort.Strings(sorted)
+	return sorted
 }
 
-// APIRole represents a role as expected by the Replicated API with v1 wrapper
+// LabelsDefinitionKey is the key under which a role's labels are stored in the policy
+// definition, next to the versioned role rather than inside it, so the API's own schema
+// is untouched
+const LabelsDefinitionKey = "replbac.io/labels"
+
+// DefaultDefinitionVersion is the key wrapping the role in a policy definition,
+// as in {"v1": {...}}
+const DefaultDefinitionVersion = "v1"
+
+// DefinitionVersion is the key used to write and read the role in policy definitions.
+// It only needs changing to try out a newer definition schema.
+var DefinitionVersion = DefaultDefinitionVersion
+
+// APIRole represents a role as expected by the Replicated API, wrapped in its definition
+// version, e.g. {"v1": {...}, "replbac.io/labels": {...}}
 type APIRole struct {
-	V1 Role `json:"v1"`
+	Role   Role              // Stored under DefinitionVersion
+	Labels map[string]string // Stored under LabelsDefinitionKey
+}
+
+// MarshalJSON writes the role under DefinitionVersion, followed by any labels
+func (ar APIRole) MarshalJSON() ([]byte, error) {
+	version, err := json.Marshal(DefinitionVersion)
+	if err != nil {
+		return nil, err
+	}
+	role, err := json.Marshal(ar.Role)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	buf.Write(version)
+	buf.WriteByte(':')
+	buf.Write(role)
+	if len(ar.Labels) > 0 {
+		labels, err := json.Marshal(ar.Labels)
+		if err != nil {
+			return nil, err
+		}
+		key, _ := json.Marshal(LabelsDefinitionKey)
+		buf.WriteByte(',')
+		buf.Write(key)
+		buf.WriteByte(':')
+		buf.Write(labels)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// UnmarshalJSON reads the role from under DefinitionVersion, failing if the definition
+// has no role of that version, so a schema change is not mistaken for an empty role
+func (ar *APIRole) UnmarshalJSON(data []byte) error {
+	var definition map[string]json.RawMessage
+	if err := json.Unmarshal(data, &definition); err != nil {
+		return err
+	}
+
+	raw, exists := definition[DefinitionVersion]
+	if !exists {
+		return fmt.Errorf("definition has no %q role", DefinitionVersion)
+	}
+	*ar = APIRole{}
+	if err := json.Unmarshal(raw, &ar.Role); err != nil {
+		return err
+	}
+	if labels, exists := definition[LabelsDefinitionKey]; exists {
+		if err := json.Unmarshal(labels, &ar.Labels); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 // Policy represents a full policy object from the Replicated API
@@ -44,14 +251,22 @@ type Policy struct {
 
 // ToAPIRole converts a Role to an APIRole for API communication
 func (r Role) ToAPIRole() APIRole {
+	role := r
+	role.Labels = nil
 	return APIRole{
-		V1: r,
+		Role:   role,
+		Labels: r.Labels,
 	}
 }
 
 // ToRole converts an APIRole to a Role for local processing
 func (ar APIRole) ToRole() Role {
-	return ar.V1
+	role := ar.Role
+	role.Resources = role.Resources.Normalized()
+	if len(ar.Labels) > 0 {
+		role.Labels = ar.Labels
+	}
+	return role
 }
 
 // ToRole converts a Policy to a Role by parsing the definition JSON
@@ -96,6 +311,27 @@ func (tm TeamMember) IsPendingInvite() bool {
 	return false
 }
 
+// DisplayName returns the member's email followed by their name and username,
+// when the API provided them, e.g. "alice@example.com (Alice Smith, @alice)"
+func (tm TeamMember) DisplayName() string {
+	email := tm.Email
+	if email == "" {
+		email = tm.ID
+	}
+
+	var details []string
+	if tm.Name != "" {
+		details = append(details, tm.Name)
+	}
+	if tm.Username != "" {
+		details = append(details, "@"+tm.Username)
+	}
+	if len(details) == 0 {
+		return email
+	}
+	return fmt.Sprintf("%s (%s)", email, strings.Join(details, ", "))
+}
+
 // InviteUserRequest represents the request payload for inviting a user
 type InviteUserRequest struct {
 	Email    string `json:"email"`
@@ -116,4 +352,44 @@ type Config struct {
 	APIToken string `yaml:"api_token" json:"api_token"`
 	Confirm  bool   `yaml:"confirm" json:"confirm"`
 	LogLevel string `yaml:"log_level" json:"log_level"`
+
+	// ReadOnly blocks every write to the Replicated API, whatever the command
+	ReadOnly bool `yaml:"read_only,omitempty" json:"read_only,omitempty"`
+
+	// APIVersion selects the vendor API version, e.g. v3; empty means DefaultAPIVersion
+	APIVersion string `yaml:"api_version,omitempty" json:"api_version,omitempty"`
+
+	// ProtectedRoles lists role names or glob patterns for remote roles that sync never deletes
+	ProtectedRoles []string `yaml:"protected_roles,omitempty" json:"protected_roles,omitempty"`
+
+	// IgnoreResources lists resource strings the API manages itself, such as entries it adds
+	// to every policy, which are left out when local and remote roles are compared
+	IgnoreResources []string `yaml:"ignore_resources,omitempty" json:"ignore_resources,omitempty"`
+
+	// Manage is the manage mode, ManageAll or ManageDeniedOnly, of roles whose files do
+	// not set one; empty means ManageAll
+	Manage string `yaml:"manage,omitempty" json:"manage,omitempty"`
+
+	// Transforms lists the transforms applied, in order, to every local role before comparison
+	Transforms []TransformConfig `yaml:"transforms,omitempty" json:"transforms,omitempty"`
+
+	// Proxy is the URL of the proxy for API requests, with credentials if it requires them;
+	// empty uses the HTTPS_PROXY, HTTP_PROXY, and NO_PROXY environment variables
+	Proxy string `yaml:"proxy,omitempty" json:"proxy,omitempty"`
+
+	// CACert is a PEM file of certificate authorities to trust for the API, in addition to the system's
+	CACert string `yaml:"ca_cert,omitempty" json:"ca_cert,omitempty"`
+
+	// InsecureSkipVerify disables verification of the API's TLS certificate
+	InsecureSkipVerify bool `yaml:"insecure_skip_verify,omitempty" json:"insecure_skip_verify,omitempty"`
+}
+
+// TransformConfig configures one transform in the transforms pipeline
+type TransformConfig struct {
+	// Name selects the transform, e.g. ensure-deny or add-prefix
+	Name string `yaml:"name" json:"name"`
+	// Resources lists the resources ensure-deny adds to every role's denied list
+	Resources []string `yaml:"resources,omitempty" json:"resources,omitempty"`
+	// Prefix is the text add-prefix puts before every role name
+	Prefix string `yaml:"prefix,omitempty" json:"prefix,omitempty"`
 }
@@ -2,6 +2,7 @@ package models
 
 import (
 	"encoding/json"
+	"strings"
 	"testing"
 
 	"gopkg.in/yaml.v3"
@@ -48,7 +49,7 @@ func TestRole_YAMLMarshaling(t *testing.T) {
 
 func TestAPIRole_JSONMarshaling(t *testing.T) {
 	apiRole := APIRole{
-		V1: Role{
+		Role: Role{
 			Name: "View Customers Only",
 			Resources: Resources{
 				Allowed: []string{
@@ -76,8 +77,8 @@ func TestAPIRole_JSONMarshaling(t *testing.T) {
 	}
 
 	// Verify the data is preserved
-	if unmarshaledAPIRole.V1.Name != apiRole.V1.Name {
-		t.Errorf("Expected name %s, got %s", apiRole.V1.Name, unmarshaledAPIRole.V1.Name)
+	if unmarshaledAPIRole.Role.Name != apiRole.Role.Name {
+		t.Errorf("Expected name %s, got %s", apiRole.Role.Name, unmarshaledAPIRole.Role.Name)
 	}
 }
 
@@ -92,17 +93,17 @@ func TestRole_ToAPIRole(t *testing.T) {
 
 	apiRole := role.ToAPIRole()
 
-	if apiRole.V1.Name != role.Name {
-		t.Errorf("Expected API role name %s, got %s", role.Name, apiRole.V1.Name)
+	if apiRole.Role.Name != role.Name {
+		t.Errorf("Expected API role name %s, got %s", role.Name, apiRole.Role.Name)
 	}
-	if len(apiRole.V1.Resources.Allowed) != len(role.Resources.Allowed) {
-		t.Errorf("Expected %d allowed resources, got %d", len(role.Resources.Allowed), len(apiRole.V1.Resources.Allowed))
+	if len(apiRole.Role.Resources.Allowed) != len(role.Resources.Allowed) {
+		t.Errorf("Expected %d allowed resources, got %d", len(role.Resources.Allowed), len(apiRole.Role.Resources.Allowed))
 	}
 }
 
 func TestAPIRole_ToRole(t *testing.T) {
 	apiRole := APIRole{
-		V1: Role{
+		Role: Role{
 			Name: "Test Role",
 			Resources: Resources{
 				Allowed: []string{"resource1", "resource2"},
@@ -113,11 +114,69 @@ func TestAPIRole_ToRole(t *testing.T) {
 
 	role := apiRole.ToRole()
 
-	if role.Name != apiRole.V1.Name {
-		t.Errorf("Expected role name %s, got %s", apiRole.V1.Name, role.Name)
+	if role.Name != apiRole.Role.Name {
+		t.Errorf("Expected role name %s, got %s", apiRole.Role.Name, role.Name)
 	}
-	if len(role.Resources.Allowed) != len(apiRole.V1.Resources.Allowed) {
-		t.Errorf("Expected %d allowed resources, got %d", len(apiRole.V1.Resources.Allowed), len(role.Resources.Allowed))
+	if len(role.Resources.Allowed) != len(apiRole.Role.Resources.Allowed) {
+		t.Errorf("Expected %d allowed resources, got %d", len(apiRole.Role.Resources.Allowed), len(role.Resources.Allowed))
+	}
+}
+
+func TestAPIRole_DefinitionVersion(t *testing.T) {
+	role := Role{Name: "viewer", Resources: Resources{Allowed: []string{"kots/app/*/read"}, Denied: []string{}}}
+
+	definition, err := json.Marshal(role.ToAPIRole())
+	if err != nil {
+		t.Fatalf("Failed to marshal APIRole: %v", err)
+	}
+	if !strings.HasPrefix(string(definition), `{"v1":{`) {
+		t.Errorf("Expected the role under v1 by default, got %s", definition)
+	}
+
+	defer func() { DefinitionVersion = DefaultDefinitionVersion }()
+	DefinitionVersion = "v2"
+
+	// A v1 definition is not read as a v2 role
+	if _, err := (Policy{Name: "viewer", Definition: string(definition)}).ToRole(); err == nil || !strings.Contains(err.Error(), `no "v2" role`) {
+		t.Errorf("Expected an error naming the missing version, got %v", err)
+	}
+
+	definition, err = json.Marshal(role.ToAPIRole())
+	if err != nil {
+		t.Fatalf("Failed to marshal APIRole: %v", err)
+	}
+	if !strings.HasPrefix(string(definition), `{"v2":{`) {
+		t.Errorf("Expected the role under the overridden version, got %s", definition)
+	}
+	got, err := Policy{Name: "viewer", Definition: string(definition)}.ToRole()
+	if err != nil {
+		t.Fatalf("ToRole failed: %v", err)
+	}
+	if got.Resources.Allowed[0] != "kots/app/*/read" {
+		t.Errorf("Expected the role to round-trip, got %+v", got)
+	}
+}
+
+func TestPolicy_ToRoleNormalizesResourceLists(t *testing.T) {
+	for _, definition := range []string{
+		`{"v1":{"name":"viewer","resources":{"allowed":["kots/app/*/read"]}}}`,
+		`{"v1":{"name":"viewer","resources":{"allowed":["kots/app/*/read"],"denied":null}}}`,
+		`{"v1":{"name":"viewer","resources":{"allowed":["kots/app/*/read"],"denied":[]}}}`,
+	} {
+		role, err := Policy{ID: "policy-1", Name: "viewer", Definition: definition}.ToRole()
+		if err != nil {
+			t.Fatalf("ToRole(%s) failed: %v", definition, err)
+		}
+		if role.Resources.Denied == nil || len(role.Resources.Denied) != 0 {
+			t.Errorf("ToRole(%s) denied = %#v, want empty non-nil list", definition, role.Resources.Denied)
+		}
+		output, err := json.Marshal(role)
+		if err != nil {
+			t.Fatalf("Failed to marshal role: %v", err)
+		}
+		if !strings.Contains(string(output), `"denied":[]`) {
+			t.Errorf("Expected JSON to contain an empty denied list, got %s", output)
+		}
 	}
 }
 
@@ -281,3 +340,142 @@ func TestRole_WithMembers_JSONMarshaling(t *testing.T) {
 		}
 	}
 }
+
+func TestRole_ContentHash(t *testing.T) {
+	base := Role{
+		ID:   "role-1",
+		Name: "admin",
+		Resources: Resources{
+			Allowed: []string{"kots/app/*/read", "kots/app/*/write"},
+			Denied:  nil,
+		},
+		Members: []string{"b@example.com", "a@example.com"},
+	}
+
+	equivalent := Role{
+		ID:   "different-id",
+		Name: "admin",
+		Resources: Resources{
+			Allowed: []string{"kots/app/*/write", "kots/app/*/read"},
+			Denied:  []string{},
+		},
+		Members: []string{"a@example.com", "b@example.com"},
+	}
+
+	hash := base.ContentHash()
+	if len(hash) != 64 {
+		t.Errorf("Expected a hex-encoded SHA-256 hash, got %q", hash)
+	}
+	if hash != base.ContentHash() {
+		t.Error("ContentHash should be deterministic")
+	}
+	if hash != equivalent.ContentHash() {
+		t.Error("ContentHash should ignore ID, slice order, and nil-vs-empty slices")
+	}
+
+	fromFile := base
+	fromFile.SourceFile = "prod/admin.yaml"
+	if hash != fromFile.ContentHash() {
+		t.Error("ContentHash should ignore the source file")
+	}
+
+	// Moving a resource between lists must change the hash even though the combined content is the same
+	moved := Role{
+		Name: "admin",
+		Resources: Resources{
+			Allowed: []string{"kots/app/*/read"},
+			Denied:  []string{"kots/app/*/write"},
+		},
+		Members: []string{"a@example.com", "b@example.com"},
+	}
+	if hash == moved.ContentHash() {
+		t.Error("ContentHash should distinguish allowed from denied resources")
+	}
+
+	labeled := base
+	labeled.Labels = map[string]string{"tier": "prod"}
+	if hash == labeled.ContentHash() {
+		t.Error("ContentHash should include labels")
+	}
+	emptyLabels := base
+	emptyLabels.Labels = map[string]string{}
+	if hash != emptyLabels.ContentHash() {
+		t.Error("ContentHash should not change for an empty label map")
+	}
+}
+
+func TestRole_LabelsRoundTripThroughPolicy(t *testing.T) {
+	role := Role{
+		Name:      "payments-viewer",
+		Resources: Resources{Allowed: []string{"kots/app/*/read"}},
+		Labels:    map[string]string{"team": "payments", "tier": "prod"},
+	}
+
+	definition, err := json.Marshal(role.ToAPIRole())
+	if err != nil {
+		t.Fatalf("Failed to marshal APIRole: %v", err)
+	}
+
+	// Labels are stored next to the v1 role under the namespaced key, not inside it
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(definition, &raw); err != nil {
+		t.Fatalf("Failed to parse definition: %v", err)
+	}
+	if _, ok := raw[LabelsDefinitionKey]; !ok {
+		t.Errorf("Expected definition to have key %q, got %s", LabelsDefinitionKey, definition)
+	}
+	if strings.Contains(string(raw["v1"]), "labels") {
+		t.Errorf("Expected no labels inside v1, got %s", raw["v1"])
+	}
+
+	policy := Policy{ID: "policy-1", Name: role.Name, Definition: string(definition)}
+	got, err := policy.ToRole()
+	if err != nil {
+		t.Fatalf("ToRole failed: %v", err)
+	}
+	if got.LabelPairs()[0] != "team=payments" || got.LabelPairs()[1] != "tier=prod" || len(got.Labels) != 2 {
+		t.Errorf("Expected labels to survive the round trip, got %v", got.Labels)
+	}
+	if role.Labels == nil {
+		t.Error("ToAPIRole should not modify the role's labels")
+	}
+}
+
+func TestRole_Origin(t *testing.T) {
+	if origin := (Role{Name: "admin"}).Origin(); origin != "" {
+		t.Errorf("Origin() without a source file = %q, want empty", origin)
+	}
+	if origin := (Role{Name: "admin", SourceFile: "prod/admin.yaml"}).Origin(); origin != " (from prod/admin.yaml)" {
+		t.Errorf("Origin() = %q, want %q", origin, " (from prod/admin.yaml)")
+	}
+}
+
+func TestTeamMember_DisplayName(t *testing.T) {
+	tests := []struct {
+		member TeamMember
+		want   string
+	}{
+		{TeamMember{ID: "alice@example.com"}, "alice@example.com"},
+		{TeamMember{ID: "m-1", Email: "alice@example.com", Name: "Alice Smith"}, "alice@example.com (Alice Smith)"},
+		{TeamMember{ID: "m-1", Email: "alice@example.com", Username: "alice"}, "alice@example.com (@alice)"},
+		{TeamMember{ID: "m-1", Email: "alice@example.com", Name: "Alice Smith", Username: "alice"}, "alice@example.com (Alice Smith, @alice)"},
+	}
+
+	for _, tt := range tests {
+		if got := tt.member.DisplayName(); got != tt.want {
+			t.Errorf("DisplayName() = %q, want %q", got, tt.want)
+		}
+	}
+}
+
+func TestRole_MemberDetailsNotSerialized(t *testing.T) {
+	role := Role{Name: "admin", Members: []string{"alice@example.com"}, MemberDetails: []TeamMember{{ID: "alice@example.com", Name: "Alice Smith"}}}
+
+	data, err := yaml.Marshal(role)
+	if err != nil {
+		t.Fatalf("Failed to marshal role: %v", err)
+	}
+	if strings.Contains(string(data), "Alice Smith") {
+		t.Errorf("Expected member details to be left out of YAML, got:\n%s", data)
+	}
+}
@@ -0,0 +1,185 @@
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/user"
+	"sort"
+	"time"
+
+	"replbac/internal/sync"
+)
+
+// Status values recorded for a sync run
+const (
+	StatusSucceeded = "succeeded"
+	StatusFailed    = "failed"
+	StatusCancelled = "cancelled"
+)
+
+// Entry is a single audit record describing one sync run. Entries are written
+// as JSON lines so that a report file can be consumed by compliance tooling.
+type Entry struct {
+	Timestamp   time.Time `json:"timestamp"`
+	User        string    `json:"user"`
+	Host        string    `json:"host"`
+	Directories []string  `json:"directories"`
+	DryRun      bool      `json:"dry_run"`
+	Status      string    `json:"status"`
+	Error       string    `json:"error,omitempty"`
+	Plan        Plan      `json:"plan"`
+	Outcome     Outcome   `json:"outcome"`
+}
+
+// Plan lists the role changes a sync run intended to make
+type Plan struct {
+	Create     []string                        `json:"create"`
+	Update     []string                        `json:"update"`
+	Delete     []string                        `json:"delete"`
+	Privileges map[string]sync.PrivilegeChange `json:"privileges"` // Privilege change of each update, by role name
+	Reasons    []sync.Explanation              `json:"reasons"`    // Why each change was planned
+}
+
+// Outcome lists the changes that were actually applied
+type Outcome struct {
+	Created          []string `json:"created"`
+	Updated          []string `json:"updated"`
+	Deleted          []string `json:"deleted"`
+	MembersInvited   []string `json:"members_invited"`
+	MembersRemoved   []string `json:"members_removed"`
+	InvitesCancelled []string `json:"invites_cancelled"`
+}
+
+// NewEntry creates an entry for a sync run starting now, recording the current user and host
+func NewEntry(directories []string, dryRun bool) *Entry {
+	return &Entry{
+		Timestamp:   time.Now().UTC(),
+		User:        currentUser(),
+		Host:        currentHost(),
+		Directories: directories,
+		DryRun:      dryRun,
+		Plan:        Plan{Create: []string{}, Update: []string{}, Delete: []string{}, Privileges: map[string]sync.PrivilegeChange{}, Reasons: []sync.Explanation{}},
+		Outcome: Outcome{
+			Created:          []string{},
+			Updated:          []string{},
+			Deleted:          []string{},
+			MembersInvited:   []string{},
+			MembersRemoved:   []string{},
+			InvitesCancelled: []string{},
+		},
+	}
+}
+
+// RecordPlan records the role changes planned for the run
+func (e *Entry) RecordPlan(plan sync.SyncPlan) {
+	e.Plan = Plan{
+		Create:     []string{},
+		Update:     []string{},
+		Delete:     append([]string{}, plan.Deletes...),
+		Privileges: sync.ClassifyPlan(plan),
+		Reasons:    sync.ExplainPlan(plan),
+	}
+	for _, role := range plan.Creates {
+		e.Plan.Create = append(e.Plan.Create, role.Name)
+	}
+	for _, update := range plan.Updates {
+		e.Plan.Update = append(e.Plan.Update, update.Name)
+	}
+}
+
+// RecordExecution records the changes that completed. The executor applies creates,
+// updates, and deletes in plan order and stops at the first failure, so the counts in
+// result identify exactly which planned changes were applied.
+func (e *Entry) RecordExecution(result sync.ExecutionResult) {
+	if result.DryRun {
+		return
+	}
+	e.Outcome.Created = prefix(e.Plan.Create, result.Created)
+	e.Outcome.Updated = prefix(e.Plan.Update, result.Updated)
+	e.Outcome.Deleted = prefix(e.Plan.Delete, result.Deleted)
+	e.Outcome.MembersInvited = sortedCopy(result.InvitedMembers)
+}
+
+// RecordMemberDeletions records team members and invites that were removed
+func (e *Entry) RecordMemberDeletions(deletions *sync.MemberDeletions) {
+	if deletions == nil {
+		return
+	}
+	e.Outcome.MembersRemoved = sortedCopy(deletions.OrphanedUsers)
+	e.Outcome.InvitesCancelled = sortedCopy(deletions.OrphanedInvites)
+}
+
+// MarkCancelled records that the run was cancelled by the user
+func (e *Entry) MarkCancelled() {
+	e.Status = StatusCancelled
+}
+
+// Finish sets the final status of the run from its error, unless it was cancelled
+func (e *Entry) Finish(err error) {
+	if err != nil {
+		e.Status = StatusFailed
+		e.Error = err.Error()
+		return
+	}
+	if e.Status == "" {
+		e.Status = StatusSucceeded
+	}
+}
+
+// Append writes the entry as a single JSON line at the end of the report file, creating it if needed
+func Append(filePath string, entry *Entry) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to encode report entry: %w", err)
+	}
+
+	file, err := os.OpenFile(filePath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to open report file %s: %w", filePath, err)
+	}
+
+	if _, err := file.Write(append(data, '\n')); err != nil {
+		_ = file.Close() //nolint:errcheck
+		return fmt.Errorf("failed to write report file %s: %w", filePath, err)
+	}
+
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("failed to close report file %s: %w", filePath, err)
+	}
+	return nil
+}
+
+// prefix returns the first n names, bounded by the length of names
+func prefix(names []string, n int) []string {
+	if n > len(names) {
+		n = len(names)
+	}
+	return append([]string{}, names[:n]...)
+}
+
+// sortedCopy returns a sorted copy of values, never nil
+func sortedCopy(values []string) []string {
+	sorted := append([]string{}, values...)
+	sort.Strings(sorted)
+	return sorted
+}
+
+// currentUser returns the name of the user running the sync
+func currentUser() string {
+	if u, err := user.Current(); err == nil && u.Username != "" {
+		return u.Username
+	}
+	if name := os.Getenv("USER"); name != "" {
+		return name
+	}
+	return "unknown"
+}
+
+// currentHost returns the name of the machine running the sync
+func currentHost() string {
+	if host, err := os.Hostname(); err == nil && host != "" {
+		return host
+	}
+	return "unknown"
+}
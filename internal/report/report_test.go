@@ -0,0 +1,117 @@
+package report
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"replbac/internal/models"
+	"replbac/internal/sync"
+)
+
+func TestEntryRecordsPartialExecution(t *testing.T) {
+	plan := sync.SyncPlan{
+		Creates: []models.Role{{Name: "first"}, {Name: "second"}},
+		Updates: []sync.RoleUpdate{{Name: "editor"}},
+		Deletes: []string{"legacy"},
+	}
+
+	entry := NewEntry([]string{"roles"}, false)
+	entry.RecordPlan(plan)
+	entry.RecordExecution(sync.ExecutionResult{Created: 1, InvitedMembers: []string{"b@example.com", "a@example.com"}})
+	entry.Finish(errors.New("failed to create role 'second'"))
+
+	if entry.Status != StatusFailed || entry.Error != "failed to create role 'second'" {
+		t.Errorf("Expected failed status with error, got status %q error %q", entry.Status, entry.Error)
+	}
+	wantPlan := Plan{
+		Create:     []string{"first", "second"},
+		Update:     []string{"editor"},
+		Delete:     []string{"legacy"},
+		Privileges: map[string]sync.PrivilegeChange{"editor": sync.PrivilegeNone},
+		Reasons: []sync.Explanation{
+			{Operation: sync.OperationCreate, Role: "first", Reason: sync.ReasonNoRemoteRole},
+			{Operation: sync.OperationCreate, Role: "second", Reason: sync.ReasonNoRemoteRole},
+			{Operation: sync.OperationUpdate, Role: "editor", Reason: "no differences"},
+			{Operation: sync.OperationDelete, Role: "legacy", Reason: sync.ReasonNoLocalFile},
+		},
+	}
+	if !reflect.DeepEqual(entry.Plan, wantPlan) {
+		t.Errorf("Plan = %+v, want %+v", entry.Plan, wantPlan)
+	}
+	wantOutcome := Outcome{
+		Created:          []string{"first"},
+		Updated:          []string{},
+		Deleted:          []string{},
+		MembersInvited:   []string{"a@example.com", "b@example.com"},
+		MembersRemoved:   []string{},
+		InvitesCancelled: []string{},
+	}
+	if !reflect.DeepEqual(entry.Outcome, wantOutcome) {
+		t.Errorf("Outcome = %+v, want %+v", entry.Outcome, wantOutcome)
+	}
+}
+
+func TestEntryStatus(t *testing.T) {
+	tests := []struct {
+		name      string
+		cancelled bool
+		err       error
+		want      string
+	}{
+		{name: "success", want: StatusSucceeded},
+		{name: "failure", err: errors.New("boom"), want: StatusFailed},
+		{name: "cancelled", cancelled: true, want: StatusCancelled},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entry := NewEntry([]string{"."}, false)
+			if tt.cancelled {
+				entry.MarkCancelled()
+			}
+			entry.Finish(tt.err)
+			if entry.Status != tt.want {
+				t.Errorf("Status = %q, want %q", entry.Status, tt.want)
+			}
+		})
+	}
+}
+
+func TestAppendWritesJSONLines(t *testing.T) {
+	reportFile := filepath.Join(t.TempDir(), "changes.log")
+
+	for _, dryRun := range []bool{true, false} {
+		entry := NewEntry([]string{"roles"}, dryRun)
+		entry.Finish(nil)
+		if err := Append(reportFile, entry); err != nil {
+			t.Fatalf("Append() unexpected error: %v", err)
+		}
+	}
+
+	data, err := os.ReadFile(reportFile)
+	if err != nil {
+		t.Fatalf("Failed to read report file: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("Expected 2 report lines, got %d:\n%s", len(lines), data)
+	}
+
+	for i, line := range lines {
+		var entry Entry
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			t.Fatalf("Line %d is not valid JSON: %v", i+1, err)
+		}
+		if entry.User == "" || entry.Host == "" || entry.Timestamp.IsZero() {
+			t.Errorf("Line %d is missing who/when details: %s", i+1, line)
+		}
+		if entry.DryRun != (i == 0) {
+			t.Errorf("Line %d dry_run = %v, want %v", i+1, entry.DryRun, i == 0)
+		}
+	}
+}
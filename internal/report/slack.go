@@ -0,0 +1,135 @@
+package report
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Attachment colors, by how the run ended
+const (
+	slackColorSucceeded = "good"
+	slackColorFailed    = "danger"
+	slackColorCancelled = "warning"
+	slackColorDryRun    = "#439FE0"
+)
+
+// SlackMessage is the body posted to a Slack incoming webhook for a sync run
+type SlackMessage struct {
+	Text        string            `json:"text"`
+	Attachments []SlackAttachment `json:"attachments"`
+}
+
+// SlackAttachment is the colored block of a message, listing the changed roles and members
+type SlackAttachment struct {
+	Color     string       `json:"color"`
+	Text      string       `json:"text,omitempty"`
+	Fields    []SlackField `json:"fields,omitempty"`
+	Footer    string       `json:"footer"`
+	Timestamp int64        `json:"ts"`
+}
+
+// SlackField is one titled list in an attachment
+type SlackField struct {
+	Title string `json:"title"`
+	Value string `json:"value"`
+}
+
+// slackList is a titled list of names that becomes a field when it is not empty
+type slackList struct {
+	title string
+	names []string
+}
+
+// NewSlackMessage summarizes a finished entry: what a dry run would change, or what an
+// apply changed before it succeeded, failed, or was cancelled
+func NewSlackMessage(e *Entry) SlackMessage {
+	attachment := SlackAttachment{
+		Footer:    fmt.Sprintf("%s@%s, %s", e.User, e.Host, strings.Join(e.Directories, ", ")),
+		Timestamp: e.Timestamp.Unix(),
+	}
+
+	var text string
+	var lists []slackList
+	if e.DryRun {
+		text = fmt.Sprintf("replbac sync dry run %s: would create %d, update %d, delete %d role(s)",
+			e.Status, len(e.Plan.Create), len(e.Plan.Update), len(e.Plan.Delete))
+		attachment.Color = slackColorDryRun
+		lists = []slackList{
+			{"Would create", e.Plan.Create},
+			{"Would update", e.Plan.Update},
+			{"Would delete", e.Plan.Delete},
+		}
+	} else {
+		text = fmt.Sprintf("replbac sync %s: %d created, %d updated, %d deleted",
+			e.Status, len(e.Outcome.Created), len(e.Outcome.Updated), len(e.Outcome.Deleted))
+		attachment.Color = slackColorSucceeded
+		lists = []slackList{
+			{"Created", e.Outcome.Created},
+			{"Updated", e.Outcome.Updated},
+			{"Deleted", e.Outcome.Deleted},
+			{"Members invited", e.Outcome.MembersInvited},
+			{"Members removed", e.Outcome.MembersRemoved},
+			{"Invites cancelled", e.Outcome.InvitesCancelled},
+		}
+	}
+
+	switch e.Status {
+	case StatusFailed:
+		attachment.Color = slackColorFailed
+		attachment.Text = "Error: " + e.Error
+	case StatusCancelled:
+		attachment.Color = slackColorCancelled
+	}
+	for _, list := range lists {
+		if len(list.names) > 0 {
+			attachment.Fields = append(attachment.Fields, SlackField{Title: list.title, Value: strings.Join(list.names, ", ")})
+		}
+	}
+
+	return SlackMessage{Text: text, Attachments: []SlackAttachment{attachment}}
+}
+
+// SlackWebhookEnv is the environment variable read for the Slack webhook URL when no
+// --slack-webhook is given, which keeps the URL out of the process arguments
+const SlackWebhookEnv = "REPLBAC_SLACK_WEBHOOK"
+
+// NotifySlack posts a summary of the finished entry to a Slack incoming webhook with
+// client, failing on any non-2xx response. The webhook URL is a secret, so errors name
+// only its host.
+func NotifySlack(client *http.Client, webhook string, e *Entry) error {
+	body, err := json.Marshal(NewSlackMessage(e))
+	if err != nil {
+		return fmt.Errorf("failed to encode Slack message: %w", err)
+	}
+
+	// A fresh context, so that a sync stopped by an interrupt is still reported
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook, bytes.NewReader(body))
+	if err != nil {
+		return errors.New("invalid Slack webhook URL")
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		// The url.Error would repeat the whole URL
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+		return fmt.Errorf("failed to post to %s: %w", req.URL.Host, err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook returned %s", resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,118 @@
+package report
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"replbac/internal/models"
+	"replbac/internal/sync"
+)
+
+func TestNewSlackMessage(t *testing.T) {
+	plan := sync.SyncPlan{
+		Creates: []models.Role{{Name: "support"}},
+		Updates: []sync.RoleUpdate{{Name: "viewer"}},
+		Deletes: []string{"legacy"},
+	}
+
+	applied := NewEntry([]string{"roles"}, false)
+	applied.RecordPlan(plan)
+	applied.RecordExecution(sync.ExecutionResult{Created: 1, Updated: 1, Deleted: 1, InvitedMembers: []string{"dave@example.com"}})
+	applied.RecordMemberDeletions(&sync.MemberDeletions{OrphanedUsers: []string{"eve@example.com"}})
+	applied.Finish(nil)
+
+	message := NewSlackMessage(applied)
+	if message.Text != "replbac sync succeeded: 1 created, 1 updated, 1 deleted" {
+		t.Errorf("Unexpected text %q", message.Text)
+	}
+	expectedFields := []SlackField{
+		{Title: "Created", Value: "support"},
+		{Title: "Updated", Value: "viewer"},
+		{Title: "Deleted", Value: "legacy"},
+		{Title: "Members invited", Value: "dave@example.com"},
+		{Title: "Members removed", Value: "eve@example.com"},
+	}
+	attachment := message.Attachments[0]
+	if attachment.Color != "good" || !reflect.DeepEqual(attachment.Fields, expectedFields) {
+		t.Errorf("Unexpected attachment %+v", attachment)
+	}
+	if !strings.HasSuffix(attachment.Footer, ", roles") {
+		t.Errorf("Expected the footer to name the directory, got %q", attachment.Footer)
+	}
+
+	failed := NewEntry([]string{"roles"}, false)
+	failed.RecordPlan(plan)
+	failed.RecordExecution(sync.ExecutionResult{Created: 1})
+	failed.Finish(errors.New("failed to update role 'viewer'"))
+
+	message = NewSlackMessage(failed)
+	attachment = message.Attachments[0]
+	if message.Text != "replbac sync failed: 1 created, 0 updated, 0 deleted" || attachment.Color != "danger" {
+		t.Errorf("Unexpected failure message %+v", message)
+	}
+	if attachment.Text != "Error: failed to update role 'viewer'" || len(attachment.Fields) != 1 {
+		t.Errorf("Expected the error and only the applied create, got %+v", attachment)
+	}
+
+	preview := NewEntry([]string{"roles"}, true)
+	preview.RecordPlan(plan)
+	preview.Finish(nil)
+
+	message = NewSlackMessage(preview)
+	if message.Text != "replbac sync dry run succeeded: would create 1, update 1, delete 1 role(s)" || len(message.Attachments[0].Fields) != 3 {
+		t.Errorf("Unexpected dry-run message %+v", message)
+	}
+}
+
+func TestNotifySlack(t *testing.T) {
+	var received SlackMessage
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Content-Type") != "application/json" {
+			t.Errorf("Expected a JSON body, got Content-Type %q", r.Header.Get("Content-Type"))
+		}
+		if err := json.NewDecoder(r.Body).Decode(&received); err != nil {
+			t.Errorf("Failed to decode message: %v", err)
+		}
+		if received.Text == "" {
+			w.WriteHeader(http.StatusBadRequest)
+		}
+	}))
+	defer server.Close()
+
+	entry := NewEntry([]string{"roles"}, false)
+	entry.Finish(nil)
+	if err := NotifySlack(http.DefaultClient, server.URL, entry); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if received.Text != "replbac sync succeeded: 0 created, 0 updated, 0 deleted" {
+		t.Errorf("Unexpected message %+v", received)
+	}
+
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "invalid_token", http.StatusForbidden)
+	}))
+	defer failing.Close()
+	if err := NotifySlack(http.DefaultClient, failing.URL, entry); err == nil || !strings.Contains(err.Error(), "403") {
+		t.Errorf("Expected an error naming the status, got %v", err)
+	}
+
+	// The webhook's path is its secret, so a failed request names only the host
+	closed := httptest.NewServer(http.NotFoundHandler())
+	closed.Close()
+	unreachable := closed.URL + "/services/T000/B000/secret"
+	err := NotifySlack(http.DefaultClient, unreachable, entry)
+	if err == nil || !strings.Contains(err.Error(), strings.TrimPrefix(closed.URL, "http://")) {
+		t.Errorf("Expected an error naming the host, got %v", err)
+	}
+	if err != nil && strings.Contains(err.Error(), "secret") {
+		t.Errorf("Expected the webhook path to be redacted, got %v", err)
+	}
+	if err := NotifySlack(http.DefaultClient, "https://hooks.slack.com/services/\x00secret", entry); err == nil || strings.Contains(err.Error(), "secret") {
+		t.Errorf("Expected an invalid URL error without the URL, got %v", err)
+	}
+}
@@ -0,0 +1,66 @@
+package roles
+
+import (
+	"strings"
+
+	"replbac/internal/models"
+)
+
+// DenyReachable reports whether some resource could match both denied and one of the
+// allowed patterns, so that the deny has an effect. The check is conservative: any
+// segment containing a wildcard is assumed to match any number of path segments, so a
+// deny is only reported unreachable when, for every allowed pattern, some literal
+// segment rules out any overlap. With no allowed patterns nothing is reachable.
+func DenyReachable(denied string, allowed []string) bool {
+	deniedSegments := strings.Split(denied, "/")
+	for _, pattern := range allowed {
+		if patternsOverlap(deniedSegments, strings.Split(pattern, "/")) {
+			return true
+		}
+	}
+	return false
+}
+
+// NormalizeDenies returns copies of roles without the denied entries that no allowed
+// entry can reach, as the API drops them, so that roles differing only in such
+// entries compare equal
+func NormalizeDenies(roles []models.Role) []models.Role {
+	normalized := make([]models.Role, len(roles))
+	for i, role := range roles {
+		var denied []string
+		if role.Resources.Denied != nil {
+			denied = []string{}
+		}
+		for _, resource := range role.Resources.Denied {
+			if DenyReachable(resource, role.Resources.Allowed) {
+				denied = append(denied, resource)
+			}
+		}
+		role.Resources.Denied = denied
+		normalized[i] = role
+	}
+	return normalized
+}
+
+// patternsOverlap reports whether two segmented resource patterns could match the same resource
+func patternsOverlap(a, b []string) bool {
+	if len(a) == 0 && len(b) == 0 {
+		return true
+	}
+	if len(a) > 0 && isWildcardSegment(a[0]) {
+		// Match no segments of b, or consume one and keep the wildcard for the rest
+		return patternsOverlap(a[1:], b) || (len(b) > 0 && patternsOverlap(a, b[1:]))
+	}
+	if len(b) > 0 && isWildcardSegment(b[0]) {
+		return patternsOverlap(a, b[1:]) || (len(a) > 0 && patternsOverlap(a[1:], b))
+	}
+	if len(a) == 0 || len(b) == 0 {
+		return false
+	}
+	return a[0] == b[0] && patternsOverlap(a[1:], b[1:])
+}
+
+// isWildcardSegment reports whether a path segment contains a wildcard
+func isWildcardSegment(segment string) bool {
+	return strings.Contains(segment, "*")
+}
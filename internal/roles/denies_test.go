@@ -0,0 +1,57 @@
+package roles
+
+import (
+	"testing"
+
+	"replbac/internal/models"
+)
+
+func TestDenyReachable(t *testing.T) {
+	tests := []struct {
+		name    string
+		denied  string
+		allowed []string
+		want    bool
+	}{
+		{name: "deny inside a wildcard allow", denied: "kots/app/*/delete", allowed: []string{"kots/app/*/read", "kots/app/*/delete"}, want: true},
+		{name: "deny under a catch-all", denied: "kots/app/*/delete", allowed: []string{"**/*"}, want: true},
+		{name: "deny under a recursive allow", denied: "kots/app/abc/channel/*/promote", allowed: []string{"kots/app/abc/**"}, want: true},
+		{name: "deny of a concrete resource within a wildcard allow", denied: "kots/app/abc/read", allowed: []string{"kots/app/*/read"}, want: true},
+		{name: "different action", denied: "kots/app/*/delete", allowed: []string{"kots/app/*/read"}, want: false},
+		{name: "different namespace", denied: "team/support-issues/write", allowed: []string{"kots/app/*/read", "kots/app/*/write"}, want: false},
+		{name: "deny longer than a literal allow", denied: "kots/app/abc/read/extra", allowed: []string{"kots/app/abc/read"}, want: false},
+		{name: "no allowed entries", denied: "kots/app/*/delete", want: false},
+		{name: "partial wildcard segment is assumed to overlap", denied: "kots/app/prod-*/read", allowed: []string{"kots/app/*/read"}, want: true},
+		{name: "wildcard may span segments", denied: "kots/app/abc/channel/x/read", allowed: []string{"kots/app/*/read"}, want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := DenyReachable(tt.denied, tt.allowed); got != tt.want {
+				t.Errorf("DenyReachable(%q, %v) = %v, want %v", tt.denied, tt.allowed, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNormalizeDenies(t *testing.T) {
+	input := []models.Role{
+		{Name: "viewer", Resources: models.Resources{
+			Allowed: []string{"kots/app/*/read"},
+			Denied:  []string{"kots/app/*/delete", "kots/app/secret/read"},
+		}},
+		{Name: "empty", Resources: models.Resources{Allowed: []string{"**/*"}}},
+	}
+
+	normalized := NormalizeDenies(input)
+
+	if denied := normalized[0].Resources.Denied; len(denied) != 1 || denied[0] != "kots/app/secret/read" {
+		t.Errorf("Expected only the reachable deny to remain, got %v", denied)
+	}
+	if normalized[1].Resources.Denied != nil {
+		t.Errorf("Expected a nil denied list to stay nil, got %v", normalized[1].Resources.Denied)
+	}
+	if len(input[0].Resources.Denied) != 2 {
+		t.Errorf("Expected the input roles to be left unchanged, got %v", input[0].Resources.Denied)
+	}
+}
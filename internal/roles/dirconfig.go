@@ -0,0 +1,65 @@
+package roles
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// DirectoryConfigFile is the name of the optional configuration file at the root of a
+// roles directory. It is never loaded as a role file.
+const DirectoryConfigFile = ".replbac.yaml"
+
+// DirectoryConfig holds settings that apply to every role in a roles directory
+type DirectoryConfig struct {
+	// Ignore lists role names or glob patterns (as in path.Match) for roles that sync
+	// must never create, update, or delete, such as roles managed in the Replicated UI
+	Ignore []string `yaml:"ignore"`
+
+	// Macros defines resource macros by name. An allowed or denied entry of @name in a role
+	// file is replaced by the resources listed for name when the directory is loaded.
+	Macros map[string][]string `yaml:"macros"`
+}
+
+// LoadDirectoryConfig reads the directory configuration file from dir, returning an
+// empty configuration if the directory has none
+func LoadDirectoryConfig(dir string) (DirectoryConfig, error) {
+	var config DirectoryConfig
+
+	configPath := filepath.Join(dir, DirectoryConfigFile)
+	data, err := os.ReadFile(configPath) // #nosec G304 -- Reading the roles directory's own config file is expected behavior
+	if errors.Is(err, os.ErrNotExist) {
+		return config, nil
+	}
+	if err != nil {
+		return config, fmt.Errorf("failed to read %s: %w", configPath, err)
+	}
+
+	if err := yaml.Unmarshal(data, &config); err != nil {
+		return config, fmt.Errorf("failed to parse %s: %w", configPath, err)
+	}
+
+	for _, pattern := range config.Ignore {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return config, fmt.Errorf("invalid ignore pattern %q in %s: %w", pattern, configPath, err)
+		}
+	}
+
+	for name, resources := range config.Macros {
+		if name == "" || strings.HasPrefix(name, MacroPrefix) {
+			return config, fmt.Errorf("invalid macro name %q in %s: name it without the %s prefix", name, configPath, MacroPrefix)
+		}
+		for _, resource := range resources {
+			if strings.HasPrefix(resource, MacroPrefix) {
+				return config, fmt.Errorf("macro %s in %s uses %s; macros cannot use other macros", name, configPath, resource)
+			}
+		}
+	}
+
+	return config, nil
+}
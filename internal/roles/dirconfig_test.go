@@ -0,0 +1,95 @@
+package roles
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestLoadDirectoryConfig(t *testing.T) {
+	tests := []struct {
+		name         string
+		content      string
+		noFile       bool
+		expectIgnore []string
+		expectError  string
+	}{
+		{
+			name:   "missing config file",
+			noFile: true,
+		},
+		{
+			name:         "ignore list with names and globs",
+			content:      "ignore:\n  - billing-admin\n  - ui-*\n",
+			expectIgnore: []string{"billing-admin", "ui-*"},
+		},
+		{
+			name:        "invalid glob pattern",
+			content:     "ignore:\n  - \"ui-[\"\n",
+			expectError: "invalid ignore pattern",
+		},
+		{
+			name:        "macro using another macro",
+			content:     "macros:\n  read-basics:\n    - kots/app/*/read\n  support:\n    - \"@read-basics\"\n",
+			expectError: "macros cannot use other macros",
+		},
+		{
+			name:        "macro name with prefix",
+			content:     "macros:\n  \"@read-basics\":\n    - kots/app/*/read\n",
+			expectError: "name it without the @ prefix",
+		},
+		{
+			name:        "invalid YAML",
+			content:     "ignore: [unterminated\n",
+			expectError: "failed to parse",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			if !tt.noFile {
+				if err := os.WriteFile(filepath.Join(dir, DirectoryConfigFile), []byte(tt.content), 0600); err != nil {
+					t.Fatalf("failed to write config: %v", err)
+				}
+			}
+
+			config, err := LoadDirectoryConfig(dir)
+			if tt.expectError != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.expectError) {
+					t.Fatalf("expected error containing %q, got %v", tt.expectError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(config.Ignore, tt.expectIgnore) {
+				t.Errorf("Ignore = %v, want %v", config.Ignore, tt.expectIgnore)
+			}
+		})
+	}
+}
+
+func TestLoadRolesFromDirectory_DirectoryConfig(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, DirectoryConfigFile), []byte("ignore:\n  - ui-*\n"), 0600); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "admin.yaml"), []byte("name: admin\nresources:\n  allowed: [\"*\"]\n  denied: []\n"), 0600); err != nil {
+		t.Fatalf("failed to write role: %v", err)
+	}
+
+	result, err := LoadRolesFromDirectoryWithDetails(dir)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(result.Roles) != 1 || len(result.SkippedFiles) != 0 {
+		t.Errorf("expected config file to be excluded from role files, got roles %v and skipped %v", result.Roles, result.SkippedFiles)
+	}
+	if !reflect.DeepEqual(result.Ignore, []string{"ui-*"}) {
+		t.Errorf("Ignore = %v, want [ui-*]", result.Ignore)
+	}
+}
@@ -0,0 +1,105 @@
+package roles
+
+import (
+	"fmt"
+	"sort"
+
+	"replbac/internal/models"
+)
+
+// ValidateUniqueNames returns an error naming the files of the first role name that is
+// defined more than once
+func ValidateUniqueNames(roles []models.Role) error {
+	files := make(map[string]string)
+	for _, role := range roles {
+		if previous, exists := files[role.Name]; exists {
+			return fmt.Errorf("role %q is defined in both %s and %s", role.Name, previous, role.SourceFile)
+		}
+		files[role.Name] = role.SourceFile
+	}
+	return nil
+}
+
+// MergeDuplicates combines roles that share a name into one role whose allowed, denied,
+// and members lists are the deduplicated union of theirs, for teams that split a role
+// across files. Merged roles take the place of the first file defining them and name
+// every file in SourceFile. Roles that set different IDs or give a label different
+// values cannot be merged and are reported as an error.
+func MergeDuplicates(roles []models.Role) ([]models.Role, error) {
+	merged := make([]models.Role, 0, len(roles))
+	index := make(map[string]int)
+	for _, role := range roles {
+		i, exists := index[role.Name]
+		if !exists {
+			index[role.Name] = len(merged)
+			merged = append(merged, role)
+			continue
+		}
+
+		target := &merged[i]
+		if role.ID != "" && target.ID != "" && role.ID != target.ID {
+			return nil, fmt.Errorf("cannot merge role %q: %s sets ID %s but %s sets ID %s", role.Name, target.SourceFile, target.ID, role.SourceFile, role.ID)
+		}
+		if target.ID == "" {
+			target.ID = role.ID
+		}
+		labels, err := mergeLabels(target.Labels, role.Labels)
+		if err != nil {
+			return nil, fmt.Errorf("cannot merge role %q from %s and %s: %w", role.Name, target.SourceFile, role.SourceFile, err)
+		}
+		target.Labels = labels
+
+		target.Resources.Allowed = unionStrings(target.Resources.Allowed, role.Resources.Allowed)
+		target.Resources.Denied = unionStrings(target.Resources.Denied, role.Resources.Denied)
+		target.Members = unionStrings(target.Members, role.Members)
+		if role.SourceFile != "" {
+			if target.SourceFile != "" {
+				target.SourceFile += ", "
+			}
+			target.SourceFile += role.SourceFile
+		}
+	}
+	return merged, nil
+}
+
+// unionStrings returns the values of a followed by those of b not already present,
+// without duplicates. It returns nil only if both are nil.
+func unionStrings(a, b []string) []string {
+	if a == nil && b == nil {
+		return nil
+	}
+	seen := make(map[string]bool, len(a)+len(b))
+	union := make([]string, 0, len(a)+len(b))
+	for _, list := range [][]string{a, b} {
+		for _, value := range list {
+			if !seen[value] {
+				seen[value] = true
+				union = append(union, value)
+			}
+		}
+	}
+	return union
+}
+
+// mergeLabels returns the union of two label sets, failing if a key has two different values
+func mergeLabels(a, b map[string]string) (map[string]string, error) {
+	if len(b) == 0 {
+		return a, nil
+	}
+	merged := make(map[string]string, len(a)+len(b))
+	for key, value := range a {
+		merged[key] = value
+	}
+	keys := make([]string, 0, len(b))
+	for key := range b {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		if existing, exists := merged[key]; exists && existing != b[key] {
+			return nil, fmt.Errorf("label %s is %q in one file and %q in the other", key, existing, b[key])
+		}
+		merged[key] = b[key]
+	}
+	return merged, nil
+}
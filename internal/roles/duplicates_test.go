@@ -0,0 +1,108 @@
+package roles
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"replbac/internal/models"
+)
+
+func TestValidateUniqueNames(t *testing.T) {
+	unique := []models.Role{{Name: "viewer", SourceFile: "viewer.yaml"}, {Name: "admin", SourceFile: "admin.yaml"}}
+	if err := ValidateUniqueNames(unique); err != nil {
+		t.Errorf("Unexpected error for unique names: %v", err)
+	}
+
+	duplicated := append(unique, models.Role{Name: "viewer", SourceFile: "extra/viewer.yaml"})
+	err := ValidateUniqueNames(duplicated)
+	expected := `role "viewer" is defined in both viewer.yaml and extra/viewer.yaml`
+	if err == nil || err.Error() != expected {
+		t.Errorf("Expected error %q, got %v", expected, err)
+	}
+}
+
+func TestMergeDuplicates(t *testing.T) {
+	tests := []struct {
+		name          string
+		input         []models.Role
+		expected      []models.Role
+		errorContains string
+	}{
+		{
+			name: "allowed, denied, and members are unioned without duplicates",
+			input: []models.Role{
+				{Name: "editor", SourceFile: "base/editor.yaml", Resources: models.Resources{
+					Allowed: []string{"kots/app/*/read", "kots/app/*/write"},
+					Denied:  []string{"kots/app/*/delete"},
+				}, Members: []string{"alice@example.com"}},
+				{Name: "viewer", SourceFile: "viewer.yaml", Resources: models.Resources{Allowed: []string{"kots/app/*/read"}}},
+				{Name: "editor", SourceFile: "extras/editor.yaml", Resources: models.Resources{
+					Allowed: []string{"kots/app/*/write", "kots/app/*/promote"},
+					Denied:  []string{"kots/app/*/delete", "team/members/write"},
+				}, Members: []string{"bob@example.com"}},
+			},
+			expected: []models.Role{
+				{Name: "editor", SourceFile: "base/editor.yaml, extras/editor.yaml", Resources: models.Resources{
+					Allowed: []string{"kots/app/*/read", "kots/app/*/write", "kots/app/*/promote"},
+					Denied:  []string{"kots/app/*/delete", "team/members/write"},
+				}, Members: []string{"alice@example.com", "bob@example.com"}},
+				{Name: "viewer", SourceFile: "viewer.yaml", Resources: models.Resources{Allowed: []string{"kots/app/*/read"}}},
+			},
+		},
+		{
+			name: "a member listed in both files appears once",
+			input: []models.Role{
+				{Name: "editor", Members: []string{"alice@example.com", "bob@example.com"}},
+				{Name: "editor", Members: []string{"bob@example.com", "carol@example.com"}},
+			},
+			expected: []models.Role{
+				{Name: "editor", Members: []string{"alice@example.com", "bob@example.com", "carol@example.com"}},
+			},
+		},
+		{
+			name: "ID and labels are taken from whichever file sets them",
+			input: []models.Role{
+				{Name: "editor", Labels: map[string]string{"owner": "platform"}},
+				{Name: "editor", ID: "editor-id", Labels: map[string]string{"tier": "prod"}},
+			},
+			expected: []models.Role{
+				{Name: "editor", ID: "editor-id", Labels: map[string]string{"owner": "platform", "tier": "prod"}},
+			},
+		},
+		{
+			name: "different IDs cannot be merged",
+			input: []models.Role{
+				{Name: "editor", ID: "one", SourceFile: "a.yaml"},
+				{Name: "editor", ID: "two", SourceFile: "b.yaml"},
+			},
+			errorContains: `cannot merge role "editor": a.yaml sets ID one but b.yaml sets ID two`,
+		},
+		{
+			name: "conflicting label values cannot be merged",
+			input: []models.Role{
+				{Name: "editor", SourceFile: "a.yaml", Labels: map[string]string{"owner": "platform"}},
+				{Name: "editor", SourceFile: "b.yaml", Labels: map[string]string{"owner": "apps"}},
+			},
+			errorContains: `label owner is "platform" in one file and "apps" in the other`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			merged, err := MergeDuplicates(tt.input)
+			if tt.errorContains != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.errorContains) {
+					t.Errorf("Expected error containing %q, got %v", tt.errorContains, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(merged, tt.expected) {
+				t.Errorf("MergeDuplicates() = %+v, want %+v", merged, tt.expected)
+			}
+		})
+	}
+}
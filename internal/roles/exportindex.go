@@ -0,0 +1,104 @@
+package roles
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"replbac/internal/models"
+)
+
+// ExportIndex records the content hash of every role written by an incremental pull, so
+// the next one can write only the roles that changed since, and the roles that have
+// since been deleted remotely
+type ExportIndex struct {
+	ExportedAt string            `json:"exported_at,omitempty"`
+	Roles      map[string]string `json:"roles"`             // Content hash of each remote role, by name
+	Deleted    map[string]string `json:"deleted,omitempty"` // When each role was found deleted remotely, by name
+}
+
+// ExportChanges is how the remote roles differ from an ExportIndex
+type ExportChanges struct {
+	Changed   []models.Role // Roles that are new or whose content hash differs
+	Unchanged []string      // Names of roles whose content hash is the same
+	Deleted   []string      // Sorted names of indexed roles no longer present remotely
+}
+
+// LoadExportIndex reads an export index from filePath. A missing file is an empty index,
+// as for a first export.
+func LoadExportIndex(filePath string) (ExportIndex, error) {
+	index := ExportIndex{Roles: map[string]string{}, Deleted: map[string]string{}}
+	data, err := os.ReadFile(filePath) // #nosec G304 -- Reading user-provided index path is expected behavior
+	if errors.Is(err, os.ErrNotExist) {
+		return index, nil
+	}
+	if err != nil {
+		return index, fmt.Errorf("failed to read export index: %w", err)
+	}
+
+	if err := json.Unmarshal(data, &index); err != nil {
+		return index, fmt.Errorf("failed to parse export index %s: %w", filePath, err)
+	}
+	if index.Roles == nil {
+		index.Roles = map[string]string{}
+	}
+	if index.Deleted == nil {
+		index.Deleted = map[string]string{}
+	}
+	return index, nil
+}
+
+// Compare sorts remote roles into those changed since the index was saved and those
+// not, and lists the indexed roles that are no longer present
+func (x ExportIndex) Compare(remote []models.Role) ExportChanges {
+	changes := ExportChanges{}
+	present := make(map[string]bool, len(remote))
+	for _, role := range remote {
+		present[role.Name] = true
+		if hash, exists := x.Roles[role.Name]; exists && hash == role.ContentHash() {
+			changes.Unchanged = append(changes.Unchanged, role.Name)
+		} else {
+			changes.Changed = append(changes.Changed, role)
+		}
+	}
+	for name := range x.Roles {
+		if !present[name] {
+			changes.Deleted = append(changes.Deleted, name)
+		}
+	}
+	sort.Strings(changes.Deleted)
+	return changes
+}
+
+// Update records the hashes of the remote roles as of exportedAt, replacing those of
+// earlier exports, and marks indexed roles no longer present as deleted at that time.
+// Roles already marked deleted keep their original time unless they reappear.
+func (x *ExportIndex) Update(remote []models.Role, exportedAt string) {
+	for _, name := range x.Compare(remote).Deleted {
+		x.Deleted[name] = exportedAt
+	}
+	x.Roles = make(map[string]string, len(remote))
+	for _, role := range remote {
+		x.Roles[role.Name] = role.ContentHash()
+		delete(x.Deleted, role.Name)
+	}
+	x.ExportedAt = exportedAt
+}
+
+// Save writes the index to filePath as indented JSON
+func (x ExportIndex) Save(filePath string) error {
+	data, err := json.MarshalIndent(x, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode export index: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(filePath), 0750); err != nil {
+		return fmt.Errorf("failed to create directory for export index: %w", err)
+	}
+	if err := os.WriteFile(filePath, append(data, '\n'), 0600); err != nil {
+		return fmt.Errorf("failed to write export index: %w", err)
+	}
+	return nil
+}
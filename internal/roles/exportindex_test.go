@@ -0,0 +1,76 @@
+package roles
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"replbac/internal/models"
+)
+
+func TestExportIndex(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "backup", ".last-export")
+
+	index, err := LoadExportIndex(path)
+	if err != nil {
+		t.Fatalf("Expected a missing index to load as empty, got: %v", err)
+	}
+
+	admin := models.Role{Name: "admin", Resources: models.Resources{Allowed: []string{"**/*"}}}
+	viewer := models.Role{Name: "viewer", Resources: models.Resources{Allowed: []string{"kots/app/*/read"}}}
+
+	changes := index.Compare([]models.Role{admin, viewer})
+	if len(changes.Changed) != 2 || len(changes.Unchanged) != 0 || len(changes.Deleted) != 0 {
+		t.Errorf("Expected every role to be changed on the first export, got %+v", changes)
+	}
+
+	index.Update([]models.Role{admin, viewer}, "2026-01-01T00:00:00Z")
+	if err := index.Save(path); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	index, err = LoadExportIndex(path)
+	if err != nil {
+		t.Fatalf("LoadExportIndex failed: %v", err)
+	}
+	if index.ExportedAt != "2026-01-01T00:00:00Z" || len(index.Roles) != 2 {
+		t.Errorf("Expected the saved index to load back, got %+v", index)
+	}
+
+	// viewer changes and admin is deleted
+	viewer.Resources.Allowed = append(viewer.Resources.Allowed, "team/read")
+	editor := models.Role{Name: "editor", Resources: models.Resources{Allowed: []string{"kots/app/*/write"}}}
+	changes = index.Compare([]models.Role{viewer, editor})
+	if names := roleNames(changes.Changed); !reflect.DeepEqual(names, []string{"viewer", "editor"}) {
+		t.Errorf("Expected viewer and editor to be changed, got %v", names)
+	}
+	if !reflect.DeepEqual(changes.Deleted, []string{"admin"}) {
+		t.Errorf("Expected admin to be deleted, got %v", changes.Deleted)
+	}
+
+	index.Update([]models.Role{viewer, editor}, "2026-01-02T00:00:00Z")
+	if index.Deleted["admin"] != "2026-01-02T00:00:00Z" {
+		t.Errorf("Expected admin to be recorded as deleted, got %v", index.Deleted)
+	}
+	if _, exists := index.Roles["admin"]; exists {
+		t.Errorf("Expected admin to leave the role hashes, got %v", index.Roles)
+	}
+	changes = index.Compare([]models.Role{viewer, editor})
+	if len(changes.Changed) != 0 || len(changes.Unchanged) != 2 {
+		t.Errorf("Expected nothing to change after the update, got %+v", changes)
+	}
+
+	// A deleted role that comes back is no longer noted as deleted
+	index.Update([]models.Role{viewer, editor, admin}, "2026-01-03T00:00:00Z")
+	if _, exists := index.Deleted["admin"]; exists {
+		t.Errorf("Expected admin to no longer be deleted, got %v", index.Deleted)
+	}
+}
+
+// roleNames returns the names of roles in order
+func roleNames(roles []models.Role) []string {
+	names := make([]string, 0, len(roles))
+	for _, role := range roles {
+		names = append(names, role.Name)
+	}
+	return names
+}
@@ -1,42 +1,107 @@
 package roles
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
+	"sync"
 
 	"gopkg.in/yaml.v3"
 
 	"replbac/internal/models"
 )
 
-// ReadRoleFile reads and parses a single YAML role file
+// ReadRoleFile reads and parses a YAML file holding a single role
 func ReadRoleFile(filePath string) (models.Role, error) {
-	var role models.Role
+	roles, err := ReadRoleDocuments(filePath)
+	if err != nil {
+		return models.Role{}, err
+	}
+	if len(roles) != 1 {
+		return models.Role{}, fmt.Errorf("file holds %d roles, expected one", len(roles))
+	}
+	return roles[0], nil
+}
 
+// ReadRoleDocuments reads and parses a YAML role file, which holds one role per
+// document, separated by "---" lines. Empty documents are ignored.
+func ReadRoleDocuments(filePath string) ([]models.Role, error) {
 	// Check file extension
 	ext := strings.ToLower(filepath.Ext(filePath))
 	if ext != ".yaml" && ext != ".yml" {
-		return role, errors.New("not a YAML file")
+		return nil, errors.New("not a YAML file")
 	}
 
 	// Read file contents
 	data, err := os.ReadFile(filePath) // #nosec G304 -- Reading user-provided file path is expected behavior
 	if err != nil {
-		return role, fmt.Errorf("failed to read file: %w", err)
+		return nil, fmt.Errorf("failed to read file: %w", err)
 	}
 
 	// Check if file is empty
 	if len(data) == 0 {
-		return role, errors.New("file is empty")
+		return nil, errors.New("file is empty")
 	}
 
 	// Parse YAML
-	if err := yaml.Unmarshal(data, &role); err != nil {
+	var documents []*yaml.Node
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	for {
+		var document yaml.Node
+		err := decoder.Decode(&document)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, errors.New("failed to parse YAML")
+		}
+		if len(document.Content) > 0 && document.Content[0].Tag != "!!null" {
+			documents = append(documents, &document)
+		}
+	}
+	if len(documents) == 0 {
+		// Reported as a role without a name, as for a file of comments only
+		documents = append(documents, &yaml.Node{})
+	}
+
+	roles := make([]models.Role, 0, len(documents))
+	for i, document := range documents {
+		role, err := readRoleDocument(document, filePath)
+		if err != nil {
+			if len(documents) > 1 {
+				err = fmt.Errorf("document %d: %w", i+1, err)
+			}
+			return nil, err
+		}
+		roles = append(roles, role)
+	}
+	return roles, nil
+}
+
+// readRoleDocument parses one document of the role file at filePath
+func readRoleDocument(document *yaml.Node, filePath string) (models.Role, error) {
+	var role models.Role
+
+	// Expand resource lists that reference external files
+	if err := expandResourceFileReferences(document, filepath.Dir(filePath)); err != nil {
+		return role, err
+	}
+
+	if err := document.Decode(&role); err != nil {
+		return role, errors.New("failed to parse YAML")
+	}
+	extra, err := extraFields(document)
+	if err != nil {
 		return role, errors.New("failed to parse YAML")
 	}
+	role.Extra = extra
+	role.SourceFile = filePath
+	role.Resources = role.Resources.Normalized()
 
 	// Validate the role
 	if err := ValidateRole(role); err != nil {
@@ -46,6 +111,114 @@ func ReadRoleFile(filePath string) (models.Role, error) {
 	return role, nil
 }
 
+// roleFields are the top-level keys of a role file that map to models.Role fields
+var roleFields = map[string]bool{"id": true, "name": true, "inherits": true, "manage": true, "resources": true, "members": true, "labels": true}
+
+// extraFields returns the top-level keys of a role document that are not role fields,
+// with their values, or nil if there are none
+func extraFields(document *yaml.Node) (map[string]interface{}, error) {
+	if document.Kind != yaml.DocumentNode || len(document.Content) == 0 || document.Content[0].Kind != yaml.MappingNode {
+		return nil, nil
+	}
+
+	var extra map[string]interface{}
+	mapping := document.Content[0]
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		key := mapping.Content[i].Value
+		if roleFields[key] {
+			continue
+		}
+		var value interface{}
+		if err := mapping.Content[i+1].Decode(&value); err != nil {
+			return nil, err
+		}
+		if extra == nil {
+			extra = make(map[string]interface{})
+		}
+		extra[key] = value
+	}
+	return extra, nil
+}
+
+// resourceFileKey is the key used to load a resource list from a separate file, e.g.
+// "allowed: { from_file: allowed-admin.txt }"
+const resourceFileKey = "from_file"
+
+// expandResourceFileReferences replaces allowed/denied entries of the form
+// { from_file: path } with the resources listed in that file. Paths are
+// resolved relative to baseDir, the directory holding the role file.
+func expandResourceFileReferences(document *yaml.Node, baseDir string) error {
+	if document.Kind != yaml.DocumentNode || len(document.Content) == 0 {
+		return nil
+	}
+
+	resources := mappingValue(document.Content[0], "resources")
+	if resources == nil || resources.Kind != yaml.MappingNode {
+		return nil
+	}
+
+	for _, listName := range []string{"allowed", "denied"} {
+		list := mappingValue(resources, listName)
+		if list == nil || list.Kind != yaml.MappingNode {
+			continue
+		}
+
+		reference := mappingValue(list, resourceFileKey)
+		if reference == nil || reference.Kind != yaml.ScalarNode || strings.TrimSpace(reference.Value) == "" {
+			return fmt.Errorf("%s must be a list of resources or { %s: path }", listName, resourceFileKey)
+		}
+
+		referencePath := reference.Value
+		if !filepath.IsAbs(referencePath) {
+			referencePath = filepath.Join(baseDir, referencePath)
+		}
+
+		entries, err := readResourceFile(referencePath)
+		if err != nil {
+			return fmt.Errorf("failed to load %s resources from %s: %w", listName, reference.Value, err)
+		}
+
+		*list = yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"}
+		for _, entry := range entries {
+			list.Content = append(list.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: entry})
+		}
+	}
+
+	return nil
+}
+
+// mappingValue returns the value node for key in a YAML mapping node, or nil if absent
+func mappingValue(mapping *yaml.Node, key string) *yaml.Node {
+	if mapping.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			return mapping.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// readResourceFile reads one resource per line, ignoring blank lines and # comments
+func readResourceFile(filePath string) ([]string, error) {
+	data, err := os.ReadFile(filePath) // #nosec G304 -- Reading files referenced by user role definitions is expected behavior
+	if err != nil {
+		return nil, err
+	}
+
+	entries := []string{}
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		entries = append(entries, line)
+	}
+
+	return entries, nil
+}
+
 // FindRoleFiles recursively finds all YAML files in a directory
 func FindRoleFiles(rootPath string) ([]string, error) {
 	var files []string
@@ -65,6 +238,11 @@ func FindRoleFiles(rootPath string) ([]string, error) {
 			return nil
 		}
 
+		// Skip the directory configuration file
+		if info.Name() == DirectoryConfigFile {
+			return nil
+		}
+
 		// Check if it's a YAML file
 		ext := strings.ToLower(filepath.Ext(path))
 		if ext == ".yaml" || ext == ".yml" {
@@ -85,12 +263,13 @@ func FindRoleFiles(rootPath string) ([]string, error) {
 type LoadResult struct {
 	Roles        []models.Role
 	SkippedFiles []SkippedFile
+	Ignore       []string // Ignore patterns from the directory configuration file
 }
 
 // SkippedFile represents a file that was skipped during loading
 type SkippedFile struct {
-	Path   string
-	Reason string
+	Path   string `json:"path"`
+	Reason string `json:"reason"`
 }
 
 // LoadRolesFromDirectory loads all valid role files from a directory recursively
@@ -103,37 +282,106 @@ func LoadRolesFromDirectory(rootPath string) ([]models.Role, error) {
 	return result.Roles, nil
 }
 
+// LoadOptions controls how role files are loaded from a directory
+type LoadOptions struct {
+	// Parallelism is the number of role files parsed concurrently. Zero or one parses
+	// files one at a time. Results are the same either way.
+	Parallelism int
+}
+
 // LoadRolesFromDirectoryWithDetails loads roles and returns detailed information about skipped files
 func LoadRolesFromDirectoryWithDetails(rootPath string) (*LoadResult, error) {
+	return LoadRolesFromDirectoryWithOptions(rootPath, LoadOptions{})
+}
+
+// LoadRolesFromDirectoryWithOptions loads roles using the given options and returns detailed
+// information about skipped files. Roles and skipped files are listed in file path order.
+func LoadRolesFromDirectoryWithOptions(rootPath string, opts LoadOptions) (*LoadResult, error) {
 	// Find all YAML files
 	files, err := FindRoleFiles(rootPath)
 	if err != nil {
 		return nil, err
 	}
 
+	dirConfig, err := LoadDirectoryConfig(rootPath)
+	if err != nil {
+		return nil, err
+	}
+
 	result := &LoadResult{
 		Roles:        []models.Role{},
 		SkippedFiles: []SkippedFile{},
+		Ignore:       dirConfig.Ignore,
 	}
 
 	// Load each file, tracking skipped ones
-	for _, filePath := range files {
-		role, err := ReadRoleFile(filePath)
-		if err != nil {
+	for i, loaded := range readRoleFiles(files, opts.Parallelism) {
+		if loaded.err != nil {
 			// Track skipped files with reason
-			filename := filepath.Base(filePath)
+			filename := filepath.Base(files[i])
 			result.SkippedFiles = append(result.SkippedFiles, SkippedFile{
 				Path:   filename,
-				Reason: err.Error(),
+				Reason: loaded.err.Error(),
 			})
 			continue
 		}
-		result.Roles = append(result.Roles, role)
+		result.Roles = append(result.Roles, loaded.roles...)
+	}
+
+	// Macros are expanded first, so inherited resources are concrete too
+	result.Roles, err = ExpandMacros(result.Roles, dirConfig.Macros)
+	if err != nil {
+		return nil, err
+	}
+
+	result.Roles, err = ResolveInheritance(result.Roles)
+	if err != nil {
+		return nil, err
 	}
 
 	return result, nil
 }
 
+// loadedFile is the outcome of reading one role file
+type loadedFile struct {
+	roles []models.Role
+	err   error
+}
+
+// readRoleFiles reads every file using up to parallelism workers, returning the
+// outcomes in the same order as files
+func readRoleFiles(files []string, parallelism int) []loadedFile {
+	loaded := make([]loadedFile, len(files))
+	if parallelism > len(files) {
+		parallelism = len(files)
+	}
+	if parallelism <= 1 {
+		for i, filePath := range files {
+			loaded[i].roles, loaded[i].err = ReadRoleDocuments(filePath)
+		}
+		return loaded
+	}
+
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < parallelism; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				loaded[i].roles, loaded[i].err = ReadRoleDocuments(files[i])
+			}
+		}()
+	}
+	for i := range files {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+
+	return loaded
+}
+
 // ValidateRole validates that a role has required fields and valid structure
 func ValidateRole(role models.Role) error {
 	// Check required name field
@@ -141,13 +389,46 @@ func ValidateRole(role models.Role) error {
 		return errors.New("role name is required")
 	}
 
+	if err := models.ValidateManage(role.Manage); err != nil {
+		return fmt.Errorf("role %s: %w", role.Name, err)
+	}
+
+	// A resource both allowed and denied is almost always a mistake, and which one wins
+	// is up to the API. Only identical entries are caught; overlapping wildcards are not.
+	denied := make(map[string]bool, len(role.Resources.Denied))
+	for _, resource := range role.Resources.Denied {
+		denied[resource] = true
+	}
+	for _, resource := range role.Resources.Allowed {
+		if denied[resource] {
+			return fmt.Errorf("resource '%s' is in both allowed and denied in role %s", resource, role.Name)
+		}
+	}
+
 	// Allow empty resources - some roles might be placeholders or have specific use cases
 	return nil
 }
 
-// ValidateRoleMembers validates that no member appears in multiple roles
+// DefaultMaxResourcesPerRole is the default limit on a role's resource entries, allowed
+// and denied combined, that sync checks before sending the role to the API
+const DefaultMaxResourcesPerRole = 1000
+
+// ValidateRoleSize returns an error naming the role and its count if it has more than
+// limit resource entries, allowed and denied combined. A limit of zero disables the check.
+func ValidateRoleSize(role models.Role, limit int) error {
+	count := len(role.Resources.Allowed) + len(role.Resources.Denied)
+	if limit <= 0 || count <= limit {
+		return nil
+	}
+	return fmt.Errorf("role %s%s has %d resource entries (%d allowed, %d denied), more than the limit of %d",
+		role.Name, role.Origin(), count, len(role.Resources.Allowed), len(role.Resources.Denied), limit)
+}
+
+// ValidateRoleMembers validates that no member appears in multiple roles. When several
+// members are duplicated, the one that appears first in roles is reported.
 func ValidateRoleMembers(roles []models.Role) error {
 	memberToRoles := make(map[string][]string)
+	var memberOrder []string
 
 	for _, role := range roles {
 		// Check for empty member emails within each role
@@ -156,23 +437,26 @@ func ValidateRoleMembers(roles []models.Role) error {
 		for _, member := range role.Members {
 			// Check for empty or whitespace-only emails
 			if strings.TrimSpace(member) == "" {
-				return fmt.Errorf("empty member email found in role %s", role.Name)
+				return fmt.Errorf("empty member email found in role %s%s", role.Name, role.Origin())
 			}
 
 			// Check for duplicates within the same role
 			if memberSet[member] {
-				return fmt.Errorf("member %s appears multiple times in role %s", member, role.Name)
+				return fmt.Errorf("member %s appears multiple times in role %s%s", member, role.Name, role.Origin())
 			}
 			memberSet[member] = true
 
 			// Track which roles each member appears in
-			memberToRoles[member] = append(memberToRoles[member], role.Name)
+			if _, seen := memberToRoles[member]; !seen {
+				memberOrder = append(memberOrder, member)
+			}
+			memberToRoles[member] = append(memberToRoles[member], role.Name+role.Origin())
 		}
 	}
 
 	// Check for members appearing in multiple roles
-	for member, roleNames := range memberToRoles {
-		if len(roleNames) > 1 {
+	for _, member := range memberOrder {
+		if roleNames := memberToRoles[member]; len(roleNames) > 1 {
 			return fmt.Errorf("member %s appears in multiple roles: %s", member, strings.Join(roleNames, ", "))
 		}
 	}
@@ -180,31 +464,61 @@ func ValidateRoleMembers(roles []models.Role) error {
 	return nil
 }
 
+// ValidateCaseInsensitiveNames returns an error if two roles have names that differ only by case
+func ValidateCaseInsensitiveNames(roles []models.Role) error {
+	seen := make(map[string]models.Role)
+	for _, role := range roles {
+		key := strings.ToLower(role.Name)
+		if previous, exists := seen[key]; exists && previous.Name != role.Name {
+			return fmt.Errorf("role names %q%s and %q%s differ only by case", previous.Name, previous.Origin(), role.Name, role.Origin())
+		}
+		seen[key] = role
+	}
+	return nil
+}
+
+// idWarningHeader is written above roles that carry an API-managed ID
+const idWarningHeader = "# WARNING: The 'id' field is managed by the Replicated API and should not be modified manually.\n# Changing the ID will cause sync operations to fail.\n\n"
+
+// WriteOptions controls how role files are rendered
+type WriteOptions struct {
+	// OmitIDComment suppresses the warning header normally written above a role's id field.
+	// The id itself is still written.
+	OmitIDComment bool
+
+	// OmitMembers leaves the members field out of the file entirely, so that a later
+	// sync does not manage membership for the role.
+	OmitMembers bool
+
+	// SortLists writes allowed, denied, and members in alphabetical order, so that
+	// files generated from the same roles are byte-identical whatever order the API
+	// returned the lists in. Sync already ignores list order when comparing.
+	SortLists bool
+}
+
 // WriteRoleFile writes a role to a YAML file
 func WriteRoleFile(role models.Role, filePath string) error {
+	return WriteRoleFileWithOptions(role, filePath, WriteOptions{})
+}
+
+// WriteRoleFileWithOptions writes a role to a YAML file using the given rendering options.
+// A new file is created readable only by its owner. An existing file is rewritten in
+// place, so it keeps its mode and ownership, e.g. group-readable files in a shared
+// checkout.
+func WriteRoleFileWithOptions(role models.Role, filePath string, opts WriteOptions) error {
 	// Ensure directory exists
 	dir := filepath.Dir(filePath)
 	if err := os.MkdirAll(dir, 0750); err != nil {
 		return fmt.Errorf("failed to create directory: %w", err)
 	}
 
-	// Marshal role to YAML
-	data, err := yaml.Marshal(&role)
+	content, err := GenerateRoleYAMLWithOptions(role, opts)
 	if err != nil {
-		return fmt.Errorf("failed to marshal role to YAML: %w", err)
-	}
-
-	// Add header comment if the role has an ID
-	var content []byte
-	if role.ID != "" {
-		header := "# WARNING: The 'id' field is managed by the Replicated API and should not be modified manually.\n# Changing the ID will cause sync operations to fail.\n\n"
-		content = append([]byte(header), data...)
-	} else {
-		content = data
+		return err
 	}
 
-	// Write file
-	if err := os.WriteFile(filePath, content, 0600); err != nil {
+	// Write file; the mode only applies if the file does not exist yet
+	if err := os.WriteFile(filePath, []byte(content), 0600); err != nil {
 		return fmt.Errorf("failed to write file: %w", err)
 	}
 
@@ -213,17 +527,66 @@ func WriteRoleFile(role models.Role, filePath string) error {
 
 // GenerateRoleYAML generates YAML content for a role without writing to file
 func GenerateRoleYAML(role models.Role) (string, error) {
+	return GenerateRoleYAMLWithOptions(role, WriteOptions{})
+}
+
+// GenerateRoleYAMLWithOptions generates YAML content for a role using the given rendering options.
+// The id warning header is only added when the role has an ID and it is not suppressed.
+// Members are written when present unless OmitMembers is set. Extra fields read from the
+// role's file are written after the role's own fields, sorted by key.
+func GenerateRoleYAMLWithOptions(role models.Role, opts WriteOptions) (string, error) {
+	if opts.OmitMembers {
+		role.Members = nil
+	}
+	if opts.SortLists {
+		role.Resources.Allowed = sortedStrings(role.Resources.Allowed)
+		role.Resources.Denied = sortedStrings(role.Resources.Denied)
+		role.Members = sortedStrings(role.Members)
+	}
+
 	// Marshal role to YAML
 	data, err := yaml.Marshal(&role)
 	if err != nil {
 		return "", fmt.Errorf("failed to marshal role to YAML: %w", err)
 	}
+	if len(role.Extra) > 0 {
+		extra, err := yaml.Marshal(role.Extra)
+		if err != nil {
+			return "", fmt.Errorf("failed to marshal extra fields of role %s to YAML: %w", role.Name, err)
+		}
+		data = append(data, extra...)
+	}
 
-	// Add header comment if the role has an ID
-	if role.ID != "" {
-		header := "# WARNING: The 'id' field is managed by the Replicated API and should not be modified manually.\n# Changing the ID will cause sync operations to fail.\n\n"
-		return header + string(data), nil
+	if role.ID != "" && !opts.OmitIDComment {
+		return idWarningHeader + string(data), nil
 	}
 
 	return string(data), nil
 }
+
+// GenerateRolesYAML generates one YAML file holding every role, each as its own document
+// rendered as GenerateRoleYAMLWithOptions would, separated by "---" lines
+func GenerateRolesYAML(roles []models.Role, opts WriteOptions) (string, error) {
+	var content strings.Builder
+	for i, role := range roles {
+		document, err := GenerateRoleYAMLWithOptions(role, opts)
+		if err != nil {
+			return "", err
+		}
+		if i > 0 {
+			content.WriteString("---\n")
+		}
+		content.WriteString(document)
+	}
+	return content.String(), nil
+}
+
+// sortedStrings returns a sorted copy of values, preserving nil
+func sortedStrings(values []string) []string {
+	if values == nil {
+		return nil
+	}
+	sorted := append([]string{}, values...)
+	sort.Strings(sorted)
+	return sorted
+}
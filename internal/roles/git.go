@@ -0,0 +1,130 @@
+package roles
+
+import (
+	"bytes"
+	"fmt"
+	"net/url"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// GitSourcePrefix marks a roles directory argument as a git reference, e.g.
+// git::https://github.com/org/repo//roles?ref=v1.2.3
+const GitSourcePrefix = "git::"
+
+// GitSource is a roles directory inside a git repository, pinned to an optional ref
+type GitSource struct {
+	// URL is the repository to clone, in any form git accepts
+	URL string
+	// Subdir is the roles directory within the repository; empty means the repository root
+	Subdir string
+	// Ref is the branch or tag to check out; empty means the default branch
+	Ref string
+}
+
+// IsGitSource reports whether arg names a git reference rather than a local directory
+func IsGitSource(arg string) bool {
+	return strings.HasPrefix(arg, GitSourcePrefix)
+}
+
+// ParseGitSource parses a git reference of the form git::URL[//subdir][?ref=REF].
+// As with Terraform module sources, a double slash after the repository URL
+// separates the repository from the roles directory inside it.
+func ParseGitSource(arg string) (GitSource, error) {
+	if !IsGitSource(arg) {
+		return GitSource{}, fmt.Errorf("%s is not a git reference (expected %sURL)", arg, GitSourcePrefix)
+	}
+	rest := strings.TrimPrefix(arg, GitSourcePrefix)
+
+	var source GitSource
+	if i := strings.Index(rest, "?"); i >= 0 {
+		query, err := url.ParseQuery(rest[i+1:])
+		if err != nil {
+			return GitSource{}, fmt.Errorf("invalid git reference %s: %w", arg, err)
+		}
+		for key := range query {
+			if key != "ref" {
+				return GitSource{}, fmt.Errorf("invalid git reference %s: unsupported parameter %q (only ref is supported)", arg, key)
+			}
+		}
+		source.Ref = query.Get("ref")
+		rest = rest[:i]
+	}
+
+	// Look for the subdirectory separator after the scheme's own double slash
+	searchFrom := 0
+	if i := strings.Index(rest, "://"); i >= 0 {
+		searchFrom = i + len("://")
+	}
+	if i := strings.Index(rest[searchFrom:], "//"); i >= 0 {
+		source.Subdir = rest[searchFrom+i+2:]
+		rest = rest[:searchFrom+i]
+	}
+	source.URL = rest
+
+	if source.URL == "" {
+		return GitSource{}, fmt.Errorf("invalid git reference %s: missing repository URL", arg)
+	}
+	if source.Subdir != "" {
+		cleaned := path.Clean(source.Subdir)
+		if path.IsAbs(cleaned) || cleaned == ".." || strings.HasPrefix(cleaned, "../") {
+			return GitSource{}, fmt.Errorf("invalid git reference %s: subdirectory %s is outside the repository", arg, source.Subdir)
+		}
+		source.Subdir = cleaned
+	}
+
+	return source, nil
+}
+
+// Checkout shallow-clones the repository at the source's ref into a new temporary
+// directory and returns the path of the roles directory within it. The returned
+// cleanup function removes the clone and must be called once the roles are loaded;
+// on error nothing is left behind.
+func (s GitSource) Checkout() (string, func(), error) {
+	cloneDir, err := os.MkdirTemp("", "replbac-git-")
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to create directory for %s: %w", s.URL, err)
+	}
+	cleanup := func() {
+		_ = os.RemoveAll(cloneDir)
+	}
+
+	args := []string{"clone", "--depth", "1", "--quiet"}
+	if s.Ref != "" {
+		args = append(args, "--branch", s.Ref)
+	}
+	args = append(args, "--", s.URL, cloneDir)
+
+	var stderr bytes.Buffer
+	clone := exec.Command("git", args...) // #nosec G204 -- Cloning a user-provided repository is expected behavior
+	clone.Stderr = &stderr
+	// Fail instead of waiting for credentials on a terminal nobody is watching
+	clone.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0")
+	if err := clone.Run(); err != nil {
+		cleanup()
+		if message := strings.TrimSpace(stderr.String()); message != "" {
+			return "", nil, fmt.Errorf("failed to clone %s: %s", s, message)
+		}
+		return "", nil, fmt.Errorf("failed to clone %s: %w", s, err)
+	}
+
+	rolesDir := filepath.Join(cloneDir, filepath.FromSlash(s.Subdir))
+	info, err := os.Stat(rolesDir)
+	if err != nil || !info.IsDir() {
+		cleanup()
+		return "", nil, fmt.Errorf("directory %s not found in %s", s.Subdir, s)
+	}
+
+	return rolesDir, cleanup, nil
+}
+
+// String returns the source's repository URL and ref, for messages
+func (s GitSource) String() string {
+	if s.Ref == "" {
+		return s.URL
+	}
+	return fmt.Sprintf("%s at %s", s.URL, s.Ref)
+}
@@ -0,0 +1,167 @@
+package roles
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseGitSource(t *testing.T) {
+	tests := []struct {
+		name          string
+		arg           string
+		expected      GitSource
+		errorContains string
+	}{
+		{
+			name:     "https with subdirectory and ref",
+			arg:      "git::https://github.com/org/repo//roles?ref=v1.2.3",
+			expected: GitSource{URL: "https://github.com/org/repo", Subdir: "roles", Ref: "v1.2.3"},
+		},
+		{
+			name:     "repository root without ref",
+			arg:      "git::https://github.com/org/repo.git",
+			expected: GitSource{URL: "https://github.com/org/repo.git"},
+		},
+		{
+			name:     "scp-style URL with nested subdirectory",
+			arg:      "git::git@github.com:org/repo.git//teams/prod/?ref=main",
+			expected: GitSource{URL: "git@github.com:org/repo.git", Subdir: "teams/prod", Ref: "main"},
+		},
+		{
+			name:          "not a git reference",
+			arg:           "./roles",
+			errorContains: "is not a git reference",
+		},
+		{
+			name:          "unsupported parameter",
+			arg:           "git::https://github.com/org/repo?depth=5",
+			errorContains: `unsupported parameter "depth"`,
+		},
+		{
+			name:          "subdirectory escaping the repository",
+			arg:           "git::https://github.com/org/repo//../etc",
+			errorContains: "outside the repository",
+		},
+		{
+			name:          "missing URL",
+			arg:           "git::?ref=main",
+			errorContains: "missing repository URL",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			source, err := ParseGitSource(tt.arg)
+			if tt.errorContains != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.errorContains) {
+					t.Fatalf("Expected error containing %q, got %v", tt.errorContains, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if source != tt.expected {
+				t.Errorf("ParseGitSource() = %+v, want %+v", source, tt.expected)
+			}
+		})
+	}
+}
+
+// createGitRepo creates a repository with roles/admin.yaml committed and tagged v1
+func createGitRepo(t *testing.T) string {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	repo := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(repo, "roles"), 0750); err != nil {
+		t.Fatalf("Failed to create roles directory: %v", err)
+	}
+	role := "name: admin\nresources:\n  allowed: [\"*\"]\n  denied: []\n"
+	if err := os.WriteFile(filepath.Join(repo, "roles", "admin.yaml"), []byte(role), 0600); err != nil {
+		t.Fatalf("Failed to write role file: %v", err)
+	}
+
+	for _, args := range [][]string{
+		{"init", "--quiet"},
+		{"add", "."},
+		{"-c", "user.name=test", "-c", "user.email=test@example.com", "commit", "--quiet", "-m", "roles"},
+		{"tag", "v1"},
+	} {
+		git := exec.Command("git", args...)
+		git.Dir = repo
+		if output, err := git.CombinedOutput(); err != nil {
+			t.Fatalf("git %s failed: %v\n%s", strings.Join(args, " "), err, output)
+		}
+	}
+	return repo
+}
+
+func TestGitSourceCheckout(t *testing.T) {
+	repo := createGitRepo(t)
+	t.Setenv("TMPDIR", t.TempDir())
+
+	source := GitSource{URL: "file://" + repo, Subdir: "roles", Ref: "v1"}
+	dir, cleanup, err := source.Checkout()
+	if err != nil {
+		t.Fatalf("Checkout failed: %v", err)
+	}
+
+	result, err := LoadRolesFromDirectoryWithDetails(dir)
+	if err != nil {
+		t.Fatalf("Failed to load checked-out roles: %v", err)
+	}
+	if len(result.Roles) != 1 || result.Roles[0].Name != "admin" {
+		t.Errorf("Expected the admin role from the repository, got %+v", result.Roles)
+	}
+
+	cleanup()
+	if _, err := os.Stat(dir); !os.IsNotExist(err) {
+		t.Errorf("Expected checkout %s to be removed, stat returned %v", dir, err)
+	}
+}
+
+func TestGitSourceCheckoutErrors(t *testing.T) {
+	repo := createGitRepo(t)
+	tmpDir := t.TempDir()
+	t.Setenv("TMPDIR", tmpDir)
+
+	tests := []struct {
+		name          string
+		source        GitSource
+		errorContains string
+	}{
+		{
+			name:          "unknown ref",
+			source:        GitSource{URL: "file://" + repo, Ref: "v9"},
+			errorContains: "failed to clone file://" + repo + " at v9",
+		},
+		{
+			name:          "missing subdirectory",
+			source:        GitSource{URL: "file://" + repo, Subdir: "nope"},
+			errorContains: "directory nope not found",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := tt.source.Checkout()
+			if err == nil || !strings.Contains(err.Error(), tt.errorContains) {
+				t.Fatalf("Expected error containing %q, got %v", tt.errorContains, err)
+			}
+
+			entries, err := os.ReadDir(tmpDir)
+			if err != nil {
+				t.Fatalf("Failed to read temp dir: %v", err)
+			}
+			if len(entries) != 0 {
+				t.Errorf("Expected failed checkout to leave nothing behind, found %d entries", len(entries))
+			}
+		})
+	}
+}
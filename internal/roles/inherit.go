@@ -0,0 +1,87 @@
+package roles
+
+import (
+	"fmt"
+	"strings"
+
+	"replbac/internal/models"
+)
+
+// ResolveInheritance merges into each role that names a base role in Inherits the
+// resources of that base, which must be among roles and may itself inherit. The child's
+// resources win where they conflict with the base's:
+//
+//   - allowed is the base's allowed, less any the child denies, then the child's allowed
+//   - denied is the base's denied, less any the child allows, then the child's denied
+//
+// Conflicts are exact matches only; a wildcard in one list does not remove a narrower
+// entry from the other. Members and labels are not inherited. An inheritance cycle or a
+// base role that is not defined is an error. If a name is defined more than once, the
+// first role with that name is the base.
+func ResolveInheritance(roles []models.Role) ([]models.Role, error) {
+	index := make(map[string]int, len(roles))
+	for i, role := range roles {
+		if _, exists := index[role.Name]; !exists {
+			index[role.Name] = i
+		}
+	}
+
+	resolved := append([]models.Role{}, roles...)
+	done := make([]bool, len(roles))
+	var resolve func(i int, chain []string) error
+	resolve = func(i int, chain []string) error {
+		role := resolved[i]
+		if done[i] || role.Inherits == "" {
+			done[i] = true
+			return nil
+		}
+		for j, name := range chain {
+			if name == role.Name {
+				cycle := append(append([]string{}, chain[j:]...), role.Name)
+				return fmt.Errorf("role inheritance cycle: %s", strings.Join(cycle, " -> "))
+			}
+		}
+
+		base, exists := index[role.Inherits]
+		if !exists {
+			return fmt.Errorf("role %s%s inherits from %s, which is not defined", role.Name, role.Origin(), role.Inherits)
+		}
+		if err := resolve(base, append(chain, role.Name)); err != nil {
+			return err
+		}
+		resolved[i].Resources = inheritResources(resolved[base].Resources, role.Resources)
+		done[i] = true
+		return nil
+	}
+
+	for i := range resolved {
+		if err := resolve(i, nil); err != nil {
+			return nil, err
+		}
+	}
+	return resolved, nil
+}
+
+// inheritResources merges base resources into child ones, the child's winning where an
+// entry is allowed by one and denied by the other
+func inheritResources(base, child models.Resources) models.Resources {
+	return models.Resources{
+		Allowed: unionStrings(without(base.Allowed, child.Denied), child.Allowed),
+		Denied:  unionStrings(without(base.Denied, child.Allowed), child.Denied),
+	}.Normalized()
+}
+
+// without returns the values not in exclude, in order
+func without(values, exclude []string) []string {
+	excluded := make(map[string]bool, len(exclude))
+	for _, value := range exclude {
+		excluded[value] = true
+	}
+	kept := make([]string, 0, len(values))
+	for _, value := range values {
+		if !excluded[value] {
+			kept = append(kept, value)
+		}
+	}
+	return kept
+}
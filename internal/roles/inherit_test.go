@@ -0,0 +1,152 @@
+package roles
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"replbac/internal/models"
+)
+
+func TestResolveInheritance(t *testing.T) {
+	employee := models.Role{Name: "employee", Resources: models.Resources{
+		Allowed: []string{"kots/app/*/read", "kots/app/*/write"},
+		Denied:  []string{"team/members/write"},
+	}, Members: []string{"alice@example.com"}}
+
+	tests := []struct {
+		name          string
+		input         []models.Role
+		expected      []models.Role
+		errorContains string
+	}{
+		{
+			name: "child resources are added to the base's",
+			input: []models.Role{
+				employee,
+				{Name: "developer", Inherits: "employee", Resources: models.Resources{
+					Allowed: []string{"kots/app/*/promote"},
+					Denied:  []string{"kots/app/*/delete"},
+				}},
+			},
+			expected: []models.Role{
+				employee,
+				{Name: "developer", Inherits: "employee", Resources: models.Resources{
+					Allowed: []string{"kots/app/*/read", "kots/app/*/write", "kots/app/*/promote"},
+					Denied:  []string{"team/members/write", "kots/app/*/delete"},
+				}},
+			},
+		},
+		{
+			name: "child denies override base allows and child allows override base denies",
+			input: []models.Role{
+				{Name: "contractor", Inherits: "employee", Resources: models.Resources{
+					Allowed: []string{"team/members/write"},
+					Denied:  []string{"kots/app/*/write"},
+				}},
+				employee,
+			},
+			expected: []models.Role{
+				{Name: "contractor", Inherits: "employee", Resources: models.Resources{
+					Allowed: []string{"kots/app/*/read", "team/members/write"},
+					Denied:  []string{"kots/app/*/write"},
+				}},
+				employee,
+			},
+		},
+		{
+			name: "bases are resolved through a chain",
+			input: []models.Role{
+				{Name: "lead", Inherits: "developer", Resources: models.Resources{Allowed: []string{"team/members/write"}}},
+				{Name: "developer", Inherits: "employee", Resources: models.Resources{Allowed: []string{"kots/app/*/promote"}}},
+				employee,
+			},
+			expected: []models.Role{
+				{Name: "lead", Inherits: "developer", Resources: models.Resources{
+					Allowed: []string{"kots/app/*/read", "kots/app/*/write", "kots/app/*/promote", "team/members/write"},
+					Denied:  []string{},
+				}},
+				{Name: "developer", Inherits: "employee", Resources: models.Resources{
+					Allowed: []string{"kots/app/*/read", "kots/app/*/write", "kots/app/*/promote"},
+					Denied:  []string{"team/members/write"},
+				}},
+				employee,
+			},
+		},
+		{
+			name: "a cycle is reported",
+			input: []models.Role{
+				{Name: "a", Inherits: "b"},
+				{Name: "b", Inherits: "c"},
+				{Name: "c", Inherits: "b"},
+			},
+			errorContains: "role inheritance cycle: b -> c -> b",
+		},
+		{
+			name:          "a role inheriting from itself is a cycle",
+			input:         []models.Role{{Name: "a", Inherits: "a"}},
+			errorContains: "role inheritance cycle: a -> a",
+		},
+		{
+			name:          "an undefined base is reported",
+			input:         []models.Role{{Name: "developer", Inherits: "employee", SourceFile: "developer.yaml"}},
+			errorContains: "role developer (from developer.yaml) inherits from employee, which is not defined",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resolved, err := ResolveInheritance(tt.input)
+			if tt.errorContains != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.errorContains) {
+					t.Fatalf("Expected error containing %q, got %v", tt.errorContains, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(resolved, tt.expected) {
+				t.Errorf("Expected %+v, got %+v", tt.expected, resolved)
+			}
+		})
+	}
+}
+
+func TestLoadRolesFromDirectory_Inheritance(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"employee.yaml": "name: employee\nresources:\n  allowed:\n    - kots/app/*/read\n",
+		"developer.yaml": "name: developer\ninherits: employee\nresources:\n  allowed:\n    - kots/app/*/write\n" +
+			"members:\n  - alice@example.com\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0600); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+
+	loaded, err := LoadRolesFromDirectory(dir)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(loaded) != 2 || loaded[0].Name != "developer" {
+		t.Fatalf("Expected developer and employee roles, got %+v", loaded)
+	}
+	developer := loaded[0]
+	if !reflect.DeepEqual(developer.Resources.Allowed, []string{"kots/app/*/read", "kots/app/*/write"}) {
+		t.Errorf("Expected developer to allow the base's resources and its own, got %v", developer.Resources.Allowed)
+	}
+	if developer.Extra != nil {
+		t.Errorf("Expected inherits not to be kept as an extra field, got %v", developer.Extra)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "employee.yaml"), []byte("name: employee\ninherits: developer\n"), 0600); err != nil {
+		t.Fatalf("Failed to write employee.yaml: %v", err)
+	}
+	if _, err := LoadRolesFromDirectory(dir); err == nil || !strings.Contains(err.Error(), "role inheritance cycle") {
+		t.Errorf("Expected a cycle error, got %v", err)
+	}
+}
@@ -0,0 +1,49 @@
+package roles
+
+import (
+	"fmt"
+
+	"replbac/internal/models"
+)
+
+// broadWildcards are allowed resources that grant everything
+var broadWildcards = []string{"*", "**/*"}
+
+// BroadGrant describes a role that allows a catch-all wildcard without denying anything
+type BroadGrant struct {
+	Role     string
+	Wildcard string
+}
+
+// String formats the grant as a warning for reviewers
+func (g BroadGrant) String() string {
+	return fmt.Sprintf("role %s grants wildcard '%s' — is this intended?", g.Role, g.Wildcard)
+}
+
+// FindBroadGrants returns the roles that allow a catch-all wildcard with no denied
+// resources to narrow it, which is effectively admin access
+func FindBroadGrants(roles []models.Role) []BroadGrant {
+	var grants []BroadGrant
+	for _, role := range roles {
+		if len(role.Resources.Denied) > 0 {
+			continue
+		}
+		for _, allowed := range role.Resources.Allowed {
+			if isBroadWildcard(allowed) {
+				grants = append(grants, BroadGrant{Role: role.Name, Wildcard: allowed})
+				break
+			}
+		}
+	}
+	return grants
+}
+
+// isBroadWildcard reports whether a resource pattern matches every resource
+func isBroadWildcard(resource string) bool {
+	for _, wildcard := range broadWildcards {
+		if resource == wildcard {
+			return true
+		}
+	}
+	return false
+}
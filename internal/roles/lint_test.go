@@ -0,0 +1,30 @@
+package roles
+
+import (
+	"reflect"
+	"testing"
+
+	"replbac/internal/models"
+)
+
+func TestFindBroadGrants(t *testing.T) {
+	roles := []models.Role{
+		{Name: "admin", Resources: models.Resources{Allowed: []string{"**/*"}}},
+		{Name: "viewer", Resources: models.Resources{Allowed: []string{"kots/app/*/read", "*"}}},
+		{Name: "restricted", Resources: models.Resources{Allowed: []string{"**/*"}, Denied: []string{"kots/app/*/delete"}}},
+		{Name: "scoped", Resources: models.Resources{Allowed: []string{"kots/app/*"}}},
+	}
+
+	want := []BroadGrant{
+		{Role: "admin", Wildcard: "**/*"},
+		{Role: "viewer", Wildcard: "*"},
+	}
+	got := FindBroadGrants(roles)
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("FindBroadGrants() = %+v, want %+v", got, want)
+	}
+
+	if msg := got[1].String(); msg != "role viewer grants wildcard '*' — is this intended?" {
+		t.Errorf("BroadGrant.String() = %q", msg)
+	}
+}
@@ -0,0 +1,71 @@
+package roles
+
+import (
+	"fmt"
+	"strings"
+
+	"replbac/internal/models"
+)
+
+// MacroPrefix marks an allowed or denied entry as the name of a resource macro rather
+// than a resource, e.g. @read-basics
+const MacroPrefix = "@"
+
+// ExpandMacros replaces each allowed or denied entry naming a macro, such as @read-basics,
+// with the resources macros defines for it. A list that used a macro is deduplicated,
+// keeping the first occurrence of each resource; other lists are left as they are. A
+// macro that is not defined is an error, as is a resource both allowed and denied once
+// the macros are expanded.
+func ExpandMacros(roles []models.Role, macros map[string][]string) ([]models.Role, error) {
+	expanded := append([]models.Role{}, roles...)
+	for i, role := range expanded {
+		allowed, allowedChanged, err := expandMacroList(role, role.Resources.Allowed, macros)
+		if err != nil {
+			return nil, err
+		}
+		denied, deniedChanged, err := expandMacroList(role, role.Resources.Denied, macros)
+		if err != nil {
+			return nil, err
+		}
+		if !allowedChanged && !deniedChanged {
+			continue
+		}
+		role.Resources.Allowed = allowed
+		role.Resources.Denied = denied
+		if err := ValidateRole(role); err != nil {
+			return nil, fmt.Errorf("%w%s after expanding macros", err, role.Origin())
+		}
+		expanded[i] = role
+	}
+	return expanded, nil
+}
+
+// expandMacroList expands the macros in one of role's resource lists, reporting whether
+// it used any
+func expandMacroList(role models.Role, resources []string, macros map[string][]string) ([]string, bool, error) {
+	usesMacro := false
+	for _, resource := range resources {
+		if strings.HasPrefix(resource, MacroPrefix) {
+			usesMacro = true
+			break
+		}
+	}
+	if !usesMacro {
+		return resources, false, nil
+	}
+
+	var expanded []string
+	for _, resource := range resources {
+		name, isMacro := strings.CutPrefix(resource, MacroPrefix)
+		if !isMacro {
+			expanded = append(expanded, resource)
+			continue
+		}
+		definition, defined := macros[name]
+		if !defined {
+			return nil, false, fmt.Errorf("role %s%s uses resource macro %s, which is not defined in %s", role.Name, role.Origin(), resource, DirectoryConfigFile)
+		}
+		expanded = append(expanded, definition...)
+	}
+	return unionStrings(expanded, []string{}), true, nil
+}
@@ -0,0 +1,112 @@
+package roles
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"replbac/internal/models"
+)
+
+func TestExpandMacros(t *testing.T) {
+	macros := map[string][]string{
+		"read-basics": {"kots/app/*/read", "kots/app/*/channel/*/read"},
+		"billing":     {"team/billing/**"},
+	}
+
+	tests := []struct {
+		name        string
+		resources   models.Resources
+		expected    models.Resources
+		expectError string
+	}{
+		{
+			name:      "macro expands in place",
+			resources: models.Resources{Allowed: []string{"kots/app/*/release/**", "@read-basics"}, Denied: []string{"@billing"}},
+			expected:  models.Resources{Allowed: []string{"kots/app/*/release/**", "kots/app/*/read", "kots/app/*/channel/*/read"}, Denied: []string{"team/billing/**"}},
+		},
+		{
+			name:      "expanded resources are deduplicated",
+			resources: models.Resources{Allowed: []string{"kots/app/*/read", "@read-basics", "@read-basics"}, Denied: []string{}},
+			expected:  models.Resources{Allowed: []string{"kots/app/*/read", "kots/app/*/channel/*/read"}, Denied: []string{}},
+		},
+		{
+			name:      "lists without macros are unchanged",
+			resources: models.Resources{Allowed: []string{"kots/app/*/read", "kots/app/*/read"}, Denied: []string{}},
+			expected:  models.Resources{Allowed: []string{"kots/app/*/read", "kots/app/*/read"}, Denied: []string{}},
+		},
+		{
+			name:        "undefined macro",
+			resources:   models.Resources{Allowed: []string{"@write-basics"}},
+			expectError: "role support (from support.yaml) uses resource macro @write-basics, which is not defined in .replbac.yaml",
+		},
+		{
+			name:        "resource allowed and denied after expansion",
+			resources:   models.Resources{Allowed: []string{"@read-basics"}, Denied: []string{"kots/app/*/read"}},
+			expectError: "resource 'kots/app/*/read' is in both allowed and denied in role support (from support.yaml) after expanding macros",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			role := models.Role{Name: "support", Resources: tt.resources, SourceFile: "support.yaml"}
+			expanded, err := ExpandMacros([]models.Role{role}, macros)
+			if tt.expectError != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.expectError) {
+					t.Fatalf("expected error containing %q, got %v", tt.expectError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(expanded[0].Resources, tt.expected) {
+				t.Errorf("Resources = %+v, want %+v", expanded[0].Resources, tt.expected)
+			}
+		})
+	}
+}
+
+func TestLoadRolesFromDirectory_Macros(t *testing.T) {
+	dir := t.TempDir()
+	config := "macros:\n  read-basics:\n    - kots/app/*/read\n    - kots/app/*/channel/*/read\n"
+	if err := os.WriteFile(filepath.Join(dir, DirectoryConfigFile), []byte(config), 0600); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+	files := map[string]string{
+		"base.yaml":    "name: base\nresources:\n  allowed: [\"@read-basics\"]\n  denied: []\n",
+		"support.yaml": "name: support\ninherits: base\nresources:\n  allowed: [\"kots/app/*/read\", \"team/support/**\"]\n  denied: []\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0600); err != nil {
+			t.Fatalf("failed to write role: %v", err)
+		}
+	}
+
+	loaded, err := LoadRolesFromDirectory(dir)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	byName := make(map[string]models.Role)
+	for _, role := range loaded {
+		byName[role.Name] = role
+	}
+	if want := []string{"kots/app/*/read", "kots/app/*/channel/*/read"}; !reflect.DeepEqual(byName["base"].Resources.Allowed, want) {
+		t.Errorf("base allowed = %v, want %v", byName["base"].Resources.Allowed, want)
+	}
+	for _, resource := range byName["support"].Resources.Allowed {
+		if strings.HasPrefix(resource, MacroPrefix) {
+			t.Errorf("support inherited unexpanded macro %s", resource)
+		}
+	}
+
+	// An undefined macro fails the load instead of skipping the file
+	if err := os.WriteFile(filepath.Join(dir, "broken.yaml"), []byte("name: broken\nresources:\n  allowed: [\"@missing\"]\n  denied: []\n"), 0600); err != nil {
+		t.Fatalf("failed to write role: %v", err)
+	}
+	if _, err := LoadRolesFromDirectory(dir); err == nil || !strings.Contains(err.Error(), "uses resource macro @missing, which is not defined") {
+		t.Errorf("expected undefined macro error, got %v", err)
+	}
+}
@@ -0,0 +1,150 @@
+package roles
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"text/template"
+
+	"gopkg.in/yaml.v3"
+
+	"replbac/internal/models"
+)
+
+// ValueSet holds the values for one rendering of each role template, referenced in
+// templates as {{.Key}}
+type ValueSet map[string]interface{}
+
+// LoadValueSets reads the values file used to render role templates. The file is either
+// a list of value sets, each rendered once, or a mapping whose list values are expanded
+// into every combination, e.g. {App: [billing, shipping], Env: [prod, staging]} gives
+// four value sets. Scalar values in a mapping are shared by every combination.
+func LoadValueSets(filePath string) ([]ValueSet, error) {
+	data, err := os.ReadFile(filePath) // #nosec G304 -- Reading user-provided values file is expected behavior
+	if err != nil {
+		return nil, fmt.Errorf("failed to read values file: %w", err)
+	}
+
+	var document interface{}
+	if err := yaml.Unmarshal(data, &document); err != nil {
+		return nil, fmt.Errorf("failed to parse values file %s: %w", filePath, err)
+	}
+
+	switch values := document.(type) {
+	case []interface{}:
+		sets := make([]ValueSet, 0, len(values))
+		for i, entry := range values {
+			set, ok := entry.(map[string]interface{})
+			if !ok {
+				return nil, fmt.Errorf("value set %d in %s is not a mapping", i, filePath)
+			}
+			sets = append(sets, ValueSet(set))
+		}
+		return sets, nil
+	case map[string]interface{}:
+		return expandValueMatrix(values), nil
+	case nil:
+		return nil, errors.New("values file is empty")
+	default:
+		return nil, fmt.Errorf("values file %s must contain a list of value sets or a mapping of values", filePath)
+	}
+}
+
+// expandValueMatrix returns every combination of the list values in matrix
+func expandValueMatrix(matrix map[string]interface{}) []ValueSet {
+	keys := make([]string, 0, len(matrix))
+	for key := range matrix {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	sets := []ValueSet{{}}
+	for _, key := range keys {
+		choices, isList := matrix[key].([]interface{})
+		if !isList {
+			choices = []interface{}{matrix[key]}
+		}
+
+		expanded := make([]ValueSet, 0, len(sets)*len(choices))
+		for _, set := range sets {
+			for _, choice := range choices {
+				next := make(ValueSet, len(set)+1)
+				for k, v := range set {
+					next[k] = v
+				}
+				next[key] = choice
+				expanded = append(expanded, next)
+			}
+		}
+		sets = expanded
+	}
+	return sets
+}
+
+// RenderTemplates renders every role template in templateDir once per value set and
+// returns the resulting roles. Templates use Go text/template syntax and must render
+// to a valid role file; a value missing from a set is an error, as is two renderings
+// producing the same role name.
+func RenderTemplates(templateDir string, valueSets []ValueSet) ([]models.Role, error) {
+	files, err := FindRoleFiles(templateDir)
+	if err != nil {
+		return nil, err
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no role templates found in %s", templateDir)
+	}
+
+	rendered := []models.Role{}
+	sources := make(map[string]string)
+
+	for _, filePath := range files {
+		name := filepath.Base(filePath)
+		data, err := os.ReadFile(filePath) // #nosec G304 -- Reading templates from a user-provided directory is expected behavior
+		if err != nil {
+			return nil, fmt.Errorf("failed to read template %s: %w", name, err)
+		}
+
+		tmpl, err := template.New(name).Option("missingkey=error").Parse(string(data))
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse template %s: %w", name, err)
+		}
+
+		for i, values := range valueSets {
+			role, err := renderRole(tmpl, values)
+			if err != nil {
+				return nil, fmt.Errorf("failed to render template %s with value set %d: %w", name, i+1, err)
+			}
+
+			source := fmt.Sprintf("%s (value set %d)", name, i+1)
+			if previous, exists := sources[role.Name]; exists {
+				return nil, fmt.Errorf("role %q is rendered by both %s and %s", role.Name, previous, source)
+			}
+			sources[role.Name] = source
+			rendered = append(rendered, role)
+		}
+	}
+
+	return rendered, nil
+}
+
+// renderRole executes a template with one value set and parses the result as a role
+func renderRole(tmpl *template.Template, values ValueSet) (models.Role, error) {
+	var role models.Role
+
+	var output bytes.Buffer
+	if err := tmpl.Execute(&output, map[string]interface{}(values)); err != nil {
+		return role, err
+	}
+
+	if err := yaml.Unmarshal(output.Bytes(), &role); err != nil {
+		return role, fmt.Errorf("rendered template is not valid YAML: %w", err)
+	}
+
+	if err := ValidateRole(role); err != nil {
+		return role, err
+	}
+	return role, nil
+}
@@ -0,0 +1,144 @@
+package roles
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestLoadValueSets(t *testing.T) {
+	tests := []struct {
+		name        string
+		content     string
+		expectSets  []ValueSet
+		expectError string
+	}{
+		{
+			name:    "list of value sets",
+			content: "- App: billing\n- App: shipping\n",
+			expectSets: []ValueSet{
+				{"App": "billing"},
+				{"App": "shipping"},
+			},
+		},
+		{
+			name:    "mapping expands every combination",
+			content: "App: [billing, shipping]\nEnv: [prod, staging]\nTeam: core\n",
+			expectSets: []ValueSet{
+				{"App": "billing", "Env": "prod", "Team": "core"},
+				{"App": "billing", "Env": "staging", "Team": "core"},
+				{"App": "shipping", "Env": "prod", "Team": "core"},
+				{"App": "shipping", "Env": "staging", "Team": "core"},
+			},
+		},
+		{
+			name:        "list entry that is not a mapping",
+			content:     "- billing\n",
+			expectError: "value set 0",
+		},
+		{
+			name:        "empty file",
+			content:     "",
+			expectError: "values file is empty",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			valuesFile := filepath.Join(t.TempDir(), "values.yaml")
+			if err := os.WriteFile(valuesFile, []byte(tt.content), 0600); err != nil {
+				t.Fatalf("failed to write values file: %v", err)
+			}
+
+			sets, err := LoadValueSets(valuesFile)
+			if tt.expectError != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.expectError) {
+					t.Fatalf("expected error containing %q, got %v", tt.expectError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(sets, tt.expectSets) {
+				t.Errorf("value sets = %v, want %v", sets, tt.expectSets)
+			}
+		})
+	}
+}
+
+func TestRenderTemplates(t *testing.T) {
+	tests := []struct {
+		name        string
+		templates   map[string]string
+		valueSets   []ValueSet
+		expectNames []string
+		expectError string
+	}{
+		{
+			name: "renders each template once per value set",
+			templates: map[string]string{
+				"admin.yaml":  "name: \"{{.App}}-admin\"\nresources:\n  allowed: [\"kots/app/{{.App}}/**\"]\n  denied: []\n",
+				"viewer.yaml": "name: \"{{.App}}-viewer\"\nresources:\n  allowed: [\"kots/app/{{.App}}/read\"]\n  denied: []\n",
+			},
+			valueSets:   []ValueSet{{"App": "billing"}, {"App": "shipping"}},
+			expectNames: []string{"billing-admin", "shipping-admin", "billing-viewer", "shipping-viewer"},
+		},
+		{
+			name: "missing value is an error",
+			templates: map[string]string{
+				"admin.yaml": "name: \"{{.App}}-{{.Env}}\"\nresources:\n  allowed: [\"*\"]\n  denied: []\n",
+			},
+			valueSets:   []ValueSet{{"App": "billing"}},
+			expectError: "failed to render template admin.yaml with value set 1",
+		},
+		{
+			name: "duplicate rendered name is an error",
+			templates: map[string]string{
+				"admin.yaml": "name: \"{{.Team}}-admin\"\nresources:\n  allowed: [\"*\"]\n  denied: []\n",
+			},
+			valueSets:   []ValueSet{{"Team": "core", "App": "a"}, {"Team": "core", "App": "b"}},
+			expectError: `role "core-admin" is rendered by both admin.yaml (value set 1) and admin.yaml (value set 2)`,
+		},
+		{
+			name: "rendered role must be valid",
+			templates: map[string]string{
+				"admin.yaml": "resources:\n  allowed: [\"{{.App}}\"]\n",
+			},
+			valueSets:   []ValueSet{{"App": "billing"}},
+			expectError: "role name is required",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			templateDir := t.TempDir()
+			for name, content := range tt.templates {
+				if err := os.WriteFile(filepath.Join(templateDir, name), []byte(content), 0600); err != nil {
+					t.Fatalf("failed to write template: %v", err)
+				}
+			}
+
+			rendered, err := RenderTemplates(templateDir, tt.valueSets)
+			if tt.expectError != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.expectError) {
+					t.Fatalf("expected error containing %q, got %v", tt.expectError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			names := make([]string, 0, len(rendered))
+			for _, role := range rendered {
+				names = append(names, role.Name)
+			}
+			if !reflect.DeepEqual(names, tt.expectNames) {
+				t.Errorf("rendered roles = %v, want %v", names, tt.expectNames)
+			}
+		})
+	}
+}
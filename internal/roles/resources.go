@@ -0,0 +1,170 @@
+package roles
+
+import (
+	"fmt"
+	"strings"
+
+	"replbac/internal/models"
+)
+
+// resourceGrammar lists the shapes of Replicated resource names. Literal segments must
+// match exactly; ":name" matches any segment that is not an action, and ":action"
+// matches an action. A resource may also stop early to cover everything below it.
+var resourceGrammar = []string{
+	"kots/app/create",
+	"kots/app/:app/:action",
+	"kots/app/:app/:collection/:action",
+	"kots/app/:app/:collection/:id/:action",
+	"kots/externalregistry/:action",
+	"kots/externalregistry/:registry/:action",
+	"kots/license/:license/:action",
+	"team/:collection/:action",
+	"team/:collection/:id/:action",
+	"user/:collection/:action",
+	"user/:collection/:id/:action",
+	"platform/:collection/:action",
+	"platform/:collection/:id/:action",
+}
+
+// resourceActions are the segments that end a resource name
+var resourceActions = map[string]bool{
+	"admin":     true,
+	"archive":   true,
+	"create":    true,
+	"delete":    true,
+	"list":      true,
+	"promote":   true,
+	"read":      true,
+	"unarchive": true,
+	"update":    true,
+	"write":     true,
+}
+
+// ResourceIssue describes an allowed or denied entry that does not fit the resource grammar
+type ResourceIssue struct {
+	Role       string
+	Field      string // "allowed" or "denied"
+	Resource   string
+	Problem    string
+	Suggestion string // A conforming resource the entry was probably meant to be, if one was found
+}
+
+// String formats the issue as a warning naming the role and entry
+func (i ResourceIssue) String() string {
+	message := fmt.Sprintf("role %s: %s resource '%s' %s", i.Role, i.Field, i.Resource, i.Problem)
+	if i.Suggestion != "" {
+		message += fmt.Sprintf("; did you mean '%s'?", i.Suggestion)
+	}
+	return message
+}
+
+// FindResourceIssues checks every allowed and denied entry of roles against the
+// Replicated resource grammar, catching entries with a missing or extra path segment
+// such as kots/app/read, which the API would otherwise reject or never match
+func FindResourceIssues(roles []models.Role) []ResourceIssue {
+	var issues []ResourceIssue
+	for _, role := range roles {
+		for _, field := range []struct {
+			name      string
+			resources []string
+		}{
+			{"allowed", role.Resources.Allowed},
+			{"denied", role.Resources.Denied},
+		} {
+			for _, resource := range field.resources {
+				problem := resourceProblem(resource)
+				if problem == "" {
+					continue
+				}
+				issues = append(issues, ResourceIssue{
+					Role:       role.Name,
+					Field:      field.name,
+					Resource:   resource,
+					Problem:    problem,
+					Suggestion: suggestResource(resource),
+				})
+			}
+		}
+	}
+	return issues
+}
+
+// resourceProblem describes why resource does not conform, or returns an empty string if it does
+func resourceProblem(resource string) string {
+	segments := strings.Split(resource, "/")
+	for _, segment := range segments {
+		if segment == "" {
+			return "has an empty path segment"
+		}
+		if strings.Contains(segment, "*") && segment != "*" && segment != "**" {
+			return fmt.Sprintf("has a wildcard inside segment '%s'; wildcards must be whole segments", segment)
+		}
+	}
+	if !conformsToGrammar(segments) {
+		return "does not match any known Replicated resource"
+	}
+	return ""
+}
+
+// conformsToGrammar reports whether segments match a grammar entry or a leading part of one
+func conformsToGrammar(segments []string) bool {
+	for _, pattern := range resourceGrammar {
+		if matchesPattern(segments, strings.Split(pattern, "/")) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesPattern matches resource segments against pattern segments. A "*" segment matches
+// any one pattern segment, and "**" matches everything after it.
+func matchesPattern(segments, pattern []string) bool {
+	if len(segments) > len(pattern) && !hasString(segments, "**") {
+		return false
+	}
+	for i, segment := range segments {
+		if segment == "**" {
+			return true
+		}
+		if i >= len(pattern) {
+			return false
+		}
+		if segment == "*" {
+			continue
+		}
+		switch want := pattern[i]; {
+		case want == ":action":
+			if !resourceActions[segment] {
+				return false
+			}
+		case strings.HasPrefix(want, ":"):
+			if resourceActions[segment] {
+				return false
+			}
+		case segment != want:
+			return false
+		}
+	}
+	return true
+}
+
+// suggestResource looks for a conforming resource one segment away from resource:
+// with a wildcard inserted for a missing segment, or with an extra segment removed.
+// Positions nearest the end are tried first, since a misplaced wildcard near the start
+// can make nearly anything conform.
+func suggestResource(resource string) string {
+	segments := strings.Split(resource, "/")
+	for i := len(segments) - 1; i > 0; i-- {
+		candidate := append(append(append([]string{}, segments[:i]...), "*"), segments[i:]...)
+		if conformsToGrammar(candidate) {
+			return strings.Join(candidate, "/")
+		}
+	}
+	for i := len(segments) - 1; i > 0; i-- {
+		candidate := append(append([]string{}, segments[:i]...), segments[i+1:]...)
+		if resourceProblem(strings.Join(candidate, "/")) == "" {
+			return strings.Join(candidate, "/")
+		}
+	}
+	return ""
+}
@@ -0,0 +1,67 @@
+package roles
+
+import (
+	"testing"
+
+	"replbac/internal/models"
+)
+
+func TestFindResourceIssues(t *testing.T) {
+	tests := []struct {
+		name        string
+		resource    string
+		expectIssue bool
+		suggestion  string
+	}{
+		{name: "app action with wildcard app", resource: "kots/app/*/read"},
+		{name: "app action with app ID", resource: "kots/app/2abc123/write"},
+		{name: "app creation", resource: "kots/app/create"},
+		{name: "channel action", resource: "kots/app/*/channel/*/promote"},
+		{name: "everything below an app", resource: "kots/app/*"},
+		{name: "recursive wildcard", resource: "kots/app/2abc123/**"},
+		{name: "catch-all", resource: "**/*"},
+		{name: "team resource", resource: "team/support-issues/read"},
+		{name: "missing app segment", resource: "kots/app/read", expectIssue: true, suggestion: "kots/app/*/read"},
+		{name: "extra segment", resource: "kots/app/*/read/all", expectIssue: true, suggestion: "kots/app/*/read"},
+		{name: "wildcard inside segment", resource: "kots/app*/read", expectIssue: true},
+		{name: "empty segment", resource: "kots//app/*/read", expectIssue: true, suggestion: ""},
+		{name: "unknown namespace", resource: "vendor/app/read", expectIssue: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			role := models.Role{Name: "viewer", Resources: models.Resources{Denied: []string{tt.resource}}}
+			issues := FindResourceIssues([]models.Role{role})
+			if !tt.expectIssue {
+				if len(issues) != 0 {
+					t.Errorf("Expected %s to conform, got %v", tt.resource, issues)
+				}
+				return
+			}
+			if len(issues) != 1 {
+				t.Fatalf("Expected one issue for %s, got %v", tt.resource, issues)
+			}
+			issue := issues[0]
+			if issue.Role != "viewer" || issue.Field != "denied" || issue.Resource != tt.resource {
+				t.Errorf("Unexpected issue details: %+v", issue)
+			}
+			if tt.suggestion != "" && issue.Suggestion != tt.suggestion {
+				t.Errorf("Expected suggestion %q, got %q", tt.suggestion, issue.Suggestion)
+			}
+		})
+	}
+}
+
+func TestResourceIssueString(t *testing.T) {
+	issue := ResourceIssue{
+		Role:       "viewer",
+		Field:      "allowed",
+		Resource:   "kots/app/read",
+		Problem:    "does not match any known Replicated resource",
+		Suggestion: "kots/app/*/read",
+	}
+	expected := "role viewer: allowed resource 'kots/app/read' does not match any known Replicated resource; did you mean 'kots/app/*/read'?"
+	if issue.String() != expected {
+		t.Errorf("String() = %q, want %q", issue.String(), expected)
+	}
+}
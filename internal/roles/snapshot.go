@@ -0,0 +1,37 @@
+package roles
+
+import (
+	"fmt"
+	"os"
+
+	"replbac/internal/models"
+)
+
+// LoadSnapshot reads a previously saved copy of the remote roles, as written by pull:
+// either a directory of role files or a single multi-document YAML file written with
+// pull --single.
+func LoadSnapshot(path string) ([]models.Role, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read snapshot: %w", err)
+	}
+
+	var snapshot []models.Role
+	if info.IsDir() {
+		snapshot, err = LoadRolesFromDirectory(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read snapshot: %w", err)
+		}
+	} else {
+		snapshot, err = ReadRoleDocuments(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse snapshot %s: %w", path, err)
+		}
+	}
+
+	// The roles stand in for remote roles, which have no file of their own
+	for i := range snapshot {
+		snapshot[i].SourceFile = ""
+	}
+	return snapshot, nil
+}
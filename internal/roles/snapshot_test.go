@@ -0,0 +1,97 @@
+package roles
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"replbac/internal/models"
+)
+
+func TestLoadSnapshot(t *testing.T) {
+	adminFile := "id: role-1\nname: admin\nresources:\n    allowed:\n        - '**/*'\n    denied: []\n"
+	viewerFile := "name: viewer\nresources:\n    allowed:\n        - kots/app/*/read\n    denied:\n        - '**/*'\nmembers:\n    - viewer@example.com\n"
+	expectedRoles := []models.Role{
+		{ID: "role-1", Name: "admin", Resources: models.Resources{Allowed: []string{"**/*"}, Denied: []string{}}},
+		{Name: "viewer", Resources: models.Resources{Allowed: []string{"kots/app/*/read"}, Denied: []string{"**/*"}}, Members: []string{"viewer@example.com"}},
+	}
+
+	tests := []struct {
+		name          string
+		files         map[string]string // Files written under the snapshot directory
+		single        string            // Name of the file to load instead of the directory
+		expectedRoles []models.Role
+		errorContains string
+	}{
+		{
+			name:          "pull output directory",
+			files:         map[string]string{"admin.yaml": adminFile, "team/viewer.yaml": viewerFile},
+			expectedRoles: expectedRoles,
+		},
+		{
+			name:          "pull --single file",
+			files:         map[string]string{"roles.yaml": adminFile + "---\n" + viewerFile},
+			single:        "roles.yaml",
+			expectedRoles: expectedRoles,
+		},
+		{
+			name:          "empty directory",
+			files:         map[string]string{},
+			expectedRoles: []models.Role{},
+		},
+		{
+			name:          "file that is not YAML",
+			files:         map[string]string{"snapshot.json": `[{"name": "admin"}]`},
+			single:        "snapshot.json",
+			errorContains: "not a YAML file",
+		},
+		{
+			name:          "role without name",
+			files:         map[string]string{"roles.yaml": "resources:\n    allowed:\n        - '**/*'\n"},
+			single:        "roles.yaml",
+			errorContains: "role name is required",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for name, content := range tt.files {
+				path := filepath.Join(dir, name)
+				if err := os.MkdirAll(filepath.Dir(path), 0750); err != nil {
+					t.Fatalf("Failed to create directory: %v", err)
+				}
+				if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+					t.Fatalf("Failed to write snapshot file: %v", err)
+				}
+			}
+			path := dir
+			if tt.single != "" {
+				path = filepath.Join(dir, tt.single)
+			}
+
+			roles, err := LoadSnapshot(path)
+			if tt.errorContains != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.errorContains) {
+					t.Fatalf("Expected error containing %q, got %v", tt.errorContains, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(roles, tt.expectedRoles) {
+				t.Errorf("Expected roles %+v, got %+v", tt.expectedRoles, roles)
+			}
+		})
+	}
+}
+
+func TestLoadSnapshotMissingFile(t *testing.T) {
+	_, err := LoadSnapshot(filepath.Join(t.TempDir(), "missing"))
+	if err == nil || !strings.Contains(err.Error(), "failed to read snapshot") {
+		t.Errorf("Expected read error for missing snapshot, got %v", err)
+	}
+}
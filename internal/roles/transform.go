@@ -0,0 +1,189 @@
+package roles
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"replbac/internal/models"
+)
+
+// Transform rewrites a role loaded from a file before it is compared with remote
+// roles, so that a policy can be enforced centrally instead of in every file
+type Transform interface {
+	Apply(role models.Role) (models.Role, error)
+}
+
+// TransformFactory creates a transform from its configuration entry
+type TransformFactory func(config models.TransformConfig) (Transform, error)
+
+var (
+	transformsMu sync.RWMutex
+	transforms   = map[string]TransformFactory{}
+)
+
+func init() {
+	mustRegisterTransform("ensure-deny", func(config models.TransformConfig) (Transform, error) {
+		if len(config.Resources) == 0 {
+			return nil, fmt.Errorf("ensure-deny transform requires resources to deny")
+		}
+		return EnsureDeny{Resources: config.Resources}, nil
+	})
+	mustRegisterTransform("add-prefix", func(config models.TransformConfig) (Transform, error) {
+		if config.Prefix == "" {
+			return nil, fmt.Errorf("add-prefix transform requires a prefix")
+		}
+		return AddPrefix{Prefix: config.Prefix}, nil
+	})
+}
+
+// RegisterTransform makes a transform available by name for the transforms
+// configuration. Registering a name twice is an error.
+func RegisterTransform(name string, factory TransformFactory) error {
+	if name == "" {
+		return fmt.Errorf("invalid transform name %q", name)
+	}
+	if factory == nil {
+		return fmt.Errorf("transform %q has no factory", name)
+	}
+
+	transformsMu.Lock()
+	defer transformsMu.Unlock()
+	if _, exists := transforms[name]; exists {
+		return fmt.Errorf("transform %q is already registered", name)
+	}
+	transforms[name] = factory
+	return nil
+}
+
+// mustRegisterTransform registers a built-in transform, panicking on programmer error
+func mustRegisterTransform(name string, factory TransformFactory) {
+	if err := RegisterTransform(name, factory); err != nil {
+		panic(err)
+	}
+}
+
+// TransformNames returns the names of all registered transforms in sorted order
+func TransformNames() []string {
+	transformsMu.RLock()
+	defer transformsMu.RUnlock()
+
+	names := make([]string, 0, len(transforms))
+	for name := range transforms {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// namedTransform pairs a transform with the name it was configured under, for messages
+type namedTransform struct {
+	name      string
+	transform Transform
+}
+
+// Pipeline applies configured transforms to roles in order
+type Pipeline struct {
+	steps []namedTransform
+}
+
+// NewPipeline builds the transforms named in configs, in order. An empty list gives a
+// pipeline that leaves roles unchanged.
+func NewPipeline(configs []models.TransformConfig) (*Pipeline, error) {
+	pipeline := &Pipeline{}
+	for i, config := range configs {
+		transformsMu.RLock()
+		factory, ok := transforms[config.Name]
+		transformsMu.RUnlock()
+		if !ok {
+			return nil, fmt.Errorf("unknown transform %q at position %d (available: %s)", config.Name, i+1, strings.Join(TransformNames(), ", "))
+		}
+
+		transform, err := factory(config)
+		if err != nil {
+			return nil, fmt.Errorf("transform %q at position %d: %w", config.Name, i+1, err)
+		}
+		pipeline.steps = append(pipeline.steps, namedTransform{name: config.Name, transform: transform})
+	}
+	return pipeline, nil
+}
+
+// Names returns the names of the pipeline's transforms in the order they run
+func (p *Pipeline) Names() []string {
+	names := make([]string, 0, len(p.steps))
+	for _, step := range p.steps {
+		names = append(names, step.name)
+	}
+	return names
+}
+
+// Apply runs every transform on each role and returns the transformed copies. A
+// transformed role must still be valid, and no two roles may end up with the same name.
+func (p *Pipeline) Apply(roles []models.Role) ([]models.Role, error) {
+	if len(p.steps) == 0 {
+		return roles, nil
+	}
+
+	transformed := make([]models.Role, 0, len(roles))
+	sources := make(map[string]string, len(roles))
+	for _, role := range roles {
+		for _, step := range p.steps {
+			var err error
+			role, err = step.transform.Apply(role)
+			if err != nil {
+				return nil, fmt.Errorf("transform %s failed for role %s%s: %w", step.name, role.Name, role.Origin(), err)
+			}
+		}
+
+		if err := ValidateRole(role); err != nil {
+			return nil, fmt.Errorf("transforms produced an invalid role%s: %w", role.Origin(), err)
+		}
+		if previous, exists := sources[role.Name]; exists {
+			return nil, fmt.Errorf("transforms gave roles from %s and %s the same name %q", previous, role.SourceFile, role.Name)
+		}
+		sources[role.Name] = role.SourceFile
+		transformed = append(transformed, role)
+	}
+	return transformed, nil
+}
+
+// EnsureDeny adds resources to every role's denied list, keeping those already present
+type EnsureDeny struct {
+	Resources []string
+}
+
+// Apply returns role with any missing resources appended to its denied list
+func (t EnsureDeny) Apply(role models.Role) (models.Role, error) {
+	denied := append([]string{}, role.Resources.Denied...)
+	for _, resource := range t.Resources {
+		if !hasString(denied, resource) {
+			denied = append(denied, resource)
+		}
+	}
+	role.Resources.Denied = denied
+	return role, nil
+}
+
+// AddPrefix puts a prefix before every role name that does not already start with it
+type AddPrefix struct {
+	Prefix string
+}
+
+// Apply returns role with its name prefixed
+func (t AddPrefix) Apply(role models.Role) (models.Role, error) {
+	if !strings.HasPrefix(role.Name, t.Prefix) {
+		role.Name = t.Prefix + role.Name
+	}
+	return role, nil
+}
+
+// hasString reports whether values includes value
+func hasString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,123 @@
+package roles
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"replbac/internal/models"
+)
+
+func TestNewPipeline(t *testing.T) {
+	tests := []struct {
+		name          string
+		configs       []models.TransformConfig
+		expectNames   []string
+		errorContains string
+	}{
+		{
+			name:        "no transforms",
+			expectNames: []string{},
+		},
+		{
+			name: "built-in transforms in order",
+			configs: []models.TransformConfig{
+				{Name: "add-prefix", Prefix: "team-"},
+				{Name: "ensure-deny", Resources: []string{"admin/secrets/**"}},
+			},
+			expectNames: []string{"add-prefix", "ensure-deny"},
+		},
+		{
+			name:          "unknown transform",
+			configs:       []models.TransformConfig{{Name: "shout"}},
+			errorContains: `unknown transform "shout" at position 1 (available: add-prefix, ensure-deny)`,
+		},
+		{
+			name:          "ensure-deny without resources",
+			configs:       []models.TransformConfig{{Name: "add-prefix", Prefix: "x-"}, {Name: "ensure-deny"}},
+			errorContains: `transform "ensure-deny" at position 2: ensure-deny transform requires resources to deny`,
+		},
+		{
+			name:          "add-prefix without prefix",
+			configs:       []models.TransformConfig{{Name: "add-prefix"}},
+			errorContains: "add-prefix transform requires a prefix",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pipeline, err := NewPipeline(tt.configs)
+			if tt.errorContains != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.errorContains) {
+					t.Fatalf("Expected error containing %q, got %v", tt.errorContains, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if names := pipeline.Names(); !reflect.DeepEqual(names, tt.expectNames) {
+				t.Errorf("Names() = %v, want %v", names, tt.expectNames)
+			}
+		})
+	}
+}
+
+func TestPipelineApply(t *testing.T) {
+	pipeline, err := NewPipeline([]models.TransformConfig{
+		{Name: "ensure-deny", Resources: []string{"admin/secrets/**", "kots/app/*/delete"}},
+		{Name: "add-prefix", Prefix: "team-"},
+	})
+	if err != nil {
+		t.Fatalf("Failed to build pipeline: %v", err)
+	}
+
+	denied := []string{"kots/app/*/delete"}
+	input := []models.Role{
+		{Name: "viewer", Resources: models.Resources{Allowed: []string{"read"}, Denied: denied}},
+		{Name: "team-admin", Resources: models.Resources{Allowed: []string{"*"}}},
+	}
+
+	got, err := pipeline.Apply(input)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	want := []models.Role{
+		{Name: "team-viewer", Resources: models.Resources{Allowed: []string{"read"}, Denied: []string{"kots/app/*/delete", "admin/secrets/**"}}},
+		{Name: "team-admin", Resources: models.Resources{Allowed: []string{"*"}, Denied: []string{"admin/secrets/**", "kots/app/*/delete"}}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Apply() = %+v, want %+v", got, want)
+	}
+	if input[0].Name != "viewer" || len(denied) != 1 {
+		t.Error("Expected Apply to leave the loaded roles unchanged")
+	}
+}
+
+func TestPipelineApplyNameCollision(t *testing.T) {
+	pipeline, err := NewPipeline([]models.TransformConfig{{Name: "add-prefix", Prefix: "team-"}})
+	if err != nil {
+		t.Fatalf("Failed to build pipeline: %v", err)
+	}
+
+	_, err = pipeline.Apply([]models.Role{
+		{Name: "admin", Resources: models.Resources{Allowed: []string{"*"}}, SourceFile: "admin.yaml"},
+		{Name: "team-admin", Resources: models.Resources{Allowed: []string{"*"}}, SourceFile: "team-admin.yaml"},
+	})
+	if err == nil || !strings.Contains(err.Error(), `transforms gave roles from admin.yaml and team-admin.yaml the same name "team-admin"`) {
+		t.Errorf("Expected a name collision error, got %v", err)
+	}
+}
+
+func TestRegisterTransform(t *testing.T) {
+	if err := RegisterTransform("ensure-deny", func(models.TransformConfig) (Transform, error) { return AddPrefix{}, nil }); err == nil {
+		t.Error("Expected registering a built-in name again to fail")
+	}
+	if err := RegisterTransform("", func(models.TransformConfig) (Transform, error) { return AddPrefix{}, nil }); err == nil {
+		t.Error("Expected registering an empty name to fail")
+	}
+	if err := RegisterTransform("nil-factory", nil); err == nil {
+		t.Error("Expected registering a nil factory to fail")
+	}
+}
@@ -0,0 +1,78 @@
+package sync
+
+import (
+	"errors"
+	"fmt"
+
+	"replbac/internal/models"
+)
+
+// MemberAssignment gives one member a role, by name, outside of a sync plan, e.g. a row
+// of an onboarding CSV
+type MemberAssignment struct {
+	Email string
+	Role  string
+	Line  int // Line the assignment was read from, for reporting; 0 if unknown
+}
+
+// AssignmentResult is the outcome of one MemberAssignment
+type AssignmentResult struct {
+	MemberAssignment
+	Action string // One of the Assignment actions, or "" if the assignment failed
+	Error  error  // Why the assignment failed, if it did
+}
+
+// ErrNotOnTeam is the error of an assignment whose member is not on the team while
+// auto-invite is disabled
+var ErrNotOnTeam = errors.New("not on the team")
+
+// AssignMembers gives each member their role, inviting members who are not on the team
+// when auto-invite is enabled. Unlike sync, a failed assignment does not stop the rest,
+// and every outcome is returned in order. A member not on the team without auto-invite
+// is a failure, since the assignment was asked for explicitly. Each role is looked up
+// once. An error is only returned if the team members cannot be listed, before any
+// member is changed.
+func (e *ExecutorWithMembers) AssignMembers(assignments []MemberAssignment) ([]AssignmentResult, error) {
+	teamMembers, err := e.client.GetTeamMembers()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get team members: %w", err)
+	}
+	existingMembers := make(map[string]models.TeamMember, len(teamMembers))
+	for _, member := range teamMembers {
+		existingMembers[models.NormalizeEmail(member.Email)] = member
+	}
+
+	roleErrors := make(map[string]error)
+	results := make([]AssignmentResult, 0, len(assignments))
+	for _, assignment := range assignments {
+		result := AssignmentResult{MemberAssignment: assignment}
+
+		var roleID string
+		if roleErrors[assignment.Role] == nil {
+			id, err := e.roleID(assignment.Role)
+			if err != nil {
+				roleErrors[assignment.Role] = err
+			}
+			roleID = id
+		}
+
+		if err := roleErrors[assignment.Role]; err != nil {
+			result.Error = fmt.Errorf("failed to get role %s: %w", assignment.Role, err)
+		} else if action, err := e.assignMember(assignment.Email, assignment.Role, roleID, existingMembers); err != nil {
+			result.Error = err
+		} else if action == AssignmentSkipped {
+			result.Error = fmt.Errorf("member %s is %w", assignment.Email, ErrNotOnTeam)
+		} else {
+			result.Action = action
+			if action != AssignmentUnchanged {
+				// Later rows for the same member see the role they now have
+				member := existingMembers[models.NormalizeEmail(assignment.Email)]
+				member.Email = assignment.Email
+				member.PolicyID = roleID
+				existingMembers[models.NormalizeEmail(assignment.Email)] = member
+			}
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
@@ -0,0 +1,135 @@
+package sync
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"replbac/internal/models"
+)
+
+func TestExecutorWithMembers_AssignMembers(t *testing.T) {
+	lookups := map[string]int{}
+	client := &MockAPIClientWithMembers{
+		MockAPIClient: MockAPIClient{
+			GetRoleFunc: func(roleName string) (models.Role, error) {
+				lookups[roleName]++
+				if roleName == "ghost" {
+					return models.Role{}, fmt.Errorf("role not found: %s", roleName)
+				}
+				return models.Role{ID: "id-" + roleName, Name: roleName}, nil
+			},
+		},
+		GetTeamMembersFunc: func() ([]models.TeamMember, error) {
+			return []models.TeamMember{
+				{ID: "u1", Email: "alice@example.com", PolicyID: "id-viewer"},
+				{ID: "u2", Email: "bob@example.com", PolicyID: "id-admin"},
+			}, nil
+		},
+	}
+
+	assignments := []MemberAssignment{
+		{Email: "alice@example.com", Role: "admin", Line: 2},
+		{Email: "bob@example.com", Role: "admin", Line: 3},
+		{Email: "carol@example.com", Role: "admin", Line: 4},
+		{Email: "dave@example.com", Role: "ghost", Line: 5},
+		{Email: "erin@example.com", Role: "ghost", Line: 6},
+	}
+
+	t.Run("without invites", func(t *testing.T) {
+		executor := NewExecutorWithMembersAndInvite(client, createTestLogger(), false)
+		results, err := executor.AssignMembers(assignments)
+		if err != nil {
+			t.Fatalf("AssignMembers failed: %v", err)
+		}
+		if len(results) != len(assignments) {
+			t.Fatalf("Expected %d results, got %d", len(assignments), len(results))
+		}
+
+		if results[0].Action != AssignmentAssigned || results[0].Error != nil {
+			t.Errorf("Expected alice to be assigned, got %+v", results[0])
+		}
+		if results[1].Action != AssignmentUnchanged || results[1].Error != nil {
+			t.Errorf("Expected bob to be unchanged, got %+v", results[1])
+		}
+		if !errors.Is(results[2].Error, ErrNotOnTeam) {
+			t.Errorf("Expected carol to fail as not on the team, got %+v", results[2])
+		}
+		for _, result := range results[3:] {
+			if result.Error == nil || result.Action != "" {
+				t.Errorf("Expected %s to fail on the missing role, got %+v", result.Email, result)
+			}
+		}
+		if lookups["admin"] != 1 || lookups["ghost"] != 1 {
+			t.Errorf("Expected each role to be looked up once, got %v", lookups)
+		}
+		if len(client.InvitedMembers) != 0 {
+			t.Errorf("Expected no invites, got %v", client.InvitedMembers)
+		}
+	})
+
+	t.Run("with invites", func(t *testing.T) {
+		executor := NewExecutorWithMembersAndInvite(client, createTestLogger(), true)
+		results, err := executor.AssignMembers(assignments[2:3])
+		if err != nil {
+			t.Fatalf("AssignMembers failed: %v", err)
+		}
+		if results[0].Action != AssignmentInvited || results[0].Error != nil {
+			t.Errorf("Expected carol to be invited, got %+v", results[0])
+		}
+		if invite := client.InvitedMembers["carol@example.com"]; invite == nil || invite.PolicyID != "id-admin" {
+			t.Errorf("Expected carol to be invited to id-admin, got %v", invite)
+		}
+	})
+
+	t.Run("team members cannot be listed", func(t *testing.T) {
+		failing := &MockAPIClientWithMembers{
+			GetTeamMembersFunc: func() ([]models.TeamMember, error) {
+				return nil, errors.New("forbidden")
+			},
+		}
+		executor := NewExecutorWithMembers(failing, createTestLogger())
+		if _, err := executor.AssignMembers(assignments); err == nil {
+			t.Error("Expected an error when team members cannot be listed")
+		}
+		if len(failing.AssignedMembers) != 0 || len(failing.InvitedMembers) != 0 {
+			t.Error("Expected no member to be changed")
+		}
+	})
+}
+
+// TestExecutorWithMembers_MixedCaseReassignment tests that a member listed with different
+// casing than the team's is reassigned using the team's email, and is not orphaned
+func TestExecutorWithMembers_MixedCaseReassignment(t *testing.T) {
+	var assigned []string
+	client := &MockAPIClientWithMembers{
+		MockAPIClient: MockAPIClient{
+			GetRoleFunc: func(roleName string) (models.Role, error) {
+				return models.Role{ID: "id-" + roleName, Name: roleName}, nil
+			},
+		},
+		AssignMemberRoleFunc: func(memberEmail, roleID string) error {
+			assigned = append(assigned, memberEmail)
+			if memberEmail != "Bob@Example.com" {
+				return fmt.Errorf("member with email '%s' not found in team", memberEmail)
+			}
+			return nil
+		},
+		GetTeamMembersFunc: func() ([]models.TeamMember, error) {
+			return []models.TeamMember{{ID: "u1", Email: "Bob@Example.com", PolicyID: "id-viewer"}}, nil
+		},
+	}
+
+	executor := NewExecutorWithMembers(client, createTestLogger())
+	local := []models.Role{{Name: "admin", Members: []string{"bob@example.com"}}}
+	result := executor.ExecuteMembersOnly(local, local)
+	if result.Error != nil {
+		t.Fatalf("unexpected error: %v", result.Error)
+	}
+	if len(assigned) != 1 || assigned[0] != "Bob@Example.com" {
+		t.Errorf("expected the member to be reassigned as Bob@Example.com, got %v", assigned)
+	}
+	if result.MemberDeletions != nil && len(result.MemberDeletions.OrphanedUsers) != 0 {
+		t.Errorf("expected no orphaned users, got %v", result.MemberDeletions.OrphanedUsers)
+	}
+}
@@ -0,0 +1,54 @@
+package sync
+
+import "replbac/internal/models"
+
+// RoleChanges is what an update changes in a role, going from the remote role to the
+// local one. Each list is sorted, and labels are "key=value" pairs.
+type RoleChanges struct {
+	AllowedAdded   []string
+	AllowedRemoved []string
+	DeniedAdded    []string
+	DeniedRemoved  []string
+	LabelsAdded    []string
+	LabelsRemoved  []string
+	MembersAdded   []string // Matched as models.NormalizeEmail does, keeping their casing
+	MembersRemoved []string
+
+	// Rename is the role's new name when the update renames it, as a soft delete or a
+	// case-insensitive name match does, and empty otherwise
+	Rename string
+}
+
+// ComputeRoleChanges returns the changes that turn the remote role into the local one
+func ComputeRoleChanges(remote, local models.Role) RoleChanges {
+	var changes RoleChanges
+	changes.AllowedAdded, changes.AllowedRemoved = resourceChanges(remote.Resources.Allowed, local.Resources.Allowed)
+	changes.DeniedAdded, changes.DeniedRemoved = resourceChanges(remote.Resources.Denied, local.Resources.Denied)
+	changes.LabelsAdded, changes.LabelsRemoved = resourceChanges(remote.LabelPairs(), local.LabelPairs())
+	changes.MembersAdded, changes.MembersRemoved = memberChanges(remote.Members, local.Members)
+	if local.Name != remote.Name {
+		changes.Rename = local.Name
+	}
+	return changes
+}
+
+// Diff returns the update's changes: the precomputed Changes when the update carries them,
+// and otherwise the changes computed from its remote and local roles
+func (u RoleUpdate) Diff() RoleChanges {
+	if u.Changes != nil {
+		return *u.Changes
+	}
+	return ComputeRoleChanges(u.Remote, u.Local)
+}
+
+// newRoleUpdate returns an update of remote to local with its changes precomputed
+func newRoleUpdate(name string, local, remote models.Role, reason string) RoleUpdate {
+	changes := ComputeRoleChanges(remote, local)
+	return RoleUpdate{
+		Name:    name,
+		Local:   local,
+		Remote:  remote,
+		Reason:  reason,
+		Changes: &changes,
+	}
+}
@@ -0,0 +1,113 @@
+package sync
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"replbac/internal/models"
+)
+
+// withoutChanges checks that each update carries the changes from its remote role to its
+// local role, and returns copies without them for comparing with updates written by hand
+func withoutChanges(t *testing.T, updates []RoleUpdate) []RoleUpdate {
+	t.Helper()
+	stripped := make([]RoleUpdate, len(updates))
+	for i, update := range updates {
+		if update.Changes == nil {
+			t.Errorf("update %s has no precomputed changes", update.Name)
+		} else if want := ComputeRoleChanges(update.Remote, update.Local); !reflect.DeepEqual(*update.Changes, want) {
+			t.Errorf("update %s changes = %+v, want %+v", update.Name, *update.Changes, want)
+		}
+		update.Changes = nil
+		stripped[i] = update
+	}
+	return stripped
+}
+
+func TestComputeRoleChanges(t *testing.T) {
+	remote := models.Role{
+		Name:      "editor",
+		Resources: models.Resources{Allowed: []string{"kots/app/*/read", "kots/app/*/write"}, Denied: []string{"team/**"}},
+		Members:   []string{"Alice@Example.com", "bob@example.com"},
+		Labels:    map[string]string{"owner": "platform"},
+	}
+	local := models.Role{
+		Name:      "editor",
+		Resources: models.Resources{Allowed: []string{"kots/app/*/read", "kots/app/*/release"}, Denied: []string{"kots/app/*/delete"}},
+		Members:   []string{"alice@example.com", "carol@example.com"},
+		Labels:    map[string]string{"owner": "support"},
+	}
+
+	got := ComputeRoleChanges(remote, local)
+	want := RoleChanges{
+		AllowedAdded:   []string{"kots/app/*/release"},
+		AllowedRemoved: []string{"kots/app/*/write"},
+		DeniedAdded:    []string{"kots/app/*/delete"},
+		DeniedRemoved:  []string{"team/**"},
+		LabelsAdded:    []string{"owner=support"},
+		LabelsRemoved:  []string{"owner=platform"},
+		MembersAdded:   []string{"carol@example.com"},
+		MembersRemoved: []string{"bob@example.com"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ComputeRoleChanges() = %+v, want %+v", got, want)
+	}
+
+	disabled := SoftDeletedRole(remote)
+	if renamed := ComputeRoleChanges(remote, disabled); renamed.Rename != disabled.Name {
+		t.Errorf("Rename = %q, want %q", renamed.Rename, disabled.Name)
+	}
+
+	// An update built without precomputed changes computes them on demand
+	update := RoleUpdate{Name: "editor", Local: local, Remote: remote}
+	if !reflect.DeepEqual(update.Diff(), want) {
+		t.Errorf("Diff() = %+v, want %+v", update.Diff(), want)
+	}
+}
+
+// TestRoleChangesMatchRenderedDiff tests that the diff DescribePlan renders for each update
+// lists exactly the entries of the update's precomputed changes
+func TestRoleChangesMatchRenderedDiff(t *testing.T) {
+	local := []models.Role{
+		{Name: "editor", Resources: models.Resources{Allowed: []string{"read", "write"}, Denied: []string{"delete"}}, Members: []string{"a@example.com"}},
+		{Name: "viewer", Resources: models.Resources{Allowed: []string{"read"}, Denied: []string{}}, Labels: map[string]string{"tier": "basic"}},
+	}
+	remote := []models.Role{
+		{Name: "editor", Resources: models.Resources{Allowed: []string{"read", "admin"}, Denied: []string{}}, Members: []string{"b@example.com"}},
+		{Name: "viewer", Resources: models.Resources{Allowed: []string{"read", "list"}, Denied: []string{}}},
+	}
+	plan, err := CompareRoles(local, remote)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(plan.Updates) != 2 {
+		t.Fatalf("expected 2 updates, got %+v", plan.Updates)
+	}
+
+	var want []string
+	for _, update := range sortedPlan(plan).Updates {
+		want = append(want, "UPDATE: "+update.Name+privilegeTag(update))
+		changes := *update.Changes
+		for _, list := range []struct {
+			kind           string
+			added, removed []string
+		}{
+			{"allowed", changes.AllowedAdded, changes.AllowedRemoved},
+			{"denied", changes.DeniedAdded, changes.DeniedRemoved},
+			{"labels", changes.LabelsAdded, changes.LabelsRemoved},
+			{"members", changes.MembersAdded, changes.MembersRemoved},
+		} {
+			for _, entry := range list.added {
+				want = append(want, "  + "+list.kind+": "+entry)
+			}
+			for _, entry := range list.removed {
+				want = append(want, "  - "+list.kind+": "+entry)
+			}
+		}
+	}
+
+	if got := DescribePlan(plan, true); got != strings.Join(want, "\n") {
+		t.Errorf("DescribePlan() =\n%s\nwant\n%s", got, strings.Join(want, "\n"))
+	}
+}
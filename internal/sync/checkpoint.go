@@ -0,0 +1,151 @@
+package sync
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"replbac/internal/models"
+)
+
+// Operation kinds recorded in a checkpoint
+const (
+	OperationCreate = "create"
+	OperationUpdate = "update"
+	OperationDelete = "delete"
+)
+
+// Checkpoint records the role operations completed by an interrupted sync so that a
+// later run can resume it. It is keyed to the hash of the operations still remaining,
+// so it only applies when a fresh comparison produces exactly that remaining work.
+type Checkpoint struct {
+	PlanHash  string   `json:"plan_hash"` // Hash of the operations not yet completed
+	Completed []string `json:"completed"` // Completed operations, as "kind:role"
+
+	path      string
+	remaining SyncPlan
+}
+
+// NewCheckpoint creates a checkpoint for executing plan, carrying over operations
+// completed by an earlier run that is being resumed. Nothing is written until the
+// first operation is recorded.
+func NewCheckpoint(path string, plan SyncPlan, completed []string) *Checkpoint {
+	return &Checkpoint{
+		PlanHash:  PlanHash(plan),
+		Completed: append([]string{}, completed...),
+		path:      path,
+		remaining: plan,
+	}
+}
+
+// LoadCheckpoint reads a checkpoint from path, returning nil without error if none exists
+func LoadCheckpoint(path string) (*Checkpoint, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read checkpoint: %w", err)
+	}
+
+	var checkpoint Checkpoint
+	if err := json.Unmarshal(data, &checkpoint); err != nil {
+		return nil, fmt.Errorf("failed to parse checkpoint %s: %w", path, err)
+	}
+	checkpoint.path = path
+	return &checkpoint, nil
+}
+
+// Matches reports whether the checkpoint's remaining work is exactly plan
+func (c *Checkpoint) Matches(plan SyncPlan) bool {
+	return c.PlanHash == PlanHash(plan)
+}
+
+// Record marks an operation as completed and saves the checkpoint
+func (c *Checkpoint) Record(kind, roleName string) error {
+	if c == nil {
+		return nil
+	}
+
+	c.Completed = append(c.Completed, kind+":"+roleName)
+	c.remaining = withoutOperation(c.remaining, kind, roleName)
+	c.PlanHash = PlanHash(c.remaining)
+	return c.save()
+}
+
+// Remove deletes the saved checkpoint once the sync has completed
+func (c *Checkpoint) Remove() error {
+	if c == nil {
+		return nil
+	}
+	return RemoveCheckpoint(c.path)
+}
+
+// RemoveCheckpoint deletes the checkpoint at path if one exists
+func RemoveCheckpoint(path string) error {
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to remove checkpoint: %w", err)
+	}
+	return nil
+}
+
+// save writes the checkpoint to its path
+func (c *Checkpoint) save() error {
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode checkpoint: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(c.path), 0700); err != nil {
+		return fmt.Errorf("failed to create checkpoint directory: %w", err)
+	}
+	if err := os.WriteFile(c.path, data, 0600); err != nil {
+		return fmt.Errorf("failed to write checkpoint: %w", err)
+	}
+	return nil
+}
+
+// PlanHash returns a deterministic hash of the operations in a plan, including the
+// content each create and update would write
+func PlanHash(plan SyncPlan) string {
+	operations := make([]string, 0, len(plan.Creates)+len(plan.Updates)+len(plan.Deletes))
+	for _, role := range plan.Creates {
+		operations = append(operations, OperationCreate+":"+role.Name+":"+role.ContentHash())
+	}
+	for _, update := range plan.Updates {
+		operations = append(operations, OperationUpdate+":"+update.Name+":"+update.Local.ContentHash())
+	}
+	for _, roleName := range plan.Deletes {
+		operations = append(operations, OperationDelete+":"+roleName)
+	}
+	sort.Strings(operations)
+
+	sum := sha256.Sum256([]byte(strings.Join(operations, "\n")))
+	return hex.EncodeToString(sum[:])
+}
+
+// withoutOperation returns a copy of plan with the given operation removed
+func withoutOperation(plan SyncPlan, kind, roleName string) SyncPlan {
+	result := SyncPlan{Creates: []models.Role{}, Updates: []RoleUpdate{}, Deletes: []string{}}
+	for _, role := range plan.Creates {
+		if kind != OperationCreate || role.Name != roleName {
+			result.Creates = append(result.Creates, role)
+		}
+	}
+	for _, update := range plan.Updates {
+		if kind != OperationUpdate || update.Name != roleName {
+			result.Updates = append(result.Updates, update)
+		}
+	}
+	for _, name := range plan.Deletes {
+		if kind != OperationDelete || name != roleName {
+			result.Deletes = append(result.Deletes, name)
+		}
+	}
+	return result
+}
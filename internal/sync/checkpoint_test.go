@@ -0,0 +1,59 @@
+package sync
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"replbac/internal/models"
+)
+
+func TestCheckpointRecordAndResume(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "checkpoint.json")
+	plan := SyncPlan{
+		Creates: []models.Role{{Name: "first", Resources: models.Resources{Allowed: []string{"read"}}}, {Name: "second"}},
+		Updates: []RoleUpdate{{Name: "editor", Local: models.Role{Name: "editor", Resources: models.Resources{Allowed: []string{"write"}}}}},
+		Deletes: []string{"legacy"},
+	}
+
+	// Nothing is written until an operation completes
+	checkpoint := NewCheckpoint(path, plan, nil)
+	if loaded, err := LoadCheckpoint(path); err != nil || loaded != nil {
+		t.Fatalf("LoadCheckpoint() before any progress = %v, %v; want nil, nil", loaded, err)
+	}
+
+	if err := checkpoint.Record(OperationCreate, "first"); err != nil {
+		t.Fatalf("Record() unexpected error: %v", err)
+	}
+
+	loaded, err := LoadCheckpoint(path)
+	if err != nil || loaded == nil {
+		t.Fatalf("LoadCheckpoint() = %v, %v; want saved checkpoint", loaded, err)
+	}
+	if !reflect.DeepEqual(loaded.Completed, []string{"create:first"}) {
+		t.Errorf("Completed = %v, want [create:first]", loaded.Completed)
+	}
+
+	// A fresh comparison after the create yields the remaining operations, which must match
+	remaining := SyncPlan{Creates: plan.Creates[1:], Updates: plan.Updates, Deletes: plan.Deletes}
+	if !loaded.Matches(remaining) {
+		t.Error("Expected checkpoint to match the remaining plan")
+	}
+	if loaded.Matches(plan) {
+		t.Error("Expected checkpoint not to match the original plan")
+	}
+
+	// Any change in the content of a remaining operation makes the checkpoint stale
+	changed := remaining
+	changed.Updates = []RoleUpdate{{Name: "editor", Local: models.Role{Name: "editor", Resources: models.Resources{Allowed: []string{"admin"}}}}}
+	if loaded.Matches(changed) {
+		t.Error("Expected checkpoint not to match a plan with different content")
+	}
+
+	if err := loaded.Remove(); err != nil {
+		t.Fatalf("Remove() unexpected error: %v", err)
+	}
+	if again, err := LoadCheckpoint(path); err != nil || again != nil {
+		t.Errorf("LoadCheckpoint() after Remove = %v, %v; want nil, nil", again, err)
+	}
+}
@@ -2,7 +2,9 @@ package sync
 
 import (
 	"fmt"
+	"path"
 	"sort"
+	"strings"
 
 	"replbac/internal/models"
 )
@@ -19,10 +21,52 @@ type RoleUpdate struct {
 	Name   string      // Role name
 	Local  models.Role // Local version of the role
 	Remote models.Role // Remote version of the role
+	Reason string      // Why the role differs, e.g. "allowed differs (remote missing 'create')"
+
+	// Changes are the changes from Remote to Local, precomputed by the comparison that
+	// planned the update. Updates built elsewhere may leave it nil; use Diff to read it.
+	Changes *RoleChanges
+}
+
+// CompareOptions controls how local and remote roles are matched
+type CompareOptions struct {
+	// CaseInsensitiveNames matches role names regardless of case, for APIs that treat
+	// "Admin" and "admin" as the same role
+	CaseInsensitiveNames bool
+
+	// Ignore lists role names or glob patterns (as in path.Match) for roles that are
+	// never created, updated, or deleted, whether they exist locally or remotely
+	Ignore []string
+
+	// Protected lists role names or glob patterns for remote roles that are never
+	// deleted, even when they have no local counterpart
+	Protected []string
+
+	// IgnoreResources lists resource strings, matched exactly, that are left out of the
+	// allowed and denied resources of local and remote roles when they are compared and
+	// when update diffs are rendered. The roles in the plan keep them, so they are still
+	// sent to the API with the rest of the local role.
+	IgnoreResources []string
+
+	// Manage is the manage mode of local roles that do not set their own. With
+	// models.ManageDeniedOnly, only denied resources (and members) are compared, and
+	// updates keep the remote role's allowed resources.
+	Manage string
+}
+
+// NameCaseMatch records a local and remote role matched although their names differ in case
+type NameCaseMatch struct {
+	Local  string // Local role name
+	Remote string // Remote role name
 }
 
 // CompareRoles compares local roles with remote roles and returns a sync plan
 func CompareRoles(local, remote []models.Role) (SyncPlan, error) {
+	return CompareRolesWithOptions(local, remote, CompareOptions{})
+}
+
+// CompareRolesWithOptions compares local roles with remote roles using the given matching options
+func CompareRolesWithOptions(local, remote []models.Role, opts CompareOptions) (SyncPlan, error) {
 	plan := SyncPlan{
 		Creates: []models.Role{},
 		Updates: []RoleUpdate{},
@@ -30,31 +74,41 @@ func CompareRoles(local, remote []models.Role) (SyncPlan, error) {
 	}
 
 	// Create maps for efficient lookups
-	localMap := make(map[string]models.Role)
-	remoteMap := make(map[string]models.Role)
-
-	// Build local role map
-	for _, role := range local {
-		localMap[role.Name] = role
+	localMap, err := opts.roleMap(local, "local")
+	if err != nil {
+		return SyncPlan{}, err
 	}
-
-	// Build remote role map
-	for _, role := range remote {
-		remoteMap[role.Name] = role
+	remoteMap, err := opts.roleMap(remote, "remote")
+	if err != nil {
+		return SyncPlan{}, err
 	}
 
 	// Find roles that need to be created or updated
 	for _, localRole := range local {
-		remoteRole, exists := remoteMap[localRole.Name]
+		if opts.Ignores(localRole.Name) {
+			continue
+		}
+		remoteRole, exists := remoteMap[opts.nameKey(localRole.Name)]
 		if !exists {
 			// Role doesn't exist on remote, needs to be created
 			plan.Creates = append(plan.Creates, localRole)
-		} else if !RolesEqual(localRole, remoteRole) {
+			continue
+		}
+		if opts.managesDeniedOnly(localRole) {
+			// Another tool owns the allowed resources, so the remote role's are kept as they are
+			localRole.Resources.Allowed = append([]string{}, remoteRole.Resources.Allowed...)
+		}
+		// Ignored resources are left out of the comparison only; the update sends them
+		comparedLocal, comparedRemote := opts.withoutIgnoredResources(localRole), opts.withoutIgnoredResources(remoteRole)
+		if !opts.rolesEqual(comparedLocal, comparedRemote) {
 			// Role exists but is different, needs to be updated
+			changes := ComputeRoleChanges(comparedRemote, comparedLocal)
 			plan.Updates = append(plan.Updates, RoleUpdate{
-				Name:   localRole.Name,
-				Local:  localRole,
-				Remote: remoteRole,
+				Name:    localRole.Name,
+				Local:   localRole,
+				Remote:  remoteRole,
+				Reason:  opts.updateReason(comparedLocal, comparedRemote),
+				Changes: &changes,
 			})
 		}
 		// If roles are equal, no action needed
@@ -62,16 +116,220 @@ func CompareRoles(local, remote []models.Role) (SyncPlan, error) {
 
 	// Find roles that need to be deleted
 	for _, remoteRole := range remote {
-		if _, exists := localMap[remoteRole.Name]; !exists {
+		if opts.Ignores(remoteRole.Name) || opts.Protects(remoteRole.Name) {
+			continue
+		}
+		if _, exists := localMap[opts.nameKey(remoteRole.Name)]; !exists {
 			// Role exists on remote but not local, needs to be deleted
 			plan.Deletes = append(plan.Deletes, remoteRole.Name)
 		}
 	}
 
+	if err := opts.ValidatePlan(plan); err != nil {
+		return SyncPlan{}, err
+	}
 	return plan, nil
 }
 
-// RolesEqual compares two roles for equality, ignoring order of resources and members
+// roleMap returns roles keyed as the options match names. With case-insensitive matching,
+// two roles whose names differ only in case are an error rather than one silently
+// replacing the other.
+func (o CompareOptions) roleMap(roles []models.Role, side string) (map[string]models.Role, error) {
+	roleMap := make(map[string]models.Role, len(roles))
+	for _, role := range roles {
+		key := o.nameKey(role.Name)
+		if existing, exists := roleMap[key]; exists && existing.Name != role.Name {
+			return nil, fmt.Errorf("%s roles %s and %s differ only in case, so case-insensitive name matching cannot tell them apart", side, existing.Name, role.Name)
+		}
+		roleMap[key] = role
+	}
+	return roleMap, nil
+}
+
+// ValidatePlan checks that no role the plan creates or updates is also deleted, matching
+// names as the options do. Such a plan can only come from a bug in name matching or
+// filtering, and executing it would delete a role it had just written.
+func (o CompareOptions) ValidatePlan(plan SyncPlan) error {
+	written := make(map[string]string, len(plan.Creates)+len(plan.Updates))
+	for _, role := range plan.Creates {
+		written[o.nameKey(role.Name)] = "created as " + role.Name
+	}
+	for _, update := range plan.Updates {
+		written[o.nameKey(update.Name)] = "updated as " + update.Name
+	}
+
+	for _, name := range plan.Deletes {
+		if how, exists := written[o.nameKey(name)]; exists {
+			return fmt.Errorf("inconsistent sync plan: role %s is deleted but also %s; refusing to continue", name, how)
+		}
+	}
+	return nil
+}
+
+// CaseInsensitiveMatches returns the local and remote roles that only match when case is
+// ignored, so callers can warn that case-insensitive matching merged them
+func CaseInsensitiveMatches(local, remote []models.Role) []NameCaseMatch {
+	remoteNames := make(map[string]string)
+	exact := make(map[string]bool)
+	for _, role := range remote {
+		remoteNames[strings.ToLower(role.Name)] = role.Name
+		exact[role.Name] = true
+	}
+
+	matches := []NameCaseMatch{}
+	for _, role := range local {
+		if exact[role.Name] {
+			continue
+		}
+		if remoteName, exists := remoteNames[strings.ToLower(role.Name)]; exists {
+			matches = append(matches, NameCaseMatch{Local: role.Name, Remote: remoteName})
+		}
+	}
+	return matches
+}
+
+// withoutIgnoredResources returns a copy of role with the ignored resources removed, for
+// comparing it, or role itself if no resources are ignored
+func (o CompareOptions) withoutIgnoredResources(role models.Role) models.Role {
+	if len(o.IgnoreResources) == 0 {
+		return role
+	}
+	ignored := make(map[string]bool, len(o.IgnoreResources))
+	for _, resource := range o.IgnoreResources {
+		ignored[resource] = true
+	}
+	without := func(resources []string) []string {
+		if resources == nil {
+			return nil
+		}
+		kept := make([]string, 0, len(resources))
+		for _, resource := range resources {
+			if !ignored[resource] {
+				kept = append(kept, resource)
+			}
+		}
+		return kept
+	}
+
+	role.Resources.Allowed = without(role.Resources.Allowed)
+	role.Resources.Denied = without(role.Resources.Denied)
+	return role
+}
+
+// managesDeniedOnly reports whether only the denied resources of a local role are
+// managed, by its own manage field or, if it has none, by the options
+func (o CompareOptions) managesDeniedOnly(role models.Role) bool {
+	if role.Manage != "" {
+		return role.Manage == models.ManageDeniedOnly
+	}
+	return o.Manage == models.ManageDeniedOnly
+}
+
+// Ignores reports whether a role name matches one of the ignore patterns
+func (o CompareOptions) Ignores(name string) bool {
+	return matchesAny(o.Ignore, name)
+}
+
+// Protects reports whether a role name matches one of the protected role patterns
+func (o CompareOptions) Protects(name string) bool {
+	return matchesAny(o.Protected, name)
+}
+
+// matchesAny reports whether name matches one of the glob patterns
+func matchesAny(patterns []string, name string) bool {
+	for _, pattern := range patterns {
+		if matched, err := path.Match(pattern, name); err == nil && matched {
+			return true
+		}
+	}
+	return false
+}
+
+// IgnoredRoles returns the sorted, distinct names of local and remote roles excluded by the ignore patterns
+func IgnoredRoles(local, remote []models.Role, opts CompareOptions) []string {
+	seen := make(map[string]bool)
+	ignored := []string{}
+	for _, set := range [][]models.Role{local, remote} {
+		for _, role := range set {
+			if opts.Ignores(role.Name) && !seen[role.Name] {
+				seen[role.Name] = true
+				ignored = append(ignored, role.Name)
+			}
+		}
+	}
+	sort.Strings(ignored)
+	return ignored
+}
+
+// ProtectedRoles returns the sorted names of remote roles that would be deleted
+// if they were not protected
+func ProtectedRoles(local, remote []models.Role, opts CompareOptions) []string {
+	localNames := make(map[string]bool)
+	for _, role := range local {
+		localNames[opts.nameKey(role.Name)] = true
+	}
+
+	protected := []string{}
+	for _, role := range remote {
+		if opts.Protects(role.Name) && !opts.Ignores(role.Name) && !localNames[opts.nameKey(role.Name)] {
+			protected = append(protected, role.Name)
+		}
+	}
+	sort.Strings(protected)
+	return protected
+}
+
+// UnmanagedRoles returns the remote roles that sync leaves alone: those matching an ignore
+// pattern, and those with no local role that the plan neither updates nor deletes, such as
+// protected roles and, without --delete, every role missing locally. Members assigned to
+// them are not treated as orphaned.
+func UnmanagedRoles(local, remote []models.Role, plan SyncPlan, opts CompareOptions) []models.Role {
+	managed := make(map[string]bool)
+	for _, role := range local {
+		managed[opts.nameKey(role.Name)] = true
+	}
+	for _, update := range plan.Updates {
+		managed[opts.nameKey(update.Name)] = true
+	}
+	for _, name := range plan.Deletes {
+		managed[opts.nameKey(name)] = true
+	}
+
+	unmanaged := []models.Role{}
+	for _, role := range remote {
+		if opts.Ignores(role.Name) || !managed[opts.nameKey(role.Name)] {
+			unmanaged = append(unmanaged, role)
+		}
+	}
+	return unmanaged
+}
+
+// nameKey returns the key used to match a role name
+func (o CompareOptions) nameKey(name string) string {
+	if o.CaseInsensitiveNames {
+		return strings.ToLower(name)
+	}
+	return name
+}
+
+// rolesEqual compares two matched roles, ignoring name case when matching is case-insensitive
+func (o CompareOptions) rolesEqual(r1, r2 models.Role) bool {
+	if o.CaseInsensitiveNames && strings.EqualFold(r1.Name, r2.Name) {
+		r2.Name = r1.Name
+	}
+	return RolesEqual(r1, r2)
+}
+
+// updateReason describes how two matched roles differ, ignoring name case when matching is case-insensitive
+func (o CompareOptions) updateReason(local, remote models.Role) string {
+	if o.CaseInsensitiveNames && strings.EqualFold(local.Name, remote.Name) {
+		remote.Name = local.Name
+	}
+	return UpdateReason(local, remote)
+}
+
+// RolesEqual compares two roles for equality, ignoring order of resources and members.
+// Labels are compared too, so a label change alone updates the role.
 func RolesEqual(r1, r2 models.Role) bool {
 	// Compare names
 	if r1.Name != r2.Name {
@@ -83,8 +341,20 @@ func RolesEqual(r1, r2 models.Role) bool {
 		return false
 	}
 
+	// Compare labels
+	if !StringSlicesEqual(r1.LabelPairs(), r2.LabelPairs()) {
+		return false
+	}
+
 	// Compare members
-	return StringSlicesEqual(r1.Members, r2.Members)
+	return MembersEqual(r1.Members, r2.Members)
+}
+
+// MembersEqual compares two member lists for equality, ignoring order and the case and
+// surrounding whitespace of emails (see models.NormalizeEmail), so the API and role files
+// need not agree on casing
+func MembersEqual(m1, m2 []string) bool {
+	return StringSlicesEqual(models.NormalizeEmails(m1), models.NormalizeEmails(m2))
 }
 
 // ResourcesEqual compares two resource structures for equality, ignoring order
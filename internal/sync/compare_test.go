@@ -3,6 +3,7 @@ package sync
 import (
 	"reflect"
 	"sort"
+	"strings"
 	"testing"
 
 	"replbac/internal/models"
@@ -112,6 +113,7 @@ func TestCompareRoles(t *testing.T) {
 								Denied:  []string{},
 							},
 						},
+						Reason: "allowed differs (remote missing 'write'), denied differs (remote missing 'delete')",
 					},
 				},
 				Deletes: []string{},
@@ -193,6 +195,7 @@ func TestCompareRoles(t *testing.T) {
 								Denied:  []string{},
 							},
 						},
+						Reason: "allowed differs (remote missing 'write'), denied differs (remote missing 'delete')",
 					},
 				},
 				Deletes: []string{"obsolete"},
@@ -254,6 +257,7 @@ func TestCompareRoles(t *testing.T) {
 			})
 			sort.Strings(tt.wantPlan.Deletes)
 
+			gotPlan.Updates = withoutChanges(t, gotPlan.Updates)
 			if !reflect.DeepEqual(gotPlan, tt.wantPlan) {
 				t.Errorf("CompareRoles() = %+v, want %+v", gotPlan, tt.wantPlan)
 			}
@@ -394,6 +398,18 @@ func TestRolesEqual(t *testing.T) {
 			},
 			want: true,
 		},
+		{
+			name: "different labels",
+			r1:   models.Role{Name: "test", Labels: map[string]string{"tier": "prod"}},
+			r2:   models.Role{Name: "test", Labels: map[string]string{"tier": "dev"}},
+			want: false,
+		},
+		{
+			name: "nil vs empty labels",
+			r1:   models.Role{Name: "test"},
+			r2:   models.Role{Name: "test", Labels: map[string]string{}},
+			want: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -401,6 +417,10 @@ func TestRolesEqual(t *testing.T) {
 			if got := RolesEqual(tt.r1, tt.r2); got != tt.want {
 				t.Errorf("RolesEqual() = %v, want %v", got, tt.want)
 			}
+			// Content hashes must agree with RolesEqual semantics
+			if gotHashEqual := tt.r1.ContentHash() == tt.r2.ContentHash(); gotHashEqual != tt.want {
+				t.Errorf("ContentHash() equality = %v, want %v", gotHashEqual, tt.want)
+			}
 		})
 	}
 }
@@ -552,6 +572,20 @@ func TestRolesEqual_WithMembers(t *testing.T) {
 			},
 			want: true,
 		},
+		{
+			name: "member emails differing only in case and whitespace",
+			r1: models.Role{
+				Name:      "admin",
+				Resources: models.Resources{Allowed: []string{"*"}, Denied: []string{}},
+				Members:   []string{"Bob@Example.com", " jane@example.com"},
+			},
+			r2: models.Role{
+				Name:      "admin",
+				Resources: models.Resources{Allowed: []string{"*"}, Denied: []string{}},
+				Members:   []string{"jane@example.com", "bob@example.com"},
+			},
+			want: true,
+		},
 		{
 			name: "one nil members, one empty members",
 			r1: models.Role{
@@ -579,6 +613,10 @@ func TestRolesEqual_WithMembers(t *testing.T) {
 			if got := RolesEqual(tt.r1, tt.r2); got != tt.want {
 				t.Errorf("RolesEqual() = %v, want %v", got, tt.want)
 			}
+			// Content hashes must agree with RolesEqual semantics
+			if gotHashEqual := tt.r1.ContentHash() == tt.r2.ContentHash(); gotHashEqual != tt.want {
+				t.Errorf("ContentHash() equality = %v, want %v", gotHashEqual, tt.want)
+			}
 		})
 	}
 }
@@ -633,6 +671,7 @@ func TestCompareRoles_WithMembers(t *testing.T) {
 							},
 							Members: []string{"bob@example.com"},
 						},
+						Reason: "members differ (remote missing 'jane@example.com', 'john@example.com'; remote has extra 'bob@example.com')",
 					},
 				},
 				Deletes: []string{},
@@ -682,7 +721,7 @@ func TestCompareRoles_WithMembers(t *testing.T) {
 			}
 
 			// Compare Updates
-			if !reflect.DeepEqual(got.Updates, tt.wantPlan.Updates) {
+			if !reflect.DeepEqual(withoutChanges(t, got.Updates), tt.wantPlan.Updates) {
 				t.Errorf("CompareRoles() Updates = %v, want %v", got.Updates, tt.wantPlan.Updates)
 			}
 
@@ -693,3 +732,362 @@ func TestCompareRoles_WithMembers(t *testing.T) {
 		})
 	}
 }
+
+func TestCompareRolesWithOptions_CaseInsensitiveNames(t *testing.T) {
+	local := []models.Role{
+		{Name: "Admin", Resources: models.Resources{Allowed: []string{"*"}, Denied: []string{}}},
+		{Name: "Viewer", Resources: models.Resources{Allowed: []string{"read"}, Denied: []string{}}},
+	}
+	remote := []models.Role{
+		{ID: "1", Name: "admin", Resources: models.Resources{Allowed: []string{"*"}, Denied: []string{}}},
+		{ID: "2", Name: "viewer", Resources: models.Resources{Allowed: []string{"read", "write"}, Denied: []string{}}},
+	}
+
+	tests := []struct {
+		name        string
+		opts        CompareOptions
+		wantCreates []string
+		wantUpdates []string
+		wantDeletes []string
+	}{
+		{
+			name:        "case-sensitive matching creates and deletes",
+			opts:        CompareOptions{},
+			wantCreates: []string{"Admin", "Viewer"},
+			wantUpdates: []string{},
+			wantDeletes: []string{"admin", "viewer"},
+		},
+		{
+			name:        "case-insensitive matching updates only changed roles",
+			opts:        CompareOptions{CaseInsensitiveNames: true},
+			wantCreates: []string{},
+			wantUpdates: []string{"Viewer"},
+			wantDeletes: []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plan, err := CompareRolesWithOptions(local, remote, tt.opts)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			creates := []string{}
+			for _, role := range plan.Creates {
+				creates = append(creates, role.Name)
+			}
+			updates := []string{}
+			for _, update := range plan.Updates {
+				updates = append(updates, update.Name)
+			}
+
+			if !reflect.DeepEqual(creates, tt.wantCreates) {
+				t.Errorf("creates = %v, want %v", creates, tt.wantCreates)
+			}
+			if !reflect.DeepEqual(updates, tt.wantUpdates) {
+				t.Errorf("updates = %v, want %v", updates, tt.wantUpdates)
+			}
+			if !reflect.DeepEqual(plan.Deletes, tt.wantDeletes) {
+				t.Errorf("deletes = %v, want %v", plan.Deletes, tt.wantDeletes)
+			}
+		})
+	}
+
+	matches := CaseInsensitiveMatches(local, remote)
+	want := []NameCaseMatch{{Local: "Admin", Remote: "admin"}, {Local: "Viewer", Remote: "viewer"}}
+	if !reflect.DeepEqual(matches, want) {
+		t.Errorf("CaseInsensitiveMatches = %+v, want %+v", matches, want)
+	}
+}
+
+// TestCompareRolesWithOptions_CaseCollision tests that case-insensitive matching fails
+// when two roles on the same side differ only in case, instead of dropping one of them
+func TestCompareRolesWithOptions_CaseCollision(t *testing.T) {
+	opts := CompareOptions{CaseInsensitiveNames: true}
+	roles := []models.Role{
+		{ID: "1", Name: "Admin", Resources: models.Resources{Allowed: []string{"*"}, Denied: []string{}}},
+		{ID: "2", Name: "admin", Resources: models.Resources{Allowed: []string{"read"}, Denied: []string{}}},
+	}
+
+	if _, err := CompareRolesWithOptions(roles[:1], roles, opts); err == nil || !strings.Contains(err.Error(), "remote roles Admin and admin differ only in case") {
+		t.Errorf("expected remote case collision error, got %v", err)
+	}
+	if _, err := CompareRolesWithOptions(roles, roles[:1], opts); err == nil || !strings.Contains(err.Error(), "local roles Admin and admin differ only in case") {
+		t.Errorf("expected local case collision error, got %v", err)
+	}
+	if _, err := CompareRolesWithOptions(roles, roles, CompareOptions{}); err != nil {
+		t.Errorf("expected case-sensitive matching to keep both roles, got %v", err)
+	}
+}
+
+func TestCompareRolesWithOptions_Ignore(t *testing.T) {
+	local := []models.Role{
+		{Name: "admin", Resources: models.Resources{Allowed: []string{"*"}, Denied: []string{}}},
+		{Name: "ui-support", Resources: models.Resources{Allowed: []string{"read"}, Denied: []string{}}},
+		{Name: "billing", Resources: models.Resources{Allowed: []string{"billing/*"}, Denied: []string{}}},
+	}
+	remote := []models.Role{
+		{ID: "1", Name: "ui-support", Resources: models.Resources{Allowed: []string{"read", "write"}, Denied: []string{}}},
+		{ID: "2", Name: "ui-sales", Resources: models.Resources{Allowed: []string{"read"}, Denied: []string{}}},
+		{ID: "3", Name: "legacy", Resources: models.Resources{Allowed: []string{"*"}, Denied: []string{}}},
+	}
+	opts := CompareOptions{Ignore: []string{"ui-*", "billing"}}
+
+	plan, err := CompareRolesWithOptions(local, remote, opts)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(plan.Creates) != 1 || plan.Creates[0].Name != "admin" {
+		t.Errorf("expected only admin to be created, got %+v", plan.Creates)
+	}
+	if len(plan.Updates) != 0 {
+		t.Errorf("expected ignored ui-support not to be updated, got %+v", plan.Updates)
+	}
+	if !reflect.DeepEqual(plan.Deletes, []string{"legacy"}) {
+		t.Errorf("expected only legacy to be deleted, got %v", plan.Deletes)
+	}
+
+	ignored := IgnoredRoles(local, remote, opts)
+	if want := []string{"billing", "ui-sales", "ui-support"}; !reflect.DeepEqual(ignored, want) {
+		t.Errorf("IgnoredRoles = %v, want %v", ignored, want)
+	}
+}
+
+func TestCompareRolesWithOptions_Protected(t *testing.T) {
+	local := []models.Role{
+		{Name: "admin", Resources: models.Resources{Allowed: []string{"*"}, Denied: []string{}}},
+		{Name: "team-ops", Resources: models.Resources{Allowed: []string{"read"}, Denied: []string{}}},
+	}
+	remote := []models.Role{
+		{ID: "1", Name: "admin", Resources: models.Resources{Allowed: []string{"*"}, Denied: []string{}}},
+		{ID: "2", Name: "team-ops", Resources: models.Resources{Allowed: []string{"read", "write"}, Denied: []string{}}},
+		{ID: "3", Name: "team-sales", Resources: models.Resources{Allowed: []string{"read"}, Denied: []string{}}},
+		{ID: "4", Name: "platform-admin", Resources: models.Resources{Allowed: []string{"*"}, Denied: []string{}}},
+		{ID: "5", Name: "legacy", Resources: models.Resources{Allowed: []string{"*"}, Denied: []string{}}},
+	}
+	opts := CompareOptions{Protected: []string{"team-*", "platform-admin"}}
+
+	plan, err := CompareRolesWithOptions(local, remote, opts)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if !reflect.DeepEqual(plan.Deletes, []string{"legacy"}) {
+		t.Errorf("expected only legacy to be deleted, got %v", plan.Deletes)
+	}
+	if len(plan.Updates) != 1 || plan.Updates[0].Name != "team-ops" {
+		t.Errorf("expected protected team-ops to still be updated, got %+v", plan.Updates)
+	}
+
+	protected := ProtectedRoles(local, remote, opts)
+	if want := []string{"platform-admin", "team-sales"}; !reflect.DeepEqual(protected, want) {
+		t.Errorf("ProtectedRoles = %v, want %v", protected, want)
+	}
+}
+
+func TestUnmanagedRoles(t *testing.T) {
+	local := []models.Role{
+		{Name: "admin", Resources: models.Resources{Allowed: []string{"*"}, Denied: []string{}}},
+		{Name: "vendor-support", Resources: models.Resources{Allowed: []string{"read"}, Denied: []string{}}},
+	}
+	remote := []models.Role{
+		{ID: "1", Name: "admin", Resources: models.Resources{Allowed: []string{"*"}, Denied: []string{}}},
+		{ID: "2", Name: "vendor-support", Resources: models.Resources{Allowed: []string{"*"}, Denied: []string{}}},
+		{ID: "3", Name: "team-sales", Resources: models.Resources{Allowed: []string{"read"}, Denied: []string{}}},
+		{ID: "4", Name: "legacy", Resources: models.Resources{Allowed: []string{"*"}, Denied: []string{}}},
+	}
+	opts := CompareOptions{Ignore: []string{"vendor-*"}, Protected: []string{"team-*"}}
+
+	plan, err := CompareRolesWithOptions(local, remote, opts)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	names := func(roles []models.Role) []string {
+		result := []string{}
+		for _, role := range roles {
+			result = append(result, role.Name)
+		}
+		return result
+	}
+
+	// With --delete, legacy is deleted and so managed; the ignored and protected roles are not
+	if got, want := names(UnmanagedRoles(local, remote, plan, opts)), []string{"vendor-support", "team-sales"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("UnmanagedRoles with deletes = %v, want %v", got, want)
+	}
+
+	// Without --delete, legacy is left alone too
+	plan.Deletes = []string{}
+	if got, want := names(UnmanagedRoles(local, remote, plan, opts)), []string{"vendor-support", "team-sales", "legacy"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("UnmanagedRoles without deletes = %v, want %v", got, want)
+	}
+}
+
+func TestCompareRolesWithOptions_IgnoreResources(t *testing.T) {
+	injected := "kots/app/*/platform-managed"
+	local := []models.Role{
+		{Name: "viewer", Resources: models.Resources{Allowed: []string{"kots/app/*/read"}, Denied: []string{}}},
+		{Name: "editor", Resources: models.Resources{Allowed: []string{"kots/app/*/read", "kots/app/*/write"}, Denied: []string{injected}}},
+		{Name: "support", Resources: models.Resources{Allowed: []string{"team/support-issues/read", injected}, Denied: []string{}}},
+	}
+	remote := []models.Role{
+		{ID: "1", Name: "viewer", Resources: models.Resources{Allowed: []string{"kots/app/*/read", injected}, Denied: []string{}}},
+		{ID: "2", Name: "editor", Resources: models.Resources{Allowed: []string{"kots/app/*/read", injected}, Denied: []string{}}},
+	}
+	opts := CompareOptions{IgnoreResources: []string{injected}}
+
+	plan, err := CompareRolesWithOptions(local, remote, opts)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// viewer differs only by the ignored resource, so it is not updated
+	if len(plan.Updates) != 1 || plan.Updates[0].Name != "editor" {
+		t.Fatalf("expected only editor to be updated, got %+v", plan.Updates)
+	}
+	// The update still sends the ignored resource; only the comparison and diff leave it out
+	update := plan.Updates[0]
+	if !reflect.DeepEqual(update.Local.Resources.Denied, []string{injected}) {
+		t.Errorf("expected the updated role to keep its ignored resource, got %+v", update.Local.Resources)
+	}
+	if diff := DescribePlan(SyncPlan{Updates: plan.Updates}, false); strings.Contains(diff, injected) {
+		t.Errorf("expected the ignored resource not to appear in the update diff, got:\n%s", diff)
+	}
+	if len(plan.Creates) != 1 || !reflect.DeepEqual(plan.Creates[0].Resources.Allowed, []string{"team/support-issues/read", injected}) {
+		t.Errorf("expected support to be created with its ignored resource, got %+v", plan.Creates)
+	}
+	if !reflect.DeepEqual(local[2].Resources.Allowed, []string{"team/support-issues/read", injected}) {
+		t.Errorf("expected the local roles to be left unchanged, got %v", local[2].Resources.Allowed)
+	}
+
+	plan, err = CompareRolesWithOptions(local[:1], remote[:1], CompareOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(plan.Updates) != 1 {
+		t.Errorf("expected viewer to be updated when nothing is ignored, got %+v", plan.Updates)
+	}
+}
+
+func TestCompareRolesWithOptions_ManageDeniedOnly(t *testing.T) {
+	remote := []models.Role{
+		{ID: "1", Name: "viewer", Resources: models.Resources{Allowed: []string{"kots/app/*/read", "team/support-issues/read"}, Denied: []string{}}},
+		{ID: "2", Name: "editor", Resources: models.Resources{Allowed: []string{"kots/app/*/read"}, Denied: []string{}}},
+	}
+	local := []models.Role{
+		// Only the allows differ, which denied-only ignores
+		{Name: "viewer", Resources: models.Resources{Allowed: []string{"kots/app/*/read"}, Denied: []string{}}},
+		// The denies differ too, so the role is updated with its remote allows kept
+		{Name: "editor", Resources: models.Resources{Allowed: []string{}, Denied: []string{"kots/app/*/delete"}}},
+		{Name: "support", Resources: models.Resources{Allowed: []string{"team/support-issues/read"}, Denied: []string{}}},
+	}
+
+	plan, err := CompareRolesWithOptions(local, remote, CompareOptions{Manage: models.ManageDeniedOnly})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(plan.Updates) != 1 || plan.Updates[0].Name != "editor" {
+		t.Fatalf("expected only editor to be updated, got %+v", plan.Updates)
+	}
+	update := plan.Updates[0]
+	if !reflect.DeepEqual(update.Local.Resources.Allowed, []string{"kots/app/*/read"}) {
+		t.Errorf("expected the update to keep the remote allows, got %v", update.Local.Resources.Allowed)
+	}
+	if !reflect.DeepEqual(update.Local.Resources.Denied, []string{"kots/app/*/delete"}) {
+		t.Errorf("expected the update to set the local denies, got %v", update.Local.Resources.Denied)
+	}
+	if strings.Contains(update.Reason, "allowed") {
+		t.Errorf("expected the reason to name only denied differences, got %q", update.Reason)
+	}
+	if len(plan.Creates) != 1 || !reflect.DeepEqual(plan.Creates[0].Resources.Allowed, []string{"team/support-issues/read"}) {
+		t.Errorf("expected support to be created with its local allows, got %+v", plan.Creates)
+	}
+	if len(local[1].Resources.Allowed) != 0 {
+		t.Errorf("expected the local roles to be left unchanged, got %v", local[1].Resources.Allowed)
+	}
+
+	// A role's own manage field overrides the option in either direction
+	local[0].Manage = models.ManageAll
+	plan, err = CompareRolesWithOptions(local[:1], remote[:1], CompareOptions{Manage: models.ManageDeniedOnly})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(plan.Updates) != 1 {
+		t.Errorf("expected viewer to be updated when it manages all resources, got %+v", plan.Updates)
+	}
+	local[0].Manage = models.ManageDeniedOnly
+	plan, err = CompareRolesWithOptions(local[:1], remote[:1], CompareOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if plan.HasChanges() {
+		t.Errorf("expected no changes for a denied-only viewer, got %+v", plan)
+	}
+}
+
+func TestValidatePlan(t *testing.T) {
+	tests := []struct {
+		name          string
+		plan          SyncPlan
+		opts          CompareOptions
+		errorContains string
+	}{
+		{
+			name: "disjoint plan",
+			plan: SyncPlan{
+				Creates: []models.Role{{Name: "new"}},
+				Updates: []RoleUpdate{{Name: "changed"}},
+				Deletes: []string{"old"},
+			},
+		},
+		{
+			name: "role both created and deleted",
+			plan: SyncPlan{
+				Creates: []models.Role{{Name: "admin"}},
+				Deletes: []string{"admin"},
+			},
+			errorContains: "role admin is deleted but also created as admin",
+		},
+		{
+			name: "role both updated and deleted",
+			plan: SyncPlan{
+				Updates: []RoleUpdate{{Name: "viewer"}},
+				Deletes: []string{"viewer"},
+			},
+			errorContains: "role viewer is deleted but also updated as viewer",
+		},
+		{
+			name: "names differing in case are distinct roles by default",
+			plan: SyncPlan{
+				Creates: []models.Role{{Name: "Admin"}},
+				Deletes: []string{"admin"},
+			},
+		},
+		{
+			name: "names differing in case overlap when matching ignores case",
+			plan: SyncPlan{
+				Creates: []models.Role{{Name: "Admin"}},
+				Deletes: []string{"admin"},
+			},
+			opts:          CompareOptions{CaseInsensitiveNames: true},
+			errorContains: "role admin is deleted but also created as Admin",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.opts.ValidatePlan(tt.plan)
+			if tt.errorContains == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.errorContains) {
+				t.Errorf("expected error containing %q, got %v", tt.errorContains, err)
+			}
+		})
+	}
+}
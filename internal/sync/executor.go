@@ -1,12 +1,17 @@
 package sync
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"sort"
 	"strings"
+	gosync "sync"
+	"time"
 
 	"replbac/internal/logging"
 	"replbac/internal/models"
+	"replbac/internal/roles"
 )
 
 // APIClient defines the interface for API operations needed by the executor
@@ -17,6 +22,11 @@ type APIClient interface {
 	GetRole(roleName string) (models.Role, error)
 }
 
+// roleCreatorWithID is implemented by clients that report the ID of the role they create
+type roleCreatorWithID interface {
+	CreateRoleWithID(role models.Role) (string, error)
+}
+
 // APIClientWithMembers extends APIClient with member management operations
 type APIClientWithMembers interface {
 	APIClient
@@ -28,8 +38,11 @@ type APIClientWithMembers interface {
 
 // Executor handles the execution of sync plans
 type Executor struct {
-	client APIClient
-	logger *logging.Logger
+	client     APIClient
+	logger     *logging.Logger
+	checkpoint *Checkpoint     // Records completed operations for resumption, if set
+	ctx        context.Context // Stops execution between operations when cancelled, if set
+	observe    func(OperationRecord)
 }
 
 // ExecutorWithMembers handles the execution of sync plans including member assignments
@@ -37,17 +50,112 @@ type ExecutorWithMembers struct {
 	client     APIClientWithMembers
 	logger     *logging.Logger
 	autoInvite bool
+	checkpoint *Checkpoint     // Records completed operations for resumption, if set
+	ctx        context.Context // Stops execution between operations when cancelled, if set
+	observe    func(OperationRecord)
+
+	// skipMembersOnError skips member sync with a warning, instead of failing, when team
+	// members cannot be listed. Member sync is always skipped when listing is forbidden.
+	skipMembersOnError bool
+
+	// strictMembers fails member sync when a role lists a member who is not on the team,
+	// instead of inviting them or skipping them with a warning
+	strictMembers bool
+
+	// inviteInterval is the least time between two invitations, so that onboarding many
+	// members neither floods them with emails nor trips the API's rate limits. Zero
+	// sends invitations as fast as the API allows.
+	inviteInterval time.Duration
+	lastInvite     time.Time
+
+	// confirmInvites, if set, is asked before any member is invited, with the sorted
+	// emails about to be invited. If it declines, those members are skipped as they would
+	// be without auto-invite.
+	confirmInvites func(emails []string) (bool, error)
+
+	// roleIDs holds the ID of each role by name, from the remote roles and those created
+	// during execution, so members can be assigned without looking their roles up
+	roleIDs map[string]string
+
+	// unmanagedRoleIDs holds the IDs of remote roles that sync leaves alone, whose
+	// members are never treated as orphaned
+	unmanagedRoleIDs map[string]bool
+
+	mu      gosync.Mutex // Guards the progress recorded while processing members
+	invited []string     // Members invited during the current execution
+	counts  MemberCounts // Members assigned, invited, or skipped during the current execution
 }
 
 // ExecutionResult represents the result of executing a sync plan
 type ExecutionResult struct {
-	Created         int              // Number of roles created
-	Updated         int              // Number of roles updated
-	Deleted         int              // Number of roles deleted
-	Error           error            // Error if execution failed
-	DryRun          bool             // Whether this was a dry run
-	DetailedInfo    string           // Detailed information about changes (for enhanced dry-run)
-	MemberDeletions *MemberDeletions // Members and invites that would be deleted
+	Created         int                        // Number of roles created
+	Updated         int                        // Number of roles updated
+	Deleted         int                        // Number of roles deleted
+	Error           error                      // Error if execution failed
+	DryRun          bool                       // Whether this was a dry run
+	DetailedInfo    string                     // Detailed information about changes (for enhanced dry-run)
+	Privileges      map[string]PrivilegeChange // Privilege change of each updated role, by name (dry-run only)
+	MemberDeletions *MemberDeletions           // Members and invites that would be deleted
+	InvitedMembers  []string                   // Members invited to the team during execution
+	MembersSkipped  error                      // Why member sync was skipped after roles were synced, if it was
+	Members         MemberCounts               // Members assigned, invited, or skipped during member sync
+}
+
+// MemberCounts counts the members whose role member sync changed or could not change
+type MemberCounts struct {
+	Assigned int // Members moved to their role
+	Invited  int // Members invited to the team with their role
+	Skipped  int // Members not on the team while auto-invite is disabled
+}
+
+// Summary returns a human-readable summary of the counts, such as
+// "assigned 2 member(s), invited 1 member(s)"
+func (c MemberCounts) Summary() string {
+	var parts []string
+	if c.Assigned > 0 {
+		parts = append(parts, fmt.Sprintf("assigned %d member(s)", c.Assigned))
+	}
+	if c.Invited > 0 {
+		parts = append(parts, fmt.Sprintf("invited %d member(s)", c.Invited))
+	}
+	if c.Skipped > 0 {
+		parts = append(parts, fmt.Sprintf("skipped %d member(s) not on the team", c.Skipped))
+	}
+	if len(parts) == 0 {
+		return "no member changes made"
+	}
+	return strings.Join(parts, ", ")
+}
+
+// Operation statuses reported in an OperationRecord
+const (
+	OperationPlanned = "planned"
+	OperationApplied = "applied"
+	OperationFailed  = "failed"
+)
+
+// OperationRecord describes one role operation as it is applied, or as it would be in a dry run
+type OperationRecord struct {
+	Operation string `json:"operation"`        // create, update, or delete
+	Role      string `json:"role"`             // Name of the role
+	Status    string `json:"status"`           // planned, applied, or failed
+	Reason    string `json:"reason,omitempty"` // How an updated role differs
+	Error     string `json:"error,omitempty"`  // Why the operation failed
+}
+
+// PlannedOperations returns a record for each operation in plan, in execution order
+func PlannedOperations(plan SyncPlan) []OperationRecord {
+	records := make([]OperationRecord, 0, len(plan.Creates)+len(plan.Updates)+len(plan.Deletes))
+	for _, role := range plan.Creates {
+		records = append(records, OperationRecord{Operation: OperationCreate, Role: role.Name, Status: OperationPlanned})
+	}
+	for _, update := range plan.Updates {
+		records = append(records, OperationRecord{Operation: OperationUpdate, Role: update.Name, Status: OperationPlanned, Reason: update.Reason})
+	}
+	for _, roleName := range plan.Deletes {
+		records = append(records, OperationRecord{Operation: OperationDelete, Role: roleName, Status: OperationPlanned})
+	}
+	return records
 }
 
 // MemberDeletions represents members and invites that need to be deleted
@@ -70,6 +178,7 @@ func NewExecutorWithMembers(client APIClientWithMembers, logger *logging.Logger)
 		client:     client,
 		logger:     logger,
 		autoInvite: true, // Default to auto-invite for backward compatibility
+		roleIDs:    make(map[string]string),
 	}
 }
 
@@ -79,6 +188,7 @@ func NewExecutorWithMembersAndInvite(client APIClientWithMembers, logger *loggin
 		client:     client,
 		logger:     logger,
 		autoInvite: autoInvite,
+		roleIDs:    make(map[string]string),
 	}
 }
 
@@ -89,44 +199,222 @@ func (e *Executor) ExecutePlan(plan SyncPlan) ExecutionResult {
 		DryRun: false,
 	}
 
+	// Execute creates, updates, and deletes
+	if err := e.logger.TimedOperation("execute role changes", func() error {
+		return applyRoleChanges(e.ctx, e.client, e.logger, plan, &result, applyOptions{checkpoint: e.checkpoint, observe: e.observe})
+	}); err != nil {
+		result.Error = err
+		return result
+	}
+
+	e.logger.Info("sync plan execution completed successfully")
+	return result
+}
+
+// SetOperationObserver makes execution pass a record of each role operation to observe
+// as soon as it has been applied or has failed
+func (e *Executor) SetOperationObserver(observe func(OperationRecord)) {
+	e.observe = observe
+}
+
+// SetOperationObserver makes execution pass a record of each role operation to observe
+// as soon as it has been applied or has failed
+func (e *ExecutorWithMembers) SetOperationObserver(observe func(OperationRecord)) {
+	e.observe = observe
+}
+
+// SetCheckpoint records each completed role operation in checkpoint so that an interrupted sync can be resumed
+func (e *Executor) SetCheckpoint(checkpoint *Checkpoint) {
+	e.checkpoint = checkpoint
+}
+
+// SetCheckpoint records each completed role operation in checkpoint so that an interrupted sync can be resumed
+func (e *ExecutorWithMembers) SetCheckpoint(checkpoint *Checkpoint) {
+	e.checkpoint = checkpoint
+}
+
+// SetContext makes execution stop before its next operation once ctx is cancelled,
+// returning an *InterruptedError with the work completed so far. An operation already
+// sent to the API is allowed to finish, so no role is left half-changed.
+func (e *Executor) SetContext(ctx context.Context) {
+	e.ctx = ctx
+}
+
+// SetContext makes execution stop before its next operation once ctx is cancelled,
+// returning an *InterruptedError with the work completed so far. An operation already
+// sent to the API is allowed to finish, so no role is left half-changed.
+func (e *ExecutorWithMembers) SetContext(ctx context.Context) {
+	e.ctx = ctx
+}
+
+// InterruptedError reports that execution stopped because its context was cancelled.
+// The counts in the accompanying ExecutionResult are the operations that completed.
+type InterruptedError struct {
+	Result        ExecutionResult // Operations completed before the interruption
+	Plan          SyncPlan        // The plan that was being executed
+	Err           error           // The context's error
+	DuringMembers bool            // Whether member sync had started
+}
+
+// Error describes how far execution got, e.g. "interrupted after creating 3 of 10 role(s)"
+func (e *InterruptedError) Error() string {
+	var progress []string
+	finished := true
+	for _, step := range []struct {
+		verb          string
+		done, planned int
+	}{
+		{"creating", e.Result.Created, len(e.Plan.Creates)},
+		{"updating", e.Result.Updated, len(e.Plan.Updates)},
+		{"deleting", e.Result.Deleted, len(e.Plan.Deletes)},
+	} {
+		if step.planned > 0 {
+			progress = append(progress, fmt.Sprintf("%s %d of %d role(s)", step.verb, step.done, step.planned))
+			finished = finished && step.done == step.planned
+		}
+	}
+	switch {
+	case e.DuringMembers && len(progress) == 0:
+		return "interrupted during member sync"
+	case e.DuringMembers:
+		return "interrupted during member sync, after " + strings.Join(progress, ", ")
+	case len(progress) == 0:
+		return "interrupted before member sync"
+	case finished:
+		return "interrupted after " + strings.Join(progress, ", ") + ", before member sync"
+	default:
+		return "interrupted after " + strings.Join(progress, ", ")
+	}
+}
+
+// Unwrap returns the context's error
+func (e *InterruptedError) Unwrap() error {
+	return e.Err
+}
+
+// checkInterrupted returns an *InterruptedError if ctx has been cancelled
+func checkInterrupted(ctx context.Context, plan SyncPlan, result *ExecutionResult) error {
+	if ctx == nil || ctx.Err() == nil {
+		return nil
+	}
+	return &InterruptedError{Result: *result, Plan: plan, Err: ctx.Err()}
+}
+
+// applyOptions are what applyRoleChanges records as it applies a plan, each optional
+type applyOptions struct {
+	checkpoint *Checkpoint           // Records completed operations, if set
+	roleIDs    map[string]string     // Receives the IDs of created and updated roles, if not nil
+	observe    func(OperationRecord) // Receives each operation once applied or failed, if set
+}
+
+// applyRoleChanges executes the creates, updates, and deletes of a plan in order,
+// counting each successful operation in result and stopping at the first failure,
+// or before the next operation once ctx is cancelled. Completed operations, role IDs,
+// and operation records are reported as opts asks.
+func applyRoleChanges(ctx context.Context, client APIClient, logger *logging.Logger, plan SyncPlan, result *ExecutionResult, opts applyOptions) error {
+
 	// Execute creates
 	for _, role := range plan.Creates {
-		e.logger.Debug("creating role: %s", role.Name)
-		if err := e.client.CreateRole(role); err != nil {
-			e.logger.Error("failed to create role %s: %v", role.Name, err)
-			result.Error = fmt.Errorf("failed to create role '%s': %w", role.Name, err)
-			return result
+		if err := checkInterrupted(ctx, plan, result); err != nil {
+			return err
+		}
+		logger.Debug("creating role: %s", role.Name)
+		id, err := createRole(client, role)
+		observeOperation(opts.observe, OperationRecord{Operation: OperationCreate, Role: role.Name}, err)
+		if err != nil {
+			logger.Error("failed to create role %s%s: %v", role.Name, role.Origin(), err)
+			return fmt.Errorf("failed to create role '%s'%s: %w", role.Name, role.Origin(), err)
+		}
+		if id != "" && opts.roleIDs != nil {
+			opts.roleIDs[role.Name] = id
 		}
-		e.logger.Info("successfully created role: %s", role.Name)
+		logger.Info("successfully created role: %s", role.Name)
 		result.Created++
+		recordCheckpoint(opts.checkpoint, logger, OperationCreate, role.Name)
 	}
 
 	// Execute updates
 	for _, update := range plan.Updates {
-		e.logger.Debug("updating role: %s", update.Name)
-		if err := e.client.UpdateRole(update.Local); err != nil {
-			e.logger.Error("failed to update role %s: %v", update.Name, err)
-			result.Error = fmt.Errorf("failed to update role '%s': %w", update.Name, err)
-			return result
+		if err := checkInterrupted(ctx, plan, result); err != nil {
+			return err
+		}
+		logger.Debug("updating role: %s", update.Name)
+		role := update.Local
+		if role.ID == "" {
+			// Role files need not keep the ID, but the API updates roles by ID
+			role.ID = update.Remote.ID
 		}
-		e.logger.Info("successfully updated role: %s", update.Name)
+		err := client.UpdateRole(role)
+		observeOperation(opts.observe, OperationRecord{Operation: OperationUpdate, Role: update.Name, Reason: update.Reason}, err)
+		if err != nil {
+			logger.Error("failed to update role %s%s: %v", update.Name, update.Local.Origin(), err)
+			return fmt.Errorf("failed to update role '%s'%s: %w", update.Name, update.Local.Origin(), err)
+		}
+		if role.ID != "" && opts.roleIDs != nil {
+			// A soft delete renames the role it updates
+			delete(opts.roleIDs, update.Name)
+			opts.roleIDs[role.Name] = role.ID
+		}
+		logger.Info("successfully updated role: %s", update.Name)
 		result.Updated++
+		recordCheckpoint(opts.checkpoint, logger, OperationUpdate, update.Name)
 	}
 
 	// Execute deletes
 	for _, roleName := range plan.Deletes {
-		e.logger.Debug("deleting role: %s", roleName)
-		if err := e.client.DeleteRole(roleName); err != nil {
-			e.logger.Error("failed to delete role %s: %v", roleName, err)
-			result.Error = fmt.Errorf("failed to delete role '%s': %w", roleName, err)
-			return result
+		if err := checkInterrupted(ctx, plan, result); err != nil {
+			return err
+		}
+		logger.Debug("deleting role: %s", roleName)
+		err := client.DeleteRole(roleName)
+		observeOperation(opts.observe, OperationRecord{Operation: OperationDelete, Role: roleName}, err)
+		if err != nil {
+			logger.Error("failed to delete role %s: %v", roleName, err)
+			return fmt.Errorf("failed to delete role '%s': %w", roleName, err)
 		}
-		e.logger.Info("successfully deleted role: %s", roleName)
+		if opts.roleIDs != nil {
+			delete(opts.roleIDs, roleName)
+		}
+		logger.Info("successfully deleted role: %s", roleName)
 		result.Deleted++
+		recordCheckpoint(opts.checkpoint, logger, OperationDelete, roleName)
 	}
 
-	e.logger.Info("sync plan execution completed successfully")
-	return result
+	return nil
+}
+
+// applyOptions returns what applying a plan records for this executor: its checkpoint,
+// the role IDs used to assign members, and its operation observer
+func (e *ExecutorWithMembers) applyOptions() applyOptions {
+	return applyOptions{checkpoint: e.checkpoint, roleIDs: e.roleIDs, observe: e.observe}
+}
+
+// observeOperation passes record to observe, if set, as applied or as failed with err
+func observeOperation(observe func(OperationRecord), record OperationRecord, err error) {
+	if observe == nil {
+		return
+	}
+	record.Status = OperationApplied
+	if err != nil {
+		record.Status = OperationFailed
+		record.Error = err.Error()
+	}
+	observe(record)
+}
+
+// createRole creates role with client, returning its ID if the client reports it
+func createRole(client APIClient, role models.Role) (string, error) {
+	if creator, ok := client.(roleCreatorWithID); ok {
+		return creator.CreateRoleWithID(role)
+	}
+	return "", client.CreateRole(role)
+}
+
+// recordCheckpoint saves a completed operation; failing to save only costs the ability to resume
+func recordCheckpoint(checkpoint *Checkpoint, logger *logging.Logger, kind, roleName string) {
+	if err := checkpoint.Record(kind, roleName); err != nil {
+		logger.Warn("failed to record %s of role %s in checkpoint: %v", kind, roleName, err)
+	}
 }
 
 // ExecutePlanDryRun simulates executing a sync plan without making actual API calls
@@ -134,11 +422,12 @@ func (e *Executor) ExecutePlanDryRun(plan SyncPlan) ExecutionResult {
 	e.logger.Info("executing sync plan in dry-run mode: %d creates, %d updates, %d deletes", len(plan.Creates), len(plan.Updates), len(plan.Deletes))
 
 	result := ExecutionResult{
-		Created: len(plan.Creates),
-		Updated: len(plan.Updates),
-		Deleted: len(plan.Deletes),
-		DryRun:  true,
-		Error:   nil,
+		Created:    len(plan.Creates),
+		Updated:    len(plan.Updates),
+		Deleted:    len(plan.Deletes),
+		DryRun:     true,
+		Error:      nil,
+		Privileges: ClassifyPlan(plan),
 	}
 
 	e.logger.Debug("dry-run completed - no actual changes made")
@@ -148,38 +437,48 @@ func (e *Executor) ExecutePlanDryRun(plan SyncPlan) ExecutionResult {
 // ExecutePlanDryRunWithDiffs simulates executing a sync plan with detailed diff information
 func (e *Executor) ExecutePlanDryRunWithDiffs(plan SyncPlan) ExecutionResult {
 	result := ExecutionResult{
-		Created: len(plan.Creates),
-		Updated: len(plan.Updates),
-		Deleted: len(plan.Deletes),
-		DryRun:  true,
-		Error:   nil,
+		Created:    len(plan.Creates),
+		Updated:    len(plan.Updates),
+		Deleted:    len(plan.Deletes),
+		DryRun:     true,
+		Error:      nil,
+		Privileges: ClassifyPlan(plan),
 	}
 
-	// Generate detailed diff information
+	result.DetailedInfo = DescribePlan(plan, false)
+	return result
+}
+
+// DescribePlan renders a line-per-change description of a sync plan, with
+// resource-level diffs for updates. Member changes are included when includeMembers is set.
+// Changes are listed by role name so the output is the same for any plan order.
+func DescribePlan(plan SyncPlan, includeMembers bool) string {
+	return DescribePlanWithNames(plan, includeMembers, nil)
+}
+
+// DescribePlanWithNames renders the same description as DescribePlan, but shows each
+// member by the label in names (see MemberNames) instead of their bare email
+func DescribePlanWithNames(plan SyncPlan, includeMembers bool, names map[string]string) string {
+	plan = sortedPlan(plan)
 	detailsBuilder := make([]string, 0)
 
 	// Add create details
 	for _, role := range plan.Creates {
-		detailsBuilder = append(detailsBuilder,
-			fmt.Sprintf("CREATE: %s (allowed: %v, denied: %v)",
-				role.Name, role.Resources.Allowed, role.Resources.Denied))
+		if includeMembers {
+			detailsBuilder = append(detailsBuilder,
+				fmt.Sprintf("CREATE: %s (allowed: %v, denied: %v, members: %v)%s",
+					role.Name, role.Resources.Allowed, role.Resources.Denied, labelMembers(role.Members, names), role.Origin()))
+		} else {
+			detailsBuilder = append(detailsBuilder,
+				fmt.Sprintf("CREATE: %s (allowed: %v, denied: %v)%s",
+					role.Name, role.Resources.Allowed, role.Resources.Denied, role.Origin()))
+		}
 	}
 
 	// Add update details with diffs
 	for _, update := range plan.Updates {
-		detailsBuilder = append(detailsBuilder, fmt.Sprintf("UPDATE: %s", update.Name))
-
-		// Compare allowed resources
-		allowedDiff := generateResourceDiff("allowed", update.Remote.Resources.Allowed, update.Local.Resources.Allowed)
-		if allowedDiff != "" {
-			detailsBuilder = append(detailsBuilder, fmt.Sprintf("  %s", allowedDiff))
-		}
-
-		// Compare denied resources
-		deniedDiff := generateResourceDiff("denied", update.Remote.Resources.Denied, update.Local.Resources.Denied)
-		if deniedDiff != "" {
-			detailsBuilder = append(detailsBuilder, fmt.Sprintf("  %s", deniedDiff))
-		}
+		detailsBuilder = append(detailsBuilder, fmt.Sprintf("UPDATE: %s%s%s", update.Name, privilegeTag(update), update.Local.Origin()))
+		detailsBuilder = appendIndented(detailsBuilder, formatChanges(update.Diff(), includeMembers, names))
 	}
 
 	// Add delete details
@@ -187,8 +486,39 @@ func (e *Executor) ExecutePlanDryRunWithDiffs(plan SyncPlan) ExecutionResult {
 		detailsBuilder = append(detailsBuilder, fmt.Sprintf("DELETE: %s", roleName))
 	}
 
-	result.DetailedInfo = strings.Join(detailsBuilder, "\n")
-	return result
+	return strings.Join(detailsBuilder, "\n")
+}
+
+// MemberNames maps each member email found on roles read from the API to a display
+// label with the member's name and username, for use with DescribePlanWithNames
+func MemberNames(roles []models.Role) map[string]string {
+	names := make(map[string]string)
+	for _, role := range roles {
+		for _, member := range role.MemberDetails {
+			label := member.DisplayName()
+			names[member.ID] = label
+			if member.Email != "" {
+				names[member.Email] = label
+			}
+		}
+	}
+	return names
+}
+
+// labelMembers replaces each member email that has an entry in names with its label
+func labelMembers(members []string, names map[string]string) []string {
+	if len(names) == 0 {
+		return members
+	}
+	labeled := make([]string, len(members))
+	for i, member := range members {
+		if label, ok := names[member]; ok {
+			labeled[i] = label
+		} else {
+			labeled[i] = member
+		}
+	}
+	return labeled
 }
 
 // Summary returns a human-readable summary of the execution result
@@ -256,17 +586,58 @@ func (r ExecutionResult) DetailedSummary() string {
 	return summary
 }
 
-// generateResourceDiff generates a diff string showing changes between old and new resource lists
-func generateResourceDiff(resourceType string, oldResources, newResources []string) string {
-	// Normalize slices (handle nil as empty)
-	if oldResources == nil {
-		oldResources = []string{}
+// appendIndented appends each line of a multi-line diff to details, indented under its
+// role; an empty diff appends nothing
+func appendIndented(details []string, diff string) []string {
+	if diff == "" {
+		return details
 	}
-	if newResources == nil {
-		newResources = []string{}
+	for _, line := range strings.Split(diff, "\n") {
+		details = append(details, "  "+line)
 	}
+	return details
+}
 
-	// Create maps for efficient lookup
+// formatChanges renders an update's changes as "+ type: entry" and "- type: entry" lines,
+// allowed then denied, labels, and members when includeMembers is set, with each member
+// shown by their label in names
+func formatChanges(changes RoleChanges, includeMembers bool, names map[string]string) string {
+	parts := []string{
+		formatDiff("allowed", changes.AllowedAdded, changes.AllowedRemoved),
+		formatDiff("denied", changes.DeniedAdded, changes.DeniedRemoved),
+		formatDiff("labels", changes.LabelsAdded, changes.LabelsRemoved),
+	}
+	if includeMembers {
+		parts = append(parts, formatDiff("members", labelMembers(changes.MembersAdded, names), labelMembers(changes.MembersRemoved, names)))
+	}
+
+	var lines []string
+	for _, part := range parts {
+		if part != "" {
+			lines = append(lines, part)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// formatDiff formats additions and removals as "+ type: entry" and "- type: entry" lines
+func formatDiff(resourceType string, additions, removals []string) string {
+	var diffParts []string
+
+	for _, addition := range additions {
+		diffParts = append(diffParts, fmt.Sprintf("+ %s: %s", resourceType, addition))
+	}
+
+	for _, removal := range removals {
+		diffParts = append(diffParts, fmt.Sprintf("- %s: %s", resourceType, removal))
+	}
+
+	return strings.Join(diffParts, "\n")
+}
+
+// resourceChanges returns the sorted entries added in newResources and removed from oldResources
+func resourceChanges(oldResources, newResources []string) (additions, removals []string) {
+	// Create maps for efficient lookup (nil slices behave as empty)
 	oldMap := make(map[string]bool)
 	newMap := make(map[string]bool)
 
@@ -277,9 +648,6 @@ func generateResourceDiff(resourceType string, oldResources, newResources []stri
 		newMap[resource] = true
 	}
 
-	// Find additions and removals
-	var additions, removals []string
-
 	// Check for additions (in new but not in old)
 	for resource := range newMap {
 		if !oldMap[resource] {
@@ -298,22 +666,156 @@ func generateResourceDiff(resourceType string, oldResources, newResources []stri
 	sort.Strings(additions)
 	sort.Strings(removals)
 
-	// Build diff string
-	var diffParts []string
+	return additions, removals
+}
 
-	if len(additions) > 0 {
-		for _, addition := range additions {
-			diffParts = append(diffParts, fmt.Sprintf("+ %s: %s", resourceType, addition))
+// memberChanges returns the sorted members added in newMembers and removed from
+// oldMembers, matching emails as models.NormalizeEmail does but keeping their casing
+func memberChanges(oldMembers, newMembers []string) (additions, removals []string) {
+	missingFrom := func(members, others []string) []string {
+		present := make(map[string]bool, len(others))
+		for _, member := range others {
+			present[models.NormalizeEmail(member)] = true
 		}
+		var missing []string
+		for _, member := range members {
+			if key := models.NormalizeEmail(member); !present[key] {
+				present[key] = true
+				missing = append(missing, member)
+			}
+		}
+		sort.Strings(missing)
+		return missing
 	}
+	return missingFrom(newMembers, oldMembers), missingFrom(oldMembers, newMembers)
+}
+
+// DescribePlanSummary renders a compact description of a sync plan that shows
+// counts of changed entries per role instead of listing every resource,
+// e.g. "UPDATE: admin (+12 allowed, -3 denied, +2 members)"
+func DescribePlanSummary(plan SyncPlan, includeMembers bool) string {
+	plan = sortedPlan(plan)
+	lines := make([]string, 0)
 
+	for _, role := range plan.Creates {
+		counts := []string{
+			fmt.Sprintf("+%d allowed", len(role.Resources.Allowed)),
+			fmt.Sprintf("+%d denied", len(role.Resources.Denied)),
+		}
+		if includeMembers {
+			counts = append(counts, fmt.Sprintf("+%d members", len(role.Members)))
+		}
+		lines = append(lines, fmt.Sprintf("CREATE: %s (%s)%s", role.Name, strings.Join(counts, ", "), role.Origin()))
+	}
+
+	for _, update := range plan.Updates {
+		changes := update.Diff()
+		var counts []string
+		counts = appendChangeCounts(counts, "allowed", changes.AllowedAdded, changes.AllowedRemoved)
+		counts = appendChangeCounts(counts, "denied", changes.DeniedAdded, changes.DeniedRemoved)
+		if includeMembers {
+			counts = appendChangeCounts(counts, "members", changes.MembersAdded, changes.MembersRemoved)
+		}
+		if len(counts) == 0 {
+			lines = append(lines, fmt.Sprintf("UPDATE: %s%s%s", update.Name, privilegeTag(update), update.Local.Origin()))
+			continue
+		}
+		lines = append(lines, fmt.Sprintf("UPDATE: %s (%s)%s%s", update.Name, strings.Join(counts, ", "), privilegeTag(update), update.Local.Origin()))
+	}
+
+	for _, roleName := range plan.Deletes {
+		lines = append(lines, fmt.Sprintf("DELETE: %s", roleName))
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// sortedPlan returns a copy of plan with creates, updates, and deletes each sorted by role name
+func sortedPlan(plan SyncPlan) SyncPlan {
+	sorted := SyncPlan{
+		Creates: append([]models.Role{}, plan.Creates...),
+		Updates: append([]RoleUpdate{}, plan.Updates...),
+		Deletes: append([]string{}, plan.Deletes...),
+	}
+	sort.SliceStable(sorted.Creates, func(i, j int) bool { return sorted.Creates[i].Name < sorted.Creates[j].Name })
+	sort.SliceStable(sorted.Updates, func(i, j int) bool { return sorted.Updates[i].Name < sorted.Updates[j].Name })
+	sort.Strings(sorted.Deletes)
+	return sorted
+}
+
+// appendChangeCounts appends "+N kind" and "-N kind" entries for a changed list, skipping zero counts
+func appendChangeCounts(counts []string, kind string, additions, removals []string) []string {
+	if len(additions) > 0 {
+		counts = append(counts, fmt.Sprintf("+%d %s", len(additions), kind))
+	}
 	if len(removals) > 0 {
-		for _, removal := range removals {
-			diffParts = append(diffParts, fmt.Sprintf("- %s: %s", resourceType, removal))
+		counts = append(counts, fmt.Sprintf("-%d %s", len(removals), kind))
+	}
+	return counts
+}
+
+// SetSkipMembersOnError makes any failure to list team members skip member sync with a
+// warning instead of failing the execution
+func (e *ExecutorWithMembers) SetSkipMembersOnError(skip bool) {
+	e.skipMembersOnError = skip
+}
+
+// SetStrictMembers makes a member listed in a role but missing from the team an error,
+// whether or not auto-invite is enabled, for teams that provision accounts out-of-band
+func (e *ExecutorWithMembers) SetStrictMembers(strict bool) {
+	e.strictMembers = strict
+}
+
+// SetInviteRate limits invitations to perSecond per second, which may be fractional, e.g.
+// 0.5 for one every two seconds. Zero or less removes the limit.
+func (e *ExecutorWithMembers) SetInviteRate(perSecond float64) {
+	if perSecond <= 0 {
+		e.inviteInterval = 0
+		return
+	}
+	e.inviteInterval = time.Duration(float64(time.Second) / perSecond)
+}
+
+// SetInviteConfirmation makes the executor ask confirm before sending any invitation,
+// passing the emails of every member about to be invited
+func (e *ExecutorWithMembers) SetInviteConfirmation(confirm func(emails []string) (bool, error)) {
+	e.confirmInvites = confirm
+}
+
+// SetRemoteRoles records the IDs of the remote roles a plan was made from, so that their
+// members can be assigned without looking each role up again
+func (e *ExecutorWithMembers) SetRemoteRoles(remoteRoles []models.Role) {
+	for _, role := range remoteRoles {
+		if role.ID != "" {
+			e.roleIDs[role.Name] = role.ID
 		}
 	}
+}
 
-	return strings.Join(diffParts, "\n")
+// SetUnmanagedRoles records the remote roles that sync leaves alone, such as ignored roles
+// and roles with no local file, so that members assigned to them are not removed as orphans
+// for being missing from the local roles
+func (e *ExecutorWithMembers) SetUnmanagedRoles(unmanaged []models.Role) {
+	e.unmanagedRoleIDs = make(map[string]bool, len(unmanaged))
+	for _, role := range unmanaged {
+		if role.ID != "" {
+			e.unmanagedRoleIDs[role.ID] = true
+		}
+	}
+}
+
+// roleID returns the ID of the named role, looking it up only if it is not yet known
+func (e *ExecutorWithMembers) roleID(roleName string) (string, error) {
+	if id, known := e.roleIDs[roleName]; known {
+		return id, nil
+	}
+	e.logger.Debug("looking up ID of role %s", roleName)
+	role, err := e.client.GetRole(roleName)
+	if err != nil {
+		return "", err
+	}
+	e.roleIDs[roleName] = role.ID
+	return role.ID, nil
 }
 
 // ExecutePlan executes a sync plan by making actual API calls including member assignments
@@ -323,54 +825,30 @@ func (e *ExecutorWithMembers) ExecutePlan(plan SyncPlan) ExecutionResult {
 		DryRun: false,
 	}
 
-	// Execute creates
-	for _, role := range plan.Creates {
-		e.logger.Debug("creating role: %s", role.Name)
-		if err := e.client.CreateRole(role); err != nil {
-			e.logger.Error("failed to create role %s: %v", role.Name, err)
-			result.Error = fmt.Errorf("failed to create role '%s': %w", role.Name, err)
-			return result
-		}
-		e.logger.Info("successfully created role: %s", role.Name)
-		result.Created++
-	}
-
-	// Execute updates
-	for _, update := range plan.Updates {
-		e.logger.Debug("updating role: %s", update.Name)
-		if err := e.client.UpdateRole(update.Local); err != nil {
-			e.logger.Error("failed to update role %s: %v", update.Name, err)
-			result.Error = fmt.Errorf("failed to update role '%s': %w", update.Name, err)
-			return result
-		}
-		e.logger.Info("successfully updated role: %s", update.Name)
-		result.Updated++
+	// Execute creates, updates, and deletes
+	if err := e.logger.TimedOperation("execute role changes", func() error {
+		return applyRoleChanges(e.ctx, e.client, e.logger, plan, &result, e.applyOptions())
+	}); err != nil {
+		result.Error = err
+		return result
 	}
 
-	// Execute deletes
-	for _, roleName := range plan.Deletes {
-		e.logger.Debug("deleting role: %s", roleName)
-		if err := e.client.DeleteRole(roleName); err != nil {
-			e.logger.Error("failed to delete role %s: %v", roleName, err)
-			result.Error = fmt.Errorf("failed to delete role '%s': %w", roleName, err)
-			return result
-		}
-		e.logger.Info("successfully deleted role: %s", roleName)
-		result.Deleted++
+	// Stop before member sync if interrupted after the last role operation
+	if err := checkInterrupted(e.ctx, plan, &result); err != nil {
+		result.Error = err
+		return result
 	}
 
 	// After all role operations are complete, sync members
 	// Note: This method only syncs members for creates/updates, not all local roles
 	// Use ExecutePlanWithLocalRoles for complete member sync
-	memberDeletions, err := e.syncAllMembersFromPlan(plan)
-	if err != nil {
-		e.logger.Error("failed to sync members: %v", err)
-		result.Error = fmt.Errorf("failed to sync members: %w", err)
-		return result
-	}
-	result.MemberDeletions = memberDeletions
-
-	e.logger.Info("sync plan execution completed successfully")
+	var memberDeletions *MemberDeletions
+	err := e.logger.TimedOperation("member sync", func() error {
+		var err error
+		memberDeletions, err = e.syncAllMembersFromPlan(plan)
+		return err
+	})
+	e.finishMemberSync(&result, plan, memberDeletions, err)
 	return result
 }
 
@@ -381,52 +859,51 @@ func (e *ExecutorWithMembers) ExecutePlanWithLocalRoles(plan SyncPlan, allLocalR
 		DryRun: false,
 	}
 
-	// Execute creates
-	for _, role := range plan.Creates {
-		e.logger.Debug("creating role: %s", role.Name)
-		if err := e.client.CreateRole(role); err != nil {
-			e.logger.Error("failed to create role %s: %v", role.Name, err)
-			result.Error = fmt.Errorf("failed to create role '%s': %w", role.Name, err)
-			return result
-		}
-		e.logger.Info("successfully created role: %s", role.Name)
-		result.Created++
-	}
-
-	// Execute updates
-	for _, update := range plan.Updates {
-		e.logger.Debug("updating role: %s", update.Name)
-		if err := e.client.UpdateRole(update.Local); err != nil {
-			e.logger.Error("failed to update role %s: %v", update.Name, err)
-			result.Error = fmt.Errorf("failed to update role '%s': %w", update.Name, err)
-			return result
-		}
-		e.logger.Info("successfully updated role: %s", update.Name)
-		result.Updated++
+	// Execute creates, updates, and deletes
+	if err := e.logger.TimedOperation("execute role changes", func() error {
+		return applyRoleChanges(e.ctx, e.client, e.logger, plan, &result, e.applyOptions())
+	}); err != nil {
+		result.Error = err
+		return result
 	}
 
-	// Execute deletes
-	for _, roleName := range plan.Deletes {
-		e.logger.Debug("deleting role: %s", roleName)
-		if err := e.client.DeleteRole(roleName); err != nil {
-			e.logger.Error("failed to delete role %s: %v", roleName, err)
-			result.Error = fmt.Errorf("failed to delete role '%s': %w", roleName, err)
-			return result
-		}
-		e.logger.Info("successfully deleted role: %s", roleName)
-		result.Deleted++
+	// Stop before member sync if interrupted after the last role operation
+	if err := checkInterrupted(e.ctx, plan, &result); err != nil {
+		result.Error = err
+		return result
 	}
 
 	// After all role operations are complete, sync members using ALL local roles
-	memberDeletions, err := e.syncAllMembers(allLocalRoles)
-	if err != nil {
-		e.logger.Error("failed to sync members: %v", err)
-		result.Error = fmt.Errorf("failed to sync members: %w", err)
+	var memberDeletions *MemberDeletions
+	err := e.logger.TimedOperation("member sync", func() error {
+		var err error
+		memberDeletions, err = e.syncAllMembers(allLocalRoles)
+		return err
+	})
+	e.finishMemberSync(&result, plan, memberDeletions, err)
+	return result
+}
+
+// ExecuteMembersOnly synchronizes the membership of existingRoles without creating, updating,
+// or deleting any role. Members of the other local roles are neither assigned nor treated as
+// orphaned, so roles missing from the remote do not cause their members to be removed.
+func (e *ExecutorWithMembers) ExecuteMembersOnly(existingRoles, allLocalRoles []models.Role) ExecutionResult {
+	e.logger.Info("synchronizing members only for %d existing roles", len(existingRoles))
+	result := ExecutionResult{
+		DryRun: false,
+	}
+	if err := checkInterrupted(e.ctx, SyncPlan{}, &result); err != nil {
+		result.Error = err
 		return result
 	}
-	result.MemberDeletions = memberDeletions
 
-	e.logger.Info("sync plan execution completed successfully")
+	var memberDeletions *MemberDeletions
+	err := e.logger.TimedOperation("member sync", func() error {
+		var err error
+		memberDeletions, err = e.syncMembers(existingRoles, allLocalRoles)
+		return err
+	})
+	e.finishMemberSync(&result, SyncPlan{}, memberDeletions, err)
 	return result
 }
 
@@ -434,40 +911,29 @@ func (e *ExecutorWithMembers) ExecutePlanWithLocalRoles(plan SyncPlan, allLocalR
 func (e *ExecutorWithMembers) syncAllMembersFromPlan(plan SyncPlan) (*MemberDeletions, error) {
 	e.logger.Info("synchronizing team members from plan operations only")
 
-	// Collect members from created and updated roles only
-	localMembers := make(map[string]string) // email -> roleName
-
-	// Add members from created roles
-	for _, role := range plan.Creates {
-		for _, memberEmail := range role.Members {
-			if existingRole, exists := localMembers[memberEmail]; exists {
-				return nil, fmt.Errorf("member %s appears in multiple roles: %s and %s", memberEmail, existingRole, role.Name)
-			}
-			localMembers[memberEmail] = role.Name
-		}
+	// Collect members from created and updated roles only, using the local version of updates
+	planRoles := append([]models.Role{}, plan.Creates...)
+	for _, update := range plan.Updates {
+		role := update.Local
+		role.Name = update.Name
+		planRoles = append(planRoles, role)
 	}
 
-	// Add members from updated roles (use local version)
-	for _, update := range plan.Updates {
-		for _, memberEmail := range update.Local.Members {
-			if existingRole, exists := localMembers[memberEmail]; exists {
-				return nil, fmt.Errorf("member %s appears in multiple roles: %s and %s", memberEmail, existingRole, update.Name)
-			}
-			localMembers[memberEmail] = update.Name
-		}
+	localMembers, err := memberAssignments(planRoles)
+	if err != nil {
+		return nil, err
 	}
 
 	// Get current team members
 	teamMembers, err := e.client.GetTeamMembers()
 	if err != nil {
-		e.logger.Error("failed to get team members: %v", err)
-		return nil, fmt.Errorf("failed to get team members: %w", err)
+		return nil, &teamMembersError{err: err}
 	}
 
 	// Create maps for existing team members
 	existingMembers := make(map[string]models.TeamMember)
 	for _, member := range teamMembers {
-		existingMembers[member.Email] = member
+		existingMembers[models.NormalizeEmail(member.Email)] = member
 	}
 
 	// Process member assignments
@@ -484,31 +950,31 @@ func (e *ExecutorWithMembers) syncAllMembersFromPlan(plan SyncPlan) (*MemberDele
 // syncAllMembers performs comprehensive member synchronization across all roles
 func (e *ExecutorWithMembers) syncAllMembers(allLocalRoles []models.Role) (*MemberDeletions, error) {
 	e.logger.Info("synchronizing team members across all local roles")
+	return e.syncMembers(allLocalRoles, allLocalRoles)
+}
 
-	// Collect all members from ALL local role definitions
-	localMembers := make(map[string]string) // email -> roleName
-
-	// Add members from ALL local roles
-	for _, role := range allLocalRoles {
-		for _, memberEmail := range role.Members {
-			if existingRole, exists := localMembers[memberEmail]; exists {
-				return nil, fmt.Errorf("member %s appears in multiple roles: %s and %s", memberEmail, existingRole, role.Name)
-			}
-			localMembers[memberEmail] = role.Name
-		}
+// syncMembers assigns the members of assignRoles and identifies team members and invites
+// that belong to none of keptRoles as orphaned
+func (e *ExecutorWithMembers) syncMembers(assignRoles, keptRoles []models.Role) (*MemberDeletions, error) {
+	localMembers, err := memberAssignments(assignRoles)
+	if err != nil {
+		return nil, err
+	}
+	keptMembers, err := memberAssignments(keptRoles)
+	if err != nil {
+		return nil, err
 	}
 
 	// Get current team members
 	teamMembers, err := e.client.GetTeamMembers()
 	if err != nil {
-		e.logger.Error("failed to get team members: %v", err)
-		return nil, fmt.Errorf("failed to get team members: %w", err)
+		return nil, &teamMembersError{err: err}
 	}
 
 	// Create maps for existing team members
 	existingMembers := make(map[string]models.TeamMember)
 	for _, member := range teamMembers {
-		existingMembers[member.Email] = member
+		existingMembers[models.NormalizeEmail(member.Email)] = member
 	}
 
 	// Process member assignments
@@ -517,67 +983,295 @@ func (e *ExecutorWithMembers) syncAllMembers(allLocalRoles []models.Role) (*Memb
 	}
 
 	// Identify orphaned members and invites (but don't delete them yet)
-	memberDeletions := e.identifyOrphanedMembers(localMembers, existingMembers)
+	memberDeletions := e.identifyOrphanedMembers(keptMembers, existingMembers)
 
 	return memberDeletions, nil
 }
 
-// processMemberAssignments handles assigning members to roles (invite if needed, assign if exists)
+// teamMembersError reports that team members could not be listed, before any member was changed
+type teamMembersError struct {
+	err error
+}
+
+func (e *teamMembersError) Error() string {
+	return fmt.Sprintf("failed to get team members: %v", e.err)
+}
+
+func (e *teamMembersError) Unwrap() error {
+	return e.err
+}
+
+// finishMemberSync records the outcome of member sync in result. A failure to list team
+// members is downgraded to a warning when it is forbidden or skipping is enabled, since
+// the role changes have already been applied and no member has been touched. An
+// interruption during member sync is reported with the progress through plan.
+func (e *ExecutorWithMembers) finishMemberSync(result *ExecutionResult, plan SyncPlan, memberDeletions *MemberDeletions, err error) {
+	result.InvitedMembers = e.invitedMembers()
+	result.Members = e.memberCounts()
+
+	var interrupted *InterruptedError
+	if errors.As(err, &interrupted) {
+		interrupted.Result = *result
+		interrupted.Plan = plan
+		e.logger.Warn("stopping member sync: %v", interrupted.Err)
+		result.Error = interrupted
+		return
+	}
+
+	var listErr *teamMembersError
+	if errors.As(err, &listErr) && (e.skipMembersOnError || isForbidden(listErr.err)) {
+		e.logger.Warn("skipping member sync: %v", listErr)
+		result.MembersSkipped = listErr
+		e.logger.Info("sync plan execution completed without member sync")
+		return
+	}
+	if err != nil {
+		e.logger.Error("failed to sync members: %v", err)
+		result.Error = fmt.Errorf("failed to sync members: %w", err)
+		return
+	}
+	result.MemberDeletions = memberDeletions
+
+	e.logger.Info("sync plan execution completed successfully")
+}
+
+// isForbidden reports whether err says the API token lacks permission for a request,
+// as opposed to being invalid
+func isForbidden(err error) bool {
+	var forbidden interface{ Forbidden() bool }
+	return errors.As(err, &forbidden) && forbidden.Forbidden()
+}
+
+// memberAssignments maps each member email to the role it is assigned to, after
+// validating that no member is listed in more than one role
+func memberAssignments(roleList []models.Role) (map[string]string, error) {
+	if err := roles.ValidateRoleMembers(roleList); err != nil {
+		return nil, err
+	}
+
+	localMembers := make(map[string]string) // email -> roleName
+	for _, role := range roleList {
+		for _, memberEmail := range role.Members {
+			localMembers[memberEmail] = role.Name
+		}
+	}
+	return localMembers, nil
+}
+
+// processMemberAssignments handles assigning members to roles. Members not on the team are
+// invited with auto-invite, once confirmed if confirmation is set, skipped with a warning
+// without it, and in strict mode fail the assignment before any member is changed.
 func (e *ExecutorWithMembers) processMemberAssignments(localMembers map[string]string, existingMembers map[string]models.TeamMember) error {
+	if e.strictMembers {
+		var missing []string
+		for memberEmail := range localMembers {
+			if _, exists := existingMembers[models.NormalizeEmail(memberEmail)]; !exists {
+				missing = append(missing, memberEmail)
+			}
+		}
+		if len(missing) == 1 {
+			return fmt.Errorf("member %s not found on team (strict mode)", missing[0])
+		}
+		if len(missing) > 1 {
+			sort.Strings(missing)
+			return fmt.Errorf("members %s not found on team (strict mode)", strings.Join(missing, ", "))
+		}
+	}
+
+	if e.autoInvite && e.confirmInvites != nil {
+		confirmed, err := e.confirmInvitations(localMembers, existingMembers)
+		if err != nil {
+			return err
+		}
+		localMembers = confirmed
+	}
+
 	for memberEmail, roleName := range localMembers {
 		e.logger.Debug("processing member assignment: %s -> %s", memberEmail, roleName)
 
-		// Get role ID
-		role, err := e.client.GetRole(roleName)
+		roleID, err := e.roleID(roleName)
 		if err != nil {
 			return fmt.Errorf("failed to get role %s for member %s: %w", roleName, memberEmail, err)
 		}
-		roleID := role.ID
+		action, err := e.assignMember(memberEmail, roleName, roleID, existingMembers)
+		if err != nil {
+			return err
+		}
+		e.recordAction(action)
+	}
 
-		existingMember, memberExists := existingMembers[memberEmail]
+	return nil
+}
 
-		if memberExists {
-			// Member exists - check if they're already assigned to the correct role
-			if existingMember.PolicyID == roleID {
-				e.logger.Debug("member %s already assigned to role %s (ID: %s), skipping", memberEmail, roleName, roleID)
-			} else {
-				// Member exists but assigned to different role - reassign them
-				e.logger.Debug("reassigning member %s from policy %s to role %s (ID: %s)", memberEmail, existingMember.PolicyID, roleName, roleID)
-				if err := e.client.AssignMemberRole(memberEmail, roleID); err != nil {
-					return fmt.Errorf("failed to assign member %s to role %s: %w", memberEmail, roleName, err)
-				}
-				e.logger.Info("successfully assigned member %s to role %s", memberEmail, roleName)
-			}
-		} else if e.autoInvite {
-			// Member doesn't exist - invite them
-			e.logger.Debug("member %s not found in team, sending invite for role %s", memberEmail, roleName)
-			response, err := e.client.InviteUser(memberEmail, roleID)
-			if err != nil {
-				return fmt.Errorf("failed to invite member %s to role %s: %w", memberEmail, roleName, err)
-			}
-			e.logger.Info("successfully invited member %s to role %s (status: %s)", memberEmail, roleName, response.Status)
+// confirmInvitations asks for confirmation before inviting the members of localMembers
+// who are not on the team, returning the assignments to carry out: all of them if
+// confirmed, or only those of existing members if not
+func (e *ExecutorWithMembers) confirmInvitations(localMembers map[string]string, existingMembers map[string]models.TeamMember) (map[string]string, error) {
+	var invites []string
+	for memberEmail := range localMembers {
+		if _, exists := existingMembers[models.NormalizeEmail(memberEmail)]; !exists {
+			invites = append(invites, memberEmail)
+		}
+	}
+	if len(invites) == 0 {
+		return localMembers, nil
+	}
+	sort.Strings(invites)
+
+	confirmed, err := e.confirmInvites(invites)
+	if err != nil {
+		return nil, err
+	}
+	if confirmed {
+		e.logger.Debug("user confirmed %d invitation(s)", len(invites))
+		return localMembers, nil
+	}
+
+	existing := make(map[string]string, len(localMembers))
+	for memberEmail, roleName := range localMembers {
+		if _, exists := existingMembers[models.NormalizeEmail(memberEmail)]; exists {
+			existing[memberEmail] = roleName
 		} else {
-			// Auto-invite disabled - log warning
-			e.logger.Warn("member %s not found in team for role %s (auto-invite disabled)", memberEmail, roleName)
+			e.logger.Warn("member %s not found in team for role %s (invitation declined)", memberEmail, roleName)
 		}
 	}
+	return existing, nil
+}
 
+// Actions reported by assignMember
+const (
+	AssignmentAssigned  = "assigned"  // The member was moved to the role
+	AssignmentUnchanged = "unchanged" // The member already had the role
+	AssignmentInvited   = "invited"   // The member was not on the team and was invited
+	AssignmentSkipped   = "skipped"   // The member was not on the team and auto-invite is disabled
+)
+
+// assignMember gives one member the role with roleID, inviting them when they are not in
+// existingMembers and auto-invite is enabled, and returns which of the Assignment actions
+// it took
+func (e *ExecutorWithMembers) assignMember(memberEmail, roleName, roleID string, existingMembers map[string]models.TeamMember) (string, error) {
+	existingMember, memberExists := existingMembers[models.NormalizeEmail(memberEmail)]
+
+	if memberExists {
+		// Member exists - check if they're already assigned to the correct role
+		if existingMember.PolicyID == roleID {
+			e.logger.Debug("member %s already assigned to role %s (ID: %s), skipping", memberEmail, roleName, roleID)
+			return AssignmentUnchanged, nil
+		}
+		// Member exists but assigned to different role - reassign them
+		e.logger.Debug("reassigning member %s from policy %s to role %s (ID: %s)", memberEmail, existingMember.PolicyID, roleName, roleID)
+		// The team's casing of the email, which may differ from the role file's
+		if err := e.client.AssignMemberRole(existingMember.Email, roleID); err != nil {
+			return "", fmt.Errorf("failed to assign member %s to role %s: %w", memberEmail, roleName, err)
+		}
+		e.logger.Info("successfully assigned member %s to role %s", memberEmail, roleName)
+		return AssignmentAssigned, nil
+	}
+
+	if e.autoInvite {
+		// Member doesn't exist - invite them
+		e.logger.Debug("member %s not found in team, sending invite for role %s", memberEmail, roleName)
+		if err := e.waitToInvite(); err != nil {
+			return "", fmt.Errorf("failed to invite member %s to role %s: %w", memberEmail, roleName, err)
+		}
+		response, err := e.client.InviteUser(memberEmail, roleID)
+		if err != nil {
+			return "", fmt.Errorf("failed to invite member %s to role %s: %w", memberEmail, roleName, err)
+		}
+		e.logger.Info("successfully invited member %s to role %s (status: %s)", memberEmail, roleName, response.Status)
+		e.recordInvited(memberEmail)
+		return AssignmentInvited, nil
+	}
+
+	// Auto-invite disabled - log warning
+	e.logger.Warn("member %s not found in team for role %s (auto-invite disabled)", memberEmail, roleName)
+	return AssignmentSkipped, nil
+}
+
+// waitToInvite blocks until the invite rate allows another invitation, returning an
+// *InterruptedError if execution is interrupted while waiting
+func (e *ExecutorWithMembers) waitToInvite() error {
+	if e.inviteInterval > 0 && !e.lastInvite.IsZero() {
+		if wait := e.inviteInterval - time.Since(e.lastInvite); wait > 0 {
+			e.logger.Debug("waiting %s before the next invitation (invite rate limit)", wait)
+			ctx := e.ctx
+			if ctx == nil {
+				ctx = context.Background()
+			}
+			timer := time.NewTimer(wait)
+			defer timer.Stop()
+			select {
+			case <-ctx.Done():
+				return &InterruptedError{Err: ctx.Err(), DuringMembers: true}
+			case <-timer.C:
+			}
+		}
+	}
+	e.lastInvite = time.Now()
 	return nil
 }
 
+// recordInvited records a member invited during the current execution
+func (e *ExecutorWithMembers) recordInvited(email string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.invited = append(e.invited, email)
+}
+
+// recordAction counts a member given one of the Assignment actions during the current execution
+func (e *ExecutorWithMembers) recordAction(action string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	switch action {
+	case AssignmentAssigned:
+		e.counts.Assigned++
+	case AssignmentInvited:
+		e.counts.Invited++
+	case AssignmentSkipped:
+		e.counts.Skipped++
+	}
+}
+
+// memberCounts returns the members counted during the current execution
+func (e *ExecutorWithMembers) memberCounts() MemberCounts {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.counts
+}
+
+// invitedMembers returns a copy of the members invited during the current execution
+func (e *ExecutorWithMembers) invitedMembers() []string {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.invited == nil {
+		return nil
+	}
+	return append([]string{}, e.invited...)
+}
+
 // identifyOrphanedMembers identifies members and invites that should be deleted
 func (e *ExecutorWithMembers) identifyOrphanedMembers(localMembers map[string]string, existingMembers map[string]models.TeamMember) *MemberDeletions {
 	var orphanedUsers []string
 	var orphanedInvites []string
 
+	inLocalRoles := make(map[string]bool, len(localMembers))
+	for memberEmail := range localMembers {
+		inLocalRoles[models.NormalizeEmail(memberEmail)] = true
+	}
+
 	// Find members who exist in team but not in any role files
 	for memberEmail, member := range existingMembers {
-		if _, inLocalRoles := localMembers[memberEmail]; !inLocalRoles {
-			// Only consider them orphaned if they're NOT assigned to any local roles
+		if !inLocalRoles[memberEmail] {
+			// Only consider them orphaned if they're NOT assigned to any local roles, and
+			// their current role is one sync manages
+			if e.unmanagedRoleIDs[member.PolicyID] {
+				e.logger.Debug("keeping %s: assigned to unmanaged role %s", member.Email, member.PolicyID)
+				continue
+			}
 			if member.IsPendingInvite() {
-				orphanedInvites = append(orphanedInvites, memberEmail)
+				orphanedInvites = append(orphanedInvites, member.Email)
 			} else {
-				orphanedUsers = append(orphanedUsers, memberEmail)
+				orphanedUsers = append(orphanedUsers, member.Email)
 			}
 		}
 	}
@@ -640,11 +1334,12 @@ func (e *ExecutorWithMembers) ExecutePlanDryRun(plan SyncPlan) ExecutionResult {
 	e.logger.Info("executing sync plan in dry-run mode with member support: %d creates, %d updates, %d deletes", len(plan.Creates), len(plan.Updates), len(plan.Deletes))
 
 	result := ExecutionResult{
-		Created: len(plan.Creates),
-		Updated: len(plan.Updates),
-		Deleted: len(plan.Deletes),
-		DryRun:  true,
-		Error:   nil,
+		Created:    len(plan.Creates),
+		Updated:    len(plan.Updates),
+		Deleted:    len(plan.Deletes),
+		DryRun:     true,
+		Error:      nil,
+		Privileges: ClassifyPlan(plan),
 	}
 
 	e.logger.Debug("dry-run completed - no actual changes made")
@@ -654,51 +1349,14 @@ func (e *ExecutorWithMembers) ExecutePlanDryRun(plan SyncPlan) ExecutionResult {
 // ExecutePlanDryRunWithDiffs simulates executing a sync plan with detailed diff information including members
 func (e *ExecutorWithMembers) ExecutePlanDryRunWithDiffs(plan SyncPlan) ExecutionResult {
 	result := ExecutionResult{
-		Created: len(plan.Creates),
-		Updated: len(plan.Updates),
-		Deleted: len(plan.Deletes),
-		DryRun:  true,
-		Error:   nil,
-	}
-
-	// Generate detailed diff information
-	detailsBuilder := make([]string, 0)
-
-	// Add create details
-	for _, role := range plan.Creates {
-		detailsBuilder = append(detailsBuilder,
-			fmt.Sprintf("CREATE: %s (allowed: %v, denied: %v, members: %v)",
-				role.Name, role.Resources.Allowed, role.Resources.Denied, role.Members))
-	}
-
-	// Add update details with diffs
-	for _, update := range plan.Updates {
-		detailsBuilder = append(detailsBuilder, fmt.Sprintf("UPDATE: %s", update.Name))
-
-		// Compare allowed resources
-		allowedDiff := generateResourceDiff("allowed", update.Remote.Resources.Allowed, update.Local.Resources.Allowed)
-		if allowedDiff != "" {
-			detailsBuilder = append(detailsBuilder, fmt.Sprintf("  %s", allowedDiff))
-		}
-
-		// Compare denied resources
-		deniedDiff := generateResourceDiff("denied", update.Remote.Resources.Denied, update.Local.Resources.Denied)
-		if deniedDiff != "" {
-			detailsBuilder = append(detailsBuilder, fmt.Sprintf("  %s", deniedDiff))
-		}
-
-		// Compare members
-		membersDiff := generateResourceDiff("members", update.Remote.Members, update.Local.Members)
-		if membersDiff != "" {
-			detailsBuilder = append(detailsBuilder, fmt.Sprintf("  %s", membersDiff))
-		}
-	}
-
-	// Add delete details
-	for _, roleName := range plan.Deletes {
-		detailsBuilder = append(detailsBuilder, fmt.Sprintf("DELETE: %s", roleName))
+		Created:    len(plan.Creates),
+		Updated:    len(plan.Updates),
+		Deleted:    len(plan.Deletes),
+		DryRun:     true,
+		Error:      nil,
+		Privileges: ClassifyPlan(plan),
 	}
 
-	result.DetailedInfo = strings.Join(detailsBuilder, "\n")
+	result.DetailedInfo = DescribePlan(plan, true)
 	return result
 }
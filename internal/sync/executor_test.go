@@ -4,7 +4,11 @@ import (
 	"bytes"
 	"context"
 	"errors"
+	"fmt"
+	"strings"
+	gosync "sync"
 	"testing"
+	"time"
 
 	"replbac/internal/logging"
 	"replbac/internal/models"
@@ -1315,3 +1319,598 @@ func _TestExecutorWithMembers_AutoInviteDisabled(t *testing.T) {
 	}
 }
 */
+
+func TestDescribePlan(t *testing.T) {
+	plan := SyncPlan{
+		Creates: []models.Role{{Name: "new", Resources: models.Resources{Allowed: []string{"read"}}, Members: []string{"a@example.com"}}},
+		Updates: []RoleUpdate{{
+			Name:   "editor",
+			Local:  models.Role{Name: "editor", Resources: models.Resources{Allowed: []string{"read", "write"}}, Members: []string{"b@example.com"}},
+			Remote: models.Role{Name: "editor", Resources: models.Resources{Allowed: []string{"admin"}}},
+		}},
+		Deletes: []string{"old"},
+	}
+
+	withMembers := DescribePlan(plan, true)
+	expected := "CREATE: new (allowed: [read], denied: [], members: [a@example.com])\n" +
+		"UPDATE: editor [mixed]\n" +
+		"  + allowed: read\n" +
+		"  + allowed: write\n" +
+		"  - allowed: admin\n" +
+		"  + members: b@example.com\n" +
+		"DELETE: old"
+	if withMembers != expected {
+		t.Errorf("DescribePlan(includeMembers=true) =\n%s\nwant\n%s", withMembers, expected)
+	}
+
+	withoutMembers := DescribePlan(plan, false)
+	if strings.Contains(withoutMembers, "members") {
+		t.Errorf("DescribePlan(includeMembers=false) should not mention members:\n%s", withoutMembers)
+	}
+}
+
+func TestDescribePlanSummary(t *testing.T) {
+	plan := SyncPlan{
+		Creates: []models.Role{{Name: "new", Resources: models.Resources{Allowed: []string{"a", "b"}}, Members: []string{"x@example.com"}}},
+		Updates: []RoleUpdate{
+			{
+				Name:   "editor",
+				Local:  models.Role{Name: "editor", Resources: models.Resources{Allowed: []string{"a", "b", "c"}, Denied: []string{}}, Members: []string{"y@example.com", "z@example.com"}},
+				Remote: models.Role{Name: "editor", Resources: models.Resources{Allowed: []string{"a"}, Denied: []string{"d", "e", "f"}}},
+			},
+		},
+		Deletes: []string{"old"},
+	}
+
+	expected := "CREATE: new (+2 allowed, +0 denied, +1 members)\n" +
+		"UPDATE: editor (+2 allowed, -3 denied, +2 members) [escalation]\n" +
+		"DELETE: old"
+	if got := DescribePlanSummary(plan, true); got != expected {
+		t.Errorf("DescribePlanSummary(includeMembers=true) =\n%s\nwant\n%s", got, expected)
+	}
+
+	expectedWithoutMembers := "CREATE: new (+2 allowed, +0 denied)\n" +
+		"UPDATE: editor (+2 allowed, -3 denied) [escalation]\n" +
+		"DELETE: old"
+	if got := DescribePlanSummary(plan, false); got != expectedWithoutMembers {
+		t.Errorf("DescribePlanSummary(includeMembers=false) =\n%s\nwant\n%s", got, expectedWithoutMembers)
+	}
+}
+
+func TestDescribePlan_SourceFiles(t *testing.T) {
+	plan := SyncPlan{
+		Creates: []models.Role{{Name: "new", Resources: models.Resources{Allowed: []string{"read"}}, SourceFile: "roles/new.yaml"}},
+		Updates: []RoleUpdate{{
+			Name:   "admin",
+			Local:  models.Role{Name: "admin", Resources: models.Resources{Allowed: []string{"read"}}, SourceFile: "roles/prod/admin.yaml"},
+			Remote: models.Role{Name: "admin", Resources: models.Resources{Allowed: []string{"read", "write"}}},
+		}},
+	}
+
+	expected := "CREATE: new (allowed: [read], denied: []) (from roles/new.yaml)\n" +
+		"UPDATE: admin [reduction] (from roles/prod/admin.yaml)\n" +
+		"  - allowed: write"
+	if got := DescribePlan(plan, false); got != expected {
+		t.Errorf("DescribePlan() =\n%s\nwant\n%s", got, expected)
+	}
+
+	expectedSummary := "CREATE: new (+1 allowed, +0 denied) (from roles/new.yaml)\n" +
+		"UPDATE: admin (-1 allowed) [reduction] (from roles/prod/admin.yaml)"
+	if got := DescribePlanSummary(plan, false); got != expectedSummary {
+		t.Errorf("DescribePlanSummary() =\n%s\nwant\n%s", got, expectedSummary)
+	}
+
+	// The source file is not part of the role, so it never causes an update by itself
+	if !RolesEqual(plan.Creates[0], models.Role{Name: "new", Resources: models.Resources{Allowed: []string{"read"}}}) {
+		t.Error("RolesEqual should ignore the source file")
+	}
+}
+
+func TestExecutorWithMembers_RecordInvitedConcurrently(t *testing.T) {
+	executor := NewExecutorWithMembersAndInvite(&MockAPIClientWithMembers{}, createTestLogger(), true)
+
+	var wg gosync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			executor.recordInvited(fmt.Sprintf("user%d@example.com", i))
+		}(i)
+	}
+	wg.Wait()
+
+	if invited := executor.invitedMembers(); len(invited) != 50 {
+		t.Errorf("Expected 50 invited members, got %d", len(invited))
+	}
+}
+
+// forbiddenError mimics an API error for a token that lacks permission
+type forbiddenError struct{}
+
+func (forbiddenError) Error() string   { return "authentication failed (status 403: Forbidden)" }
+func (forbiddenError) Forbidden() bool { return true }
+
+func TestExecutorWithMembers_TeamMembersUnreadable(t *testing.T) {
+	tests := []struct {
+		name        string
+		listErr     error
+		skipOnError bool
+		wantSkipped bool
+	}{
+		{name: "forbidden listing skips member sync", listErr: fmt.Errorf("request failed: %w", forbiddenError{}), wantSkipped: true},
+		{name: "other failure fails the execution", listErr: errors.New("connection reset")},
+		{name: "other failure skips member sync when enabled", listErr: errors.New("connection reset"), skipOnError: true, wantSkipped: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			created := 0
+			mockClient := &MockAPIClientWithMembers{
+				MockAPIClient: MockAPIClient{
+					CreateRoleFunc: func(role models.Role) error {
+						created++
+						return nil
+					},
+				},
+				GetTeamMembersFunc: func() ([]models.TeamMember, error) {
+					return nil, tt.listErr
+				},
+			}
+			executor := NewExecutorWithMembers(mockClient, createTestLogger())
+			executor.SetSkipMembersOnError(tt.skipOnError)
+
+			role := models.Role{Name: "admin", Resources: models.Resources{Allowed: []string{"*"}}, Members: []string{"a@example.com"}}
+			result := executor.ExecutePlanWithLocalRoles(SyncPlan{Creates: []models.Role{role}}, []models.Role{role})
+
+			if created != 1 || result.Created != 1 {
+				t.Errorf("expected the role to be created, got %d API calls and Created=%d", created, result.Created)
+			}
+			if tt.wantSkipped {
+				if result.Error != nil {
+					t.Errorf("expected no error, got %v", result.Error)
+				}
+				if result.MembersSkipped == nil || !strings.Contains(result.MembersSkipped.Error(), "failed to get team members") {
+					t.Errorf("expected MembersSkipped to describe the failure, got %v", result.MembersSkipped)
+				}
+				if len(mockClient.AssignedMembers) != 0 || len(mockClient.InvitedMembers) != 0 {
+					t.Errorf("expected no member changes, got assignments %v and invites %v", mockClient.AssignedMembers, mockClient.InvitedMembers)
+				}
+				return
+			}
+			if result.Error == nil || !strings.Contains(result.Error.Error(), "failed to sync members: failed to get team members: connection reset") {
+				t.Errorf("expected member sync failure, got %v", result.Error)
+			}
+			if result.MembersSkipped != nil {
+				t.Errorf("expected MembersSkipped to be unset, got %v", result.MembersSkipped)
+			}
+		})
+	}
+}
+
+func TestExecutePlanDryRunWithDiffs_StableOrder(t *testing.T) {
+	creates := []models.Role{{Name: "charlie"}, {Name: "alpha"}, {Name: "bravo"}}
+	updates := []RoleUpdate{
+		{Name: "zulu", Local: models.Role{Name: "zulu", Resources: models.Resources{Allowed: []string{"b", "a"}}}, Remote: models.Role{Name: "zulu"}},
+		{Name: "yankee", Local: models.Role{Name: "yankee", Resources: models.Resources{Denied: []string{"x"}}}, Remote: models.Role{Name: "yankee"}},
+	}
+	deletes := []string{"old-2", "old-1", "old-3"}
+
+	want := strings.Join([]string{
+		"CREATE: alpha (allowed: [], denied: [])",
+		"CREATE: bravo (allowed: [], denied: [])",
+		"CREATE: charlie (allowed: [], denied: [])",
+		"UPDATE: yankee [reduction]",
+		"  + denied: x",
+		"UPDATE: zulu [escalation]",
+		"  + allowed: a",
+		"  + allowed: b",
+		"DELETE: old-1",
+		"DELETE: old-2",
+		"DELETE: old-3",
+	}, "\n")
+
+	executor := NewExecutor(&MockAPIClient{}, createTestLogger())
+	for _, order := range [][3]int{{0, 1, 2}, {2, 0, 1}, {1, 2, 0}} {
+		plan := SyncPlan{
+			Creates: []models.Role{creates[order[0]], creates[order[1]], creates[order[2]]},
+			Updates: []RoleUpdate{updates[order[0]%2], updates[(order[0]+1)%2]},
+			Deletes: []string{deletes[order[0]], deletes[order[1]], deletes[order[2]]},
+		}
+		result := executor.ExecutePlanDryRunWithDiffs(plan)
+		if result.DetailedInfo != want {
+			t.Errorf("order %v: DetailedInfo =\n%s\nwant\n%s", order, result.DetailedInfo, want)
+		}
+		if plan.Creates[0].Name != creates[order[0]].Name {
+			t.Errorf("order %v: describing the plan reordered the caller's plan", order)
+		}
+	}
+}
+
+func TestDescribePlanWithNames(t *testing.T) {
+	remote := models.Role{
+		Name:    "viewer",
+		Members: []string{"bob@example.com"},
+		MemberDetails: []models.TeamMember{
+			{ID: "bob@example.com", Name: "Bob Jones", Username: "bob"},
+		},
+	}
+	admin := models.Role{
+		Name:          "admin",
+		Members:       []string{"alice@example.com"},
+		MemberDetails: []models.TeamMember{{ID: "alice@example.com", Name: "Alice Smith"}},
+	}
+	plan := SyncPlan{
+		Creates: []models.Role{{Name: "ops", Members: []string{"alice@example.com", "new@example.com"}}},
+		Updates: []RoleUpdate{{
+			Name:   "viewer",
+			Local:  models.Role{Name: "viewer", Members: []string{"alice@example.com"}},
+			Remote: remote,
+		}},
+	}
+
+	want := strings.Join([]string{
+		"CREATE: ops (allowed: [], denied: [], members: [alice@example.com (Alice Smith) new@example.com])",
+		"UPDATE: viewer",
+		"  + members: alice@example.com (Alice Smith)",
+		"  - members: bob@example.com (Bob Jones, @bob)",
+	}, "\n")
+
+	got := DescribePlanWithNames(plan, true, MemberNames([]models.Role{admin, remote}))
+	if got != want {
+		t.Errorf("DescribePlanWithNames() =\n%s\nwant\n%s", got, want)
+	}
+
+	if plain := DescribePlan(plan, true); strings.Contains(plain, "Alice Smith") {
+		t.Errorf("Expected DescribePlan to show bare emails, got:\n%s", plain)
+	}
+}
+
+func TestExecutor_ExecutePlanInterrupted(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Cancel while the second create is in flight, as Ctrl-C would
+	mockClient := &MockAPIClient{}
+	mockClient.CreateRoleFunc = func(role models.Role) error {
+		if len(mockClient.CreatedRoles) == 2 {
+			cancel()
+		}
+		return nil
+	}
+
+	plan := SyncPlan{
+		Creates: []models.Role{{Name: "a"}, {Name: "b"}, {Name: "c"}},
+		Deletes: []string{"old"},
+	}
+	executor := NewExecutor(mockClient, createTestLogger())
+	executor.SetContext(ctx)
+	result := executor.ExecutePlan(plan)
+
+	var interrupted *InterruptedError
+	if !errors.As(result.Error, &interrupted) {
+		t.Fatalf("Expected an InterruptedError, got %v", result.Error)
+	}
+	if !errors.Is(result.Error, context.Canceled) {
+		t.Errorf("Expected the error to wrap context.Canceled, got %v", result.Error)
+	}
+	if result.Created != 2 || len(mockClient.CreatedRoles) != 2 || len(mockClient.DeletedRoles) != 0 {
+		t.Errorf("Expected the in-flight create to finish and nothing after it, got %d created, calls %v / %v", result.Created, mockClient.CreatedRoles, mockClient.DeletedRoles)
+	}
+	expected := "interrupted after creating 2 of 3 role(s), deleting 0 of 1 role(s)"
+	if result.Error.Error() != expected {
+		t.Errorf("Error() = %q, want %q", result.Error.Error(), expected)
+	}
+}
+
+func TestExecutorWithMembers_InterruptedBeforeMemberSync(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	mockClient := &MockAPIClientWithMembers{}
+	mockClient.CreateRoleFunc = func(role models.Role) error {
+		cancel()
+		return nil
+	}
+	membersListed := false
+	mockClient.GetTeamMembersFunc = func() ([]models.TeamMember, error) {
+		membersListed = true
+		return nil, nil
+	}
+
+	localRoles := []models.Role{{Name: "a", Members: []string{"a@example.com"}}}
+	executor := NewExecutorWithMembers(mockClient, createTestLogger())
+	executor.SetContext(ctx)
+	result := executor.ExecutePlanWithLocalRoles(SyncPlan{Creates: localRoles}, localRoles)
+
+	if result.Error == nil || result.Error.Error() != "interrupted after creating 1 of 1 role(s), before member sync" {
+		t.Fatalf("Expected interruption before member sync, got %v", result.Error)
+	}
+	if result.Created != 1 {
+		t.Errorf("Expected the completed create to be counted, got %d", result.Created)
+	}
+	if membersListed {
+		t.Error("Expected member sync not to start after the interruption")
+	}
+}
+
+func TestExecutor_ExecutePlanUpdateWithoutLocalID(t *testing.T) {
+	// Role files usually omit the ID, which only the remote role carries
+	local := []models.Role{{Name: "viewer", Resources: models.Resources{Allowed: []string{"kots/app/*/read", "kots/app/*/list"}}}}
+	remote := []models.Role{{ID: "viewer-id", Name: "viewer", Resources: models.Resources{Allowed: []string{"kots/app/*/read"}}}}
+
+	plan, err := CompareRoles(local, remote)
+	if err != nil {
+		t.Fatalf("CompareRoles() error = %v", err)
+	}
+
+	mockClient := &MockAPIClient{}
+	mockClient.UpdateRoleFunc = func(role models.Role) error {
+		if role.ID == "" {
+			return errors.New("role ID is required for update")
+		}
+		return nil
+	}
+	result := NewExecutor(mockClient, createTestLogger()).ExecutePlan(plan)
+
+	if result.Error != nil {
+		t.Fatalf("ExecutePlan() error = %v", result.Error)
+	}
+	if result.Updated != 1 || len(mockClient.UpdatedRoles) != 1 {
+		t.Fatalf("Expected one update, got %d (calls %v)", result.Updated, mockClient.UpdatedRoles)
+	}
+	updated := mockClient.UpdatedRoles[0]
+	if updated.ID != "viewer-id" {
+		t.Errorf("Expected the remote ID to be used, got %q", updated.ID)
+	}
+	if !ResourcesEqual(updated.Resources, local[0].Resources) {
+		t.Errorf("Expected the local resources to be sent, got %v", updated.Resources)
+	}
+}
+
+func TestExecutorWithMembers_MissingMemberModes(t *testing.T) {
+	tests := []struct {
+		name        string
+		autoInvite  bool
+		strict      bool
+		wantInvited bool
+		wantError   string
+	}{
+		{name: "auto-invite invites the missing member", autoInvite: true, wantInvited: true},
+		{name: "no-invite skips the missing member"},
+		{name: "strict fails on the missing member even with auto-invite", autoInvite: true, strict: true, wantError: "member typo@example.com not found on team (strict mode)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockClient := &MockAPIClientWithMembers{
+				GetTeamMembersFunc: func() ([]models.TeamMember, error) {
+					return []models.TeamMember{{ID: "alice@example.com", Email: "alice@example.com", PolicyID: "old-role"}}, nil
+				},
+			}
+			executor := NewExecutorWithMembersAndInvite(mockClient, createTestLogger(), tt.autoInvite)
+			executor.SetStrictMembers(tt.strict)
+
+			role := models.Role{Name: "editor", Members: []string{"alice@example.com", "typo@example.com"}}
+			result := executor.ExecutePlanWithLocalRoles(SyncPlan{}, []models.Role{role})
+
+			if _, invited := mockClient.InvitedMembers["typo@example.com"]; invited != tt.wantInvited {
+				t.Errorf("expected invited=%v, got invites %v", tt.wantInvited, mockClient.InvitedMembers)
+			}
+			if tt.wantError != "" {
+				if result.Error == nil || !strings.Contains(result.Error.Error(), tt.wantError) {
+					t.Errorf("expected error containing %q, got %v", tt.wantError, result.Error)
+				}
+				if len(mockClient.AssignedMembers) != 0 {
+					t.Errorf("expected no member to be assigned in strict mode, got %v", mockClient.AssignedMembers)
+				}
+				return
+			}
+			if result.Error != nil {
+				t.Fatalf("unexpected error: %v", result.Error)
+			}
+			if assigned := mockClient.AssignedMembers["mock-id-editor"]; len(assigned) != 1 || assigned[0] != "alice@example.com" {
+				t.Errorf("expected the existing member to be assigned, got %v", mockClient.AssignedMembers)
+			}
+		})
+	}
+}
+
+func TestExecutorWithMembers_InviteRate(t *testing.T) {
+	role := models.Role{Name: "viewer", Members: []string{"a@example.com", "b@example.com", "c@example.com"}}
+
+	t.Run("spaces invitations by the rate", func(t *testing.T) {
+		mockClient := &MockAPIClientWithMembers{}
+		executor := NewExecutorWithMembers(mockClient, createTestLogger())
+		executor.SetInviteRate(20) // one every 50ms
+
+		start := time.Now()
+		result := executor.ExecutePlanWithLocalRoles(SyncPlan{}, []models.Role{role})
+		if result.Error != nil {
+			t.Fatalf("unexpected error: %v", result.Error)
+		}
+		if len(mockClient.InvitedMembers) != 3 {
+			t.Fatalf("expected 3 invitations, got %v", mockClient.InvitedMembers)
+		}
+		// The first invitation is immediate; the other two each wait one interval
+		if elapsed := time.Since(start); elapsed < 100*time.Millisecond {
+			t.Errorf("expected invitations to take at least 100ms, took %s", elapsed)
+		}
+	})
+
+	t.Run("stops waiting when interrupted", func(t *testing.T) {
+		mockClient := &MockAPIClientWithMembers{}
+		executor := NewExecutorWithMembers(mockClient, createTestLogger())
+		executor.SetInviteRate(0.01) // one every 100s
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+		executor.SetContext(ctx)
+
+		result := executor.ExecutePlanWithLocalRoles(SyncPlan{}, []models.Role{role})
+		var interrupted *InterruptedError
+		if !errors.As(result.Error, &interrupted) || !errors.Is(result.Error, context.DeadlineExceeded) {
+			t.Fatalf("expected the wait to end with an interruption, got %v", result.Error)
+		}
+		if result.Error.Error() != "interrupted during member sync" {
+			t.Errorf("Error() = %q, want %q", result.Error.Error(), "interrupted during member sync")
+		}
+		if len(mockClient.InvitedMembers) != 1 {
+			t.Errorf("expected only the first invitation to be sent, got %v", mockClient.InvitedMembers)
+		}
+	})
+}
+
+func TestExecutorWithMembers_InviteConfirmation(t *testing.T) {
+	tests := []struct {
+		name        string
+		answer      bool
+		wantInvited bool
+	}{
+		{name: "confirmed invitations are sent", answer: true, wantInvited: true},
+		{name: "declined invitations are skipped", answer: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockClient := &MockAPIClientWithMembers{
+				GetTeamMembersFunc: func() ([]models.TeamMember, error) {
+					return []models.TeamMember{{ID: "alice@example.com", Email: "alice@example.com", PolicyID: "old-role"}}, nil
+				},
+			}
+			var asked []string
+			executor := NewExecutorWithMembers(mockClient, createTestLogger())
+			executor.SetInviteConfirmation(func(emails []string) (bool, error) {
+				asked = emails
+				return tt.answer, nil
+			})
+
+			role := models.Role{Name: "editor", Members: []string{"alice@example.com", "zoe@example.com", "bob@example.com"}}
+			result := executor.ExecutePlanWithLocalRoles(SyncPlan{}, []models.Role{role})
+			if result.Error != nil {
+				t.Fatalf("unexpected error: %v", result.Error)
+			}
+
+			if len(asked) != 2 || asked[0] != "bob@example.com" || asked[1] != "zoe@example.com" {
+				t.Errorf("expected to be asked about bob and zoe in order, got %v", asked)
+			}
+			if invited := len(mockClient.InvitedMembers) == 2; invited != tt.wantInvited {
+				t.Errorf("expected invited=%v, got invites %v", tt.wantInvited, mockClient.InvitedMembers)
+			}
+			if assigned := mockClient.AssignedMembers["mock-id-editor"]; len(assigned) != 1 || assigned[0] != "alice@example.com" {
+				t.Errorf("expected the existing member to be assigned either way, got %v", mockClient.AssignedMembers)
+			}
+		})
+	}
+}
+
+// creatorWithID reports a fixed ID for every role it creates
+type creatorWithID struct {
+	*MockAPIClientWithMembers
+}
+
+func (c creatorWithID) CreateRoleWithID(role models.Role) (string, error) {
+	return "created-" + role.Name, c.CreateRole(role)
+}
+
+func TestExecutorWithMembers_RoleIDsWithoutLookups(t *testing.T) {
+	lookups := 0
+	mockClient := &MockAPIClientWithMembers{
+		GetTeamMembersFunc: func() ([]models.TeamMember, error) {
+			var team []models.TeamMember
+			for _, email := range []string{"a@example.com", "b@example.com", "c@example.com", "d@example.com"} {
+				team = append(team, models.TeamMember{ID: email, Email: email})
+			}
+			return team, nil
+		},
+	}
+	mockClient.GetRoleFunc = func(roleName string) (models.Role, error) {
+		lookups++
+		return models.Role{Name: roleName, ID: "looked-up-" + roleName}, nil
+	}
+
+	remote := []models.Role{
+		{ID: "remote-viewer", Name: "viewer"},
+		{ID: "remote-editor", Name: "editor"},
+	}
+	local := []models.Role{
+		{Name: "viewer", Members: []string{"a@example.com", "b@example.com"}},
+		{Name: "editor", Resources: models.Resources{Allowed: []string{"**/*"}}, Members: []string{"c@example.com"}},
+		{Name: "admin", Members: []string{"d@example.com"}},
+	}
+	plan := SyncPlan{
+		Creates: []models.Role{local[2]},
+		Updates: []RoleUpdate{{Name: "editor", Local: local[1], Remote: remote[1]}},
+	}
+
+	t.Run("known IDs are not looked up", func(t *testing.T) {
+		lookups = 0
+		mockClient.AssignedMembers = nil
+		executor := NewExecutorWithMembers(creatorWithID{mockClient}, createTestLogger())
+		executor.SetRemoteRoles(remote)
+
+		result := executor.ExecutePlanWithLocalRoles(plan, local)
+		if result.Error != nil {
+			t.Fatalf("unexpected error: %v", result.Error)
+		}
+		if lookups != 0 {
+			t.Errorf("expected no role lookups, got %d", lookups)
+		}
+		for id, count := range map[string]int{"remote-viewer": 2, "remote-editor": 1, "created-admin": 1} {
+			if len(mockClient.AssignedMembers[id]) != count {
+				t.Errorf("expected %d member(s) assigned to %s, got %v", count, id, mockClient.AssignedMembers)
+			}
+		}
+	})
+
+	t.Run("unknown IDs are looked up once per role", func(t *testing.T) {
+		lookups = 0
+		mockClient.AssignedMembers = nil
+		executor := NewExecutorWithMembers(mockClient, createTestLogger())
+
+		result := executor.ExecutePlanWithLocalRoles(SyncPlan{}, local)
+		if result.Error != nil {
+			t.Fatalf("unexpected error: %v", result.Error)
+		}
+		if lookups != 3 {
+			t.Errorf("expected one lookup for each of 3 roles, got %d", lookups)
+		}
+		if len(mockClient.AssignedMembers["looked-up-viewer"]) != 2 {
+			t.Errorf("expected both viewers assigned to the looked-up ID, got %v", mockClient.AssignedMembers)
+		}
+	})
+}
+
+func TestMemberEmailCase(t *testing.T) {
+	remote := models.Role{Name: "viewer", Members: []string{"bob@example.com", "carol@example.com"}}
+	local := models.Role{Name: "viewer", Members: []string{"Bob@Example.com", "dave@example.com"}}
+	plan := SyncPlan{Updates: []RoleUpdate{{Name: "viewer", Local: local, Remote: remote}}}
+
+	want := strings.Join([]string{
+		"UPDATE: viewer",
+		"  + members: dave@example.com",
+		"  - members: carol@example.com",
+	}, "\n")
+	if got := DescribePlan(plan, true); got != want {
+		t.Errorf("DescribePlan() =\n%s\nwant\n%s", got, want)
+	}
+	if reason := UpdateReason(local, remote); reason != "members differ (remote missing 'dave@example.com'; remote has extra 'carol@example.com')" {
+		t.Errorf("Unexpected update reason %q", reason)
+	}
+
+	// A member on the team under different casing is neither invited nor orphaned
+	mockClient := &MockAPIClientWithMembers{
+		GetTeamMembersFunc: func() ([]models.TeamMember, error) {
+			return []models.TeamMember{{ID: "bob@example.com", Email: "bob@example.com", PolicyID: "mock-id-viewer"}}, nil
+		},
+	}
+	executor := NewExecutorWithMembersAndInvite(mockClient, createTestLogger(), true)
+	result := executor.ExecutePlanWithLocalRoles(SyncPlan{}, []models.Role{{Name: "viewer", Members: []string{"Bob@Example.com"}}})
+	if result.Error != nil {
+		t.Fatalf("unexpected error: %v", result.Error)
+	}
+	if len(mockClient.InvitedMembers) != 0 || len(mockClient.AssignedMembers) != 0 {
+		t.Errorf("expected no invites or assignments, got invites %v and assignments %v", mockClient.InvitedMembers, mockClient.AssignedMembers)
+	}
+	if result.MemberDeletions != nil && len(result.MemberDeletions.OrphanedUsers) != 0 {
+		t.Errorf("expected no orphaned users, got %v", result.MemberDeletions.OrphanedUsers)
+	}
+}
@@ -0,0 +1,101 @@
+package sync
+
+import (
+	"fmt"
+	"strings"
+
+	"replbac/internal/models"
+)
+
+// Reasons recorded for roles that are created or deleted
+const (
+	ReasonNoRemoteRole = "no remote role with this name"
+	ReasonNoLocalFile  = "no local file"
+)
+
+// Explanation records why a role was placed in a sync plan
+type Explanation struct {
+	Operation string `json:"operation"` // OperationCreate, OperationUpdate, or OperationDelete
+	Role      string `json:"role"`
+	Reason    string `json:"reason"`
+}
+
+// String formats the explanation, e.g. "update editor: allowed differs (remote missing 'create')"
+func (e Explanation) String() string {
+	return fmt.Sprintf("%s %s: %s", e.Operation, e.Role, e.Reason)
+}
+
+// ExplainPlan returns the reason for every change in a plan, in plan order
+func ExplainPlan(plan SyncPlan) []Explanation {
+	explanations := make([]Explanation, 0, len(plan.Creates)+len(plan.Updates)+len(plan.Deletes))
+	for _, role := range plan.Creates {
+		explanations = append(explanations, Explanation{Operation: OperationCreate, Role: role.Name, Reason: ReasonNoRemoteRole})
+	}
+	for _, update := range plan.Updates {
+		reason := update.Reason
+		if reason == "" {
+			reason = UpdateReason(update.Local, update.Remote)
+		}
+		explanations = append(explanations, Explanation{Operation: OperationUpdate, Role: update.Name, Reason: reason})
+	}
+	for _, roleName := range plan.Deletes {
+		explanations = append(explanations, Explanation{Operation: OperationDelete, Role: roleName, Reason: ReasonNoLocalFile})
+	}
+	return explanations
+}
+
+// UpdateReason describes which fields of a local role differ from its remote counterpart
+func UpdateReason(local, remote models.Role) string {
+	var reasons []string
+	if local.Name != remote.Name {
+		reasons = append(reasons, fmt.Sprintf("name differs (remote '%s')", remote.Name))
+	}
+	reasons = appendFieldReason(reasons, "allowed differs", remote.Resources.Allowed, local.Resources.Allowed)
+	reasons = appendFieldReason(reasons, "denied differs", remote.Resources.Denied, local.Resources.Denied)
+	if !MembersEqual(remote.Members, local.Members) {
+		missing, extra := memberChanges(remote.Members, local.Members)
+		reasons = appendChangeReason(reasons, "members differ", missing, extra)
+	}
+	reasons = appendFieldReason(reasons, "labels differ", remote.LabelPairs(), local.LabelPairs())
+	if len(reasons) == 0 {
+		return "no differences"
+	}
+	return strings.Join(reasons, ", ")
+}
+
+// appendFieldReason appends a reason for a list field that differs, naming the entries
+// the remote is missing and those it has in addition to the local list
+func appendFieldReason(reasons []string, label string, remoteValues, localValues []string) []string {
+	if StringSlicesEqual(remoteValues, localValues) {
+		return reasons
+	}
+
+	missing, extra := resourceChanges(remoteValues, localValues)
+	return appendChangeReason(reasons, label, missing, extra)
+}
+
+// appendChangeReason appends a reason for a list field that differs, given the entries
+// the remote is missing and those it has in addition to the local list
+func appendChangeReason(reasons []string, label string, missing, extra []string) []string {
+	var details []string
+	if len(missing) > 0 {
+		details = append(details, "remote missing "+quoteAll(missing))
+	}
+	if len(extra) > 0 {
+		details = append(details, "remote has extra "+quoteAll(extra))
+	}
+	if len(details) == 0 {
+		// Only the number of repeated entries differs
+		details = append(details, "duplicate entries")
+	}
+	return append(reasons, fmt.Sprintf("%s (%s)", label, strings.Join(details, "; ")))
+}
+
+// quoteAll formats values as a comma-separated list of quoted entries
+func quoteAll(values []string) string {
+	quoted := make([]string, len(values))
+	for i, value := range values {
+		quoted[i] = "'" + value + "'"
+	}
+	return strings.Join(quoted, ", ")
+}
@@ -0,0 +1,85 @@
+package sync
+
+import (
+	"reflect"
+	"testing"
+
+	"replbac/internal/models"
+)
+
+func TestUpdateReason(t *testing.T) {
+	tests := []struct {
+		name   string
+		local  models.Role
+		remote models.Role
+		want   string
+	}{
+		{
+			name:   "remote missing an allowed resource",
+			local:  models.Role{Name: "editor", Resources: models.Resources{Allowed: []string{"read", "create"}}},
+			remote: models.Role{Name: "editor", Resources: models.Resources{Allowed: []string{"read"}}},
+			want:   "allowed differs (remote missing 'create')",
+		},
+		{
+			name:   "remote has extra denied resources and members",
+			local:  models.Role{Name: "viewer", Members: []string{"a@example.com"}},
+			remote: models.Role{Name: "viewer", Resources: models.Resources{Denied: []string{"admin/**", "kots/**"}}, Members: []string{"a@example.com", "b@example.com"}},
+			want:   "denied differs (remote has extra 'admin/**', 'kots/**'), members differ (remote has extra 'b@example.com')",
+		},
+		{
+			name:   "name differs in case",
+			local:  models.Role{Name: "Admin"},
+			remote: models.Role{Name: "admin"},
+			want:   "name differs (remote 'admin')",
+		},
+		{
+			name:   "duplicate entries only",
+			local:  models.Role{Name: "dup", Resources: models.Resources{Allowed: []string{"read", "read"}}},
+			remote: models.Role{Name: "dup", Resources: models.Resources{Allowed: []string{"read"}}},
+			want:   "allowed differs (duplicate entries)",
+		},
+		{
+			name:   "label value changed",
+			local:  models.Role{Name: "viewer", Labels: map[string]string{"team": "payments", "tier": "prod"}},
+			remote: models.Role{Name: "viewer", Labels: map[string]string{"team": "payments", "tier": "dev"}},
+			want:   "labels differ (remote missing 'tier=prod'; remote has extra 'tier=dev')",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := UpdateReason(tt.local, tt.remote); got != tt.want {
+				t.Errorf("UpdateReason() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExplainPlan(t *testing.T) {
+	local := []models.Role{
+		{Name: "admin", Resources: models.Resources{Allowed: []string{"*"}}},
+		{Name: "editor", Resources: models.Resources{Allowed: []string{"read", "create"}}},
+	}
+	remote := []models.Role{
+		{Name: "editor", Resources: models.Resources{Allowed: []string{"read"}}},
+		{Name: "obsolete"},
+	}
+
+	plan, err := CompareRoles(local, remote)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var got []string
+	for _, explanation := range ExplainPlan(plan) {
+		got = append(got, explanation.String())
+	}
+	want := []string{
+		"create admin: no remote role with this name",
+		"update editor: allowed differs (remote missing 'create')",
+		"delete obsolete: no local file",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ExplainPlan() = %q, want %q", got, want)
+	}
+}
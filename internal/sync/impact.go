@@ -0,0 +1,151 @@
+package sync
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"replbac/internal/models"
+)
+
+// MemberImpact is the net change a sync plan makes to one member's access, from the
+// resources of the role they hold remotely to those of the role they will hold after
+type MemberImpact struct {
+	Member    string   `json:"member"`
+	FromRole  string   `json:"from_role,omitempty"` // Role held remotely, or empty for none
+	ToRole    string   `json:"to_role,omitempty"`   // Role held after the sync, or empty for none
+	Gains     []string `json:"gains,omitempty"`     // Newly allowed resources
+	Unblocked []string `json:"unblocked,omitempty"` // Resources no longer denied
+	Loses     []string `json:"loses,omitempty"`     // Resources no longer allowed
+	Blocked   []string `json:"blocked,omitempty"`   // Newly denied resources
+}
+
+// Escalates reports whether the member gains access, by a newly allowed resource or a
+// deny lifted
+func (m MemberImpact) Escalates() bool {
+	return len(m.Gains) > 0 || len(m.Unblocked) > 0
+}
+
+// String formats the impact, e.g. "alice@example.com: moving from viewer to admin gains **/*"
+func (m MemberImpact) String() string {
+	var move string
+	switch {
+	case m.FromRole == m.ToRole:
+		move = "in " + m.ToRole
+	case m.FromRole == "":
+		move = "joining " + m.ToRole
+	case m.ToRole == "":
+		move = "leaving " + m.FromRole
+	default:
+		move = fmt.Sprintf("moving from %s to %s", m.FromRole, m.ToRole)
+	}
+
+	var changes []string
+	for _, change := range []struct {
+		verb      string
+		resources []string
+	}{
+		{"gains", m.Gains},
+		{"is no longer denied", m.Unblocked},
+		{"loses", m.Loses},
+		{"is now denied", m.Blocked},
+	} {
+		if len(change.resources) > 0 {
+			changes = append(changes, change.verb+" "+strings.Join(change.resources, ", "))
+		}
+	}
+	return fmt.Sprintf("%s: %s %s", m.Member, move, strings.Join(changes, "; "))
+}
+
+// AccessImpact returns the net access change for every member whose effective resources
+// the plan changes, sorted by member. A member's access is that of the role they belong
+// to; remote holds the roles as they are, with members. When includeMembers is false
+// the plan does not reassign members, so only resource changes to their roles count.
+func AccessImpact(plan SyncPlan, remote []models.Role, includeMembers bool) []MemberImpact {
+	before := make(map[string]models.Role, len(remote))
+	after := make(map[string]models.Role, len(remote))
+	membership := make(map[string]string) // Normalized email -> role name
+	emails := make(map[string]string)     // Normalized email -> email as first listed
+	for _, role := range remote {
+		before[role.Name] = role
+		after[role.Name] = role
+		for _, member := range role.Members {
+			key := models.NormalizeEmail(member)
+			if _, found := membership[key]; !found {
+				membership[key] = role.Name
+				emails[key] = member
+			}
+		}
+	}
+
+	// Apply the plan to the roles and, when members are synced, to who holds them
+	newMembership := make(map[string]string, len(membership))
+	for member, roleName := range membership {
+		newMembership[member] = roleName
+	}
+	assign := func(roleName string, members []string) {
+		if !includeMembers {
+			return
+		}
+		listed := make(map[string]bool, len(members))
+		for _, member := range members {
+			key := models.NormalizeEmail(member)
+			listed[key] = true
+			newMembership[key] = roleName
+			if _, found := emails[key]; !found {
+				emails[key] = member
+			}
+		}
+		for member, held := range membership {
+			if held == roleName && !listed[member] && newMembership[member] == roleName {
+				delete(newMembership, member)
+			}
+		}
+	}
+	for _, role := range plan.Creates {
+		after[role.Name] = role
+		assign(role.Name, role.Members)
+	}
+	for _, update := range plan.Updates {
+		after[update.Name] = update.Local
+		assign(update.Name, update.Local.Members)
+	}
+	for _, roleName := range plan.Deletes {
+		delete(after, roleName)
+		for member, held := range newMembership {
+			if held == roleName {
+				delete(newMembership, member)
+			}
+		}
+	}
+
+	members := make(map[string]bool, len(newMembership))
+	for member := range membership {
+		members[member] = true
+	}
+	for member := range newMembership {
+		members[member] = true
+	}
+
+	var impacts []MemberImpact
+	for member := range members {
+		fromRole, toRole := membership[member], newMembership[member]
+		from, to := before[fromRole].Resources, after[toRole].Resources
+		impact := MemberImpact{Member: emails[member], FromRole: fromRole, ToRole: toRole}
+		impact.Gains, impact.Loses = resourceChanges(from.Allowed, to.Allowed)
+		impact.Blocked, impact.Unblocked = resourceChanges(from.Denied, to.Denied)
+		if len(impact.Gains)+len(impact.Unblocked)+len(impact.Loses)+len(impact.Blocked) == 0 {
+			continue
+		}
+		for _, resources := range [][]string{impact.Gains, impact.Unblocked, impact.Loses, impact.Blocked} {
+			sort.Strings(resources)
+		}
+		if toRole != "" {
+			// A soft delete renames the role it disables
+			impact.ToRole = after[toRole].Name
+		}
+		impacts = append(impacts, impact)
+	}
+	sort.Slice(impacts, func(i, j int) bool { return impacts[i].Member < impacts[j].Member })
+	return impacts
+}
@@ -0,0 +1,94 @@
+package sync
+
+import (
+	"reflect"
+	"testing"
+
+	"replbac/internal/models"
+)
+
+func TestAccessImpact(t *testing.T) {
+	remote := []models.Role{
+		{Name: "viewer", Resources: models.Resources{Allowed: []string{"kots/app/*/read"}, Denied: []string{}}, Members: []string{"alice@example.com", "bob@example.com"}},
+		{Name: "admin", Resources: models.Resources{Allowed: []string{"**/*"}, Denied: []string{"team/members/write"}}, Members: []string{"carol@example.com"}},
+	}
+	viewer, admin := remote[0], remote[1]
+
+	tests := []struct {
+		name           string
+		plan           SyncPlan
+		includeMembers bool
+		expected       []MemberImpact
+	}{
+		{
+			name: "moving a member to another role",
+			plan: SyncPlan{Updates: []RoleUpdate{
+				{Name: "viewer", Local: withMembers(viewer, "bob@example.com"), Remote: viewer},
+				{Name: "admin", Local: withMembers(admin, "alice@example.com", "carol@example.com"), Remote: admin},
+			}},
+			includeMembers: true,
+			expected: []MemberImpact{{
+				Member: "alice@example.com", FromRole: "viewer", ToRole: "admin",
+				Gains: []string{"**/*"}, Loses: []string{"kots/app/*/read"}, Blocked: []string{"team/members/write"},
+			}},
+		},
+		{
+			name: "changing a role's resources affects all its members",
+			plan: SyncPlan{Updates: []RoleUpdate{{Name: "admin", Local: models.Role{
+				Name: "admin", Resources: models.Resources{Allowed: []string{"**/*"}, Denied: []string{}},
+			}, Remote: admin}}},
+			expected: []MemberImpact{{
+				Member: "carol@example.com", FromRole: "admin", ToRole: "admin", Unblocked: []string{"team/members/write"},
+			}},
+		},
+		{
+			name: "members of a deleted role lose its access",
+			plan: SyncPlan{Deletes: []string{"admin"}},
+			expected: []MemberImpact{{
+				Member: "carol@example.com", FromRole: "admin", Loses: []string{"**/*"}, Unblocked: []string{"team/members/write"},
+			}},
+		},
+		{
+			name: "a new member of a created role",
+			plan: SyncPlan{Creates: []models.Role{{
+				Name: "support", Resources: models.Resources{Allowed: []string{"team/support-issues/read"}}, Members: []string{"dave@example.com"},
+			}}},
+			includeMembers: true,
+			expected: []MemberImpact{{
+				Member: "dave@example.com", ToRole: "support", Gains: []string{"team/support-issues/read"},
+			}},
+		},
+		{
+			name: "membership changes are ignored when members are not synced",
+			plan: SyncPlan{Updates: []RoleUpdate{
+				{Name: "viewer", Local: withMembers(viewer), Remote: viewer},
+			}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			impacts := AccessImpact(tt.plan, remote, tt.includeMembers)
+			if !reflect.DeepEqual(impacts, tt.expected) {
+				t.Errorf("Expected %+v, got %+v", tt.expected, impacts)
+			}
+		})
+	}
+}
+
+func TestMemberImpactString(t *testing.T) {
+	impact := MemberImpact{Member: "alice@example.com", FromRole: "viewer", ToRole: "admin", Gains: []string{"**/*"}, Loses: []string{"kots/app/*/read"}}
+	expected := "alice@example.com: moving from viewer to admin gains **/*; loses kots/app/*/read"
+	if impact.String() != expected {
+		t.Errorf("Expected %q, got %q", expected, impact.String())
+	}
+	if !impact.Escalates() {
+		t.Errorf("Expected gaining **/* to escalate")
+	}
+}
+
+// withMembers returns a copy of role with its members replaced
+func withMembers(role models.Role, members ...string) models.Role {
+	role.Members = members
+	return role
+}
@@ -10,6 +10,7 @@ func TestExecutorWithMembers_MemberDeletions(t *testing.T) {
 	tests := []struct {
 		name                    string
 		plan                    SyncPlan
+		unmanaged               []models.Role
 		existingMembers         []models.TeamMember
 		expectedOrphanedUsers   []string
 		expectedOrphanedInvites []string
@@ -37,6 +38,29 @@ func TestExecutorWithMembers_MemberDeletions(t *testing.T) {
 			expectedOrphanedInvites: []string{"delete-invite@example.com"},
 			expectError:             false,
 		},
+		{
+			name: "members of unmanaged roles are not orphaned",
+			plan: SyncPlan{
+				Creates: []models.Role{
+					{
+						Name:    "admin",
+						Members: []string{"keep@example.com"},
+					},
+				},
+				Updates: []RoleUpdate{},
+				Deletes: []string{},
+			},
+			unmanaged: []models.Role{{ID: "role-ignored", Name: "ignored"}},
+			existingMembers: []models.TeamMember{
+				{ID: "1", Email: "keep@example.com", Status: "active"},
+				{ID: "2", Email: "ignored-user@example.com", Status: "active", PolicyID: "role-ignored"},
+				{ID: "3", Email: "ignored-invite@example.com", Status: "pending", PolicyID: "role-ignored"},
+				{ID: "4", Email: "delete-user@example.com", Status: "active", PolicyID: "role-managed"},
+			},
+			expectedOrphanedUsers:   []string{"delete-user@example.com"},
+			expectedOrphanedInvites: []string{},
+			expectError:             false,
+		},
 		{
 			name: "no orphans when all members are in roles",
 			plan: SyncPlan{
@@ -77,7 +101,7 @@ func TestExecutorWithMembers_MemberDeletions(t *testing.T) {
 				{ID: "1", Email: "duplicate@example.com", Status: "active"},
 			},
 			expectError:   true,
-			errorContains: "member duplicate@example.com appears in multiple roles",
+			errorContains: "member duplicate@example.com appears in multiple roles: admin, viewer",
 		},
 		{
 			name: "member in update role conflicts with create role - error",
@@ -107,7 +131,7 @@ func TestExecutorWithMembers_MemberDeletions(t *testing.T) {
 				{ID: "1", Email: "conflict@example.com", Status: "active"},
 			},
 			expectError:   true,
-			errorContains: "member conflict@example.com appears in multiple roles",
+			errorContains: "member conflict@example.com appears in multiple roles: admin, viewer",
 		},
 		{
 			name: "orphaned users only",
@@ -174,6 +198,7 @@ func TestExecutorWithMembers_MemberDeletions(t *testing.T) {
 			}
 
 			executor := NewExecutorWithMembersAndInvite(mockClient, createTestLogger(), true)
+			executor.SetUnmanagedRoles(tt.unmanaged)
 			result := executor.ExecutePlan(tt.plan)
 
 			// Check error expectation
@@ -0,0 +1,50 @@
+package sync
+
+// PrivilegeChange classifies how a role update changes the access the role grants,
+// so that reviewers can focus on updates that widen access
+type PrivilegeChange string
+
+// Privilege change classifications
+const (
+	PrivilegeNone       PrivilegeChange = "none"       // Resources are unchanged; only members or the name differ
+	PrivilegeEscalation PrivilegeChange = "escalation" // Adds allowed or removes denied resources
+	PrivilegeReduction  PrivilegeChange = "reduction"  // Removes allowed or adds denied resources
+	PrivilegeMixed      PrivilegeChange = "mixed"      // Both escalates and reduces
+)
+
+// ClassifyUpdate classifies a role update by the direction of its resource changes
+func ClassifyUpdate(update RoleUpdate) PrivilegeChange {
+	changes := update.Diff()
+	escalates := len(changes.AllowedAdded) > 0 || len(changes.DeniedRemoved) > 0
+	reduces := len(changes.AllowedRemoved) > 0 || len(changes.DeniedAdded) > 0
+
+	switch {
+	case escalates && reduces:
+		return PrivilegeMixed
+	case escalates:
+		return PrivilegeEscalation
+	case reduces:
+		return PrivilegeReduction
+	default:
+		return PrivilegeNone
+	}
+}
+
+// ClassifyPlan classifies every update in a plan, keyed by role name
+func ClassifyPlan(plan SyncPlan) map[string]PrivilegeChange {
+	changes := make(map[string]PrivilegeChange, len(plan.Updates))
+	for _, update := range plan.Updates {
+		changes[update.Name] = ClassifyUpdate(update)
+	}
+	return changes
+}
+
+// privilegeTag returns the " [classification]" suffix shown after an update, or an empty
+// string when the update does not change resources
+func privilegeTag(update RoleUpdate) string {
+	change := ClassifyUpdate(update)
+	if change == PrivilegeNone {
+		return ""
+	}
+	return " [" + string(change) + "]"
+}
@@ -0,0 +1,93 @@
+package sync
+
+import (
+	"reflect"
+	"testing"
+
+	"replbac/internal/models"
+)
+
+func TestClassifyUpdate(t *testing.T) {
+	remote := models.Role{
+		Name:      "support",
+		Resources: models.Resources{Allowed: []string{"kots/app/*/read"}, Denied: []string{"kots/app/*/delete"}},
+		Members:   []string{"a@example.com"},
+	}
+
+	tests := []struct {
+		name     string
+		local    models.Resources
+		members  []string
+		expected PrivilegeChange
+	}{
+		{
+			name:     "adding allowed resources escalates",
+			local:    models.Resources{Allowed: []string{"kots/app/*/read", "kots/app/*/write"}, Denied: []string{"kots/app/*/delete"}},
+			expected: PrivilegeEscalation,
+		},
+		{
+			name:     "removing denied resources escalates",
+			local:    models.Resources{Allowed: []string{"kots/app/*/read"}, Denied: []string{}},
+			expected: PrivilegeEscalation,
+		},
+		{
+			name:     "removing allowed resources reduces",
+			local:    models.Resources{Allowed: []string{}, Denied: []string{"kots/app/*/delete"}},
+			expected: PrivilegeReduction,
+		},
+		{
+			name:     "adding denied resources reduces",
+			local:    models.Resources{Allowed: []string{"kots/app/*/read"}, Denied: []string{"kots/app/*/delete", "team/*"}},
+			expected: PrivilegeReduction,
+		},
+		{
+			name:     "escalating and reducing is mixed",
+			local:    models.Resources{Allowed: []string{"kots/app/*/write"}, Denied: []string{"kots/app/*/delete"}},
+			expected: PrivilegeMixed,
+		},
+		{
+			name:     "member-only change does not change privileges",
+			local:    models.Resources{Allowed: []string{"kots/app/*/read"}, Denied: []string{"kots/app/*/delete"}},
+			members:  []string{"b@example.com"},
+			expected: PrivilegeNone,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			local := models.Role{Name: "support", Resources: tt.local, Members: tt.members}
+			update := RoleUpdate{Name: "support", Local: local, Remote: remote}
+
+			if got := ClassifyUpdate(update); got != tt.expected {
+				t.Errorf("ClassifyUpdate() = %q, want %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestDryRunResultPrivileges(t *testing.T) {
+	plan := SyncPlan{
+		Updates: []RoleUpdate{
+			{
+				Name:   "admin",
+				Local:  models.Role{Name: "admin", Resources: models.Resources{Allowed: []string{"**/*"}}},
+				Remote: models.Role{Name: "admin", Resources: models.Resources{Allowed: []string{"kots/*"}}},
+			},
+			{
+				Name:   "viewer",
+				Local:  models.Role{Name: "viewer", Resources: models.Resources{Allowed: []string{"read"}, Denied: []string{"write"}}},
+				Remote: models.Role{Name: "viewer", Resources: models.Resources{Allowed: []string{"read"}}},
+			},
+		},
+	}
+
+	result := NewExecutor(&MockAPIClient{}, createTestLogger()).ExecutePlanDryRunWithDiffs(plan)
+
+	expected := map[string]PrivilegeChange{"admin": PrivilegeMixed, "viewer": PrivilegeReduction}
+	if !reflect.DeepEqual(result.Privileges, expected) {
+		t.Errorf("Privileges = %v, want %v", result.Privileges, expected)
+	}
+	if !containsString(result.DetailedInfo, "UPDATE: admin [mixed]") || !containsString(result.DetailedInfo, "UPDATE: viewer [reduction]") {
+		t.Errorf("Expected classifications in DetailedInfo, got:\n%s", result.DetailedInfo)
+	}
+}
@@ -0,0 +1,59 @@
+package sync
+
+import (
+	"sort"
+
+	"replbac/internal/models"
+)
+
+// ProjectRemote returns the remote roles as they would be after applying plan, sorted by
+// name: created roles are added, updated roles take their local definition under their
+// remote ID, and deleted roles are removed. When includeMembers is false the plan does
+// not manage membership, so updated roles keep their remote members.
+func ProjectRemote(remote []models.Role, plan SyncPlan, includeMembers bool) []models.Role {
+	projected := make(map[string]models.Role, len(remote)+len(plan.Creates))
+	for _, role := range remote {
+		projected[role.Name] = projectedRole(role)
+	}
+
+	for _, role := range plan.Creates {
+		created := projectedRole(role)
+		created.ID = ""
+		if !includeMembers {
+			created.Members = nil
+		}
+		projected[role.Name] = created
+	}
+	for _, update := range plan.Updates {
+		updated := projectedRole(update.Local)
+		updated.ID = update.Remote.ID
+		if !includeMembers {
+			updated.Members = update.Remote.Members
+		}
+		// A soft delete renames the role it disables
+		delete(projected, update.Name)
+		projected[updated.Name] = updated
+	}
+	for _, roleName := range plan.Deletes {
+		delete(projected, roleName)
+	}
+
+	result := make([]models.Role, 0, len(projected))
+	for _, role := range projected {
+		result = append(result, role)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Name < result[j].Name })
+	return result
+}
+
+// projectedRole returns the part of a role that is stored remotely, dropping where it
+// was loaded from and fields that are not synced
+func projectedRole(role models.Role) models.Role {
+	return models.Role{
+		ID:        role.ID,
+		Name:      role.Name,
+		Resources: role.Resources,
+		Members:   role.Members,
+		Labels:    role.Labels,
+	}
+}
@@ -0,0 +1,62 @@
+package sync
+
+import (
+	"reflect"
+	"testing"
+
+	"replbac/internal/models"
+)
+
+func TestProjectRemote(t *testing.T) {
+	viewer := models.Role{ID: "1", Name: "viewer", Resources: models.Resources{Allowed: []string{"kots/app/*/read"}, Denied: []string{}}, Members: []string{"alice@example.com"}}
+	admin := models.Role{ID: "2", Name: "admin", Resources: models.Resources{Allowed: []string{"**/*"}, Denied: []string{}}, Members: []string{"carol@example.com"}}
+	remote := []models.Role{viewer, admin}
+
+	localViewer := models.Role{
+		Name:       "viewer",
+		Resources:  models.Resources{Allowed: []string{"kots/app/*/read", "team/support-issues/read"}, Denied: []string{}},
+		Members:    []string{"bob@example.com"},
+		SourceFile: "viewer.yaml",
+	}
+	support := models.Role{Name: "support", Resources: models.Resources{Allowed: []string{"team/support-issues/read"}}, Members: []string{"dave@example.com"}}
+	plan := SyncPlan{
+		Creates: []models.Role{support},
+		Updates: []RoleUpdate{{Name: "viewer", Local: localViewer, Remote: viewer}},
+		Deletes: []string{"admin"},
+	}
+
+	tests := []struct {
+		name           string
+		includeMembers bool
+		expected       []models.Role
+	}{
+		{
+			name:           "plan applied with members",
+			includeMembers: true,
+			expected: []models.Role{
+				{Name: "support", Resources: support.Resources, Members: []string{"dave@example.com"}},
+				{ID: "1", Name: "viewer", Resources: localViewer.Resources, Members: []string{"bob@example.com"}},
+			},
+		},
+		{
+			name: "updated roles keep their remote members when members are not synced",
+			expected: []models.Role{
+				{Name: "support", Resources: support.Resources},
+				{ID: "1", Name: "viewer", Resources: localViewer.Resources, Members: []string{"alice@example.com"}},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			projected := ProjectRemote(remote, plan, tt.includeMembers)
+			if !reflect.DeepEqual(projected, tt.expected) {
+				t.Errorf("ProjectRemote() = %+v, want %+v", projected, tt.expected)
+			}
+		})
+	}
+
+	if len(remote) != 2 || !reflect.DeepEqual(remote[0], viewer) {
+		t.Errorf("ProjectRemote() modified the remote roles: %+v", remote)
+	}
+}
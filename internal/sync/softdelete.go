@@ -0,0 +1,82 @@
+package sync
+
+import (
+	"fmt"
+	"strings"
+
+	"replbac/internal/models"
+)
+
+// The Replicated API has no disabled or archived state for policies, so soft deletion
+// is emulated: the policy is renamed with SoftDeletePrefix and every resource is denied.
+// The policy, its ID, and its member assignments are retained for auditing.
+
+// SoftDeletePrefix is prepended to the name of a soft-deleted role
+const SoftDeletePrefix = "disabled-"
+
+// softDeleteDenied is the deny-all resource list applied to soft-deleted roles
+var softDeleteDenied = []string{"**/*"}
+
+// IsSoftDeleted reports whether a role has been soft-deleted
+func IsSoftDeleted(role models.Role) bool {
+	return strings.HasPrefix(role.Name, SoftDeletePrefix)
+}
+
+// ActiveRoles returns the roles that have not been soft-deleted
+func ActiveRoles(roles []models.Role) []models.Role {
+	active := make([]models.Role, 0, len(roles))
+	for _, role := range roles {
+		if !IsSoftDeleted(role) {
+			active = append(active, role)
+		}
+	}
+	return active
+}
+
+// SoftDeletedRole returns the disabled form of a role: renamed with SoftDeletePrefix
+// and with all resources denied. The ID and members are kept.
+func SoftDeletedRole(role models.Role) models.Role {
+	disabled := role
+	disabled.Name = SoftDeletePrefix + role.Name
+	disabled.Resources = models.Resources{
+		Allowed: []string{},
+		Denied:  append([]string{}, softDeleteDenied...),
+	}
+	return disabled
+}
+
+// ApplySoftDelete converts the deletions in a plan into updates that disable each role instead.
+// remote must contain every remote role, including already soft-deleted ones, so that name
+// collisions with an earlier soft-deleted role can be detected.
+func ApplySoftDelete(plan SyncPlan, remote []models.Role) (SyncPlan, error) {
+	if len(plan.Deletes) == 0 {
+		return plan, nil
+	}
+
+	remoteMap := make(map[string]models.Role)
+	for _, role := range remote {
+		remoteMap[role.Name] = role
+	}
+
+	result := SyncPlan{
+		Creates: plan.Creates,
+		Updates: append([]RoleUpdate{}, plan.Updates...),
+		Deletes: []string{},
+	}
+
+	for _, roleName := range plan.Deletes {
+		remoteRole, exists := remoteMap[roleName]
+		if !exists {
+			return SyncPlan{}, fmt.Errorf("cannot soft-delete role '%s': role not found on remote", roleName)
+		}
+
+		disabled := SoftDeletedRole(remoteRole)
+		if _, taken := remoteMap[disabled.Name]; taken {
+			return SyncPlan{}, fmt.Errorf("cannot soft-delete role '%s': a role named '%s' already exists", roleName, disabled.Name)
+		}
+
+		result.Updates = append(result.Updates, newRoleUpdate(roleName, disabled, remoteRole, ReasonNoLocalFile+"; soft-deleted as "+disabled.Name))
+	}
+
+	return result, nil
+}
@@ -0,0 +1,77 @@
+package sync
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"replbac/internal/models"
+)
+
+func TestApplySoftDelete(t *testing.T) {
+	remote := []models.Role{
+		{ID: "1", Name: "legacy", Resources: models.Resources{Allowed: []string{"read"}}, Members: []string{"a@example.com"}},
+		{ID: "2", Name: "old", Resources: models.Resources{Allowed: []string{"*"}}},
+		{ID: "3", Name: "disabled-old", Resources: models.Resources{Denied: []string{"**/*"}}},
+	}
+
+	tests := []struct {
+		name        string
+		plan        SyncPlan
+		wantUpdates []RoleUpdate
+		wantError   string
+	}{
+		{
+			name: "deletions become disabling updates",
+			plan: SyncPlan{Deletes: []string{"legacy"}},
+			wantUpdates: []RoleUpdate{
+				{
+					Name: "legacy",
+					Local: models.Role{
+						ID:        "1",
+						Name:      "disabled-legacy",
+						Resources: models.Resources{Allowed: []string{}, Denied: []string{"**/*"}},
+						Members:   []string{"a@example.com"},
+					},
+					Remote: remote[0],
+					Reason: "no local file; soft-deleted as disabled-legacy",
+				},
+			},
+		},
+		{
+			name:      "existing disabled role with the same name is an error",
+			plan:      SyncPlan{Deletes: []string{"old"}},
+			wantError: "'disabled-old' already exists",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ApplySoftDelete(tt.plan, remote)
+			if tt.wantError != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantError) {
+					t.Fatalf("ApplySoftDelete() error = %v, want error containing %q", err, tt.wantError)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ApplySoftDelete() unexpected error: %v", err)
+			}
+			if len(got.Deletes) != 0 {
+				t.Errorf("ApplySoftDelete() left deletes %v", got.Deletes)
+			}
+			if !reflect.DeepEqual(withoutChanges(t, got.Updates), tt.wantUpdates) {
+				t.Errorf("ApplySoftDelete() updates = %+v, want %+v", got.Updates, tt.wantUpdates)
+			}
+		})
+	}
+}
+
+func TestActiveRoles(t *testing.T) {
+	roles := []models.Role{{Name: "admin"}, {Name: "disabled-viewer"}, {Name: "viewer"}}
+
+	active := ActiveRoles(roles)
+	if len(active) != 2 || active[0].Name != "admin" || active[1].Name != "viewer" {
+		t.Errorf("ActiveRoles() = %+v, want admin and viewer", active)
+	}
+}
@@ -0,0 +1,198 @@
+package sync
+
+import (
+	"fmt"
+	"strings"
+
+	"replbac/internal/models"
+	"replbac/internal/roles"
+)
+
+// DefaultDiffContext is the number of unchanged lines shown around each change in a
+// unified diff, as with diff -U
+const DefaultDiffContext = 3
+
+// diffLine is one line of a line-by-line diff: ' ' for unchanged, '-' for a line only
+// in the old text, and '+' for a line only in the new text
+type diffLine struct {
+	op   byte
+	text string
+}
+
+// DescribePlanUnified renders each change in plan as a unified diff of the role's YAML,
+// from the remote role to the local one, showing context unchanged lines around each
+// change. remote supplies the content of roles the plan deletes. Lists are sorted and
+// IDs left out, since neither affects whether a role differs.
+func DescribePlanUnified(plan SyncPlan, remote []models.Role, context int) (string, error) {
+	if context < 0 {
+		return "", fmt.Errorf("diff context must be at least 0, got %d", context)
+	}
+	plan = sortedPlan(plan)
+
+	remoteByName := make(map[string]models.Role, len(remote))
+	for _, role := range remote {
+		remoteByName[role.Name] = role
+	}
+
+	var sections []string
+	addSection := func(oldLabel, newLabel string, oldRole, newRole *models.Role) error {
+		oldLines, err := roleYAMLLines(oldRole)
+		if err != nil {
+			return err
+		}
+		newLines, err := roleYAMLLines(newRole)
+		if err != nil {
+			return err
+		}
+		hunks := unifiedHunks(lineDiff(oldLines, newLines), context)
+		sections = append(sections, fmt.Sprintf("--- %s\n+++ %s\n%s", oldLabel, newLabel, strings.Join(hunks, "\n")))
+		return nil
+	}
+
+	for i := range plan.Creates {
+		role := plan.Creates[i]
+		if err := addSection("/dev/null", "local/"+role.Name, nil, &role); err != nil {
+			return "", err
+		}
+	}
+	for _, update := range plan.Updates {
+		local, remote := update.Local, update.Remote
+		if err := addSection("remote/"+remote.Name, "local/"+local.Name, &remote, &local); err != nil {
+			return "", err
+		}
+	}
+	for _, name := range plan.Deletes {
+		role := remoteByName[name]
+		if err := addSection("remote/"+name, "/dev/null", &role, nil); err != nil {
+			return "", err
+		}
+	}
+
+	return strings.Join(sections, "\n"), nil
+}
+
+// roleYAMLLines renders role as it would be written to a file, split into lines.
+// A nil role has no lines.
+func roleYAMLLines(role *models.Role) ([]string, error) {
+	if role == nil {
+		return nil, nil
+	}
+	withoutID := *role
+	withoutID.ID = ""
+	content, err := roles.GenerateRoleYAMLWithOptions(withoutID, roles.WriteOptions{SortLists: true})
+	if err != nil {
+		return nil, err
+	}
+	return strings.Split(strings.TrimSuffix(content, "\n"), "\n"), nil
+}
+
+// lineDiff returns the shortest edit turning oldLines into newLines, found through their
+// longest common subsequence. Role files are small enough for the quadratic table.
+func lineDiff(oldLines, newLines []string) []diffLine {
+	common := make([][]int, len(oldLines)+1)
+	for i := range common {
+		common[i] = make([]int, len(newLines)+1)
+	}
+	for i := len(oldLines) - 1; i >= 0; i-- {
+		for j := len(newLines) - 1; j >= 0; j-- {
+			if oldLines[i] == newLines[j] {
+				common[i][j] = common[i+1][j+1] + 1
+			} else {
+				common[i][j] = max(common[i+1][j], common[i][j+1])
+			}
+		}
+	}
+
+	lines := make([]diffLine, 0, len(oldLines)+len(newLines))
+	i, j := 0, 0
+	for i < len(oldLines) && j < len(newLines) {
+		switch {
+		case oldLines[i] == newLines[j]:
+			lines = append(lines, diffLine{' ', oldLines[i]})
+			i++
+			j++
+		case common[i+1][j] >= common[i][j+1]:
+			lines = append(lines, diffLine{'-', oldLines[i]})
+			i++
+		default:
+			lines = append(lines, diffLine{'+', newLines[j]})
+			j++
+		}
+	}
+	for ; i < len(oldLines); i++ {
+		lines = append(lines, diffLine{'-', oldLines[i]})
+	}
+	for ; j < len(newLines); j++ {
+		lines = append(lines, diffLine{'+', newLines[j]})
+	}
+	return lines
+}
+
+// unifiedHunks groups the changes in lines into hunks with up to context unchanged
+// lines on either side, merging hunks whose context would overlap
+func unifiedHunks(lines []diffLine, context int) []string {
+	// oldBefore[k] and newBefore[k] count the old and new lines preceding lines[k]
+	oldBefore := make([]int, len(lines)+1)
+	newBefore := make([]int, len(lines)+1)
+	for k, line := range lines {
+		oldBefore[k+1], newBefore[k+1] = oldBefore[k], newBefore[k]
+		if line.op != '+' {
+			oldBefore[k+1]++
+		}
+		if line.op != '-' {
+			newBefore[k+1]++
+		}
+	}
+
+	var hunks []string
+	for i := 0; i < len(lines); {
+		for i < len(lines) && lines[i].op == ' ' {
+			i++
+		}
+		if i == len(lines) {
+			break
+		}
+
+		start := max(i-context, 0)
+		end := i
+		for {
+			for end < len(lines) && lines[end].op != ' ' {
+				end++
+			}
+			next := end
+			for next < len(lines) && lines[next].op == ' ' {
+				next++
+			}
+			if next < len(lines) && next-end <= 2*context {
+				end = next
+				continue
+			}
+			end = min(end+context, len(lines))
+			break
+		}
+
+		body := make([]string, 0, end-start+1)
+		body = append(body, fmt.Sprintf("@@ -%s +%s @@",
+			hunkRange(oldBefore[start], oldBefore[end]-oldBefore[start]),
+			hunkRange(newBefore[start], newBefore[end]-newBefore[start])))
+		for _, line := range lines[start:end] {
+			body = append(body, string(line.op)+line.text)
+		}
+		hunks = append(hunks, strings.Join(body, "\n"))
+		i = end
+	}
+	return hunks
+}
+
+// hunkRange formats the line range of one side of a hunk header as diff does: the first
+// line (or the line before an empty range) and the count, omitted when it is 1
+func hunkRange(before, count int) string {
+	switch count {
+	case 0:
+		return fmt.Sprintf("%d,0", before)
+	case 1:
+		return fmt.Sprintf("%d", before+1)
+	default:
+		return fmt.Sprintf("%d,%d", before+1, count)
+	}
+}
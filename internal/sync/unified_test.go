@@ -0,0 +1,138 @@
+package sync
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"replbac/internal/models"
+)
+
+// numberedResources returns resource/0 through resource/n-1
+func numberedResources(n int) []string {
+	resources := make([]string, 0, n)
+	for i := 0; i < n; i++ {
+		resources = append(resources, fmt.Sprintf("resource/%d", i))
+	}
+	return resources
+}
+
+func TestDescribePlanUnified(t *testing.T) {
+	remoteAllowed := numberedResources(10)
+	localAllowed := append([]string{}, remoteAllowed...)
+	localAllowed[5] = "resource/5-changed"
+
+	plan := SyncPlan{
+		Creates: []models.Role{{Name: "new", Resources: models.Resources{Allowed: []string{"read"}, Denied: []string{}}}},
+		Updates: []RoleUpdate{{
+			Name:   "big",
+			Local:  models.Role{Name: "big", Resources: models.Resources{Allowed: localAllowed, Denied: []string{}}},
+			Remote: models.Role{ID: "1", Name: "big", Resources: models.Resources{Allowed: remoteAllowed, Denied: []string{}}},
+		}},
+		Deletes: []string{"old"},
+	}
+	remote := []models.Role{
+		plan.Updates[0].Remote,
+		{ID: "2", Name: "old", Resources: models.Resources{Allowed: []string{"read"}, Denied: []string{}}},
+	}
+
+	create := []string{
+		"--- /dev/null",
+		"+++ local/new",
+		"@@ -0,0 +1,5 @@",
+		"+name: new",
+		"+resources:",
+		"+    allowed:",
+		"+        - read",
+		"+    denied: []",
+	}
+	deletion := []string{
+		"--- remote/old",
+		"+++ /dev/null",
+		"@@ -1,5 +0,0 @@",
+		"-name: old",
+		"-resources:",
+		"-    allowed:",
+		"-        - read",
+		"-    denied: []",
+	}
+
+	tests := []struct {
+		context int
+		update  []string
+	}{
+		{
+			context: 3,
+			update: []string{
+				"--- remote/big",
+				"+++ local/big",
+				"@@ -6,7 +6,7 @@",
+				"         - resource/2",
+				"         - resource/3",
+				"         - resource/4",
+				"-        - resource/5",
+				"+        - resource/5-changed",
+				"         - resource/6",
+				"         - resource/7",
+				"         - resource/8",
+			},
+		},
+		{
+			context: 0,
+			update: []string{
+				"--- remote/big",
+				"+++ local/big",
+				"@@ -9 +9 @@",
+				"-        - resource/5",
+				"+        - resource/5-changed",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("context %d", tt.context), func(t *testing.T) {
+			got, err := DescribePlanUnified(plan, remote, tt.context)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			want := strings.Join(append(append(append([]string{}, create...), tt.update...), deletion...), "\n")
+			if got != want {
+				t.Errorf("DescribePlanUnified() =\n%s\nwant\n%s", got, want)
+			}
+		})
+	}
+
+	if _, err := DescribePlanUnified(plan, remote, -1); err == nil || !strings.Contains(err.Error(), "at least 0") {
+		t.Errorf("Expected an error for negative context, got %v", err)
+	}
+}
+
+func TestUnifiedHunks(t *testing.T) {
+	oldLines := numberedResources(20)
+	newLines := append([]string{}, oldLines...)
+	newLines[4] = "changed/4"
+	newLines[10] = "changed/10"
+	newLines = append(newLines[:18], newLines[19:]...)
+
+	tests := []struct {
+		context int
+		headers []string
+	}{
+		// Changes 5 lines apart share a hunk at context 3; the removal 7 lines later does not
+		{context: 3, headers: []string{"@@ -2,13 +2,13 @@", "@@ -16,5 +16,4 @@"}},
+		{context: 2, headers: []string{"@@ -3,5 +3,5 @@", "@@ -9,5 +9,5 @@", "@@ -17,4 +17,3 @@"}},
+		{context: 0, headers: []string{"@@ -5 +5 @@", "@@ -11 +11 @@", "@@ -19 +18,0 @@"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("context %d", tt.context), func(t *testing.T) {
+			var headers []string
+			for _, hunk := range unifiedHunks(lineDiff(oldLines, newLines), tt.context) {
+				headers = append(headers, strings.SplitN(hunk, "\n", 2)[0])
+			}
+			if strings.Join(headers, " ") != strings.Join(tt.headers, " ") {
+				t.Errorf("hunk headers = %v, want %v", headers, tt.headers)
+			}
+		})
+	}
+}